@@ -33,6 +33,14 @@ type Account struct {
 	URL     URL            `json:"url"`     // Optional resource locator within a backend
 }
 
+// Content types of the data signed through external signers, allowing the
+// signer to decode and display the data prior to signing it.
+const (
+	MimetypeTextPlain  = "text/plain"                // Arbitrary text, signed with the Ethereum message prefix
+	MimetypeWPoaHeader = "application/x-wpoa-header" // RLP encoded wpoa header without its seal
+	MimetypeDbft       = "application/x-dbft"        // dbft header seal hashes and PBFT messages
+)
+
 // Wallet represents a software or hardware wallet that might contain one or more
 // accounts (derived from the same seed).
 type Wallet interface {
@@ -0,0 +1,241 @@
+// Package external implements an account backend forwarding all signing requests
+// to an external clef-compatible signer over JSON-RPC.
+package external
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sync"
+
+	ethereum "github.com/bcos-one/BCOS"
+	"github.com/bcos-one/BCOS/accounts"
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/common/hexutil"
+	"github.com/bcos-one/BCOS/core/types"
+	"github.com/bcos-one/BCOS/event"
+	"github.com/bcos-one/BCOS/internal/ethapi"
+	"github.com/bcos-one/BCOS/log"
+	"github.com/bcos-one/BCOS/rpc"
+)
+
+// ExternalBackendType is the reflect type of the external signer backend.
+var ExternalBackendType = reflect.TypeOf(&ExternalBackend{})
+
+// ErrPassphraseNotSupported is returned when a passphrase is passed to the
+// external signer, which prompts its own user for any credentials.
+var ErrPassphraseNotSupported = errors.New("passphrases are not supported by external signers")
+
+// ErrHashNotSupported is returned when requesting an external signer to sign a
+// raw hash, which clef refuses to sign blindly.
+var ErrHashNotSupported = errors.New("signing raw hashes is not supported by external signers")
+
+// ExternalBackend is an account backend exposing the accounts of an external signer.
+type ExternalBackend struct {
+	signers []accounts.Wallet
+}
+
+// NewExternalBackend creates an account backend connected to the external
+// signer listening on the given endpoint.
+func NewExternalBackend(endpoint string) (*ExternalBackend, error) {
+	signer, err := NewExternalSigner(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &ExternalBackend{signers: []accounts.Wallet{signer}}, nil
+}
+
+// Wallets implements accounts.Backend, returning the external signer.
+func (eb *ExternalBackend) Wallets() []accounts.Wallet {
+	return eb.signers
+}
+
+// Subscribe implements accounts.Backend. The external signer never arrives nor
+// departs, so the subscription only waits to be unsubscribed.
+func (eb *ExternalBackend) Subscribe(sink chan<- accounts.WalletEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
+// ExternalSigner is a wallet forwarding the signing requests to an external signer,
+// which asks its own user, or its rules, for the approval of each request.
+type ExternalSigner struct {
+	client   *rpc.Client
+	endpoint string
+
+	cache   []accounts.Account // Accounts of the signer, listed upon the first request
+	cacheMu sync.RWMutex       // Protects the cached accounts
+}
+
+// NewExternalSigner creates a wallet connected to the external signer listening
+// on the given endpoint, which may be an IPC path or an HTTP url.
+func NewExternalSigner(endpoint string) (*ExternalSigner, error) {
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return newExternalSigner(client, endpoint), nil
+}
+
+// newExternalSigner wraps an already connected rpc client into an external signer.
+func newExternalSigner(client *rpc.Client, endpoint string) *ExternalSigner {
+	return &ExternalSigner{client: client, endpoint: endpoint}
+}
+
+// URL implements accounts.Wallet, returning the endpoint of the external signer.
+func (api *ExternalSigner) URL() accounts.URL {
+	return accounts.URL{Scheme: "extapi", Path: api.endpoint}
+}
+
+// Status implements accounts.Wallet, returning the number of accounts exposed by
+// the external signer.
+func (api *ExternalSigner) Status() (string, error) {
+	return fmt.Sprintf("Ok, %d accounts exposed", len(api.Accounts())), nil
+}
+
+// Open implements accounts.Wallet, but is a noop since the external signer
+// manages its own credentials.
+func (api *ExternalSigner) Open(passphrase string) error { return nil }
+
+// Close implements accounts.Wallet, disconnecting from the external signer.
+func (api *ExternalSigner) Close() error {
+	api.client.Close()
+	return nil
+}
+
+// Accounts implements accounts.Wallet, returning the accounts the external
+// signer is willing to expose.
+func (api *ExternalSigner) Accounts() []accounts.Account {
+	api.cacheMu.RLock()
+	cache := api.cache
+	api.cacheMu.RUnlock()
+
+	if cache != nil {
+		return cache
+	}
+	res, err := api.listAccounts()
+	if err != nil {
+		log.Error("Failed to list accounts of external signer", "endpoint", api.endpoint, "err", err)
+		return nil
+	}
+	accnts := make([]accounts.Account, 0, len(res))
+	for _, addr := range res {
+		accnts = append(accnts, accounts.Account{
+			Address: addr,
+			URL:     api.URL(),
+		})
+	}
+	api.cacheMu.Lock()
+	api.cache = accnts
+	api.cacheMu.Unlock()
+
+	return accnts
+}
+
+// Contains implements accounts.Wallet, returning whether the account is exposed
+// by the external signer.
+func (api *ExternalSigner) Contains(account accounts.Account) bool {
+	for _, a := range api.Accounts() {
+		if a.Address == account.Address && (account.URL == (accounts.URL{}) || account.URL == api.URL()) {
+			return true
+		}
+	}
+	return false
+}
+
+// Derive implements accounts.Wallet, but is not supported by external signers.
+func (api *ExternalSigner) Derive(path accounts.DerivationPath, pin bool) (accounts.Account, error) {
+	return accounts.Account{}, accounts.ErrNotSupported
+}
+
+// SelfDerive implements accounts.Wallet, but is a noop for external signers.
+func (api *ExternalSigner) SelfDerive(base accounts.DerivationPath, chain ethereum.ChainStateReader) {
+}
+
+// SignHash implements accounts.Wallet. The external signer refuses to sign raw
+// hashes, use SignData to sign data of a known content type instead.
+func (api *ExternalSigner) SignHash(account accounts.Account, hash []byte) ([]byte, error) {
+	return nil, ErrHashNotSupported
+}
+
+// SignData requests the external signer to sign the data of the given content
+// type, which allows the signer to decode the data for its user. The signature
+// is returned with V as 27 or 28.
+func (api *ExternalSigner) SignData(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+	var (
+		res  hexutil.Bytes
+		addr = common.NewMixedcaseAddress(account.Address)
+	)
+	if err := api.client.Call(&res, "account_signData", mimeType, &addr, hexutil.Bytes(data)); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// SignTx implements accounts.Wallet, requesting the external signer to sign the
+// transaction.
+func (api *ExternalSigner) SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	data := hexutil.Bytes(tx.Data())
+	args := &sendTxArgs{
+		From:     common.NewMixedcaseAddress(account.Address),
+		Gas:      hexutil.Uint64(tx.Gas()),
+		GasPrice: hexutil.Big(*tx.GasPrice()),
+		Value:    hexutil.Big(*tx.Value()),
+		Nonce:    hexutil.Uint64(tx.Nonce()),
+		Data:     &data,
+	}
+	if to := tx.To(); to != nil {
+		t := common.NewMixedcaseAddress(*to)
+		args.To = &t
+	}
+	if token := tx.Token(); token != nil {
+		t := common.NewMixedcaseAddress(*token)
+		args.Token = &t
+	}
+	var res ethapi.SignTransactionResult
+	if err := api.client.Call(&res, "account_signTransaction", args); err != nil {
+		return nil, err
+	}
+	// The external signer signs for its own chain, refuse signatures for another one
+	if chainID != nil && res.Tx.ChainId().Cmp(chainID) != 0 {
+		return nil, fmt.Errorf("external signer signed for chain %v, not %v", res.Tx.ChainId(), chainID)
+	}
+	return res.Tx, nil
+}
+
+// SignHashWithPassphrase implements accounts.Wallet, but passphrases are not
+// supported by external signers.
+func (api *ExternalSigner) SignHashWithPassphrase(account accounts.Account, passphrase string, hash []byte) ([]byte, error) {
+	return nil, ErrPassphraseNotSupported
+}
+
+// SignTxWithPassphrase implements accounts.Wallet, but passphrases are not
+// supported by external signers.
+func (api *ExternalSigner) SignTxWithPassphrase(account accounts.Account, passphrase string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return nil, ErrPassphraseNotSupported
+}
+
+// sendTxArgs represents the transaction arguments of account_signTransaction, see
+// core.SendTxArgs of the signer.
+type sendTxArgs struct {
+	From     common.MixedcaseAddress  `json:"from"`
+	To       *common.MixedcaseAddress `json:"to"`
+	Gas      hexutil.Uint64           `json:"gas"`
+	GasPrice hexutil.Big              `json:"gasPrice"`
+	Token    *common.MixedcaseAddress `json:"token"`
+	Value    hexutil.Big              `json:"value"`
+	Nonce    hexutil.Uint64           `json:"nonce"`
+	Data     *hexutil.Bytes           `json:"data"`
+}
+
+// listAccounts retrieves the addresses the external signer is willing to expose.
+func (api *ExternalSigner) listAccounts() ([]common.Address, error) {
+	var res []common.Address
+	if err := api.client.Call(&res, "account_list"); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
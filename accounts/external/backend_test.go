@@ -0,0 +1,128 @@
+package external
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/bcos-one/BCOS/accounts"
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/common/hexutil"
+	"github.com/bcos-one/BCOS/consensus"
+	"github.com/bcos-one/BCOS/core/types"
+	"github.com/bcos-one/BCOS/crypto"
+	"github.com/bcos-one/BCOS/internal/ethapi"
+	"github.com/bcos-one/BCOS/rpc"
+)
+
+// TestSigner is a mock of the account namespace of clef, approving all requests.
+type TestSigner struct {
+	key     *ecdsa.PrivateKey
+	chainID *big.Int
+}
+
+func (s *TestSigner) List() []common.Address {
+	return []common.Address{crypto.PubkeyToAddress(s.key.PublicKey)}
+}
+
+func (s *TestSigner) SignData(contentType string, addr common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error) {
+	sig, err := crypto.Sign(crypto.Keccak256(data), s.key)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+func (s *TestSigner) SignTransaction(raw json.RawMessage, methodSelector *string) (*ethapi.SignTransactionResult, error) {
+	var args sendTxArgs
+	if err := json.Unmarshal(raw, &args); err != nil {
+		return nil, err
+	}
+	tx := types.NewTransaction(uint64(args.Nonce), args.To.Address(), nil, (*big.Int)(&args.Value), uint64(args.Gas), (*big.Int)(&args.GasPrice), *args.Data)
+	signed, err := types.SignTx(tx, types.NewEIP155Signer(s.chainID), s.key)
+	if err != nil {
+		return nil, err
+	}
+	enc, _ := signed.MarshalBinary()
+	return &ethapi.SignTransactionResult{Raw: enc, Tx: signed}, nil
+}
+
+func newTestExternalSigner(t *testing.T) (*ExternalSigner, *TestSigner) {
+	key, _ := crypto.GenerateKey()
+	mock := &TestSigner{key: key, chainID: big.NewInt(1)}
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("account", mock); err != nil {
+		t.Fatalf("failed to register mock signer: %v", err)
+	}
+	return newExternalSigner(rpc.DialInProc(server), "inproc"), mock
+}
+
+// Tests that the accounts of the external signer are exposed and that consensus
+// data signed through it recovers to the sealing account.
+func TestExternalSignerConsensus(t *testing.T) {
+	signer, mock := newTestExternalSigner(t)
+	defer signer.Close()
+
+	addr := crypto.PubkeyToAddress(mock.key.PublicKey)
+	accnts := signer.Accounts()
+	if len(accnts) != 1 || accnts[0].Address != addr {
+		t.Fatalf("accounts mismatch: have %v, want [%x]", accnts, addr)
+	}
+	if !signer.Contains(accounts.Account{Address: addr}) {
+		t.Errorf("signer misses its own account")
+	}
+	if _, err := signer.SignHash(accnts[0], make([]byte, 32)); err != ErrHashNotSupported {
+		t.Errorf("raw hash signing error mismatch: have %v, want %v", err, ErrHashNotSupported)
+	}
+	sealer := consensus.NewAccountSigner(accnts[0], signer.SignData)
+	local := consensus.NewKeySigner(mock.key)
+
+	data := []byte("consensus message")
+	remoteSig, err := sealer.SignData(accounts.MimetypeDbft, data)
+	if err != nil {
+		t.Fatalf("failed to sign through external signer: %v", err)
+	}
+	localSig, err := local.SignData(accounts.MimetypeDbft, data)
+	if err != nil {
+		t.Fatalf("failed to sign with key: %v", err)
+	}
+	if !bytes.Equal(remoteSig, localSig) {
+		t.Errorf("signature mismatch: have %x, want %x", remoteSig, localSig)
+	}
+	pubkey, err := crypto.SigToPub(crypto.Keccak256(data), remoteSig)
+	if err != nil {
+		t.Fatalf("failed to recover signer: %v", err)
+	}
+	if have := crypto.PubkeyToAddress(*pubkey); have != sealer.Address() {
+		t.Errorf("recovered signer mismatch: have %x, want %x", have, sealer.Address())
+	}
+}
+
+// Tests that transactions are signed by the external signer, refusing signatures
+// for other chains.
+func TestExternalSignerSignTx(t *testing.T) {
+	signer, mock := newTestExternalSigner(t)
+	defer signer.Close()
+
+	account := signer.Accounts()[0]
+	tx := types.NewTransaction(1, common.Address{0x01}, nil, big.NewInt(10), 21000, big.NewInt(1), nil)
+
+	signed, err := signer.SignTx(account, tx, mock.chainID)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	from, err := types.Sender(types.NewEIP155Signer(mock.chainID), signed)
+	if err != nil {
+		t.Fatalf("failed to recover sender: %v", err)
+	}
+	if from != account.Address {
+		t.Errorf("sender mismatch: have %x, want %x", from, account.Address)
+	}
+	if _, err := signer.SignTx(account, tx, big.NewInt(2)); err == nil {
+		t.Errorf("signature for another chain accepted")
+	}
+}
@@ -156,6 +156,10 @@ func (w *trezorDriver) SignTx(path accounts.DerivationPath, tx *types.Transactio
 	if w.device == nil {
 		return common.Address{}, nil, accounts.ErrWalletClosed
 	}
+	// The Ethereum app only knows legacy transactions
+	if tx.Type() != types.LegacyTxType {
+		return common.Address{}, nil, types.ErrTxTypeNotSupported
+	}
 	return w.trezorSign(path, tx, chainID)
 }
 
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"sync/atomic"
@@ -29,6 +30,7 @@ import (
 	"github.com/bcos-one/BCOS/common"
 	"github.com/bcos-one/BCOS/console"
 	"github.com/bcos-one/BCOS/core"
+	"github.com/bcos-one/BCOS/core/rawdb"
 	"github.com/bcos-one/BCOS/core/state"
 	"github.com/bcos-one/BCOS/core/types"
 	"github.com/bcos-one/BCOS/eth/downloader"
@@ -36,10 +38,16 @@ import (
 	"github.com/bcos-one/BCOS/event"
 	"github.com/bcos-one/BCOS/log"
 	"github.com/bcos-one/BCOS/trie"
-	"github.com/syndtr/goleveldb/leveldb/util"
 	"gopkg.in/urfave/cli.v1"
 )
 
+var (
+	copydbRawFlag = cli.BoolFlag{
+		Name:  "raw",
+		Usage: "Copy the raw database entries instead of syncing the chain",
+	}
+)
+
 var (
 	initCommand = cli.Command{
 		Action:    utils.MigrateFlags(initGenesis),
@@ -131,15 +139,24 @@ The export-preimages command export hash preimages to an RLP encoded stream`,
 		ArgsUsage: "<sourceChaindataDir>",
 		Flags: []cli.Flag{
 			utils.DataDirFlag,
+			utils.DBEngineFlag,
 			utils.CacheFlag,
 			utils.SyncModeFlag,
 			utils.FakePoWFlag,
 			utils.TestnetFlag,
 			utils.RinkebyFlag,
+			copydbRawFlag,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
-The first argument must be the directory containing the blockchain to download from`,
+The first argument must be the directory containing the blockchain to download from.
+The source database is opened with the engine it was created with, the local chain
+is created with the one selected by --db.engine.
+
+With --raw all database entries are copied verbatim into an empty local database
+instead of syncing the chain, which migrates a datadir between storage engines:
+
+    bcos --datadir <newDatadir> --db.engine bolt copydb --raw <oldDatadir>/bcos/chaindata`,
 	}
 	removedbCommand = cli.Command{
 		Action:    utils.MigrateFlags(removeDB),
@@ -153,6 +170,43 @@ The first argument must be the directory containing the blockchain to download f
 		Description: `
 Remove blockchain and state databases`,
 	}
+	ancientCommand = cli.Command{
+		Name:     "ancient",
+		Usage:    "Manage the ancient store of immutable chain data",
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+Canonical blocks, receipts and headers older than --ancient.threshold blocks are
+moved out of the key-value database into an append-only ancient store inside the
+chaindata directory.`,
+		Subcommands: []cli.Command{
+			{
+				Name:      "inspect",
+				Usage:     "Print the contents and disk usage of the ancient store",
+				ArgsUsage: " ",
+				Action:    utils.MigrateFlags(inspectAncient),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.SyncModeFlag,
+				},
+				Description: `
+Print the number of frozen blocks and the size of every ancient table.`,
+			},
+			{
+				Name:      "repair",
+				Usage:     "Verify the ancient store and truncate any inconsistent data",
+				ArgsUsage: " ",
+				Action:    utils.MigrateFlags(repairAncient),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.SyncModeFlag,
+				},
+				Description: `
+Verify every frozen block against its hash and parent, truncating the ancient store
+at the first inconsistent one, and remove any copies of frozen blocks left in the
+key-value database.`,
+			},
+		},
+	}
 	dumpCommand = cli.Command{
 		Action:    utils.MigrateFlags(dump),
 		Name:      "dump",
@@ -245,19 +299,7 @@ func importChain(ctx *cli.Context) error {
 	fmt.Printf("Import done in %v.\n\n", time.Since(start))
 
 	// Output pre-compaction stats mostly to see the import trashing
-	db := chainDb.(*ethdb.LDBDatabase)
-
-	stats, err := db.LDB().GetProperty("leveldb.stats")
-	if err != nil {
-		utils.Fatalf("Failed to read database stats: %v", err)
-	}
-	fmt.Println(stats)
-
-	ioStats, err := db.LDB().GetProperty("leveldb.iostats")
-	if err != nil {
-		utils.Fatalf("Failed to read database iostats: %v", err)
-	}
-	fmt.Println(ioStats)
+	utils.PrintDatabaseStats(chainDb)
 
 	fmt.Printf("Trie cache misses:  %d\n", trie.CacheMisses())
 	fmt.Printf("Trie cache unloads: %d\n\n", trie.CacheUnloads())
@@ -278,22 +320,12 @@ func importChain(ctx *cli.Context) error {
 	// Compact the entire database to more accurately measure disk io and print the stats
 	start = time.Now()
 	fmt.Println("Compacting entire database...")
-	if err = db.LDB().CompactRange(util.Range{}); err != nil {
+	if err := chainDb.Compact(nil, nil); err != nil {
 		utils.Fatalf("Compaction failed: %v", err)
 	}
 	fmt.Printf("Compaction done in %v.\n\n", time.Since(start))
 
-	stats, err = db.LDB().GetProperty("leveldb.stats")
-	if err != nil {
-		utils.Fatalf("Failed to read database stats: %v", err)
-	}
-	fmt.Println(stats)
-
-	ioStats, err = db.LDB().GetProperty("leveldb.iostats")
-	if err != nil {
-		utils.Fatalf("Failed to read database iostats: %v", err)
-	}
-	fmt.Println(ioStats)
+	utils.PrintDatabaseStats(chainDb)
 
 	return nil
 }
@@ -336,7 +368,7 @@ func importPreimages(ctx *cli.Context) error {
 		utils.Fatalf("This command requires an argument.")
 	}
 	stack := makeFullNode(ctx)
-	diskdb := utils.MakeChainDatabase(ctx, stack).(*ethdb.LDBDatabase)
+	diskdb := utils.MakeChainDatabase(ctx, stack)
 
 	start := time.Now()
 	if err := utils.ImportPreimages(diskdb, ctx.Args().First()); err != nil {
@@ -352,7 +384,7 @@ func exportPreimages(ctx *cli.Context) error {
 		utils.Fatalf("This command requires an argument.")
 	}
 	stack := makeFullNode(ctx)
-	diskdb := utils.MakeChainDatabase(ctx, stack).(*ethdb.LDBDatabase)
+	diskdb := utils.MakeChainDatabase(ctx, stack)
 
 	start := time.Now()
 	if err := utils.ExportPreimages(diskdb, ctx.Args().First()); err != nil {
@@ -367,6 +399,26 @@ func copyDb(ctx *cli.Context) error {
 	if len(ctx.Args()) != 1 {
 		utils.Fatalf("Source chaindata directory path argument missing")
 	}
+	// Opening a missing directory would create an empty source database
+	if _, err := os.Stat(ctx.Args().First()); err != nil {
+		utils.Fatalf("Source chaindata directory unavailable: %v", err)
+	}
+	// Open the source database with whatever engine it was created with
+	db, err := ethdb.NewDatabase("", ctx.Args().First(), ctx.GlobalInt(utils.CacheFlag.Name), 256)
+	if err != nil {
+		return err
+	}
+	// Attach the source ancient store, if any, to reach the frozen blocks too
+	if ancient := filepath.Join(ctx.Args().First(), "ancient"); common.FileExist(ancient) {
+		if db, err = rawdb.NewDatabaseWithFreezer(db, ancient); err != nil {
+			utils.Fatalf("Could not open source ancient database: %v", err)
+		}
+	}
+	defer db.Close()
+
+	if ctx.GlobalBool(copydbRawFlag.Name) {
+		return copyDbRaw(ctx, db)
+	}
 	// Initialize a new chain for the running node to sync into
 	stack := makeFullNode(ctx)
 	chain, chainDb := utils.MakeChain(ctx, stack)
@@ -375,10 +427,6 @@ func copyDb(ctx *cli.Context) error {
 	dl := downloader.New(syncmode, chainDb, new(event.TypeMux), chain, nil, nil)
 
 	// Create a source peer to satisfy downloader requests from
-	db, err := ethdb.NewLDBDatabase(ctx.Args().First(), ctx.GlobalInt(utils.CacheFlag.Name), 256)
-	if err != nil {
-		return err
-	}
 	hc, err := core.NewHeaderChain(db, chain.Config(), chain.Engine(), func() bool { return false })
 	if err != nil {
 		return err
@@ -402,7 +450,7 @@ func copyDb(ctx *cli.Context) error {
 	// Compact the entire database to remove any sync overhead
 	start = time.Now()
 	fmt.Println("Compacting entire database...")
-	if err = chainDb.(*ethdb.LDBDatabase).LDB().CompactRange(util.Range{}); err != nil {
+	if err = chainDb.Compact(nil, nil); err != nil {
 		utils.Fatalf("Compaction failed: %v", err)
 	}
 	fmt.Printf("Compaction done in %v.\n\n", time.Since(start))
@@ -410,6 +458,119 @@ func copyDb(ctx *cli.Context) error {
 	return nil
 }
 
+// copyDbRaw copies every entry of the source database into the empty local chain
+// database, which may use a different storage engine.
+func copyDbRaw(ctx *cli.Context, src ethdb.Database) error {
+	stack := makeFullNode(ctx)
+	chainDb := utils.MakeChainDatabase(ctx, stack)
+	defer chainDb.Close()
+
+	it := chainDb.NewIteratorWithPrefix(nil)
+	empty := !it.Next()
+	it.Release()
+	if ancient, ok := chainDb.(rawdb.AncientReader); ok {
+		if frozen, _ := ancient.Ancients(); frozen > 0 {
+			empty = false
+		}
+	}
+	if !empty {
+		utils.Fatalf("Local chain database is not empty")
+	}
+	start := time.Now()
+
+	// Frozen blocks live outside of the key-value store, copy them separately
+	ancients, err := rawdb.CopyAncients(chainDb, src)
+	if err != nil {
+		return err
+	}
+
+	it = src.NewIteratorWithPrefix(nil)
+	defer it.Release()
+
+	var (
+		batch   = chainDb.NewBatch()
+		entries uint64
+		logged  = time.Now()
+	)
+	for it.Next() {
+		if err := batch.Put(it.Key(), it.Value()); err != nil {
+			return err
+		}
+		entries++
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Copying database", "entries", entries, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	fmt.Printf("Database copy of %d entries and %d frozen blocks done in %v\n", entries, ancients, time.Since(start))
+	return nil
+}
+
+func inspectAncient(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	db := utils.MakeChainDatabase(ctx, stack)
+	defer db.Close()
+
+	stats := rawdb.InspectAncients(db)
+	if stats == nil {
+		utils.Fatalf("No ancient store attached to the database")
+	}
+	var total common.StorageSize
+
+	fmt.Printf("%-10s %12s %12s\n", "Table", "Items", "Size")
+	for _, stat := range stats {
+		fmt.Printf("%-10s %12d %12s\n", stat.Kind, stat.Items, common.StorageSize(stat.Size))
+		total += common.StorageSize(stat.Size)
+	}
+	fmt.Printf("%-10s %12s %12s\n\n", "Total", "", total)
+
+	frozen, _ := db.(rawdb.AncientReader).Ancients()
+	if frozen == 0 {
+		fmt.Println("Frozen blocks: none")
+	} else {
+		fmt.Printf("Frozen blocks: #0 - #%d [%x…]\n", frozen-1, rawdb.ReadCanonicalHash(db, frozen-1).Bytes()[:4])
+	}
+	if head := rawdb.ReadHeadBlockHash(db); head != (common.Hash{}) {
+		if number := rawdb.ReadHeaderNumber(db, head); number != nil {
+			fmt.Printf("Head block:    #%d [%x…]\n", *number, head.Bytes()[:4])
+		}
+	}
+	return nil
+}
+
+func repairAncient(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	db := utils.MakeChainDatabase(ctx, stack)
+	defer db.Close()
+
+	start := time.Now()
+	frozen, err := rawdb.RepairAncients(db)
+	if err != nil {
+		utils.Fatalf("Ancient store repair failed: %v", err)
+	}
+	fmt.Printf("Ancient store verified in %v, %d blocks frozen\n", time.Since(start), frozen)
+
+	// A truncated ancient store leaves a gap below the recent blocks
+	if head := rawdb.ReadHeadBlockHash(db); head != (common.Hash{}) {
+		if number := rawdb.ReadHeaderNumber(db, head); number != nil && *number >= frozen && rawdb.ReadCanonicalHash(db, frozen) == (common.Hash{}) {
+			log.Warn("Chain data missing above the ancient store, resync required", "number", frozen, "head", *number)
+		}
+	}
+	return nil
+}
+
 func removeDB(ctx *cli.Context) error {
 	stack, _ := makeConfigNode(ctx)
 
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/bcos-one/BCOS/cmd/utils"
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/core/rawdb"
+	"github.com/bcos-one/BCOS/core/state"
+	"github.com/bcos-one/BCOS/core/state/pruner"
+	"github.com/bcos-one/BCOS/log"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// pruneRecentBlocks is the number of recent blocks whose states on disk are
+// retained by default, matching the window a non-archive node keeps in memory.
+const pruneRecentBlocks = 128
+
+var (
+	snapshotCommand = cli.Command{
+		Name:     "snapshot",
+		Usage:    "A set of commands based on the state of the chain",
+		Category: "BLOCKCHAIN COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Name:      "prune-state",
+				Usage:     "Prune stale state data",
+				ArgsUsage: "[<root>]",
+				Action:    utils.MigrateFlags(pruneState),
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.TestnetFlag,
+					utils.RinkebyFlag,
+				},
+				Description: `
+bcos snapshot prune-state [<root>]
+
+Deletes all state trie nodes and contract codes that are not reachable from the
+retained state roots, including the storage and token balance tries of every
+account. Without an argument the states of the recent blocks still present on
+disk are retained, otherwise only the state of the given root.
+
+The node must not be running. The command can be interrupted at any time without
+damaging the retained states and restarted later.`,
+			},
+		},
+	}
+)
+
+func pruneState(ctx *cli.Context) error {
+	if len(ctx.Args()) > 1 {
+		utils.Fatalf("Too many arguments given")
+	}
+	stack, _ := makeConfigNode(ctx)
+	chaindb := utils.MakeChainDatabase(ctx, stack)
+	defer chaindb.Close()
+
+	var (
+		sdb   = state.NewDatabase(chaindb)
+		roots []common.Hash
+	)
+	retain := func(root common.Hash) bool {
+		for _, have := range roots {
+			if have == root {
+				return true
+			}
+		}
+		if _, err := state.New(root, sdb); err != nil {
+			return false
+		}
+		roots = append(roots, root)
+		return true
+	}
+	if len(ctx.Args()) == 1 {
+		root := common.HexToHash(ctx.Args().First())
+		if !retain(root) {
+			utils.Fatalf("State root %x not found", root)
+		}
+	} else {
+		head := rawdb.ReadHeadBlockHash(chaindb)
+		number := rawdb.ReadHeaderNumber(chaindb, head)
+		if number == nil {
+			utils.Fatalf("No head block found")
+		}
+		for n := *number; n+pruneRecentBlocks > *number; n-- {
+			if header := rawdb.ReadHeader(chaindb, rawdb.ReadCanonicalHash(chaindb, n), n); header != nil {
+				retain(header.Root)
+			}
+			if n == 0 {
+				break
+			}
+		}
+		if len(roots) == 0 {
+			utils.Fatalf("No recent state found on disk")
+		}
+		// Keep the genesis state around too, it's cheap and used for chain repairs
+		if genesis := rawdb.ReadHeader(chaindb, rawdb.ReadCanonicalHash(chaindb, 0), 0); genesis != nil {
+			retain(genesis.Root)
+		}
+	}
+	log.Info("Pruning state data", "retained", len(roots))
+
+	// Stop at the next progress report on Ctrl-C, keeping the retained states intact
+	var (
+		sigc      = make(chan os.Signal, 1)
+		interrupt = make(chan struct{})
+	)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+	go func() {
+		<-sigc
+		log.Info("Interrupted during pruning, stopping at next checkpoint")
+		close(interrupt)
+	}()
+	if err := pruner.NewPruner(chaindb, roots).Prune(interrupt); err != nil {
+		utils.Fatalf("Failed to prune state: %v", err)
+	}
+	return nil
+}
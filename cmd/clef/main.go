@@ -50,10 +50,10 @@ import (
 )
 
 // ExternalAPIVersion -- see extapi_changelog.md
-const ExternalAPIVersion = "4.0.0"
+const ExternalAPIVersion = "4.3.0"
 
 // InternalAPIVersion -- see intapi_changelog.md
-const InternalAPIVersion = "3.0.0"
+const InternalAPIVersion = "3.3.0"
 
 const legalWarning = `
 WARNING! 
@@ -105,6 +105,10 @@ var (
 		Usage: "File used for writing new 4byte-identifiers submitted via API",
 		Value: "./4byte-custom.json",
 	}
+	expansionsFlag = cli.StringFlag{
+		Name:  "expansions",
+		Usage: "File containing the BCOS expansion storages and known tokens of the chain",
+	}
 	auditLogFlag = cli.StringFlag{
 		Name:  "auditlog",
 		Usage: "File used to emit audit logs. Set to \"\" to disable",
@@ -193,6 +197,7 @@ func init() {
 		signerSecretFlag,
 		dBFlag,
 		customDBFlag,
+		expansionsFlag,
 		auditLogFlag,
 		ruleFlag,
 		stdiouiFlag,
@@ -358,6 +363,14 @@ func signer(c *cli.Context) error {
 	}
 	log.Info("Loaded 4byte db", "signatures", db.Size(), "file", fourByteDb, "local", fourByteLocal)
 
+	var expansions *core.Expansions
+	if file := c.GlobalString(expansionsFlag.Name); file != "" {
+		if expansions, err = core.NewExpansionsFromFile(file); err != nil {
+			utils.Fatalf(err.Error())
+		}
+		log.Info("Loaded expansions", "tokens", len(expansions.Tokens), "file", file)
+	}
+
 	var (
 		api core.ExternalAPI
 	)
@@ -410,7 +423,7 @@ func signer(c *cli.Context) error {
 		c.GlobalInt64(utils.NetworkIdFlag.Name),
 		c.GlobalString(keystoreFlag.Name),
 		c.GlobalBool(utils.NoUSBFlag.Name),
-		ui, db,
+		ui, db, expansions,
 		c.GlobalBool(utils.LightKDFFlag.Name),
 		c.GlobalBool(advancedMode.Name))
 	api = apiImpl
@@ -441,7 +454,7 @@ func signer(c *cli.Context) error {
 
 		// start http server
 		httpEndpoint := fmt.Sprintf("%s:%d", c.GlobalString(utils.RPCListenAddrFlag.Name), c.Int(rpcPortFlag.Name))
-		listener, _, err := rpc.StartHTTPEndpoint(httpEndpoint, rpcAPI, []string{"account"}, cors, vhosts, rpc.DefaultHTTPTimeouts)
+		listener, _, err := rpc.StartHTTPEndpoint(httpEndpoint, rpcAPI, []string{"account"}, cors, vhosts, rpc.DefaultHTTPTimeouts, nil, rpc.Limits{})
 		if err != nil {
 			utils.Fatalf("Could not start RPC api: %v", err)
 		}
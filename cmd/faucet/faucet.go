@@ -44,6 +44,7 @@ import (
 	"github.com/bcos-one/BCOS/accounts"
 	"github.com/bcos-one/BCOS/accounts/keystore"
 	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/common/hexutil"
 	"github.com/bcos-one/BCOS/core"
 	"github.com/bcos-one/BCOS/core/types"
 	"github.com/bcos-one/BCOS/eth"
@@ -58,6 +59,7 @@ import (
 	"github.com/bcos-one/BCOS/p2p/enode"
 	"github.com/bcos-one/BCOS/p2p/nat"
 	"github.com/bcos-one/BCOS/params"
+	"github.com/bcos-one/BCOS/rpc"
 	"golang.org/x/net/websocket"
 )
 
@@ -71,6 +73,7 @@ var (
 
 	netnameFlag = flag.String("faucet.name", "", "Network name to assign to the faucet")
 	payoutFlag  = flag.Int("faucet.amount", 1, "Number of Ethers to pay out per user request")
+	tokensFlag  = flag.String("faucet.tokens", "", "Comma separated native tokens to pay out per user request (id:amount, in the smallest unit)")
 	minutesFlag = flag.Int("faucet.minutes", 1440, "Number of minutes to wait between funding rounds")
 	tiersFlag   = flag.Int("faucet.tiers", 3, "Number of funding tiers to enable (x3 time, x2.5 funds)")
 
@@ -93,15 +96,33 @@ func main() {
 	flag.Parse()
 	log.Root().SetHandler(log.LvlFilterHandler(log.Lvl(*logFlag), log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
 
+	// Parse the native tokens to pay out along with the Ethers
+	tokens, err := parseTokens(*tokensFlag)
+	if err != nil {
+		log.Crit("Failed to parse token payouts", "err", err)
+	}
+	if *payoutFlag <= 0 && len(tokens) == 0 {
+		log.Crit("Faucet has nothing to pay out, set an Ether amount or tokens")
+	}
 	// Construct the payout tiers
-	amounts := make([]string, *tiersFlag)
+	payouts := make([][]payout, *tiersFlag)
 	periods := make([]string, *tiersFlag)
 	for i := 0; i < *tiersFlag; i++ {
-		// Calculate the amount for the next tier and format it
-		amount := float64(*payoutFlag) * math.Pow(2.5, float64(i))
-		amounts[i] = fmt.Sprintf("%s Ethers", strconv.FormatFloat(amount, 'f', -1, 64))
-		if amount == 1 {
-			amounts[i] = strings.TrimSuffix(amounts[i], "s")
+		// Calculate the amounts for the next tier and format them
+		if *payoutFlag > 0 {
+			amount := float64(*payoutFlag) * math.Pow(2.5, float64(i))
+			unit := "Ethers"
+			if amount == 1 {
+				unit = "Ether"
+			}
+			payouts[i] = append(payouts[i], payout{Amount: strconv.FormatFloat(amount, 'f', -1, 64), Unit: unit})
+		}
+		for _, token := range tokens {
+			payouts[i] = append(payouts[i], payout{
+				Amount: tierAmount(token.amount, uint(i)).String(),
+				Unit:   token.unit(),
+				Token:  token.id.Hex(),
+			})
 		}
 		// Calculate the period for the next tier and format it
 		period := *minutesFlag * int(math.Pow(3, float64(i)))
@@ -120,6 +141,10 @@ func main() {
 		}
 	}
 	// Load up and render the faucet website
+	units := make([]payout, len(tokens))
+	for i, token := range tokens {
+		units[i] = payout{Unit: token.unit(), Token: token.id.Hex()}
+	}
 	tmpl, err := Asset("faucet.html")
 	if err != nil {
 		log.Crit("Failed to load the faucet template", "err", err)
@@ -127,7 +152,8 @@ func main() {
 	website := new(bytes.Buffer)
 	err = template.Must(template.New("").Parse(string(tmpl))).Execute(website, map[string]interface{}{
 		"Network":   *netnameFlag,
-		"Amounts":   amounts,
+		"Payouts":   payouts,
+		"Tokens":    units,
 		"Periods":   periods,
 		"Recaptcha": *captchaToken,
 		"NoAuth":    *noauthFlag,
@@ -171,7 +197,7 @@ func main() {
 	ks.Unlock(acc, pass)
 
 	// Assemble and start the faucet light service
-	faucet, err := newFaucet(genesis, *ethPortFlag, enodes, *netFlag, *statsFlag, ks, website.Bytes())
+	faucet, err := newFaucet(genesis, *ethPortFlag, enodes, *netFlag, *statsFlag, ks, tokens, website.Bytes())
 	if err != nil {
 		log.Crit("Failed to start faucet", "err", err)
 	}
@@ -182,20 +208,74 @@ func main() {
 	}
 }
 
+// payout is a single amount paid out per request, as rendered on the website.
+type payout struct {
+	Amount string // Amount paid out, in Ethers or the smallest unit of the token
+	Unit   string // Unit of the amount, the token name if known
+	Token  string // Hex identifier of the native token, empty for Ethers
+}
+
+// tokenPayout is a native token paid out along with the Ethers.
+type tokenPayout struct {
+	id     common.Address // Identifier of the native token
+	amount *big.Int       // Amount to pay out in the first tier, in the smallest unit
+
+	name    string   // Name the token was issued with, once retrieved
+	balance *big.Int // Current token balance of the faucet
+}
+
+// unit returns the name to display the token amounts with until its real name
+// is retrieved from the chain.
+func (t *tokenPayout) unit() string {
+	if t.name != "" {
+		return t.name
+	}
+	return t.id.Hex()[:10]
+}
+
+// parseTokens parses the comma separated id:amount list of native tokens to pay
+// out per user request.
+func parseTokens(spec string) ([]*tokenPayout, error) {
+	var tokens []*tokenPayout
+	for _, entry := range strings.Split(spec, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) != 2 || !common.IsHexAddress(parts[0]) {
+			return nil, fmt.Errorf("invalid token payout %q, want id:amount", entry)
+		}
+		amount, ok := new(big.Int).SetString(parts[1], 10)
+		if !ok || amount.Sign() <= 0 {
+			return nil, fmt.Errorf("invalid token payout amount %q", parts[1])
+		}
+		tokens = append(tokens, &tokenPayout{id: common.HexToAddress(parts[0]), amount: amount})
+	}
+	return tokens, nil
+}
+
+// tierAmount scales the amount paid out in the first tier up to the given tier.
+func tierAmount(amount *big.Int, tier uint) *big.Int {
+	amount = new(big.Int).Mul(amount, new(big.Int).Exp(big.NewInt(5), big.NewInt(int64(tier)), nil))
+	return amount.Div(amount, new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(tier)), nil))
+}
+
 // request represents an accepted funding request.
 type request struct {
-	Avatar  string             `json:"avatar"`  // Avatar URL to make the UI nicer
-	Account common.Address     `json:"account"` // Ethereum address being funded
-	Time    time.Time          `json:"time"`    // Timestamp when the request was accepted
-	Tx      *types.Transaction `json:"tx"`      // Transaction funding the account
+	Avatar  string               `json:"avatar"`  // Avatar URL to make the UI nicer
+	Account common.Address       `json:"account"` // Ethereum address being funded
+	Time    time.Time            `json:"time"`    // Timestamp when the request was accepted
+	Txs     []*types.Transaction `json:"txs"`     // Transactions funding the account
 }
 
 // faucet represents a crypto faucet backed by an Ethereum light client.
 type faucet struct {
 	config *params.ChainConfig // Chain configurations for signing
 	stack  *node.Node          // Ethereum protocol stack
+	api    *rpc.Client         // Raw RPC connection to the Ethereum chain
 	client *ethclient.Client   // Client connection to the Ethereum chain
 	index  []byte              // Index page to serve up on the web
+	tokens []*tokenPayout      // Native tokens to pay out along with the Ethers
 
 	keystore *keystore.KeyStore // Keystore containing the single signer
 	account  accounts.Account   // Account funding user faucet requests
@@ -212,7 +292,7 @@ type faucet struct {
 	lock sync.RWMutex // Lock protecting the faucet's internals
 }
 
-func newFaucet(genesis *core.Genesis, port int, enodes []*discv5.Node, network uint64, stats string, ks *keystore.KeyStore, index []byte) (*faucet, error) {
+func newFaucet(genesis *core.Genesis, port int, enodes []*discv5.Node, network uint64, stats string, ks *keystore.KeyStore, tokens []*tokenPayout, index []byte) (*faucet, error) {
 	// Assemble the raw devp2p protocol stack
 	stack, err := node.New(&node.Config{
 		Name:    "geth",
@@ -271,8 +351,10 @@ func newFaucet(genesis *core.Genesis, port int, enodes []*discv5.Node, network u
 	return &faucet{
 		config:   genesis.Config,
 		stack:    stack,
+		api:      api,
 		client:   client,
 		index:    index,
+		tokens:   tokens,
 		keystore: ks,
 		account:  ks.Accounts()[0],
 		timeouts: make(map[string]time.Time),
@@ -350,8 +432,13 @@ func (f *faucet) apiHandler(conn *websocket.Conn) {
 		}
 	}
 	// Send over the initial stats and the latest header
+	f.lock.RLock()
+	tokens := f.tokenStats()
+	f.lock.RUnlock()
+
 	if err = send(conn, map[string]interface{}{
 		"funds":    new(big.Int).Div(balance, ether),
+		"tokens":   tokens,
 		"funded":   nonce,
 		"peers":    f.stack.Server().PeerCount(),
 		"requests": f.reqs,
@@ -467,13 +554,26 @@ func (f *faucet) apiHandler(conn *websocket.Conn) {
 			timeout time.Time
 		)
 		if timeout = f.timeouts[username]; time.Now().After(timeout) {
-			// User wasn't funded recently, create the funding transaction
-			amount := new(big.Int).Mul(big.NewInt(int64(*payoutFlag)), ether)
-			amount = new(big.Int).Mul(amount, new(big.Int).Exp(big.NewInt(5), big.NewInt(int64(msg.Tier)), nil))
-			amount = new(big.Int).Div(amount, new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(msg.Tier)), nil))
-
-			tx := types.NewTransaction(f.nonce+uint64(len(f.reqs)), address, nil, amount, 21000, f.price, nil)
-			signed, err := f.keystore.SignTx(f.account, tx, f.config.ChainID)
+			// User wasn't funded recently, create the funding transactions
+			nonce := f.nonce
+			for _, req := range f.reqs {
+				nonce += uint64(len(req.Txs))
+			}
+			var txs []*types.Transaction
+			if *payoutFlag > 0 {
+				amount := tierAmount(new(big.Int).Mul(big.NewInt(int64(*payoutFlag)), ether), msg.Tier)
+				txs = append(txs, types.NewTransaction(nonce, address, nil, amount, 21000, f.price, nil))
+			}
+			for _, token := range f.tokens {
+				id := token.id
+				txs = append(txs, types.NewTransaction(nonce+uint64(len(txs)), address, &id, tierAmount(token.amount, msg.Tier), 21000, f.price, nil))
+			}
+			signed := make([]*types.Transaction, len(txs))
+			for i, tx := range txs {
+				if signed[i], err = f.keystore.SignTx(f.account, tx, f.config.ChainID); err != nil {
+					break
+				}
+			}
 			if err != nil {
 				f.lock.Unlock()
 				if err = sendError(conn, err); err != nil {
@@ -482,26 +582,38 @@ func (f *faucet) apiHandler(conn *websocket.Conn) {
 				}
 				continue
 			}
-			// Submit the transaction and mark as funded if successful
-			if err := f.client.SendTransaction(context.Background(), signed); err != nil {
-				f.lock.Unlock()
-				if err = sendError(conn, err); err != nil {
-					log.Warn("Failed to send transaction transmission error to client", "err", err)
-					return
+			// Submit the transactions and mark as funded if any went out. A failed
+			// submission leaves a nonce gap, so the remaining ones are dropped.
+			var sent int
+			for ; sent < len(signed); sent++ {
+				if err = f.client.SendTransaction(context.Background(), signed[sent]); err != nil {
+					break
 				}
-				continue
 			}
-			f.reqs = append(f.reqs, &request{
-				Avatar:  avatar,
-				Account: address,
-				Time:    time.Now(),
-				Tx:      signed,
-			})
-			f.timeouts[username] = time.Now().Add(time.Duration(*minutesFlag*int(math.Pow(3, float64(msg.Tier)))) * time.Minute)
-			fund = true
+			if sent > 0 {
+				f.reqs = append(f.reqs, &request{
+					Avatar:  avatar,
+					Account: address,
+					Time:    time.Now(),
+					Txs:     signed[:sent],
+				})
+				f.timeouts[username] = time.Now().Add(time.Duration(*minutesFlag*int(math.Pow(3, float64(msg.Tier)))) * time.Minute)
+				fund = true
+			}
 		}
 		f.lock.Unlock()
 
+		// Report any failed submission, even if the request was partially funded
+		if err != nil {
+			if err = sendError(conn, err); err != nil {
+				log.Warn("Failed to send transaction transmission error to client", "err", err)
+				return
+			}
+			if !fund {
+				continue
+			}
+		}
+
 		// Send an error if too frequent funding, othewise a success
 		if !fund {
 			if err = sendError(conn, fmt.Errorf("%s left until next allowance", common.PrettyDuration(timeout.Sub(time.Now())))); err != nil { // nolint: gosimple
@@ -547,14 +659,43 @@ func (f *faucet) refresh(head *types.Header) error {
 	if nonce, err = f.client.NonceAt(ctx, f.account.Address, head.Number); err != nil {
 		return err
 	}
-	if price, err = f.client.SuggestGasPrice(ctx); err != nil {
+	// Chains without gas fees reject any transaction with a non-zero price
+	if config := f.config.GasFeeConfig; config != nil && config.IsGaspriceZero {
+		price = new(big.Int)
+	} else if price, err = f.client.SuggestGasPrice(ctx); err != nil {
 		return err
 	}
+	// Retrieve the token balances, and the token names not yet known
+	balances := make([]*big.Int, len(f.tokens))
+	for i, token := range f.tokens {
+		if balances[i], err = f.client.TokenBalanceAt(ctx, f.account.Address, token.id, head.Number); err != nil {
+			return err
+		}
+	}
+	names := make([]string, len(f.tokens))
+	for i, token := range f.tokens {
+		f.lock.RLock()
+		names[i] = token.name
+		f.lock.RUnlock()
+
+		if names[i] == "" {
+			var info struct {
+				Name string `json:"name"`
+			}
+			if err := f.api.CallContext(ctx, &info, "eth_getToken", token.id, hexutil.EncodeBig(head.Number)); err != nil {
+				log.Debug("Failed to retrieve token name", "token", token.id, "err", err)
+			}
+			names[i] = info.Name
+		}
+	}
 	// Everything succeeded, update the cached stats and eject old requests
 	f.lock.Lock()
 	f.head, f.balance = head, balance
 	f.price, f.nonce = price, nonce
-	for len(f.reqs) > 0 && f.reqs[0].Tx.Nonce() < f.nonce {
+	for i, token := range f.tokens {
+		token.name, token.balance = names[i], balances[i]
+	}
+	for len(f.reqs) > 0 && f.reqs[0].Txs[len(f.reqs[0].Txs)-1].Nonce() < f.nonce {
 		f.reqs = f.reqs[1:]
 	}
 	f.lock.Unlock()
@@ -562,6 +703,25 @@ func (f *faucet) refresh(head *types.Header) error {
 	return nil
 }
 
+// tokenStats assembles the token balances of the faucet to report to clients.
+//
+// Note, this method assumes the faucet lock is held!
+func (f *faucet) tokenStats() []map[string]interface{} {
+	stats := make([]map[string]interface{}, 0, len(f.tokens))
+	for _, token := range f.tokens {
+		funds := "?"
+		if token.balance != nil {
+			funds = token.balance.String()
+		}
+		stats = append(stats, map[string]interface{}{
+			"id":    token.id.Hex(),
+			"name":  token.unit(),
+			"funds": funds,
+		})
+	}
+	return stats
+}
+
 // loop keeps waiting for interesting events and pushes them out to connected
 // websockets.
 func (f *faucet) loop() {
@@ -593,11 +753,13 @@ func (f *faucet) loop() {
 			log.Info("Updated faucet state", "number", head.Number, "hash", head.Hash(), "age", common.PrettyAge(timestamp), "balance", f.balance, "nonce", f.nonce, "price", f.price)
 
 			balance := new(big.Int).Div(f.balance, ether)
+			tokens := f.tokenStats()
 			peers := f.stack.Server().PeerCount()
 
 			for _, conn := range f.conns {
 				if err := send(conn, map[string]interface{}{
 					"funds":    balance,
+					"tokens":   tokens,
 					"funded":   f.nonce,
 					"peers":    peers,
 					"requests": f.reqs,
@@ -67,7 +67,7 @@ func (fi bindataFileInfo) Sys() interface{} {
 	return nil
 }
 
-var _faucetHtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xcc\x5a\x71\x73\xdb\xb6\x92\xff\xdb\xf9\x14\x5b\x5e\xfc\x24\x9d\x4d\x52\xb6\x93\x3c\x9f\x44\xaa\x93\x97\xd7\xd7\xcb\xcd\x5d\xdb\x69\xd3\xb9\x7b\xd3\xd7\xb9\x01\x89\x95\x88\x18\x04\x58\x00\x94\xac\x7a\xf4\xdd\x6f\x00\x90\x14\x29\xc9\x6e\xd2\xe4\xde\x34\x7f\x38\x24\xb0\xd8\x5d\xec\xfe\x16\xbb\x58\x2a\xf9\xe2\xaf\xdf\xbe\x79\xf7\xf7\xef\xbe\x82\xc2\x94\x7c\xf1\x2c\xb1\xff\x01\x27\x62\x95\x06\x28\x82\xc5\xb3\xb3\xa4\x40\x42\x17\xcf\xce\xce\x92\x12\x0d\x81\xbc\x20\x4a\xa3\x49\x83\xda\x2c\xc3\xdb\x60\x3f\x51\x18\x53\x85\xf8\x4b\xcd\xd6\x69\xf0\x3f\xe1\x8f\xaf\xc3\x37\xb2\xac\x88\x61\x19\xc7\x00\x72\x29\x0c\x0a\x93\x06\x6f\xbf\x4a\x91\xae\xb0\xb7\x4e\x90\x12\xd3\x60\xcd\x70\x53\x49\x65\x7a\xa4\x1b\x46\x4d\x91\x52\x5c\xb3\x1c\x43\xf7\x72\x09\x4c\x30\xc3\x08\x0f\x75\x4e\x38\xa6\x57\xc1\xe2\x99\xe5\x63\x98\xe1\xb8\x78\x78\x88\xbe\x41\xb3\x91\xea\x6e\xb7\x9b\xc1\xeb\xda\x14\x28\x0c\xcb\x89\x41\x0a\x7f\x23\x75\x8e\x26\x89\x3d\xa5\x5b\xc4\x99\xb8\x83\x42\xe1\x32\x0d\xac\xea\x7a\x16\xc7\x39\x15\xef\x75\x94\x73\x59\xd3\x25\x27\x0a\xa3\x5c\x96\x31\x79\x4f\xee\x63\xce\x32\x1d\x9b\x0d\x33\x06\x55\x98\x49\x69\xb4\x51\xa4\x8a\x6f\xa2\x9b\xe8\xcf\x71\xae\x75\xdc\x8d\x45\x25\x13\x51\xae\x75\x00\x0a\x79\x1a\x68\xb3\xe5\xa8\x0b\x44\x13\x40\xbc\xf8\x7d\x72\x97\x52\x98\x90\x6c\x50\xcb\x12\xe3\x17\xd1\x9f\xa3\xa9\x13\xd9\x1f\x7e\x5a\xaa\x15\xab\x73\xc5\x2a\x03\x5a\xe5\x1f\x2c\xf7\xfd\x2f\x35\xaa\x6d\x7c\x13\x5d\x45\x57\xcd\x8b\x93\xf3\x5e\x07\x8b\x24\xf6\x0c\x17\x9f\xc4\x3b\x14\xd2\x6c\xe3\xeb\xe8\x45\x74\x15\x57\x24\xbf\x23\x2b\xa4\xad\x24\x3b\x15\xb5\x83\x9f\x4d\xee\x63\x3e\x7c\x7f\xe8\xc2\xcf\x21\xac\x94\x25\x0a\x13\xbd\xd7\xf1\x75\x74\x75\x1b\x4d\xdb\x81\x63\xfe\x4e\x80\x75\x9a\x15\x75\x16\xad\x51\x59\xe4\xf2\x30\x47\x61\x50\xc1\x83\x1d\x3d\x2b\x99\x08\x0b\x64\xab\xc2\xcc\xe0\x6a\x3a\x3d\x9f\x9f\x1a\x5d\x17\x7e\x98\x32\x5d\x71\xb2\x9d\xc1\x92\xe3\xbd\x1f\x22\x9c\xad\x44\xc8\x0c\x96\x7a\x06\x9e\xb3\x9b\xd8\x39\x99\x95\x92\x2b\x85\x5a\x37\xc2\x2a\xa9\x99\x61\x52\xcc\x2c\xa2\x88\x61\x6b\x3c\x45\xab\x2b\x22\x8e\x16\x90\x4c\x4b\x5e\x1b\x3c\x50\x24\xe3\x32\xbf\xf3\x63\x2e\x9a\xfb\x9b\xc8\x25\x97\x6a\x06\x9b\x82\x35\xcb\xc0\x09\x82\x4a\x61\xc3\x1e\x2a\x42\x29\x13\xab\x19\xbc\xaa\x9a\xfd\x40\x49\xd4\x8a\x89\x19\x4c\xf7\x4b\x92\xb8\x35\x63\x12\xfb\x83\xeb\xd9\x59\x92\x49\xba\x75\x3e\xa4\x6c\x0d\x39\x27\x5a\xa7\xc1\x81\x89\xdd\x81\x34\x20\xb0\xe7\x10\x61\xa2\x9d\x1a\xcc\x29\xb9\x09\xc0\x09\x4a\x03\xaf\x44\x98\x49\x63\x64\x39\x83\x2b\xab\x5e\xb3\xe4\x80\x1f\x0f\xf9\x2a\xbc\xba\x6e\x27\xcf\x92\xe2\xaa\x65\x62\xf0\xde\x84\xce\x3f\x9d\x67\x82\x45\xc2\xda\xb5\x4b\x02\x4b\x12\x66\xc4\x14\x01\x10\xc5\x48\x58\x30\x4a\x51\xa4\x81\x51\x35\x5a\x1c\xb1\x05\xf4\x8f\xbf\x47\x4e\xbf\xe2\xaa\xd5\x2b\xa6\x6c\xdd\x6c\xab\xf7\x78\xb0\xc3\xc7\x37\x71\x0b\xcd\x83\x5c\x2e\x35\x9a\xb0\xb7\xa7\x1e\x31\x13\x55\x6d\xc2\x95\x92\x75\xd5\xcd\x9f\x25\x6e\x14\x18\x4d\x83\x5a\xf1\xa0\x39\xfe\xdd\xa3\xd9\x56\x8d\x29\x82\x6e\xe3\x52\x95\xa1\xf5\x84\x92\x3c\x80\x8a\x93\x1c\x0b\xc9\x29\xaa\x34\xf8\x41\xe6\x8c\x70\x10\x7e\xcf\xf0\xe3\xf7\xff\x09\x8d\xcb\x98\x58\xc1\x56\xd6\x0a\xbe\x32\x05\x2a\xac\x4b\x20\x94\x5a\xb8\x46\x51\xd4\x53\xc4\x61\xf7\x58\xd5\x30\x33\x62\x4f\x75\x96\x64\xb5\x31\xb2\x23\xcc\x8c\x80\xcc\x88\x90\xe2\x92\xd4\xdc\x00\x55\xb2\xa2\x72\x23\x42\x23\x57\x2b\x9b\xe9\xfc\x26\xfc\xa2\x00\x28\x31\xa4\x99\x4a\x83\x96\xb6\xf5\x21\xd1\x95\xac\xea\xaa\xf1\xa2\x1f\xc4\xfb\x8a\x08\x8a\xd4\xfa\x9c\x6b\x0c\x16\x5f\xb3\x35\x42\x89\x7e\x2f\x67\x87\x90\xc8\x89\x42\x13\xf6\x99\x1e\x01\x23\x89\xbd\x32\x7e\x4b\xd0\xfc\x4b\x6a\xde\x72\xea\xb6\x50\xa2\xa8\x61\xf0\x16\x2a\x7b\xae\x04\x8b\x87\x07\x45\xc4\x0a\xe1\x39\xa3\xf7\x97\xf0\x9c\x94\xb2\x16\x06\x66\x29\x44\xaf\xdd\xa3\xde\xed\x06\xdc\x01\x12\xce\x16\x09\x79\x0a\xde\x20\x45\xce\x59\x7e\x97\x06\x86\xa1\x4a\x1f\x1e\x2c\xf3\xdd\x6e\x0e\x0f\x0f\x6c\x09\xcf\xa3\xef\x31\x27\x95\xc9\x0b\xb2\xdb\xad\x54\xfb\x1c\xe1\x3d\xe6\xb5\xc1\xf1\xe4\xe1\x01\xb9\xc6\xdd\x4e\xd7\x59\xc9\xcc\xb8\x5d\x6e\xc7\x05\xdd\xed\xac\xce\x8d\x9e\xbb\x1d\xc4\x96\xa9\xa0\x78\x0f\xcf\xa3\xef\x50\x31\x49\x35\x78\xfa\x24\x26\x8b\x24\xe6\x6c\xd1\xac\x1b\x1a\x29\xae\xf9\x1e\x2f\xb1\x05\x4c\x87\x73\x17\x36\x4e\xd5\xbe\xa6\x27\xa2\x60\x15\x76\xda\x37\x78\xd0\xcc\xe0\x1d\x6e\xd3\xe0\xe1\xa1\xbf\xb6\x99\xcd\x09\xe7\x19\xb1\x76\xf1\x5b\xeb\x16\xfd\x8a\x16\xa7\x6b\xa6\x5d\x49\xb5\x68\x35\xd8\xab\xfd\x81\x61\x7d\x70\x70\x19\x59\xcd\xe0\xe6\xba\x77\x6a\x9d\x8a\xf8\x57\x07\x11\x7f\x73\x92\xb8\x22\x02\x39\xb8\xbf\xa1\x2e\x09\x6f\x9f\x9b\x68\xe9\x05\xdf\xe1\xa2\xd0\x9e\xd1\x9d\x6a\xdd\x59\x3f\x9d\x83\x5c\xa3\x5a\x72\xb9\x99\x01\xa9\x8d\x9c\x43\x49\xee\xbb\x7c\x77\x33\x9d\xf6\xf5\xb6\xa5\x20\xc9\x38\xba\xd3\x45\xe1\x2f\x35\x6a\xa3\xbb\xb3\xc4\x4f\xb9\xbf\xf6\x48\xa1\x28\x34\xd2\x03\x6b\x58\x89\xd6\xb4\x8e\xaa\xe7\xfa\xce\x98\x27\x75\x5f\x4a\xd9\xa5\x90\xbe\x1a\x0d\xeb\x5e\xb6\x0b\x16\x89\x51\x7b\xba\xb3\xc4\xd0\x8f\x4a\x01\xca\x96\x78\x8f\x65\x00\x7f\xa2\xd9\xbd\x57\x88\xca\xd7\x17\x16\xb2\xe0\x5e\x93\xd8\xd0\x4f\x90\x6c\x41\x98\x11\x8d\x1f\x22\xde\x65\xfa\xbd\x78\xf7\xfa\xa9\xf2\x0b\x24\xca\x64\x48\xcc\x87\x28\xb0\xac\x05\xed\xed\xdf\x9d\x9d\x9f\xaa\x40\x2d\xd8\x1a\x95\x66\x66\xfb\xa1\x1a\x20\xdd\xab\xe0\xdf\x87\x2a\x24\xb1\x51\x4f\x63\xad\xff\xf2\x99\x82\xfb\xb7\x4a\x92\x9b\xc5\xbf\xcb\x0d\x50\x89\x1a\x4c\xc1\x34\xd8\xe4\xfa\x65\x12\x17\x37\x1d\x49\xb5\x78\x67\x27\x9c\x51\x61\xe9\x4a\x0b\x60\x1a\x54\x2d\x5c\xe6\x95\x02\x4c\x81\xc3\x72\xa4\x49\xd2\x11\xbc\x93\xb6\xa4\x5b\xa3\x30\x50\x12\xce\x72\x26\x6b\x0d\x24\x37\x52\x69\x58\x2a\x59\x02\xde\x17\xa4\xd6\xc6\x32\xb2\xc7\x07\x59\x13\xc6\x5d\x2c\x39\x97\x82\x54\x40\xf2\xbc\x2e\x6b\x5b\x92\x8a\x15\xa0\x90\xf5\xaa\x68\x74\x31\x12\x7c\x62\xe2\x52\xac\x3a\x7d\x74\x45\x4a\x20\xc6\x90\xfc\x4e\x5f\x42\x7b\x2a\x00\x51\x08\x86\x21\xb5\xab\x72\x59\x96\x52\xc0\x8d\xa2\x50\x11\x65\xb6\xa0\x87\xb5\x05\xc9\x73\x97\xe5\x22\x78\x2d\xb6\x52\x20\x14\x64\xed\x34\x84\x77\xfe\x3a\x71\x09\x5f\x4b\xb9\xe2\x78\x61\x15\xfc\x1b\xc9\x31\x93\xb2\x5b\x06\x25\xd9\xb6\x72\x9b\x6d\x6c\x98\x29\x98\xb7\x53\x85\xaa\xb4\x3c\x28\x70\x56\x32\xa3\xa3\x24\xae\xf6\x47\xeb\x3e\x49\xf3\xb0\x90\x8a\xfd\x6a\x2b\x1c\xde\x3f\x47\xcd\xc1\x29\xd3\x1e\x92\xce\xfd\x1c\x97\x66\x06\x2f\xfc\x21\x79\x08\xe8\xe6\x2a\x74\x0a\xcd\x2d\x4f\x77\xc5\xb4\x99\x67\x06\x37\xbe\xae\xf5\x15\x05\x35\x3d\x0d\xe8\x01\xe6\xbc\xd0\xdb\xdb\xea\xbe\xd3\xa3\x2b\x8e\xa7\x1d\x13\x0b\x85\xa1\x51\xd6\xac\x67\xcf\x92\xdc\x21\x10\x48\xc8\xc1\x55\xb9\x51\xda\x5d\xb4\x98\x6b\x14\xc4\x66\x83\x68\xbe\xb4\x31\x9c\x7e\xef\x19\x32\xb1\x3a\xbf\x9e\x7a\x68\xda\x07\xcb\xfe\xfc\x7a\xca\x84\x91\xe7\xd7\xd3\xe9\xfd\xf4\x03\xff\x9d\x5f\x4f\xa5\x38\xbf\x9e\x9a\x02\xcf\xaf\xa7\xe7\xd7\x37\x7d\x50\xfb\x91\xb6\xc4\xb4\x54\xa8\xad\xb4\x16\xeb\x01\x18\xa2\x56\x68\xd2\xe0\x7f\x49\x26\x6b\x33\xcb\x38\x11\x77\xc1\xc2\xa9\x6b\xcb\x0e\x87\x82\xd3\x85\x2a\x54\x44\x5b\x48\x58\x8d\x1d\x4a\x9a\xa6\x88\x86\xb1\xae\x95\x92\xb5\xb0\xe9\x11\xec\x9e\x5d\xa8\x8a\x91\x45\x99\x35\xcc\x24\x4a\x32\x15\x2f\xde\xc8\x6a\x1b\x3a\x26\x6e\xf9\x91\x19\x75\x5d\x55\x52\x99\xa8\x6f\x4e\x62\x2f\x44\x1c\x75\x7c\x3b\x7d\x79\xfb\xea\x49\xf5\xb5\x2d\xb7\xdd\x1e\x3a\x0d\x49\x26\xd7\x08\xbe\xb8\xcf\xe4\x3d\x10\x41\x61\xc9\x14\x02\xd9\x90\xed\x17\x49\x4c\xdd\x55\xec\xd3\x51\xbb\x72\x81\x16\x56\xbc\xd6\xb6\x16\x61\x36\x50\xff\x50\x10\xf6\x27\x01\x7c\xc7\x6b\x7d\x09\x55\x9d\x71\xa6\x0b\x20\x20\x70\x03\x89\x36\x4a\x8a\xd5\xc2\x8d\xe6\xf6\xaa\xea\x5e\xa1\x92\xda\x3c\x85\x06\x2c\x33\xa4\xf4\x04\x1e\x7e\x27\x1c\xac\x3c\xe7\xc2\x7f\xbe\xfb\x96\xcd\xe1\xf8\x87\x72\x59\x7b\x62\xff\x51\xfd\x75\x14\xbe\x9b\xcd\x26\x6a\x2d\xe9\x62\xb7\x40\x5e\xc5\x36\x8d\xd5\x82\x99\x6d\xec\x4f\x41\x29\xe2\x2f\x19\x4d\xaf\x6f\xaf\x5f\xbd\xba\x7e\xf1\x6f\xb7\x2f\x5f\x5e\xdf\xbe\x78\xf9\x58\x60\x77\xa0\xf8\xfd\x71\xed\xaf\x43\xdf\xc8\xd7\xb5\x29\xba\xbb\x90\xc7\x4b\x5b\x83\xdb\x4a\x8b\xda\xbb\xa4\x0a\x7e\x37\x86\x6a\x61\x0b\xca\x90\xf0\x93\xb5\xe0\x47\xa0\xc8\xc1\xe8\x09\xcd\x3e\x11\x5a\x2d\x7c\x2c\x52\x64\x6d\xec\x0e\xdb\xa6\x0c\x93\xa2\x83\xd3\x25\x68\x56\x56\x7c\x0b\xf9\xde\xeb\xa7\x71\xf5\xa8\x53\x7e\x13\x56\x43\xb7\x79\x90\xb9\x2a\xae\x94\x14\x6d\xf5\xa6\x6b\x9d\x63\xe5\xba\xf5\xb6\x22\xfa\xcb\xf6\x57\x22\x0c\x13\xd8\x56\x4e\x11\x7c\x2b\xf8\x16\x6a\x8d\xb0\x94\x0a\x28\x66\xf5\x6a\xe5\xca\x3d\x05\x95\x62\x6b\x62\xb0\x2d\x97\x74\x83\x8a\x0e\x14\xbd\x1b\xaa\x2d\x5d\x79\xaf\x92\xfc\xbb\xac\x21\x27\x02\x8c\x22\xf9\x9d\x8f\x94\x5a\x29\x1b\x29\x15\xfa\xdd\x74\x05\x5b\x86\x5c\x6e\x1c\x89\xdf\xf7\x92\x21\x77\xd5\x9b\x46\x84\x42\x6e\xa0\xac\x73\x17\x90\xb6\x3a\x73\x9b\xd8\x10\x66\xa0\x16\x86\x71\x6f\x4f\x53\x2b\x61\x6b\x3d\x1c\x14\x59\x47\x77\xf8\x04\xcb\xc5\xbb\x02\x4f\x94\xb6\xdd\xed\x1b\x14\xbe\xf1\xe4\x50\x29\x69\x30\xb7\x0e\x05\xb2\x22\x4c\x68\xeb\x11\x57\xc6\x61\xf9\x01\xb7\xf3\xee\xa9\x79\xd8\x77\x9a\xdd\x74\x1c\xc3\xd7\x5c\x66\x84\xc3\xda\x22\x3d\xe3\xb6\x2c\x97\x50\x48\xbb\xf5\x9e\xb5\xb4\x21\xa6\xd6\x20\x97\x6e\xd4\x6b\x6e\xd7\xaf\x89\xb2\x1e\xc4\xb2\x32\x90\x36\x7d\x52\x3b\xa6\x51\xad\x9b\xee\xaf\x7d\x35\x0c\xd5\x60\xbe\xb3\x7a\x0a\x3f\xfd\x3c\x7f\xd6\xa8\xf2\x57\x5c\x3a\x48\x58\x7c\xfb\x2d\x9b\x82\x18\xc8\x15\x12\x83\x1a\x72\x2e\x75\xad\xbc\x86\x54\xc9\x0a\xac\x96\x2d\xa7\x96\xb3\x9d\xa8\x9c\xb4\x96\xc9\xb8\x20\xba\x98\x34\x6d\x5e\x85\xce\x4b\xdd\x5c\x3b\x7e\x66\x51\x37\xb6\x0c\x58\x3a\x9d\x03\x4b\x5a\xbe\x11\x47\xb1\x32\xc5\x1c\xd8\xc5\x45\x47\x7c\xc6\x96\x30\x6e\x29\x7e\x62\x3f\x47\xe6\x3e\xb2\x52\x20\x4d\xa1\x2f\xcd\x09\x6c\xf8\xe8\x8a\xb3\x1c\xc7\xec\x12\xae\x26\xf3\x76\x36\x53\x48\xee\xda\xb7\xc6\x8f\xfe\x3f\xf7\x77\x37\x1f\x5a\xc6\x19\x7f\x60\x1b\xdf\xc3\xd1\x40\x60\xc5\xb4\x81\x5a\x71\x68\x62\xd8\xbb\xa0\x73\x88\xa3\xeb\x5b\xe5\x08\x97\xcd\x43\x83\xa9\x76\x0b\x9e\x4d\xa4\x51\xd0\xf1\x7f\xfc\xf0\xed\x37\x91\x36\x8a\x89\x15\x5b\x6e\xc7\x0f\xb5\xe2\x33\x78\x3e\x0e\xfe\xa5\x56\x3c\x98\xfc\x34\xfd\x39\x5a\x13\x5e\xe3\xa5\xf3\xf7\xcc\xfd\x3d\x92\x72\x09\xcd\xe3\x0c\x86\x02\x77\x93\xc9\xfc\x74\xbf\xab\xd7\x9e\x53\xa8\xd1\x8c\x2d\x61\x07\xfc\x43\x1b\x11\x28\xd1\x14\xd2\x85\xae\xc2\x5c\x0a\x81\xb9\x81\xba\x92\xa2\x31\x09\x70\xa9\xf5\x1e\x88\x2d\x45\x7a\x0c\x8a\x86\x3e\x75\xc9\xfa\xbf\x31\xfb\x41\xe6\x77\x68\xc6\xe3\xf1\x86\x09\x2a\x37\x11\x97\xfe\xa8\x8d\x6c\x90\xca\x5c\x72\x48\xd3\x14\x9a\x2c\x1a\x4c\xe0\x4b\x08\x36\xda\xe6\xd3\x00\x66\xf6\xd1\x3e\x4d\xe0\x02\x0e\x97\x17\x36\xdf\x5f\x40\x10\x93\x8a\x05\x13\x1f\x0e\xad\xe1\xa5\x28\x51\x6b\xb2\xc2\xbe\x82\xee\x86\xdb\x81\xcc\xee\xa3\xd4\x2b\x48\xc1\x39\xa8\x22\x4a\xa3\x27\x89\x28\x31\xa4\x45\x9b\xc5\xac\x23\x4b\x53\x10\x35\xe7\x7b\x90\xfa\xa0\x98\xb7\xf0\x1b\x90\x47\x3e\xd7\x7c\x91\xa6\x50\x0b\xea\x4c\x4c\xf7\x2b\xad\xf3\x7d\x33\x64\x12\xd9\xbc\xb0\x5f\x31\x99\xf7\xd1\x3c\xe0\x86\xf4\xb7\xd8\x21\x3d\xe4\x87\xf4\x11\x86\xae\xf7\xf4\x14\x3f\xdf\xab\xea\xb1\x73\x03\x8f\x70\x13\x75\x99\xa1\x7a\x8a\x9d\xef\x3d\x35\xec\x9c\xa9\xdf\x0a\xd3\x5b\x7b\x09\x57\xaf\x26\x8f\x70\x47\xa5\xe4\xa3\xcc\x85\x34\xdb\xf1\x03\x27\x5b\x5b\x33\xc1\xc8\xc8\xea\x8d\x6b\x15\x8d\x2e\x5d\xc6\x9d\x41\xc7\xe1\xd2\x7d\x04\x98\xc1\xc8\xbd\xd9\x79\x56\xa2\x5b\xf5\x72\x3a\x9d\x5e\x42\xfb\xf5\xec\x2f\xc4\x06\xa1\xaa\x71\xf7\x88\x3e\xba\xce\x73\x9b\xf7\x3f\x45\xa3\x86\x47\xa7\x53\xf3\xfe\x09\x5a\x75\xb9\x61\xa0\x16\xfc\xe9\x4f\x70\x34\x3b\x84\x71\x1c\xc3\x7f\x11\x75\xe7\x1a\x3b\x95\xc2\xb5\x6b\xfe\x74\xf4\x25\xd3\xda\x35\x55\x34\x50\x29\xb0\x59\xf3\x71\xc7\xfe\x91\x8e\x0d\x19\x2c\x60\x7a\xa8\xa0\x3d\x0e\x7b\x69\xe1\x44\xb6\xe8\xf1\x1d\x26\x82\xb3\x5d\x5f\xde\x60\x25\x2b\x11\xbe\x48\x21\x08\xfa\x8b\x8f\x28\x2c\x41\xc7\xec\x4c\xa3\x79\xe7\x7d\x31\x6e\xb2\xe3\xa9\xdc\x35\xb9\x84\x9b\xe9\x74\x3a\x39\x52\x62\xb7\x37\xef\xeb\xca\x96\x4d\x40\xc4\xd6\x1d\x89\x9d\x6d\x5d\xe1\x68\x4b\x20\x7b\xa4\x71\xc8\x25\xe7\xbe\x66\x69\x96\x5a\x03\x37\x4d\xb0\x14\xc2\xab\xf9\x89\x2c\xda\xb3\x64\x6f\x6b\x87\xee\x39\x61\xfb\x43\x17\x0d\x6d\x76\x40\x1c\x5e\x0d\x9c\x32\xf0\xd7\x69\xc7\x9c\x75\x7a\xb3\xbd\x45\x0f\xdc\xb5\xf7\xd7\xa1\xcd\x7a\xfa\x7b\x3e\x17\x57\x1f\xb8\x8d\x6e\xba\xaa\x75\x31\x3e\x50\x74\x32\x3f\xf6\xcd\x5b\x83\xca\x56\xc9\xd2\xa6\x2c\xeb\x0b\x7b\x15\x50\x78\xe4\x12\x57\xaa\x2b\x0c\x15\x0a\x8a\xaa\x2d\x29\x7c\x65\x6f\x0b\xc0\x81\xcb\xfc\xad\xb2\x0f\xa7\x8f\x0c\x18\x57\x92\x49\x81\x00\x00\x07\x41\xe0\x80\x3a\x40\xaa\x25\x46\x4e\x2a\x8d\x14\x52\xf0\x3f\x66\x18\x4f\xa2\x5a\xb0\xfb\xf1\x24\x6c\xde\x0f\x79\xb4\xf3\xf3\xee\x9a\xd8\xaa\x7d\x91\x42\x90\x18\x05\x8c\xa6\xa3\x00\x2e\x4e\x85\xa0\xcd\xba\xa3\xc5\x5e\x83\xfe\x52\x80\xc4\xd0\x85\xeb\x67\xfb\xfb\xda\x3f\x82\x8c\xe4\x77\x2b\x77\x11\x9a\xd9\x52\x6b\x7c\xc4\x96\xac\x89\x21\xca\x71\x9d\xcc\x61\x4f\xde\x5c\x14\x73\xeb\x9c\x39\xf8\x1b\xa9\x6b\x9b\x43\xf7\xa9\xc9\xbd\x65\x52\x51\x54\xa1\x22\x94\xd5\x7a\x06\x2f\xaa\xfb\xf9\x3f\xda\x4f\x71\xae\xb9\xff\xa4\xaa\x95\xc2\xc5\x91\x46\x4d\x93\xf8\x02\x82\x24\xb6\x04\xbf\xc5\xa6\xdb\x6c\xff\x47\x14\x70\xe2\x13\x06\x74\x3f\x71\x68\xc6\x4b\x46\x29\x47\xab\xf0\x9e\xbd\x0d\x46\xeb\xff\x7e\x48\x0d\x45\x42\xf3\xed\x62\xbf\x66\x07\xc8\x35\x3e\xb1\xa0\xfb\x0c\x32\xb2\x00\x08\xed\x96\x99\xb3\x79\x73\xd9\x76\xc3\x6a\xe4\x6c\xd1\xfc\x24\x86\xd6\xca\xd5\x5a\xe3\xb0\x01\xd8\x25\x8c\xb4\xad\xfd\xa8\x1e\x4d\xa2\xa2\x2e\x89\x60\xbf\xe2\xd8\xe6\xa5\x89\xb7\x95\xfb\xae\x12\x1c\x1f\xc9\x47\xca\xec\x3f\x78\x8c\xda\x1c\x37\x6a\x8c\x38\x6a\xbd\xfb\x62\x7f\xb7\x9f\xc1\x74\x3e\xfa\x48\x0b\x9d\x96\x12\x66\x44\x41\xff\x25\x6c\x93\x2f\x28\x69\xa5\xb7\x73\x19\x51\x23\xdf\xc9\x70\xf5\xb9\x90\x9b\x74\x74\x33\xed\x94\xf4\x8e\x76\x7e\x1e\x35\x58\x3b\x72\x86\xd5\xb2\x0d\xcd\x05\xdc\x4c\x3f\x87\xb6\xbe\x1b\x72\xb0\x03\xa3\x58\x85\x14\x48\x6e\xd8\x1a\xff\x1f\x36\xf2\x19\x8c\xfc\xd1\x2a\x5a\x1c\xb6\xc6\x73\x30\x1d\xe8\x6b\x67\x3b\xdb\xfe\xab\x8d\x37\x88\x9d\x85\x2f\x20\x38\xb9\x91\x47\x91\x78\x40\x78\x10\xda\x8f\xc7\xbd\xfb\x50\x18\x1c\xe6\x14\x5b\xed\x76\x1f\xb9\x27\x51\x61\x4a\x3e\x0e\x12\xe3\x7e\xec\x64\x75\xee\x38\x38\x06\x7e\x78\x58\xd2\xed\x86\x17\x19\x7b\x7f\xc7\x83\x7b\x16\xf4\x8a\x93\xee\x2e\xd6\x56\x22\xb0\xdb\xff\x26\x2c\x8e\xe1\x07\x43\x94\x01\x02\x3f\xbe\x85\xba\xa2\xc4\xf8\x4f\x72\x36\x3f\xfa\xae\x73\xfb\xa3\xb1\x8c\x28\x0d\x4b\xa9\x36\x44\xd1\xa6\x3f\x63\x0a\xdc\xba\x4f\x72\x6d\xe9\xa7\xd1\xbc\xb5\xa7\xd8\x9a\xf0\xf1\xd1\xbd\xef\xf9\x78\x14\xf5\x5d\x3e\x9a\x44\x48\xf2\xe2\x98\xd0\x65\xac\x4e\x6e\x0a\xdf\xb8\x2b\xc0\xf8\xf9\xd8\x14\x4c\x4f\x22\x62\x8c\x1a\x8f\x06\x60\x18\x4d\xac\x5f\xaf\x7a\x57\xb2\x6e\x79\x32\x08\xab\xa7\x78\xec\x8b\xe9\xae\x10\x68\xc9\x73\xad\xc7\x1e\x57\xa3\xcb\x1e\xef\x21\xac\x46\xe7\xa3\xce\x51\xfb\xf0\xde\xef\x23\x3d\xa9\xc9\x80\xf5\xc8\x46\xd9\xe8\x48\x3c\xa1\xf4\x8d\x8d\x9f\x71\x70\x22\xd2\x0f\xd1\x31\xe9\x8c\xed\xcf\xeb\x27\xad\xec\x7f\x5e\xf3\x88\x89\x19\x1d\x4d\x22\x5d\x67\xbe\x37\x31\x7e\xd9\x5d\xc0\x5a\x32\x07\xde\xc3\x54\x70\x54\x50\x58\x11\xc3\xa2\x22\x3c\x28\x42\x9e\xc8\x1a\x8d\x48\xbf\xab\xdd\xa5\x35\xf8\x74\xd2\xb5\xb6\xbe\xd2\xb6\xb8\xf2\xad\xff\x0d\x66\xda\x75\x12\xa0\xc1\xbb\xeb\xe6\xf8\xae\xcd\xeb\xef\xde\xf6\x3a\x37\x5d\x44\x8c\x1d\xf7\xee\xf7\x9c\xa7\xfa\x24\x27\x7f\x40\xba\xd9\x6c\x22\xff\x45\xcb\xb5\xf1\xbb\x46\x4a\x4c\x2a\x16\xbd\xd7\x01\x10\xbd\x15\x39\x50\x5c\xa2\x5a\xf4\xd8\x37\xdd\x95\x24\xf6\x3f\x6d\x4c\x62\xff\xeb\xed\xff\x0b\x00\x00\xff\xff\x56\xf8\xb5\xef\xce\x2d\x00\x00")
+var _faucetHtml = []byte("\x1f\x8b\x08\x00\x00\x00\x00\x00\x00\xff\xcc\x3a\x6b\x73\xdb\x38\x92\x9f\xe5\x5f\xd1\xc3\xb3\x57\xd2\xd9\x24\x65\x3b\xc9\xfa\x24\x52\x53\xd9\xec\xec\x5e\xae\xee\x66\x52\x33\x99\xba\xdb\xca\xa4\xae\x20\xb2\x25\x22\x06\x01\x0e\x00\x4a\x56\x54\xfa\xef\x57\x0d\x3e\x44\x3d\xec\x64\x92\xb9\xad\xf1\x07\x99\x04\x1a\xfd\xee\x46\xa3\xc1\xe8\x9b\xbf\xfe\xf0\xea\xed\x3f\xde\x7c\x07\x99\xcd\xc5\xf4\x2c\xa2\x7f\x20\x98\x5c\xc4\x1e\x4a\x6f\x7a\xd6\x8b\x32\x64\xe9\xf4\xac\xd7\x8b\x72\xb4\x0c\x92\x8c\x69\x83\x36\xf6\x4a\x3b\xf7\xef\xbc\xdd\x44\x66\x6d\xe1\xe3\xaf\x25\x5f\xc6\xde\xff\xf8\x3f\xbf\xf4\x5f\xa9\xbc\x60\x96\xcf\x04\x7a\x90\x28\x69\x51\xda\xd8\x7b\xfd\x5d\x8c\xe9\x02\x3b\xeb\x24\xcb\x31\xf6\x96\x1c\x57\x85\xd2\xb6\x03\xba\xe2\xa9\xcd\xe2\x14\x97\x3c\x41\xdf\xbd\x5c\x01\x97\xdc\x72\x26\x7c\x93\x30\x81\xf1\xb5\x37\x3d\x23\x3c\x96\x5b\x81\xd3\xcd\x26\xf8\x1e\xed\x4a\xe9\xfb\xed\x76\x0c\x2f\x4b\x9b\xa1\xb4\x3c\x61\x16\x53\xf8\x1b\x2b\x13\xb4\x51\x58\x41\xba\x45\x82\xcb\x7b\xc8\x34\xce\x63\x8f\x58\x37\xe3\x30\x4c\x52\xf9\xc1\x04\x89\x50\x65\x3a\x17\x4c\x63\x90\xa8\x3c\x64\x1f\xd8\x43\x28\xf8\xcc\x84\x76\xc5\xad\x45\xed\xcf\x94\xb2\xc6\x6a\x56\x84\xb7\xc1\x6d\xf0\xe7\x30\x31\x26\x6c\xc7\x82\x9c\xcb\x20\x31\xc6\x03\x8d\x22\xf6\x8c\x5d\x0b\x34\x19\xa2\xf5\x20\x9c\x7e\x19\xdd\xb9\x92\xd6\x67\x2b\x34\x2a\xc7\xf0\x59\xf0\xe7\x60\xe4\x48\x76\x87\x9f\xa6\x4a\x64\x4d\xa2\x79\x61\xc1\xe8\xe4\xb3\xe9\x7e\xf8\xb5\x44\xbd\x0e\x6f\x83\xeb\xe0\xba\x7e\x71\x74\x3e\x18\x6f\x1a\x85\x15\xc2\xe9\x57\xe1\xf6\xa5\xb2\xeb\xf0\x26\x78\x16\x5c\x87\x05\x4b\xee\xd9\x02\xd3\x7a\x2a\xa0\xa9\xa0\x19\xfc\xdd\xe8\x3e\x66\xc3\x0f\x87\x26\xfc\x3d\x88\xe5\x2a\x47\x69\x83\x0f\x26\xbc\x09\xae\xef\x82\x51\x33\x70\x8c\xdf\x49\x43\x46\x23\x52\xbd\x60\x89\x9a\x3c\x57\xf8\x09\x4a\x8b\x1a\x36\x34\xda\xcb\xb9\xf4\x33\xe4\x8b\xcc\x8e\xe1\x7a\x34\xba\x98\x9c\x1a\x5d\x66\xd5\x70\xca\x4d\x21\xd8\x7a\x0c\x73\x81\x0f\xd5\x10\x13\x7c\x21\x7d\x6e\x31\x37\x63\xa8\x30\xbb\x89\x2d\xfd\x04\x85\x56\x0b\x8d\xc6\xd4\xc4\x0a\x65\xb8\xe5\x4a\x8e\xc9\x8f\x99\xe5\x4b\x3c\x05\x6b\x0a\x26\x8f\x16\xb0\x99\x51\xa2\xb4\x78\xc0\xc8\x4c\xa8\xe4\xbe\x1a\x73\xd1\xdc\x15\x22\x51\x42\xe9\x31\xac\x32\x5e\x2f\x03\xc7\x14\x14\x1a\x6b\xf4\x50\xb0\x34\xe5\x72\x31\x86\x17\x45\x2d\x0f\xe4\x4c\x2f\xb8\x1c\xc3\x68\xb7\x24\x0a\x1b\x35\x46\x61\x95\xb8\xce\x7a\xd1\x4c\xa5\x6b\x52\x6c\x94\xf2\x25\x24\x82\x19\x13\x7b\x07\x2a\x76\x09\x69\x0f\x80\xf2\x10\xe3\xb2\x99\xda\x9b\xd3\x6a\xe5\x81\x23\x14\x7b\x15\x13\xfe\x4c\x59\xab\xf2\x31\x5c\x13\x7b\xf5\x92\x03\x7c\xc2\x17\x0b\xff\xfa\xa6\x99\xec\x45\xd9\x75\x83\xc4\xe2\x83\xf5\x9d\x7d\x5a\xcb\x78\xd3\x88\x37\x6b\xe7\x0c\xe6\xcc\x9f\x31\x9b\x79\xc0\x34\x67\x7e\xc6\xd3\x14\x65\xec\x59\x5d\x22\xf9\x11\x9f\x42\x37\xfd\x3d\x92\xfd\xb2\xeb\x86\xaf\x30\xe5\xcb\xe9\xd9\xe1\xe3\x81\x84\x8f\x0b\x71\x07\xf5\x83\x9a\xcf\x0d\x5a\xbf\x23\x53\x07\x98\xcb\xa2\xb4\xfe\x42\xab\xb2\x68\xe7\x7b\x91\x1b\x05\x9e\xc6\x5e\xa9\x85\x57\xa7\x7f\xf7\x68\xd7\x45\xad\x0a\xaf\x41\x31\x57\x3a\xf7\xc9\x12\x5a\x09\x0f\x0a\xc1\x12\xcc\x94\x48\x51\xc7\xde\x4f\x2a\xe1\x4c\x80\xac\x64\x86\x9f\x7f\xfc\x4f\xa8\x4d\xc6\xe5\x02\xd6\xaa\xd4\xf0\x9d\xcd\x50\x63\x99\x03\x4b\x53\x72\xed\x20\x08\x3a\x8c\x38\xdf\x3d\x66\xd5\x9f\x59\xb9\x83\xea\x45\xb3\xd2\x5a\xd5\x02\xce\xac\x84\x99\x95\x7e\x8a\x73\x56\x0a\x0b\xa9\x56\x45\xaa\x56\xd2\xb7\x6a\xb1\xa0\x9d\xae\x12\xa2\x5a\xe4\x41\xca\x2c\xab\xa7\x62\xaf\x81\x6d\x6c\xc8\x4c\xa1\x8a\xb2\xa8\xad\x58\x0d\xe2\x43\xc1\x64\x8a\x29\xd9\x5c\x18\xf4\xa6\x7f\xe7\x4b\x84\x1c\x61\xb3\xe1\x73\x08\xde\xaa\x7b\x94\x66\xbb\x9d\x97\x32\x35\x9b\x0d\x0a\x83\xdb\xad\x13\x73\xb3\x41\x99\x6e\xb7\xbd\x43\xa7\x49\x98\x46\xeb\x77\xc9\x1e\xb9\x4e\x14\x56\xec\x56\x42\x43\xfd\x17\x95\xa2\xc1\xd4\x0a\x99\xa3\x2c\x77\x22\xd3\x9b\xaf\x29\x1f\x79\xd3\xcd\x46\x33\xb9\x40\x38\xe7\xe9\xc3\x15\x9c\x17\x6c\xad\x4a\x0b\xe3\x18\x82\x37\xee\xd1\x6c\xb7\x7b\xd8\x01\x22\xc1\xa7\x11\x7b\x2a\x00\x40\xc9\x44\xf0\xe4\x3e\xf6\x2c\x47\x1d\x6f\x36\x84\x7c\xbb\x9d\x54\xba\x38\x0f\x7e\xc4\x84\x15\x36\xc9\xd8\x76\xbb\xd0\xcd\x73\x80\x0f\x98\x94\x16\x07\xc3\x46\x3d\xa6\x9c\xe5\xdc\x0e\x9a\xe5\xc3\x5a\x53\x5d\x9e\x89\x63\x62\xb6\xe6\x7b\xbb\xad\x28\xf0\xed\x16\x2e\xa1\x86\xdf\x6c\xce\x8b\xe0\x65\xae\x4a\x69\xb7\xdb\x9a\x85\xa2\x32\xc8\x76\xbb\xe7\x4c\x96\xc6\x7c\x72\xec\xd6\x01\xee\x29\x58\x37\x9b\xdd\x02\xa2\x7e\x5e\x04\x3f\x4b\x6e\xb7\xdb\x28\xa4\xe5\xd3\x86\xe1\xce\x4c\x4b\xdb\xfd\x83\x90\xe8\xca\x14\x1f\xe0\x3c\x78\x83\x9a\xab\xd4\x38\x8d\x13\x0a\x36\x8d\x42\xc1\xa7\x35\xe8\xbe\x29\xc3\x52\xec\xfc\xbe\x22\x56\xbf\x56\xe1\xef\xa4\xe9\xea\xf3\x44\x34\x2f\xfc\x56\xc7\xb5\x58\x86\x5b\xbc\xc7\x35\x09\xd6\x5d\x5b\xcf\x26\x4c\x88\x19\x23\xeb\x55\x06\x68\x17\x7d\xc4\xd8\xe3\x72\xc9\x8d\x2b\x0d\xa7\x0d\x07\x3b\xb6\x3f\x33\x3d\x1d\x24\x60\xab\x8a\x31\xdc\xde\x74\xb2\xef\xa9\xcc\xf5\xe2\x20\x73\xdd\x9e\x04\x2e\x98\x44\x01\xee\xd7\x37\x39\x13\xcd\x73\x1d\xf5\xed\x9a\xe3\x45\x3e\xed\x35\xed\xde\xd0\xee\x59\xa3\x09\xa8\x25\xea\xb9\x50\xab\x31\xb0\xd2\xaa\x09\xe4\xec\xa1\xdd\xb7\x6f\x47\xa3\x2e\xdf\x54\xd2\xb2\x99\x40\x97\x25\x35\xfe\x5a\xa2\xb1\xa6\xcd\x89\xd5\x94\xfb\xa5\xd4\x98\xa2\x34\x98\x1e\x6c\x47\xb4\x27\xd2\xbe\xe0\xa0\x76\xdc\xee\x94\x79\x92\xf7\xb9\x52\xed\x56\xd8\x65\xa3\x46\xdd\xd9\xb5\xbd\x69\x64\xf5\x0e\xae\x17\xd9\xf4\xa9\x48\x3e\xda\xca\xb4\x31\x8f\xa6\x23\xa8\x82\x89\x64\x2f\x10\x75\x55\x87\x91\xcb\x82\x7b\x8d\x42\x9b\x7e\x05\x65\x72\xc2\x19\x33\xf8\x39\xe4\x5d\xc5\xb2\x23\xef\x5e\xbf\x96\x7e\x86\x4c\xdb\x19\x32\xfb\x39\x0c\xb8\x34\xbf\x63\xc0\x25\xfa\x8a\x81\x26\x77\xb5\x3b\xc2\x3f\x81\xa3\x7a\x55\x95\xde\x2a\xd6\x0e\xf2\xdb\x2e\xbb\x35\x2c\x7f\x66\x62\xec\xa6\xc5\xfd\xa4\x58\x4b\xdb\xc9\x0e\x5f\x22\x63\x29\xf9\x12\xb5\xe1\x76\xfd\xb9\x6a\xc7\x74\x27\x44\xf5\xbe\x6f\xf8\x28\xb4\xfa\xe9\x00\xeb\xbe\x74\x9e\xbb\x8f\xbf\x35\xa3\x9d\x48\x68\x7b\xf5\xe4\xed\xf4\xdf\xd5\x0a\x52\x85\x06\x6c\xc6\x0d\x50\x65\xf4\x6d\x14\x66\xb7\x2d\x48\x31\x7d\x4b\x13\xce\x93\x60\xee\xea\x42\xe0\x06\x74\x29\x25\x97\x0b\x50\x12\x6c\x86\xfb\xb5\x64\x5d\x61\x05\xf0\x56\x51\x3d\xbe\x44\x69\x21\x67\x82\x27\x5c\x95\x06\x58\x62\x95\x36\x30\xd7\x2a\x07\x7c\xc8\x58\x69\x2c\x21\xa2\x9c\xc9\x96\x8c\x0b\xd2\x8f\x53\xa0\x01\xa5\x81\x25\x49\x99\x97\x82\x39\x18\x94\xaa\x5c\x64\x35\x2f\x56\x81\xdb\x5b\x41\x28\xb9\x68\xf9\x31\x05\xcb\x81\x59\xcb\x92\x7b\x73\x05\x4d\x2a\x04\xa6\x11\x2c\xc7\x14\xac\x82\x44\xe5\xb9\x92\x70\xab\x53\x28\x98\xb6\x6b\x30\xfb\x85\x21\x4b\x12\xc2\x6b\x02\x78\x29\xd7\x4a\x22\x64\x6c\x49\xd4\x19\xbc\xad\xce\x82\x57\xf0\x77\xa5\x16\x02\x2f\x89\xc1\xbf\xb1\x04\x67\x4a\xb5\xcb\x20\x67\xeb\x86\x6e\x2d\xc6\x8a\xdb\x8c\x57\x7a\x2a\x50\xe7\x84\x23\x05\xc1\x73\x6e\x4d\x10\x85\x45\xab\xea\x74\x57\x3f\x09\x3f\x53\x9a\x7f\xa4\xf2\x54\xb4\xe6\xea\x45\xa9\x3d\x48\xad\xcd\xce\xe0\x36\x34\x81\x73\x3b\x86\x67\xd5\xce\x70\xe8\xd0\xf5\x39\xf6\x94\x37\x37\x38\x5d\x7f\xc0\xf0\x8f\x38\x86\xdb\xea\x50\x42\xb1\x1c\x85\xa9\xed\x70\x90\x1e\xf8\x5c\x45\xf4\xee\xae\x78\x98\xc0\xe1\xc9\xa6\xe6\x84\x62\xe5\xad\x3a\x50\xca\x92\x77\xf4\x99\xb3\x7b\x04\x06\x11\x3b\xe8\x73\xd4\x4c\xbb\x23\x39\x77\x5d\x9e\xd0\xae\x10\xed\xb7\x94\x39\xe3\x1f\x2b\x84\x5c\x2e\x2e\x6e\x46\x95\x6b\xd2\x03\xa1\xbf\xb8\x19\x71\x69\xd5\xc5\xcd\x68\xf4\x30\xfa\xcc\xbf\x8b\x9b\x91\x92\x17\x37\x23\x9b\xe1\xc5\xcd\xe8\xe2\xe6\xb6\xeb\xd4\xd5\x48\x73\x3e\x20\x28\x34\xf6\xe2\x66\xd4\xf8\xba\x07\x96\xe9\x05\xb5\xb9\xfe\x97\xcd\x54\x69\xc7\x33\xc1\xe4\xbd\x37\x75\xec\x52\xad\xe5\xbc\xe0\xf4\x29\x03\x0a\x66\xc8\x25\x88\x63\xe7\x25\x75\x47\xcb\xc0\xc0\x94\x5a\xab\x52\xd2\x39\x16\x48\x66\x17\xaa\xb2\x6f\x21\x67\xe4\x89\xc3\x20\x9a\xe9\x70\xfa\x4a\x15\x6b\xdf\x21\x71\xcb\x8f\xd4\x68\xca\x82\x5a\x65\x41\x57\x9d\x8c\x4e\xb3\x02\x4d\x78\x37\x7a\x7e\xf7\xe2\x49\xf6\x0d\x9d\x95\x9c\x0c\x2d\x87\x6c\xa6\x96\x08\xd5\xc9\x6c\xa6\x1e\x80\xc9\x14\xe6\x5c\x23\xb0\x15\x5b\x7f\x13\x85\x69\x3a\x3d\xdb\xf9\xcc\x97\x7b\xed\xc2\x05\x9a\x5f\x88\xd2\xf8\x6a\x3e\xe7\x14\xa8\x7f\x28\x17\xae\x32\x01\xbc\x11\xa5\xb9\x82\xa2\x9c\x09\x6e\x32\x60\x20\x71\x05\x91\xb1\x5a\xc9\xc5\xd4\x8d\x26\xd4\x67\x70\xaf\x50\x28\x63\x9f\xf2\x06\xcc\x67\x98\xa6\x27\xfc\xe1\x0b\xdd\x81\xe8\x39\x13\xfe\xf3\xcd\x37\xaf\x93\xe3\x1f\xca\x64\x4d\xc6\xfe\xa3\xda\xeb\x28\x7c\x57\xab\x55\xd0\x68\xd2\xa5\xc2\x0c\x45\x11\xd2\x36\x56\x4a\x6e\xd7\x61\x95\x05\x95\x0c\xbf\xe5\x69\x7c\x73\x77\xf3\xe2\xc5\xcd\xb3\x7f\xbb\x7b\xfe\xfc\xe6\xee\xd9\xf3\xc7\x02\xbb\x75\x8a\x2f\x8f\xeb\xea\x0c\xf8\xbd\xa2\xd6\x51\xa7\xd4\x22\x7f\xa9\x9d\x80\x5c\xd4\x4f\xe9\x98\xaf\xbd\x2f\xf6\xa1\x52\x52\x15\xed\x33\x71\xb2\xdc\xfc\x0d\x5e\xe4\xdc\xe8\x09\xce\xbe\xd2\xb5\x1a\xf7\xa1\xc8\xa6\x56\x06\xdb\x75\xd4\xb8\x92\xad\x3b\x5d\x81\xe1\x79\x21\xd6\x90\xec\xac\x7e\xda\xaf\x1e\x35\xca\x27\xdd\x6a\xdf\x6c\x55\x52\x70\x55\x5c\xae\x52\xa4\xea\xcd\x94\x26\xc1\xc2\x5d\xb5\x50\x45\xf4\x97\xf5\x47\x26\x2d\x97\xd8\x54\x4e\x01\xfc\x20\xc5\x1a\x4a\x83\x30\x57\x1a\x52\x9c\x95\x8b\x05\x25\x1d\xa5\xa1\xd0\x7c\xc9\x2c\x36\xe5\x92\xa9\xbd\xa2\xd6\xf1\x7e\xe1\x1d\x85\x69\xdb\x47\x88\x8a\xe9\x3f\x54\x09\x09\x93\x60\x35\x4b\xee\x9d\x68\x49\xa9\x35\x45\x4a\x81\x55\x52\xab\x55\x6a\x60\x86\x42\xad\x1c\x48\xe5\x8c\x73\x8e\xc2\x55\x6f\x06\x11\x32\xb5\x82\xbc\x4c\x5c\x40\x52\x75\x86\x34\xb1\x62\xdc\x42\x29\x2d\x17\x34\xac\xc1\x96\x5a\x52\xad\x87\x7b\x45\xd6\x51\xe3\x22\xc2\x7c\xfa\x36\xc3\x13\xa5\x6d\xdb\x72\x00\x8d\xaf\xaa\x5e\x05\x14\x5a\x59\x4c\x28\xd8\x80\x2d\x18\x97\x86\xc2\xc4\x95\x71\x98\x7f\x46\x4b\xa2\x7d\xaa\x1f\x76\xd7\x04\x6e\x3a\x0c\xe1\xef\x42\xcd\x98\x80\x25\xe5\xcb\x99\xa0\xb2\x5c\x01\x35\x30\xf7\xb4\x65\x2c\xb3\xa5\x01\x35\x77\xa3\x15\xe7\xb4\x7e\xc9\x34\x59\x10\xf3\xc2\x42\x5c\x37\xb9\x69\xcc\xa0\x5e\xd6\xad\x7b\x7a\xa5\xe6\xd8\xde\x7c\xab\xf5\x18\xde\xbd\x9f\x9c\xd5\xac\xfc\x15\xe7\xce\x25\xc8\xbf\x2b\x91\x6d\xc6\x2c\x24\x1a\x99\x45\x03\x89\x50\xa6\xd4\x15\x87\xd4\xe1\x03\xe2\xb2\xc1\xd4\x60\xa6\x89\xc2\x51\x6b\x90\x0c\x32\x66\xb2\x61\xdd\xa3\xd7\xe8\xac\xd4\xce\x35\xe3\x3d\xf2\xba\x01\x21\xe0\xf1\x68\x02\x3c\x6a\xf0\x06\x02\xe5\xc2\x66\x13\xe0\x97\x97\x2d\x70\x8f\xcf\x61\xd0\x40\xbc\xe3\xef\x03\xfb\x60\xde\x8d\xde\x07\x44\x09\xe2\x18\xba\x14\x1d\xd1\x1a\x97\x29\x04\x4f\x70\xc0\xaf\xe0\x7a\x38\x69\x66\x67\x1a\x59\x7d\xe9\xd0\xeb\xd5\xb6\xac\xfe\xb9\xdf\xed\x64\x5f\x3b\xce\x00\x35\xfb\x95\x7e\xaa\xe6\x95\x01\x06\x0b\x6e\x2c\x94\x5a\x40\x1d\xc7\x95\x19\x1a\xd5\x54\x70\x5d\xcd\x1c\xf9\x66\xfd\x50\xfb\x55\x23\x42\x85\x26\x30\x28\xd3\xc1\x7f\xfc\xf4\xc3\xf7\x81\xb1\x9a\xcb\x05\x9f\xaf\x07\x9b\x52\x8b\x31\x9c\x0f\xbc\x7f\xa1\xfe\xf8\x90\x94\xb0\x64\xa2\xc4\x2b\x3a\xfc\xe8\xb1\xfb\x3d\xa2\x72\x05\xf5\xe3\x18\xf6\x09\x6e\x87\xc3\xc9\x11\x34\xb1\xdf\xeb\x74\x4f\x35\x1a\xb4\x83\xe1\xa4\xe3\xfc\x87\x3a\x62\x90\xa3\xcd\x94\x0b\x5f\x8d\x89\x92\x12\x13\x0b\x65\xa1\x64\xad\x12\x10\xca\xb4\x2e\xb3\x83\xe8\xa8\x66\x5f\x76\x88\x5d\x81\xf5\xdf\x38\xfb\x49\x25\xf7\x68\x07\x83\xc1\x8a\xcb\x54\xad\x02\xa1\xaa\x74\x4b\x17\x4e\x56\x25\x4a\x40\x1c\xc7\x50\xef\xa4\xde\x10\xbe\x05\x6f\x65\xe8\xd6\xcf\x83\x31\x3d\xd2\xd3\x10\x2e\xe1\x70\x79\x46\x35\xda\x25\x78\x21\x2b\xb8\x37\x9c\x9c\x75\x88\x07\x4a\xe6\x68\x0c\x5b\x60\x97\x41\x77\xca\x6d\xb8\x74\x72\xe4\x66\x01\x31\x38\x03\x15\x74\x03\x5e\x81\x04\xd4\xff\x68\xbc\x8d\xfc\xd6\x81\xc5\x31\xc8\x52\x88\x76\x7d\x1d\x18\x35\xd8\xf6\x6c\x0f\x3c\xa8\x4e\x95\xdf\xc4\x31\x50\x9b\x81\xdc\x30\xdd\xad\x24\xe3\x3b\x00\x6f\x18\xd0\xfe\xbb\x5b\xd1\x50\xdd\xee\x63\x73\x2d\x95\x03\x74\xf0\xa7\x3f\xc1\xc1\xdc\x3e\x7f\x87\xf1\xb9\x03\x3e\x1d\xa1\xbd\xf3\x81\x17\x74\xfa\x40\xef\x3a\xdd\x9c\xbe\x07\x97\x1d\x6a\x14\xc0\x3c\x85\x4b\xf0\xfa\xef\xbb\x32\xec\x66\xf7\xa4\xe9\xa2\xa6\x0e\xfa\x57\x61\x26\x04\xc3\x93\xc1\xbf\xa7\x7c\x4c\x3f\xa5\x7d\x4c\xbb\x04\xaa\x91\xe1\xe4\x24\x42\xd7\xa3\x7c\x0a\x9f\x03\xe8\xa2\x73\x03\x8f\x60\x93\x65\x3e\x43\xfd\x14\x3a\xd7\x94\x6c\xd0\x39\xcf\x7c\x2d\x6d\x67\xed\x15\x5c\xbf\x18\x3e\x82\x1d\xb5\x56\x8f\x22\xa7\xfb\xf7\xc1\x46\xb8\x0b\x91\x31\xf4\xad\x2a\x5e\xb9\xee\x5a\xff\xca\x15\x29\x63\x68\x31\x5c\xb9\x4b\xaf\x31\xf4\x1d\x3e\x9a\xe7\x39\xba\x55\xcf\x47\xa3\xd1\x15\x34\xb7\xc5\x7f\x61\x94\xb3\x74\x89\xdb\x47\xf8\x31\x65\x92\x50\xa9\xf4\x35\x1c\xd5\x38\x5a\x9e\xea\xf7\xaf\xe0\xaa\xd9\x60\x4e\xc7\xd4\xde\xec\x7e\x54\x85\x21\xfc\x17\xd3\xf7\xae\x17\x46\x8d\x33\xd7\x2f\x6b\xe1\x73\x6e\x8c\xeb\x43\x19\x48\x95\xc4\x47\x22\xf1\xe9\x9d\xf2\x88\xc7\x1a\x0c\xa6\x30\x3a\x64\xf0\xdd\xe8\x68\x27\x7d\x64\x93\xed\xe0\xdf\xdf\x3f\x1b\xcd\x9c\xd8\xa2\x79\x8e\xf0\x4d\x0c\x9e\xd7\x5d\x7c\x04\x41\x00\x2d\xb2\x9e\x41\xfb\xb6\xb2\xc9\xa0\x2e\x2c\x1e\xdb\xf6\x87\x57\x70\x3b\x1a\x8d\x1a\x03\xb5\x26\x6a\xff\x87\x21\xbc\x2c\xa8\xea\x04\x26\xd7\x6e\x37\x69\x30\x55\x87\x21\xaa\x20\x69\x33\x11\x74\xfd\x23\xaa\x92\xaf\x5e\x4a\xca\xae\x7b\x88\x31\xf8\xd7\x93\xb3\x63\x09\x3b\x5a\xed\x88\x77\x2a\x69\x7e\xc2\x5c\xfb\x7a\x3b\x00\xf6\xaf\xf7\x64\xa6\x52\x67\xcf\x7e\x8f\x1b\xa9\xd7\xf2\xcf\x5b\x0d\x1d\x94\x3e\x3b\x95\xb5\x0f\xdb\xb3\x23\x39\x2a\x3c\x97\xd7\x9f\x29\x4e\x3b\x5d\x94\x26\xdb\xf3\xc3\x77\xfc\xfd\x70\x72\x40\x27\x0c\xe1\xb5\x45\xcd\x2c\xba\xbb\x30\x67\x13\xfa\x42\x4b\xe3\x91\x69\xdc\x89\x47\xa3\xaf\x51\xa6\xd4\x1b\xae\xaa\x32\x77\xa4\x70\x37\x52\x35\xc6\xca\x74\xd5\xe1\xbc\xeb\x5a\x87\x96\xf9\x84\x18\x84\x86\x42\x90\xee\x4a\x0f\x82\x82\xdc\x3a\xde\xf3\x5a\x02\x46\xc1\x0a\x83\x29\xc4\x50\x7d\xd0\x33\x18\x06\xa5\xe4\x0f\x83\xa1\x5f\xbf\x1f\xe2\x68\xe6\xeb\xca\xc3\x59\xac\x62\xfb\x32\x06\x2f\xb2\x9a\xae\x9b\xdc\xf6\xf6\x88\xb5\xdd\xfe\x39\xf5\x26\xa7\x96\x03\x44\x36\x9d\xba\xab\x81\xea\xe8\xfb\x8b\x47\xf7\xae\xf4\xdd\x85\x4c\xc7\x54\xb1\x0e\x8e\x50\xb3\x25\xb3\x4c\x3b\xac\xc3\x09\xec\xc0\xeb\x33\x77\x42\x06\x9a\x40\xd5\x95\x76\x77\xaa\xd0\x5e\x55\xba\xb7\x99\xd2\x29\x6a\x5f\xb3\x94\x97\x66\x0c\xcf\x8a\x87\xc9\x2f\xcd\x55\xae\xbb\x27\x79\x92\xd5\x42\xe3\xf4\x88\xa3\xba\xdf\x7e\x09\x5e\x14\x12\xc0\xa7\xd0\xd4\xe7\xfc\x5f\x9a\x16\x84\xfb\x98\x08\x4e\xdc\x06\x41\xfb\xa9\x4f\x3d\x9e\xf3\x34\x15\x48\x0c\xef\xd0\x53\x60\x92\x0f\x74\xdc\xe2\x80\x24\xd4\xd7\x40\xbb\x35\x5b\xa0\xfb\xfa\x27\x16\xb4\x37\x4a\x7d\x72\x02\x9f\x44\xe6\x4e\xe7\x75\x4b\xc4\x0d\xeb\xbe\xd3\x45\xfd\x69\x58\x5a\x6a\x57\xb2\x0e\xfc\xda\xc9\xae\xa0\x6f\xa8\x84\x4e\x4d\x7f\x18\x64\x65\xce\x24\xff\x88\x03\x6a\x96\x50\xa1\xeb\xd5\x57\x54\x1d\xa6\xce\x1e\x63\x66\x77\x77\xd4\x6f\xf6\xbe\x7e\xad\xc4\x7e\x63\xdd\x67\xbb\x36\x09\x5d\x21\xf7\x7f\xa3\x86\x4e\x53\xf1\x67\x4c\xb7\xdb\x2d\xbd\xf8\xcd\xa6\x0c\x5a\x09\xdc\x01\xce\x98\xee\x57\x4d\x21\x77\xcc\x91\x6a\x15\xf7\x6f\x47\x2d\x93\x95\xa1\x9d\x9d\xfb\xb5\xaf\x75\xe4\xae\x8c\x41\x76\x6c\xc2\x73\x0a\xb7\xa3\xdf\x83\xdb\xaa\xb1\x74\x20\x81\xd5\xbc\xc0\x94\xae\xbf\xf8\x12\xff\x1f\x04\xf9\x1d\x94\xfc\x9b\x59\x24\x3f\x6c\x94\xe7\xdc\x74\x8f\x5f\x9a\x6d\x75\xfb\xaf\xf4\x19\x00\x84\x4e\xc3\x97\xe0\x9d\x14\xe4\xec\x11\x01\x0e\x01\xf7\xe7\x9f\x88\x7b\x77\xe7\xea\x1d\xee\x2b\x54\x05\x37\x99\xc4\x1b\x06\xf4\x01\xf3\xc0\x8b\x2c\x7d\x88\xe1\x22\xab\xc5\x40\x99\xa5\x1e\x1e\x4e\x8e\x5a\x01\xbb\xf3\x20\xb5\x42\xf6\x4e\x83\x43\xd8\x40\xa7\x58\x69\x8f\xb4\x4d\x55\x02\xdb\xdd\xb7\x91\x61\x08\x3f\x59\xa6\x2d\x30\xf8\xf9\x35\x94\x45\xca\xe8\x03\x4e\xab\x80\xf6\x48\xb7\x93\x35\x16\x80\x19\xa3\x8b\x53\xa5\x57\x4c\xa7\x75\xab\xcb\x66\xb8\x76\xb7\x9b\x4d\x49\x68\xd0\xbe\xa6\xaa\x7b\xc9\xc4\xa0\xcb\x0f\xcd\xf5\xce\x07\xfd\xf6\x53\x4c\xb2\x7f\x7f\x18\x20\x4b\xb2\x63\xc0\xde\xb2\xe3\x1c\x10\xc3\xf7\xee\x68\x30\x38\x1f\xd8\x8c\x9b\x61\xc0\xac\xd5\x83\xfe\x9e\x33\xf4\x87\x94\x5e\x9a\x6a\x88\xa2\xaa\x5d\x1e\xed\x85\xd5\x53\x38\x76\x45\xf6\x70\x72\x00\x9e\x18\x33\xa8\xfc\xaa\x7f\xd5\xc1\xbd\xef\x56\xfd\x8b\x7e\x6b\xa8\x5d\x78\xb7\xc0\x71\x7c\x92\x93\x3d\xd4\x7d\x4a\x17\xfd\x23\xf2\x2c\x4d\x5f\x51\xfc\x0c\xbc\x13\x91\xee\xb5\x44\x1d\xe6\xed\xb0\x55\x76\x95\xaf\x9f\xd4\x72\xf5\x79\xd6\x23\x2a\xe6\x69\x7f\x18\x98\x72\x56\xb5\x78\x06\xcf\xdb\x83\x59\x03\xe6\x9c\xf7\x70\x2b\x38\x2a\x2a\x88\xc4\x7e\x61\xd1\x14\x1e\xcd\xfb\x13\xbb\x46\x4d\xb2\x92\x6a\x7b\x45\x0a\x1f\xd5\x85\x49\x18\xc2\x77\x86\x0a\xac\xea\x16\x65\x85\x33\xe3\x1a\x32\x50\xfb\x3b\x55\x66\x75\xf3\xeb\xe5\x9b\xd7\x9d\x06\x58\x1b\x11\x54\xe2\xf4\x7a\xed\x77\xcd\xa7\xda\x4d\x27\x3f\xa4\xa6\x3b\x91\xea\x72\xd0\xdd\x88\xb4\xfd\x28\x6a\xd8\xd0\x77\xdf\xc0\xcc\x5a\x26\x90\xe2\x1c\xf5\xee\xb3\xe9\xb6\x49\x15\x85\x2e\xac\xcf\xa2\x30\xb3\xb9\x98\x9e\xfd\xdf\x00\x6d\x8c\x66\xd3\xd6\x30\x00\x00")
 
 func faucetHtmlBytes() ([]byte, error) {
 	return bindataRead(
@@ -178,6 +178,10 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 	if cfg.Ethstats.URL != "" {
 		utils.RegisterEthStatsService(stack, cfg.Ethstats.URL)
 	}
+	// Add the GraphQL endpoint if requested.
+	if cfg.Node.GraphQLEndpoint() != "" {
+		utils.RegisterGraphQLService(stack, cfg.Node.GraphQLEndpoint(), cfg.Node.GraphQLCors, cfg.Node.GraphQLVirtualHosts, cfg.Node.HTTPTimeouts, cfg.Node.RPCAuth, cfg.Node.RPCLimits)
+	}
 	return stack
 }
 
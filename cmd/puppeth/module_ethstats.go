@@ -34,6 +34,58 @@ var ethstatsDockerfile = `
 FROM puppeth/ethstats:latest
 
 RUN echo 'module.exports = {trusted: [{{.Trusted}}], banned: [{{.Banned}}], reserved: ["yournode"]};' > lib/utils/config.js
+
+ADD consensus.js consensus.js
+RUN node consensus.js && grunt
+`
+
+// ethstatsConsensusPatch is a node script patching the ethstats sources to retain
+// the consensus section of the node stats (reported by wpoa and dbft nodes) and
+// to render it in a column next to the node uptimes.
+var ethstatsConsensusPatch = `
+var fs = require('fs');
+
+// patch replaces the pattern in the given file, warning if it's missing since
+// the ethstats sources might have changed upstream.
+function patch(file, pattern, replacement) {
+	var src = fs.readFileSync(file, 'utf8');
+	if (src.search(pattern) < 0) {
+		console.warn('Consensus section not patched, pattern ' + pattern + ' missing from ' + file);
+		return;
+	}
+	fs.writeFileSync(file, src.replace(pattern, replacement));
+}
+
+// Retain the consensus section of the stats and forward it to the browsers
+patch('lib/node.js', /this\.stats\.uptime = stats\.uptime;/, '$& this.stats.consensus = stats.consensus;');
+patch('lib/node.js', /uptime: this\.stats\.uptime,/g, '$& consensus: this.stats.consensus,');
+patch('src/js/controllers.js', /\$scope\.nodes\[index\]\.stats\.uptime = data\.stats\.uptime;/, '$& $scope.nodes[index].stats.consensus = data.stats.consensus;');
+
+// Render the engine, whether the node is in turn, the size of the sealer set and
+// for dbft the commits and missed slots of the head block
+patch('src/views/index.jade', /^(\s*)(th.*uptime.*)$/mi, '$1$2\n$1th consensus');
+patch('src/views/index.jade', /^(\s*)(td.*node\.stats\.uptime.*)$/m, '$1$2\n$1td.small {{ node.stats.consensus | consensusFilter }}');
+
+fs.appendFileSync('src/js/filters.js', [
+	"",
+	"angular.module('netStatsApp.filters').filter('consensusFilter', function() {",
+	"	return function(consensus) {",
+	"		if (!consensus) {",
+	"			return '';",
+	"		}",
+	"		var sealers = consensus.engine === 'dbft' ? consensus.validators : consensus.signers;",
+	"		var text = consensus.engine + ' ' + (sealers || []).length + ' sealers';",
+	"		if (!/^0x0+$/.test(consensus.sealer) && consensus.sealer === consensus.inturn) {",
+	"			text += ', in turn';",
+	"		}",
+	"		if (consensus.engine === 'dbft') {",
+	"			text += ', ' + consensus.commits + ' commits, ' + consensus.missed + ' missed';",
+	"		}",
+	"		return text;",
+	"	};",
+	"});",
+	""
+].join('\n'));
 `
 
 // ethstatsComposefile is the docker-compose.yml file required to deploy and
@@ -81,6 +133,7 @@ func deployEthstats(client *sshClient, network string, port int, secret string,
 		"Banned":  strings.Join(bannedLabels, ", "),
 	})
 	files[filepath.Join(workdir, "Dockerfile")] = dockerfile.Bytes()
+	files[filepath.Join(workdir, "consensus.js")] = []byte(ethstatsConsensusPatch)
 
 	composefile := new(bytes.Buffer)
 	template.Must(template.New("").Parse(ethstatsComposefile)).Execute(composefile, map[string]interface{}{
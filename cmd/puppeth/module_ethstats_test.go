@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+// Tests that the ethstats Dockerfile ships and runs the consensus patch.
+func TestEthstatsDockerfile(t *testing.T) {
+	dockerfile := new(bytes.Buffer)
+	err := template.Must(template.New("").Parse(ethstatsDockerfile)).Execute(dockerfile, map[string]interface{}{
+		"Trusted": `"1.2.3.4"`,
+		"Banned":  `"5.6.7.8"`,
+	})
+	if err != nil {
+		t.Fatalf("failed to render Dockerfile: %v", err)
+	}
+	for _, line := range []string{
+		`trusted: ["1.2.3.4"], banned: ["5.6.7.8"]`,
+		"ADD consensus.js consensus.js",
+		"RUN node consensus.js && grunt",
+	} {
+		if !strings.Contains(dockerfile.String(), line) {
+			t.Errorf("Dockerfile misses %q:\n%s", line, dockerfile)
+		}
+	}
+}
+
+// ethstatsSources are the parts of the ethstats sources the consensus patch
+// hooks into.
+var ethstatsSources = map[string]string{
+	"lib/node.js": `
+Node.prototype.setStats = function(stats, history)
+{
+	this.stats.uptime = stats.uptime;
+}
+Node.prototype.getStats = function()
+{
+	return {
+		uptime: this.stats.uptime,
+	};
+}
+Node.prototype.getBasicStats = function()
+{
+	return {
+		uptime: this.stats.uptime,
+	};
+}
+`,
+	"src/js/controllers.js": `
+function updateStats(index, data) {
+	$scope.nodes[index].stats.uptime = data.stats.uptime;
+}
+`,
+	"src/views/index.jade": `
+          tr
+            th.th-uptime
+              i.icon-check(data-toggle="tooltip", data-placement="top", title="Up-time", ng-click="orderTable(['-stats.uptime'], false)")
+          tr
+            td.small(class="{{ node.stats.uptime | upTimeClass : node.info.active }}") {{ node.stats.uptime | upTimeFilter }}
+`,
+	"src/js/filters.js": "angular.module('netStatsApp.filters', []);\n",
+}
+
+// Tests that the consensus patch forwards the consensus section of the node stats
+// to the browsers and renders it next to the uptime.
+func TestEthstatsConsensusPatch(t *testing.T) {
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node not found")
+	}
+	dir, err := ioutil.TempDir("", "ethstats")
+	if err != nil {
+		t.Fatalf("failed to create sources: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, content string) {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	for name, content := range ethstatsSources {
+		write(name, content)
+	}
+	write("consensus.js", ethstatsConsensusPatch)
+
+	cmd := exec.Command("node", "consensus.js")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil || len(out) > 0 {
+		t.Fatalf("failed to patch sources: %v\n%s", err, out)
+	}
+	patched := map[string][]string{
+		"lib/node.js":           {"this.stats.consensus = stats.consensus;", "uptime: this.stats.uptime, consensus: this.stats.consensus,"},
+		"src/js/controllers.js": {"$scope.nodes[index].stats.consensus = data.stats.consensus;"},
+		"src/views/index.jade":  {"\n            th consensus\n", "\n            td.small {{ node.stats.consensus | consensusFilter }}\n"},
+	}
+	for name, snippets := range patched {
+		blob, _ := ioutil.ReadFile(filepath.Join(dir, name))
+		for _, snippet := range snippets {
+			if !strings.Contains(string(blob), snippet) {
+				t.Errorf("%s misses %q:\n%s", name, snippet, blob)
+			}
+		}
+		if name == "lib/node.js" && strings.Count(string(blob), "consensus: this.stats.consensus,") != 2 {
+			t.Errorf("consensus not forwarded by every stats getter:\n%s", blob)
+		}
+	}
+	// Render the consensus section of the stats reported by wpoa and dbft nodes
+	write("render.js", `
+var filters = {};
+global.angular = {module: function() { return {filter: function(name, f) { filters[name] = f(); }}; }};
+require('./src/js/filters.js');
+
+var render = filters.consensusFilter;
+console.log(render(undefined));
+console.log(render({engine: 'wpoa', sealer: '0x01', inturn: '0x01', signers: ['0x01', '0x02'], commits: 0, missed: 0}));
+console.log(render({engine: 'dbft', sealer: '0x01', inturn: '0x02', validators: ['0x01', '0x02'], commits: 2, missed: 1}));
+console.log(render({engine: 'dbft', sealer: '0x0000000000000000000000000000000000000000', inturn: '0x0000000000000000000000000000000000000000', commits: 0, missed: 0}));
+`)
+	cmd = exec.Command("node", "render.js")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("failed to render consensus stats: %v\n%s", err, out)
+	}
+	want := "\nwpoa 2 sealers, in turn\ndbft 2 sealers, 2 commits, 1 missed\ndbft 0 sealers, 0 commits, 0 missed\n"
+	if string(out) != want {
+		t.Errorf("rendered consensus stats mismatch:\nhave %q\nwant %q", out, want)
+	}
+}
@@ -238,7 +238,7 @@ func ExportAppendChain(blockchain *core.BlockChain, fn string, first uint64, las
 }
 
 // ImportPreimages imports a batch of exported hash preimages into the database.
-func ImportPreimages(db *ethdb.LDBDatabase, fn string) error {
+func ImportPreimages(db ethdb.Database, fn string) error {
 	log.Info("Importing preimages", "file", fn)
 
 	// Open the file handle and potentially unwrap the gzip stream
@@ -285,7 +285,7 @@ func ImportPreimages(db *ethdb.LDBDatabase, fn string) error {
 
 // ExportPreimages exports all known hash preimages into the specified file,
 // truncating any data already present in the file.
-func ExportPreimages(db *ethdb.LDBDatabase, fn string) error {
+func ExportPreimages(db ethdb.Database, fn string) error {
 	log.Info("Exporting preimages", "file", fn)
 
 	// Open the file handle and potentially wrap with a gzip stream
@@ -310,3 +310,16 @@ func ExportPreimages(db *ethdb.LDBDatabase, fn string) error {
 	log.Info("Exported preimages", "file", fn)
 	return nil
 }
+
+// PrintDatabaseStats prints the internal stats of the database. Every engine only
+// knows its own properties, the ones of other engines are skipped.
+func PrintDatabaseStats(db ethdb.Database) {
+	for _, property := range []string{"leveldb.stats", "leveldb.iostats", "bolt.stats"} {
+		stats, err := db.Stat(property)
+		if err != nil {
+			log.Debug("Database stats unavailable", "property", property, "err", err)
+			continue
+		}
+		fmt.Println(stats)
+	}
+}
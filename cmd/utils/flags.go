@@ -19,6 +19,7 @@ package utils
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -36,6 +37,7 @@ import (
 	"github.com/bcos-one/BCOS/consensus/clique"
 	"github.com/bcos-one/BCOS/consensus/ethash"
 	"github.com/bcos-one/BCOS/core"
+	"github.com/bcos-one/BCOS/core/rawdb"
 	"github.com/bcos-one/BCOS/core/state"
 	"github.com/bcos-one/BCOS/core/vm"
 	"github.com/bcos-one/BCOS/crypto"
@@ -45,10 +47,13 @@ import (
 	"github.com/bcos-one/BCOS/eth/gasprice"
 	"github.com/bcos-one/BCOS/ethdb"
 	"github.com/bcos-one/BCOS/ethstats"
+	"github.com/bcos-one/BCOS/graphql"
 	"github.com/bcos-one/BCOS/les"
 	"github.com/bcos-one/BCOS/log"
 	"github.com/bcos-one/BCOS/metrics"
+	"github.com/bcos-one/BCOS/metrics/exp"
 	"github.com/bcos-one/BCOS/metrics/influxdb"
+	"github.com/bcos-one/BCOS/miner"
 	"github.com/bcos-one/BCOS/node"
 	"github.com/bcos-one/BCOS/p2p"
 	"github.com/bcos-one/BCOS/p2p/discv5"
@@ -56,6 +61,7 @@ import (
 	"github.com/bcos-one/BCOS/p2p/nat"
 	"github.com/bcos-one/BCOS/p2p/netutil"
 	"github.com/bcos-one/BCOS/params"
+	"github.com/bcos-one/BCOS/rpc"
 	whisper "github.com/bcos-one/BCOS/whisper/whisperv6"
 	"gopkg.in/urfave/cli.v1"
 )
@@ -138,10 +144,18 @@ var (
 		Name:  "keystore",
 		Usage: "Directory for the keystore (default = inside the datadir)",
 	}
+	DBEngineFlag = cli.StringFlag{
+		Name:  "db.engine",
+		Usage: `Storage engine for new databases ("leveldb" or "bolt")`,
+	}
 	NoUSBFlag = cli.BoolFlag{
 		Name:  "nousb",
 		Usage: "Disables monitoring for and managing USB hardware wallets",
 	}
+	ExternalSignerFlag = cli.StringFlag{
+		Name:  "signer",
+		Usage: "External signer (url or path to ipc file) replacing the local accounts and sealing the wpoa and dbft blocks of the etherbase",
+	}
 	NetworkIdFlag = cli.Uint64Flag{
 		Name:  "networkid",
 		Usage: "Network identifier (integer, 1=Frontier, 2=Morden (disused), 3=Ropsten, 4=Rinkeby)",
@@ -183,6 +197,24 @@ var (
 		Usage: `Blockchain garbage collection mode ("full", "archive")`,
 		Value: "full",
 	}
+	TokenHistoryFlag = cli.BoolFlag{
+		Name:  "tokenhistory",
+		Usage: "Enables the background indexer of token balance and supply history",
+	}
+	TokenHistoryHorizonFlag = cli.Uint64Flag{
+		Name:  "tokenhistory.horizon",
+		Usage: "Number of recent blocks to retain token history for (0 = keep everything)",
+		Value: eth.DefaultConfig.TokenHistoryHorizon,
+	}
+	AncientThresholdFlag = cli.Uint64Flag{
+		Name:  "ancient.threshold",
+		Usage: "Number of recent blocks kept before moving chain data into the ancient store (0 = disable freezing)",
+		Value: eth.DefaultConfig.AncientThreshold,
+	}
+	NoSnapshotFlag = cli.BoolFlag{
+		Name:  "nosnapshot",
+		Usage: "Disables the flat state snapshot used for fast account, storage and token balance reads",
+	}
 	LightServFlag = cli.IntFlag{
 		Name:  "lightserv",
 		Usage: "Maximum percentage of time allowed for serving LES requests (0-90)",
@@ -301,6 +333,28 @@ var (
 		Usage: "Maximum amount of time non-executable transaction are queued",
 		Value: eth.DefaultConfig.TxPool.Lifetime,
 	}
+	TxPoolSenderRateFlag = cli.Float64Flag{
+		Name:  "txpool.senderrate",
+		Usage: "Transactions per second admitted from a remote sender (0 = unlimited)",
+	}
+	TxPoolSenderBurstFlag = cli.Uint64Flag{
+		Name:  "txpool.senderburst",
+		Usage: "Number of transactions admitted from a remote sender at once",
+		Value: 1,
+	}
+	TxPoolPeerRateFlag = cli.Float64Flag{
+		Name:  "txpool.peerrate",
+		Usage: "Transactions per second admitted from a single peer (0 = unlimited)",
+	}
+	TxPoolPeerBurstFlag = cli.Uint64Flag{
+		Name:  "txpool.peerburst",
+		Usage: "Number of transactions admitted from a single peer at once",
+		Value: 1,
+	}
+	TxPoolExemptFlag = cli.StringFlag{
+		Name:  "txpool.exempt",
+		Usage: "Comma separated accounts exempt from the rate limits and gas quotas",
+	}
 	// Performance tuning settings
 	CacheFlag = cli.IntFlag{
 		Name:  "cache",
@@ -393,6 +447,11 @@ var (
 		Name:  "miner.noverify",
 		Usage: "Disable remote sealing verification",
 	}
+	MinerOrderingFlag = cli.StringFlag{
+		Name:  "miner.ordering",
+		Usage: `Ordering of pending transactions in mined blocks ("price", "fifo" or "roundrobin")`,
+		Value: eth.DefaultConfig.MinerOrdering,
+	}
 	// Account settings
 	UnlockedAccountFlag = cli.StringFlag{
 		Name:  "unlock",
@@ -452,6 +511,22 @@ var (
 		Usage: "API's offered over the HTTP-RPC interface",
 		Value: "",
 	}
+	RPCBatchLimitFlag = cli.IntFlag{
+		Name:  "rpc.batchlimit",
+		Usage: "Maximum number of requests in an HTTP/WS-RPC batch (0 = unlimited)",
+	}
+	RPCResponseLimitFlag = cli.IntFlag{
+		Name:  "rpc.responselimit",
+		Usage: "Maximum size in bytes of an HTTP/WS-RPC response (0 = unlimited)",
+	}
+	RPCCallTimeoutFlag = cli.DurationFlag{
+		Name:  "rpc.calltimeout",
+		Usage: "Maximum execution time of an HTTP/WS-RPC call (0 = unlimited)",
+	}
+	RPCConcurrencyFlag = cli.IntFlag{
+		Name:  "rpc.concurrency",
+		Usage: "Maximum number of concurrently executing HTTP/WS-RPC calls (0 = unlimited)",
+	}
 	IPCDisabledFlag = cli.BoolFlag{
 		Name:  "ipcdisable",
 		Usage: "Disable the IPC-RPC server",
@@ -484,6 +559,30 @@ var (
 		Usage: "Origins from which to accept websockets requests",
 		Value: "",
 	}
+	GraphQLEnabledFlag = cli.BoolFlag{
+		Name:  "graphql",
+		Usage: "Enable the GraphQL query server",
+	}
+	GraphQLListenAddrFlag = cli.StringFlag{
+		Name:  "graphql.addr",
+		Usage: "GraphQL server listening interface",
+		Value: node.DefaultGraphQLHost,
+	}
+	GraphQLPortFlag = cli.IntFlag{
+		Name:  "graphql.port",
+		Usage: "GraphQL server listening port",
+		Value: node.DefaultGraphQLPort,
+	}
+	GraphQLCORSDomainFlag = cli.StringFlag{
+		Name:  "graphql.corsdomain",
+		Usage: "Comma separated list of domains from which to accept cross origin requests (browser enforced)",
+		Value: "",
+	}
+	GraphQLVirtualHostsFlag = cli.StringFlag{
+		Name:  "graphql.vhosts",
+		Usage: "Comma separated list of virtual hostnames from which to accept requests (server enforced). Accepts '*' wildcard.",
+		Value: strings.Join(node.DefaultConfig.GraphQLVirtualHosts, ","),
+	}
 	ExecFlag = cli.StringFlag{
 		Name:  "exec",
 		Usage: "Execute JavaScript statement",
@@ -592,6 +691,20 @@ var (
 		Name:  metrics.MetricsEnabledFlag,
 		Usage: "Enable metrics collection and reporting",
 	}
+	// MetricsHTTPFlag defines the endpoint for a stand-alone metrics HTTP endpoint.
+	// Since the pprof service enables sensitive/vulnerable behavior, this allows a user
+	// to enable a public-OK metrics endpoint without having to worry about ALSO exposing
+	// other profiling behavior or information.
+	MetricsHTTPFlag = cli.StringFlag{
+		Name:  "metrics.addr",
+		Usage: "Enable stand-alone metrics HTTP server listening interface, serving expvar and Prometheus metrics",
+		Value: "",
+	}
+	MetricsPortFlag = cli.IntFlag{
+		Name:  "metrics.port",
+		Usage: "Metrics HTTP server listening port",
+		Value: 6060,
+	}
 	MetricsEnableInfluxDBFlag = cli.BoolFlag{
 		Name:  "metrics.influxdb",
 		Usage: "Enable metrics export/push to an external InfluxDB database",
@@ -636,6 +749,11 @@ var (
 		Usage: "External EVM configuration (default = built-in interpreter)",
 		Value: "",
 	}
+	ParallelTxsFlag = cli.IntFlag{
+		Name:  "vm.parallel",
+		Usage: "Number of workers executing block transactions optimistically in parallel (0 = sequential)",
+		Value: 0,
+	}
 
 	EnableNodePermissionFlag = cli.BoolFlag{
 		Name:  "permissioned",
@@ -814,6 +932,23 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	}
 }
 
+// setRPCLimits applies the resource limits of the HTTP and WebSocket RPC
+// interfaces from the set command line flags.
+func setRPCLimits(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalIsSet(RPCBatchLimitFlag.Name) {
+		cfg.RPCLimits.BatchItems = ctx.GlobalInt(RPCBatchLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCResponseLimitFlag.Name) {
+		cfg.RPCLimits.ResponseBytes = ctx.GlobalInt(RPCResponseLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCCallTimeoutFlag.Name) {
+		cfg.RPCLimits.CallTimeout = ctx.GlobalDuration(RPCCallTimeoutFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCConcurrencyFlag.Name) {
+		cfg.RPCLimits.Concurrency = ctx.GlobalInt(RPCConcurrencyFlag.Name)
+	}
+}
+
 // setWS creates the WebSocket RPC listener interface string from the set
 // command line flags, returning empty if the HTTP endpoint is disabled.
 func setWS(ctx *cli.Context, cfg *node.Config) {
@@ -835,6 +970,26 @@ func setWS(ctx *cli.Context, cfg *node.Config) {
 	}
 }
 
+// setGraphQL creates the GraphQL listener interface string from the set
+// command line flags, returning empty if the GraphQL endpoint is disabled.
+func setGraphQL(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalBool(GraphQLEnabledFlag.Name) && cfg.GraphQLHost == "" {
+		cfg.GraphQLHost = "127.0.0.1"
+		if ctx.GlobalIsSet(GraphQLListenAddrFlag.Name) {
+			cfg.GraphQLHost = ctx.GlobalString(GraphQLListenAddrFlag.Name)
+		}
+	}
+	if ctx.GlobalIsSet(GraphQLPortFlag.Name) {
+		cfg.GraphQLPort = ctx.GlobalInt(GraphQLPortFlag.Name)
+	}
+	if ctx.GlobalIsSet(GraphQLCORSDomainFlag.Name) {
+		cfg.GraphQLCors = splitAndTrim(ctx.GlobalString(GraphQLCORSDomainFlag.Name))
+	}
+	if ctx.GlobalIsSet(GraphQLVirtualHostsFlag.Name) {
+		cfg.GraphQLVirtualHosts = splitAndTrim(ctx.GlobalString(GraphQLVirtualHostsFlag.Name))
+	}
+}
+
 // setIPC creates an IPC path configuration from the set command line flags,
 // returning an empty string if IPC was explicitly disabled, or the set path.
 func setIPC(ctx *cli.Context, cfg *node.Config) {
@@ -1002,6 +1157,8 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setIPC(ctx, cfg)
 	setHTTP(ctx, cfg)
 	setWS(ctx, cfg)
+	setGraphQL(ctx, cfg)
+	setRPCLimits(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
 
 	switch {
@@ -1024,6 +1181,12 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(NoUSBFlag.Name) {
 		cfg.NoUSB = ctx.GlobalBool(NoUSBFlag.Name)
 	}
+	if ctx.GlobalIsSet(ExternalSignerFlag.Name) {
+		cfg.ExternalSigner = ctx.GlobalString(ExternalSignerFlag.Name)
+	}
+	if ctx.GlobalIsSet(DBEngineFlag.Name) {
+		cfg.DatabaseEngine = ctx.GlobalString(DBEngineFlag.Name)
+	}
 }
 
 func setGPO(ctx *cli.Context, cfg *gasprice.Config) {
@@ -1076,6 +1239,24 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolSenderRateFlag.Name) {
+		cfg.SenderRate = ctx.GlobalFloat64(TxPoolSenderRateFlag.Name)
+		cfg.SenderBurst = ctx.GlobalUint64(TxPoolSenderBurstFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolPeerRateFlag.Name) {
+		cfg.PeerRate = ctx.GlobalFloat64(TxPoolPeerRateFlag.Name)
+		cfg.PeerBurst = ctx.GlobalUint64(TxPoolPeerBurstFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolExemptFlag.Name) {
+		exempt := strings.Split(ctx.GlobalString(TxPoolExemptFlag.Name), ",")
+		for _, account := range exempt {
+			if trimmed := strings.TrimSpace(account); !common.IsHexAddress(trimmed) {
+				Fatalf("Invalid account in --txpool.exempt: %s", trimmed)
+			} else {
+				cfg.Exempt = append(cfg.Exempt, common.HexToAddress(account))
+			}
+		}
+	}
 }
 
 func setEthash(ctx *cli.Context, cfg *eth.Config) {
@@ -1197,6 +1378,19 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	}
 	cfg.NoPruning = ctx.GlobalString(GCModeFlag.Name) == "archive"
 
+	if ctx.GlobalIsSet(TokenHistoryFlag.Name) {
+		cfg.TokenHistory = ctx.GlobalBool(TokenHistoryFlag.Name)
+	}
+	if ctx.GlobalIsSet(TokenHistoryHorizonFlag.Name) {
+		cfg.TokenHistoryHorizon = ctx.GlobalUint64(TokenHistoryHorizonFlag.Name)
+	}
+	if ctx.GlobalIsSet(AncientThresholdFlag.Name) {
+		cfg.AncientThreshold = ctx.GlobalUint64(AncientThresholdFlag.Name)
+	}
+	if ctx.GlobalIsSet(NoSnapshotFlag.Name) {
+		cfg.NoSnapshot = ctx.GlobalBool(NoSnapshotFlag.Name)
+	}
+
 	if ctx.GlobalIsSet(CacheFlag.Name) || ctx.GlobalIsSet(CacheGCFlag.Name) {
 		cfg.TrieCache = ctx.GlobalInt(CacheFlag.Name) * ctx.GlobalInt(CacheGCFlag.Name) / 100
 	}
@@ -1233,6 +1427,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 	if ctx.GlobalIsSet(MinerNoVerfiyFlag.Name) {
 		cfg.MinerNoverify = ctx.Bool(MinerNoVerfiyFlag.Name)
 	}
+	if ctx.GlobalIsSet(MinerOrderingFlag.Name) {
+		cfg.MinerOrdering = ctx.GlobalString(MinerOrderingFlag.Name)
+		if !miner.ValidOrdering(cfg.MinerOrdering) {
+			Fatalf("Invalid transaction ordering %q, want price, fifo or roundrobin", cfg.MinerOrdering)
+		}
+	}
 	if ctx.GlobalIsSet(VMEnableDebugFlag.Name) {
 		// TODO(fjl): force-enable this in --dev mode
 		cfg.EnablePreimageRecording = ctx.GlobalBool(VMEnableDebugFlag.Name)
@@ -1246,6 +1446,10 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *eth.Config) {
 		cfg.EVMInterpreter = ctx.GlobalString(EVMInterpreterFlag.Name)
 	}
 
+	if ctx.GlobalIsSet(ParallelTxsFlag.Name) {
+		cfg.ParallelTxs = ctx.GlobalInt(ParallelTxsFlag.Name)
+	}
+
 	// Override any default configs for hard coded networks.
 	switch {
 	case ctx.GlobalBool(TestnetFlag.Name):
@@ -1353,6 +1557,25 @@ func RegisterEthStatsService(stack *node.Node, url string) {
 	}
 }
 
+// RegisterGraphQLService is a utility function to construct a new service and
+// register it against the node.
+func RegisterGraphQLService(stack *node.Node, endpoint string, cors, vhosts []string, timeouts rpc.HTTPTimeouts, auth *rpc.AuthConfig, limits rpc.Limits) {
+	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+		// Serve the queries from the full node if running, or the light client
+		var ethServ *eth.Ethereum
+		if err := ctx.Service(&ethServ); err == nil {
+			return graphql.New(ethServ.APIBackend, endpoint, cors, vhosts, timeouts, auth, limits)
+		}
+		var lesServ *les.LightEthereum
+		if err := ctx.Service(&lesServ); err == nil {
+			return graphql.New(lesServ.ApiBackend, endpoint, cors, vhosts, timeouts, auth, limits)
+		}
+		return nil, errors.New("no Ethereum service to serve GraphQL queries from")
+	}); err != nil {
+		Fatalf("Failed to register the GraphQL service: %v", err)
+	}
+}
+
 func SetupMetrics(ctx *cli.Context) {
 	if metrics.Enabled {
 		log.Info("Enabling metrics collection")
@@ -1371,6 +1594,12 @@ func SetupMetrics(ctx *cli.Context) {
 				"host": hosttag,
 			})
 		}
+
+		if ctx.GlobalIsSet(MetricsHTTPFlag.Name) {
+			address := fmt.Sprintf("%s:%d", ctx.GlobalString(MetricsHTTPFlag.Name), ctx.GlobalInt(MetricsPortFlag.Name))
+			log.Info("Enabling stand-alone metrics HTTP endpoint", "address", address)
+			exp.Setup(address)
+		}
 	}
 }
 
@@ -1388,6 +1617,11 @@ func MakeChainDatabase(ctx *cli.Context, stack *node.Node) ethdb.Database {
 	if err != nil {
 		Fatalf("Could not open database: %v", err)
 	}
+	if path := stack.ResolvePath(name); path != "" {
+		if chainDb, err = rawdb.NewDatabaseWithFreezer(chainDb, filepath.Join(path, "ancient")); err != nil {
+			Fatalf("Could not open ancient database: %v", err)
+		}
+	}
 	return chainDb
 }
 
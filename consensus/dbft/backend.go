@@ -1,6 +1,10 @@
 package dbft
 
-import "github.com/bcos-one/BCOS/common"
+import (
+	"math/big"
+
+	"github.com/bcos-one/BCOS/common"
+)
 
 type Backend interface {
 	// Verify verifies the proposal.
@@ -12,6 +16,10 @@ type Backend interface {
 	// Sign signs input data with the backend's private key
 	Sign([]byte) ([]byte, error)
 
+	// IsSealDomain returns whether domain separated data is signed for the proposal
+	// with the given number.
+	IsSealDomain(number *big.Int) bool
+
 	// CheckSignature verifies the signature by checking if it's signed by
 	// the given validator
 	CheckSignature(data []byte, addr common.Address, sig []byte) error
@@ -1,6 +1,9 @@
 package backend
 
 import (
+	"math/big"
+	"time"
+
 	"github.com/bcos-one/BCOS/common"
 	"github.com/bcos-one/BCOS/consensus"
 	"github.com/bcos-one/BCOS/core/types"
@@ -29,3 +32,70 @@ func (api *API) GetValidators(number *rpc.BlockNumber) ([]common.Address, error)
 
 	return snap.Validators(), nil
 }
+
+// GetLiveness retrieves the slots produced and missed by the validators since the
+// last checkpoint before the specified block.
+func (api *API) GetLiveness(number *rpc.BlockNumber) (map[common.Address]consensus.Liveness, error) {
+	var header *types.Header
+	if number == nil || *number == rpc.LatestBlockNumber {
+		header = api.chain.CurrentHeader()
+	} else {
+		header = api.chain.GetHeaderByNumber(uint64(number.Int64()))
+	}
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	snap, err := api.dbft.dpos.Snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return snap.Liveness(), nil
+}
+
+// Status is the sealing status of the dbft engine at the chain head.
+type Status struct {
+	Validators []common.Address `json:"validators"` // Authorized validators of the current loop
+	InTurn     common.Address   `json:"inturn"`     // Validator owning the current time slot
+	Sealer     common.Address   `json:"sealer"`     // Account sealing blocks on the local node
+	Commits    int              `json:"commits"`    // Number of committed seals of the head block
+	Missed     uint64           `json:"missed"`     // Time slots skipped between the head and its parent
+}
+
+// GetStatus retrieves the validators at the chain head, the validator owning the
+// current time slot and the PBFT commits and missed slots of the head block.
+func (api *API) GetStatus() (*Status, error) {
+	header := api.chain.CurrentHeader()
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	snap, err := api.dbft.dpos.Snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{
+		Validators: snap.Validators(),
+		Sealer:     api.dbft.address,
+	}
+	var (
+		now  = big.NewInt(time.Now().Unix())
+		next = new(big.Int).Add(header.Number, common.Big1)
+	)
+	for _, validator := range status.Validators {
+		if snap.Inturn(validator, now, next) {
+			status.InTurn = validator
+			break
+		}
+	}
+	if header.Number.Sign() > 0 {
+		if extra, err := types.ExtractDbftExtra(header); err == nil {
+			status.Commits = len(extra.CommittedSeal)
+		}
+		parent := api.chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+		if period := api.dbft.config.BlockPeriod; parent != nil && period > 0 {
+			if slots := (header.Time.Uint64() - parent.Time.Uint64()) / period; slots > 1 {
+				status.Missed = slots - 1
+			}
+		}
+	}
+	return status, nil
+}
@@ -1,7 +1,6 @@
 package backend
 
 import (
-	"crypto/ecdsa"
 	"github.com/bcos-one/BCOS/accounts"
 	"github.com/bcos-one/BCOS/common"
 	"github.com/bcos-one/BCOS/consensus"
@@ -10,13 +9,13 @@ import (
 	"github.com/bcos-one/BCOS/consensus/dbft/pbft"
 	"github.com/bcos-one/BCOS/core"
 	"github.com/bcos-one/BCOS/core/types"
-	"github.com/bcos-one/BCOS/crypto"
 	"github.com/bcos-one/BCOS/ethdb"
 	"github.com/bcos-one/BCOS/event"
 	"github.com/bcos-one/BCOS/log"
 	"github.com/bcos-one/BCOS/p2p"
 	"github.com/bcos-one/BCOS/params"
 	"github.com/hashicorp/golang-lru"
+	"math/big"
 	"sync"
 )
 
@@ -27,16 +26,19 @@ const (
 
 type SignerFn func(accounts.Account, []byte) ([]byte, error)
 
-func New(config *params.DbftConfig, privateKey *ecdsa.PrivateKey, db ethdb.Database) consensus.Dbft {
+func New(config *params.DbftConfig, signer consensus.Signer, db ethdb.Database) consensus.Dbft {
 	signatures, _ := lru.NewARC(inmemorySignatures)
-	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	address := signer.Address()
+	recover := func(header *types.Header, sigcache *lru.ARCCache) (common.Address, error) {
+		return ecrecover(config, header, sigcache)
+	}
 
 	backend := &backend{
 		config:     config,
-		privateKey: privateKey,
+		signer:     signer,
 		address:    address,
 		db:         db,
-		dpos:       dpos.New(config, db, signatures, ecrecover),
+		dpos:       dpos.New(config, db, signatures, recover),
 		signatures: signatures,
 	}
 	backend.pbft = pbft.New(backend, address)
@@ -47,11 +49,11 @@ func New(config *params.DbftConfig, privateKey *ecdsa.PrivateKey, db ethdb.Datab
 type backend struct {
 	config *params.DbftConfig
 
-	privateKey *ecdsa.PrivateKey
-	address    common.Address
-	db         ethdb.Database
-	dpos       dbft.DPOS
-	pbft       dbft.PBFT
+	signer  consensus.Signer // Signer of the seals and messages, holding the node key or forwarding to an external signer
+	address common.Address
+	db      ethdb.Database
+	dpos    dbft.DPOS
+	pbft    dbft.PBFT
 
 	// the channels for pbft engine notifications
 	commitCh          chan *types.Block
@@ -71,8 +73,12 @@ type backend struct {
 
 // Sign implements istanbul.Backend.Sign
 func (b *backend) Sign(data []byte) ([]byte, error) {
-	hashData := crypto.Keccak256([]byte(data))
-	return crypto.Sign(hashData, b.privateKey)
+	return b.signer.SignData(accounts.MimetypeDbft, data)
+}
+
+// IsSealDomain implements dbft.Backend.IsSealDomain
+func (b *backend) IsSealDomain(number *big.Int) bool {
+	return b.config.IsSealDomain(number)
 }
 
 func (b *backend) LastProposal() (dbft.Proposal, common.Address) {
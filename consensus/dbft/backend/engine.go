@@ -10,6 +10,7 @@ import (
 	"github.com/bcos-one/BCOS/core/types"
 	"github.com/bcos-one/BCOS/crypto/sha3"
 	"github.com/bcos-one/BCOS/log"
+	"github.com/bcos-one/BCOS/params"
 	"github.com/bcos-one/BCOS/rlp"
 	"github.com/bcos-one/BCOS/rpc"
 	"github.com/hashicorp/golang-lru"
@@ -144,7 +145,7 @@ func (b *backend) verifySigner(chain consensus.ChainReader, header *types.Header
 	}
 
 	// resolve the authorization key and check against signers
-	signer, err := ecrecover(header, b.signatures)
+	signer, err := ecrecover(b.config, header, b.signatures)
 	if err != nil {
 		return err
 	}
@@ -256,6 +257,9 @@ func (b *backend) verifyCommittedSeals(chain consensus.ChainReader, header *type
 	// Check whether the committed seals are generated by parent's validators
 	validSeal := 0
 	proposalSeal := header.Hash().Bytes()
+	if b.config.IsSealDomain(header.Number) {
+		proposalSeal = dbft.CommitData(header.Hash())
+	}
 	// 1. Get committed seals from current header
 	for _, seal := range extra.CommittedSeal {
 		// 2. Get the original address by seal and parent block hash
@@ -298,7 +302,7 @@ func (b *backend) verifySeal(chain consensus.ChainReader, header *types.Header,
 	}
 
 	// Resolve the authorization key and check against signers
-	signer, err := ecrecover(header, b.signatures)
+	signer, err := ecrecover(b.config, header, b.signatures)
 	if err != nil {
 		return err
 	}
@@ -506,7 +510,7 @@ func (b *backend) Stop() error {
 func (b *backend) updateBlock(parent *types.Header, block *types.Block) (*types.Block, error) {
 	header := block.Header()
 	// sign the hash
-	seal, err := b.Sign(sigHash(header).Bytes())
+	seal, err := b.Sign(sealData(b.config, header))
 	if err != nil {
 		return nil, err
 	}
@@ -534,8 +538,17 @@ func sigHash(header *types.Header) (hash common.Hash) {
 	return hash
 }
 
+// sealData returns the data the sealer of a header signs, which is domain separated
+// from the seal domain fork on.
+func sealData(config *params.DbftConfig, header *types.Header) []byte {
+	if config.IsSealDomain(header.Number) {
+		return dbft.SealData(sigHash(header))
+	}
+	return sigHash(header).Bytes()
+}
+
 // ecrecover extracts the Ethereum account address from a signed header.
-func ecrecover(header *types.Header, sigcache *lru.ARCCache) (common.Address, error) {
+func ecrecover(config *params.DbftConfig, header *types.Header, sigcache *lru.ARCCache) (common.Address, error) {
 	// If the signature's already cached, return that
 	hash := header.Hash()
 	if address, known := sigcache.Get(hash); known {
@@ -548,7 +561,7 @@ func ecrecover(header *types.Header, sigcache *lru.ARCCache) (common.Address, er
 		return common.Address{}, err
 	}
 
-	addr, err := dbft.GetSignatureAddress(sigHash(header).Bytes(), dbftExtra.Seal)
+	addr, err := dbft.GetSignatureAddress(sealData(config, header), dbftExtra.Seal)
 	if err != nil {
 		return addr, err
 	}
@@ -0,0 +1,85 @@
+package dbft
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/rlp"
+)
+
+// Domains prefixed to the data validators sign once the seal domain fork is active.
+// Signers hash the data as it is, so without them a signature over a seal hash
+// could be requested for the signing payload of a transaction or any other data.
+// The leading 0x19 keeps them apart from RLP encoded data, the version byte from
+// the other EIP-191 signed data.
+var (
+	sealDomain    = []byte("\x19dbft seal:\n")
+	commitDomain  = []byte("\x19dbft commit:\n")
+	messageDomain = []byte("\x19dbft message:\n")
+)
+
+var (
+	errUnknownSignData = errors.New("unknown dbft consensus data")
+	errInvalidSealData = errors.New("invalid dbft seal hash")
+	errSignedMessage   = errors.New("dbft message already signed")
+	errInvalidMsgCode  = errors.New("invalid dbft message code")
+)
+
+// SealData returns the domain separated data signed to seal a block with the
+// given seal hash.
+func SealData(hash common.Hash) []byte {
+	return append(common.CopyBytes(sealDomain), hash.Bytes()...)
+}
+
+// CommitData returns the domain separated data signed to commit to the block
+// with the given hash.
+func CommitData(hash common.Hash) []byte {
+	return append(common.CopyBytes(commitDomain), hash.Bytes()...)
+}
+
+// MessageData returns the domain separated data signed to send a PBFT message
+// with the given unsigned payload.
+func MessageData(payload []byte) []byte {
+	return append(common.CopyBytes(messageDomain), payload...)
+}
+
+// CheckSignData checks that data to be signed by a validator is a domain separated
+// block seal, committed seal or unsigned PBFT message, returning a description of
+// it. External signers use it to refuse signing anything else.
+func CheckSignData(data []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(data, sealDomain):
+		if len(data) != len(sealDomain)+common.HashLength {
+			return "", errInvalidSealData
+		}
+		return fmt.Sprintf("dbft block seal %x", data[len(sealDomain):]), nil
+
+	case bytes.HasPrefix(data, commitDomain):
+		if len(data) != len(commitDomain)+common.HashLength {
+			return "", errInvalidSealData
+		}
+		return fmt.Sprintf("dbft commit to block %x", data[len(commitDomain):]), nil
+
+	case bytes.HasPrefix(data, messageDomain):
+		payload := data[len(messageDomain):]
+
+		msg := new(Message)
+		if err := rlp.DecodeBytes(payload, msg); err != nil {
+			return "", fmt.Errorf("invalid dbft message: %v", err)
+		}
+		if len(msg.Signature) != 0 {
+			return "", errSignedMessage
+		}
+		if msg.Code > MsgCommit {
+			return "", errInvalidMsgCode
+		}
+		// Only canonical encodings, so the description matches the signed data
+		if enc, err := msg.PayloadNoSig(); err != nil || !bytes.Equal(enc, payload) {
+			return "", fmt.Errorf("invalid dbft message: non-canonical encoding")
+		}
+		return fmt.Sprintf("dbft message %d from %s", msg.Code, msg.Address.Hex()), nil
+	}
+	return "", errUnknownSignData
+}
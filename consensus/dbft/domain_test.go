@@ -0,0 +1,50 @@
+package dbft
+
+import (
+	"testing"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/crypto"
+)
+
+// Tests that only domain separated consensus data passes the signer checks and
+// that messages signed on either side of the seal domain fork verify.
+func TestSignDataDomains(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+
+	msg := &Message{Code: MsgPrepare, Msg: []byte{0x01}, Address: crypto.PubkeyToAddress(key.PublicKey)}
+	payload, err := msg.PayloadNoSig()
+	if err != nil {
+		t.Fatalf("failed to encode message: %v", err)
+	}
+	for i, data := range [][]byte{SealData(common.Hash{1}), CommitData(common.Hash{2}), MessageData(payload)} {
+		if _, err := CheckSignData(data); err != nil {
+			t.Errorf("data %d: consensus data refused: %v", i, err)
+		}
+	}
+	for i, data := range [][]byte{payload, common.Hash{1}.Bytes(), SealData(common.Hash{1})[1:], append(SealData(common.Hash{1}), 0x00)} {
+		if _, err := CheckSignData(data); err == nil {
+			t.Errorf("data %d: non-consensus data accepted", i)
+		}
+	}
+	for i, data := range [][]byte{payload, MessageData(payload)} {
+		if msg.Signature, err = crypto.Sign(crypto.Keccak256(data), key); err != nil {
+			t.Fatalf("failed to sign message: %v", err)
+		}
+		if err := msg.CheckSignature(); err != nil {
+			t.Errorf("message %d: signature refused: %v", i, err)
+		}
+		if _, err := CheckSignData(MessageData(mustPayload(t, msg))); err != errSignedMessage {
+			t.Errorf("message %d: signed message error mismatch: have %v, want %v", i, err, errSignedMessage)
+		}
+	}
+}
+
+// mustPayload returns the encoding of a message including its signature.
+func mustPayload(t *testing.T, msg *Message) []byte {
+	payload, err := msg.Payload()
+	if err != nil {
+		t.Fatalf("failed to encode message: %v", err)
+	}
+	return payload
+}
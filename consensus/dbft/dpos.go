@@ -16,6 +16,10 @@ type Snapshot interface {
 
 	// Intrun returns next timestamp when validator can sign a block
 	NextTimeSlot(validator common.Address) *big.Int
+
+	// Liveness returns the slots produced and missed by the validators since the
+	// last checkpoint.
+	Liveness() map[common.Address]consensus.Liveness
 }
 
 type DPOS interface {
@@ -15,10 +15,13 @@ import (
 	"github.com/bcos-one/BCOS/core/vm"
 	"github.com/bcos-one/BCOS/ethdb"
 	"github.com/bcos-one/BCOS/log"
+	"github.com/bcos-one/BCOS/metrics"
 	"github.com/bcos-one/BCOS/params"
 	"github.com/hashicorp/golang-lru"
 	"math/big"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 const (
@@ -52,6 +55,9 @@ type DPos struct {
 	sigcache *lru.ARCCache // Cache of recent block signatures to speed up ecrecover
 	recents  *lru.ARCCache // Snapshots for recent block to speed up reorgs
 	recover  recoverFunc
+
+	metered     uint64    // Highest block whose slots were metered (atomic access)
+	meteredInit sync.Once // Starts the metering at the chain head
 }
 
 func New(config *params.DbftConfig, db ethdb.Database, sigcache *lru.ARCCache, recover recoverFunc) dbft.DPOS {
@@ -74,6 +80,13 @@ func (d *DPos) Snapshot(chain consensus.ChainReader, number uint64, hash common.
 		headers []*types.Header
 		snap    *Snapshot
 	)
+	// Only meter the blocks beyond the head known at startup, not the ones of
+	// recomputed or historical snapshots
+	d.meteredInit.Do(func() {
+		if head := chain.CurrentHeader(); head != nil {
+			atomic.StoreUint64(&d.metered, head.Number.Uint64())
+		}
+	})
 
 	for snap == nil {
 		// If an in-memory snapshot was found, use that
@@ -111,6 +124,11 @@ func (d *DPos) Snapshot(chain consensus.ChainReader, number uint64, hash common.
 				if err != nil {
 					return nil, err
 				}
+				if number > 0 && d.config.IsExclusion(checkpoint.Number) {
+					if validators, err = d.excludeOffline(chain, checkpoint, validators); err != nil {
+						return nil, err
+					}
+				}
 
 				snap = newSnapshot(d, number, hash, validators, checkpoint.Time.Uint64())
 				if err := snap.store(d.db); err != nil {
@@ -150,6 +168,11 @@ func (d *DPos) Snapshot(chain consensus.ChainReader, number uint64, hash common.
 		return nil, err
 	}
 	d.recents.Add(snap.Hash, snap)
+	if len(headers) > 0 && snap.Number >= atomic.LoadUint64(&d.metered) {
+		for validator, record := range snap.Records {
+			metrics.GetOrRegisterGauge("consensus/dbft/missed/"+validator.Hex(), nil).Update(int64(record.Missed))
+		}
+	}
 
 	// If we've generated a new checkpoint snapshot, save to disk
 	if snap.Number%checkpointInterval == 0 && len(headers) > 0 {
@@ -161,6 +184,79 @@ func (d *DPos) Snapshot(chain consensus.ChainReader, number uint64, hash common.
 	return snap, err
 }
 
+// excludeOffline drops the candidates which missed more than the configured share
+// of their slots in the epoch preceding the checkpoint. Excluded candidates are
+// eligible again from the following epoch on. If all the candidates were offline,
+// none of them is excluded.
+func (d *DPos) excludeOffline(chain consensus.ChainReader, checkpoint *types.Header, candidates []common.Address) ([]common.Address, error) {
+	records, err := d.epochLiveness(chain, checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	online := make([]common.Address, 0, len(candidates))
+	for _, candidate := range candidates {
+		if record, ok := records[candidate]; ok && record.MissedPercent() > d.config.Exclusion {
+			log.Info("Excluding offline validator", "number", checkpoint.Number, "validator", candidate, "produced", record.Produced, "missed", record.Missed)
+			continue
+		}
+		online = append(online, candidate)
+	}
+	if len(online) == 0 {
+		log.Warn("All validators offline, excluding none", "number", checkpoint.Number)
+		return candidates, nil
+	}
+	return online, nil
+}
+
+// epochLiveness counts the slots produced and missed by the validators in the
+// epoch preceding the checkpoint. The records are recounted from the headers of
+// the epoch, as the ones of persisted snapshots depend on when the node started
+// accounting them.
+func (d *DPos) epochLiveness(chain consensus.ChainReader, checkpoint *types.Header) (map[common.Address]consensus.Liveness, error) {
+	var (
+		number  = checkpoint.Number.Uint64()
+		start   = number - d.config.Epoch
+		hash    = checkpoint.ParentHash
+		headers = make([]*types.Header, 0, d.config.Epoch)
+	)
+	for n := number - 1; n > start; n-- {
+		header := chain.GetHeader(hash, n)
+		if header == nil {
+			return nil, consensus.ErrUnknownAncestor
+		}
+		headers = append(headers, header)
+		hash = header.ParentHash
+	}
+	for i := 0; i < len(headers)/2; i++ {
+		headers[i], headers[len(headers)-1-i] = headers[len(headers)-1-i], headers[i]
+	}
+	parent, err := d.Snapshot(chain, start, hash, nil)
+	if err != nil {
+		return nil, err
+	}
+	epoch := parent.(*Snapshot)
+
+	snap, err := newSnapshot(d, start, hash, epoch.Validator, epoch.LoopStartTime).apply(headers)
+	if err != nil {
+		return nil, err
+	}
+	return snap.Records, nil
+}
+
+// meter reports whether the slots of the block with the given number are to be
+// metered, which they are once as it becomes the new head.
+func (d *DPos) meter(number uint64) bool {
+	for {
+		metered := atomic.LoadUint64(&d.metered)
+		if number <= metered {
+			return false
+		}
+		if atomic.CompareAndSwapUint64(&d.metered, metered, number) {
+			return true
+		}
+	}
+}
+
 // loadSnapshot loads an existing snapshot from the database.
 func (d *DPos) loadSnapshot(db ethdb.Database, hash common.Hash) (*Snapshot, error) {
 	blob, err := db.Get(append([]byte("dpos-"), hash[:]...))
@@ -4,13 +4,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/consensus"
 	"github.com/bcos-one/BCOS/consensus/dbft"
 	"github.com/bcos-one/BCOS/core/types"
 	"github.com/bcos-one/BCOS/ethdb"
+	"github.com/bcos-one/BCOS/metrics"
 	"math/big"
 	"time"
 )
 
+var (
+	producedMeter  = metrics.NewRegisteredMeter("consensus/dbft/slots/produced", nil)  // Blocks sealed in the slot of their validator
+	outOfTurnMeter = metrics.NewRegisteredMeter("consensus/dbft/slots/outofturn", nil) // Blocks sealed in the slot of another validator
+	missedMeter    = metrics.NewRegisteredMeter("consensus/dbft/slots/missed", nil)    // Slots passed without a block
+)
+
 type Snapshot struct {
 	dpos          *DPos
 	Number        uint64                    `json:"Number"`        // Block Number where the snapshot was created
@@ -18,6 +26,9 @@ type Snapshot struct {
 	Validator     []common.Address          `json:"Validator"`     // Set of authorized Validator at this moment
 	Recents       map[uint64]common.Address `json:"Recents"`       // Set of recent signers for spam protections
 	LoopStartTime uint64                    `json:"LoopStartTime"` // Start Time of the current loop
+	Time          uint64                    `json:"Time"`          // Timestamp of the block where the snapshot was created
+
+	Records map[common.Address]consensus.Liveness `json:"Records"` // Slots produced and missed by the validators since the checkpoint
 }
 
 func newSnapshot(dpos *DPos, number uint64, hash common.Hash, validators []common.Address, loopStartTime uint64) *Snapshot {
@@ -28,6 +39,8 @@ func newSnapshot(dpos *DPos, number uint64, hash common.Hash, validators []commo
 		Validator:     validators,
 		Recents:       make(map[uint64]common.Address),
 		LoopStartTime: loopStartTime,
+		Time:          loopStartTime,
+		Records:       make(map[common.Address]consensus.Liveness),
 	}
 
 	return snap
@@ -37,8 +50,13 @@ func (s *Snapshot) Validators() dbft.Validators {
 	return s.Validator
 }
 
+// Liveness returns the slots produced and missed by the validators since the
+// last checkpoint.
+func (s *Snapshot) Liveness() map[common.Address]consensus.Liveness {
+	return s.Records
+}
+
 func (s *Snapshot) Inturn(validator common.Address, headerTime *big.Int, blockNumber *big.Int) bool {
-	period := s.dpos.config.BlockPeriod
 	time := headerTime.Uint64()
 	number := blockNumber.Uint64()
 
@@ -50,13 +68,14 @@ func (s *Snapshot) Inturn(validator common.Address, headerTime *big.Int, blockNu
 		}
 	}
 
-	loopIndex := int((time-s.LoopStartTime)/period) % len(s.Validator)
+	return s.slotOwner(time) == validator
+}
 
-	if s.Validator[loopIndex] == validator {
-		return true
-	}
+// slotOwner returns the validator owning the time slot of the given timestamp.
+func (s *Snapshot) slotOwner(time uint64) common.Address {
+	loopIndex := int((time-s.LoopStartTime)/s.dpos.config.BlockPeriod) % len(s.Validator)
 
-	return false
+	return s.Validator[loopIndex]
 }
 
 func (s *Snapshot) NextTimeSlot(signer common.Address) *big.Int {
@@ -128,6 +147,7 @@ func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
 				return nil, errRecentlySigned
 			}
 		}
+		snap.account(header, validator)
 		snap.Recents[number] = validator
 	}
 
@@ -137,6 +157,52 @@ func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
 	return snap, nil
 }
 
+// account updates the liveness records with the header sealed by the validator,
+// charging the validators owning the slots skipped since the previous block.
+func (s *Snapshot) account(header *types.Header, validator common.Address) {
+	var (
+		period = s.dpos.config.BlockPeriod
+		time   = header.Time.Uint64()
+		count  = uint64(len(s.Validator))
+		meter  = s.dpos.meter(header.Number.Uint64())
+	)
+	// Snapshots stored before the accounting have no timestamp, start afresh
+	if count > 0 && s.Time >= s.LoopStartTime && time > s.Time {
+		from := (s.Time-s.LoopStartTime)/period + 1
+		if to := (time - s.LoopStartTime) / period; to > from {
+			skipped := to - from
+			for i, owner := range s.Validator {
+				missed := skipped / count
+				if (uint64(i)+count-from%count)%count < skipped%count {
+					missed++
+				}
+				if missed > 0 {
+					record := s.Records[owner]
+					record.Missed += missed
+					s.Records[owner] = record
+				}
+			}
+			if meter {
+				missedMeter.Mark(int64(skipped))
+			}
+		}
+	}
+	record := s.Records[validator]
+	if s.Inturn(validator, header.Time, header.Number) {
+		record.Produced++
+		if meter {
+			producedMeter.Mark(1)
+		}
+	} else {
+		record.OutOfTurn++
+		if meter {
+			outOfTurnMeter.Mark(1)
+		}
+	}
+	s.Records[validator] = record
+	s.Time = time
+}
+
 // copy creates a deep copy of the snapshot, though not the individual votes.
 func (s *Snapshot) copy() *Snapshot {
 	cpy := &Snapshot{
@@ -144,12 +210,17 @@ func (s *Snapshot) copy() *Snapshot {
 		Number:        s.Number,
 		Hash:          s.Hash,
 		LoopStartTime: s.LoopStartTime,
+		Time:          s.Time,
 		Validator:     s.Validator,
 		Recents:       make(map[uint64]common.Address),
+		Records:       make(map[common.Address]consensus.Liveness),
 	}
 	for block, signer := range s.Recents {
 		cpy.Recents[block] = signer
 	}
+	for validator, record := range s.Records {
+		cpy.Records[validator] = record
+	}
 
 	return cpy
 }
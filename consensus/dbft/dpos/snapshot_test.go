@@ -2,7 +2,11 @@ package dpos
 
 import (
 	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/consensus"
 	"github.com/bcos-one/BCOS/consensus/dbft"
+	"github.com/bcos-one/BCOS/core/types"
+	"github.com/bcos-one/BCOS/params"
+	"github.com/hashicorp/golang-lru"
 	"math/big"
 	"testing"
 )
@@ -83,9 +87,115 @@ func TestNextTimeSlot(t *testing.T) {
 	}
 }
 
+// Tests that the liveness records count the blocks sealed by the validators and
+// charge the validators owning the slots skipped between blocks.
+func TestLiveness(t *testing.T) {
+	snapshot := fakeSnapshot()
+	snapshot.dpos.recover = func(header *types.Header, sigcache *lru.ARCCache) (common.Address, error) {
+		return header.Coinbase, nil
+	}
+	headers := []*types.Header{
+		{Number: big.NewInt(1), Time: new(big.Int).SetUint64(loopStartTime + 1), Coinbase: validator2},
+		{Number: big.NewInt(2), Time: new(big.Int).SetUint64(loopStartTime + 3), Coinbase: validator4},
+		{Number: big.NewInt(3), Time: new(big.Int).SetUint64(loopStartTime + 14), Coinbase: validator5},
+	}
+	snap, err := snapshot.apply(headers)
+	if err != nil {
+		t.Fatalf("failed to apply headers: %v", err)
+	}
+	want := map[common.Address]consensus.Liveness{
+		validator1: {Missed: 2},
+		validator2: {Produced: 1, Missed: 2},
+		validator3: {Missed: 3},
+		validator4: {Produced: 1, Missed: 2},
+		validator5: {Produced: 1, Missed: 2},
+	}
+	for validator, record := range want {
+		if have := snap.Liveness()[validator]; have != record {
+			t.Errorf("validator %x: liveness mismatch: have %+v, want %+v", validator, have, record)
+		}
+	}
+	if len(snapshot.Records) != 0 {
+		t.Errorf("parent snapshot modified: %v", snapshot.Records)
+	}
+	if have := snap.Liveness()[validator3].MissedPercent(); have != 100 {
+		t.Errorf("missed percentage mismatch: have %d, want 100", have)
+	}
+}
+
+// Tests that offline validators are excluded based on the slots recounted from the
+// headers of the epoch, not on the records of a stale snapshot.
+func TestExcludeOffline(t *testing.T) {
+	config := &params.DbftConfig{BlockPeriod: 1, Epoch: 6, Exclusion: 50, ExclusionBlock: big.NewInt(0)}
+	if !config.IsExclusion(big.NewInt(6)) || (&params.DbftConfig{Epoch: 6, ExclusionBlock: big.NewInt(0)}).IsExclusion(big.NewInt(6)) {
+		t.Fatalf("exclusion activation mismatch")
+	}
+	recents, _ := lru.NewARC(inmemorySnapshots)
+	d := &DPos{
+		config:  config,
+		recents: recents,
+		recover: func(header *types.Header, sigcache *lru.ARCCache) (common.Address, error) {
+			return header.Coinbase, nil
+		},
+	}
+	chain := &fakeChain{headers: make(map[common.Hash]*types.Header)}
+
+	// Validator1 misses its only slot of the epoch after the checkpoint
+	parent := chain.add(&types.Header{Number: big.NewInt(0), Time: new(big.Int).SetUint64(loopStartTime)})
+	d.recents.Add(parent.Hash(), newSnapshot(d, 0, parent.Hash(), validator, loopStartTime))
+
+	for i, sealer := range []common.Address{validator2, validator3, validator4, validator5, validator2} {
+		slot := uint64(i + 1)
+		if i == 4 {
+			slot++
+		}
+		parent = chain.add(&types.Header{ParentHash: parent.Hash(), Number: big.NewInt(int64(i + 1)), Time: new(big.Int).SetUint64(loopStartTime + slot), Coinbase: sealer})
+	}
+	// A stale snapshot without records must not be picked up
+	stale := newSnapshot(d, 5, parent.Hash(), validator, loopStartTime)
+	stale.Time = 0
+	d.recents.Add(parent.Hash(), stale)
+
+	checkpoint := chain.add(&types.Header{ParentHash: parent.Hash(), Number: big.NewInt(6), Time: new(big.Int).SetUint64(loopStartTime + 7)})
+	online, err := d.excludeOffline(chain, checkpoint, validator)
+	if err != nil {
+		t.Fatalf("failed to exclude offline validators: %v", err)
+	}
+	if want := []common.Address(validator[1:]); len(online) != len(want) {
+		t.Fatalf("online validators mismatch: have %x, want %x", online, want)
+	}
+	for _, address := range online {
+		if address == validator1 {
+			t.Fatalf("offline validator not excluded: %x", online)
+		}
+	}
+}
+
+// fakeChain is a header chain to check the epoch accounting against.
+type fakeChain struct {
+	headers map[common.Hash]*types.Header
+}
+
+func (c *fakeChain) add(header *types.Header) *types.Header {
+	c.headers[header.Hash()] = header
+	return header
+}
+
+func (c *fakeChain) Config() *params.ChainConfig  { return nil }
+func (c *fakeChain) CurrentHeader() *types.Header { return nil }
+func (c *fakeChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := c.headers[hash]; header != nil && header.Number.Uint64() == number {
+		return header
+	}
+	return nil
+}
+func (c *fakeChain) GetHeaderByNumber(number uint64) *types.Header         { return nil }
+func (c *fakeChain) GetHeaderByHash(hash common.Hash) *types.Header        { return c.headers[hash] }
+func (c *fakeChain) GetBlock(hash common.Hash, number uint64) *types.Block { return nil }
+
 func fakeSnapshot() *Snapshot {
 	dpos := &DPos{
-		config: dbft.DefaultConfig,
+		config: params.DefaultConfig,
 	}
 
 	return &Snapshot{
@@ -95,5 +205,7 @@ func fakeSnapshot() *Snapshot {
 		Validator:     validator,
 		Recents:       make(map[uint64]common.Address),
 		LoopStartTime: loopStartTime,
+		Time:          loopStartTime,
+		Records:       make(map[common.Address]consensus.Liveness),
 	}
 }
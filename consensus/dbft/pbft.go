@@ -70,7 +70,12 @@ func (m *Message) CheckSignature() error {
 	if err != nil {
 		return err
 	}
-
+	// Messages aren't bound to a block, accept both sides of the seal domain fork
+	if signer != m.Address {
+		if signer, err = CheckValidatorSignature(MessageData(payload), m.Signature); err != nil {
+			return err
+		}
+	}
 	if signer != m.Address {
 		return errors.New("invalid signature")
 	}
@@ -40,6 +40,7 @@ func (e *engine) Commit(msg *dbft.Message) (error) {
 
 		e.backend.Commit(state.preprepare.Proposal, state.commitSeals())
 		state.finished = true
+		roundTimer.UpdateSince(state.started)
 	}
 
 	return nil
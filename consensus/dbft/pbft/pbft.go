@@ -7,6 +7,7 @@ import (
 	"github.com/bcos-one/BCOS/consensus/dbft"
 	"github.com/bcos-one/BCOS/event"
 	"github.com/bcos-one/BCOS/log"
+	"github.com/bcos-one/BCOS/metrics"
 	"github.com/bcos-one/BCOS/p2p"
 	"sync"
 	"sync/atomic"
@@ -41,6 +42,11 @@ var (
 
 	errInvalidProposal = errors.New("invalid proposal")
 )
+
+// roundTimer meters the duration of the PBFT rounds, from the pre-prepare until
+// the proposal is committed.
+var roundTimer = metrics.NewRegisteredTimer("consensus/dbft/round", nil)
+
 // New create pbft engine
 func New(backend dbft.Backend, address common.Address) dbft.PBFT {
 	e := &engine{
@@ -223,8 +229,13 @@ func (e *engine) finalizeMessage(msg *dbft.Message, proposal dbft.Proposal) ([]b
 	// Add proof of consensus
 	msg.CommittedSeal = []byte{}
 
+	separated := e.backend.IsSealDomain(proposal.Number())
 	if msg.Code == dbft.MsgCommit {
-		msg.CommittedSeal, err = e.backend.Sign(proposal.Hash().Bytes())
+		seal := proposal.Hash().Bytes()
+		if separated {
+			seal = dbft.CommitData(proposal.Hash())
+		}
+		msg.CommittedSeal, err = e.backend.Sign(seal)
 		if err != nil {
 			return nil, err
 		}
@@ -235,6 +246,9 @@ func (e *engine) finalizeMessage(msg *dbft.Message, proposal dbft.Proposal) ([]b
 	if err != nil {
 		return nil, err
 	}
+	if separated {
+		data = dbft.MessageData(data)
+	}
 	msg.Signature, err = e.backend.Sign(data)
 	if err != nil {
 		return nil, err
@@ -7,6 +7,7 @@ import (
 	"github.com/bcos-one/BCOS/log"
 	"math/big"
 	"reflect"
+	"time"
 )
 
 type State struct {
@@ -16,6 +17,7 @@ type State struct {
 	prepares   map[common.Address]bool
 	commits    map[common.Address]*dbft.Message
 	finished   bool
+	started    time.Time // Time the round started, to meter its duration
 }
 
 func newState(validators dbft.Validators, proposer common.Address, proposal dbft.Proposal) *State {
@@ -31,6 +33,7 @@ func newState(validators dbft.Validators, proposer common.Address, proposal dbft
 		},
 		prepares: make(map[common.Address]bool),
 		commits:  make(map[common.Address]*dbft.Message),
+		started:  time.Now(),
 	}
 }
 
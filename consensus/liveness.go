@@ -0,0 +1,18 @@
+package consensus
+
+// Liveness is the block production record of a sealer, accounted by the engines
+// while applying headers to their snapshots. Records are reset at each epoch
+// checkpoint together with the snapshots.
+type Liveness struct {
+	Produced  uint64 `json:"produced"`  // Blocks sealed in the sealer's own turn
+	OutOfTurn uint64 `json:"outOfTurn"` // Blocks sealed in the turn of another sealer
+	Missed    uint64 `json:"missed"`    // Turns of the sealer passed without its block
+}
+
+// MissedPercent returns the percentage of its own turns the sealer missed.
+func (l Liveness) MissedPercent() uint64 {
+	if turns := l.Produced + l.Missed; turns > 0 {
+		return l.Missed * 100 / turns
+	}
+	return 0
+}
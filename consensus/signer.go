@@ -0,0 +1,83 @@
+package consensus
+
+import (
+	"crypto/ecdsa"
+	"errors"
+
+	"github.com/bcos-one/BCOS/accounts"
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/crypto"
+)
+
+// errInvalidSignature is returned if a signer returns a malformed signature.
+var errInvalidSignature = errors.New("invalid signature length")
+
+// Signer signs headers and consensus messages on behalf of the sealing account,
+// allowing the sealing key to be kept outside of the node.
+type Signer interface {
+	// Address returns the address of the sealing account.
+	Address() common.Address
+
+	// SignData signs the keccak256 hash of the data, which is of the given
+	// content type. The signature is in the [R || S || V] format where V is 0 or 1.
+	SignData(mimeType string, data []byte) ([]byte, error)
+}
+
+// keySigner is a Signer holding the sealing key in memory.
+type keySigner struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+}
+
+// NewKeySigner creates a signer sealing with the given private key, typically
+// the node key.
+func NewKeySigner(key *ecdsa.PrivateKey) Signer {
+	return &keySigner{key: key, address: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+// Address implements Signer, returning the address of the key.
+func (s *keySigner) Address() common.Address {
+	return s.address
+}
+
+// SignData implements Signer, signing the hash of the data with the key.
+func (s *keySigner) SignData(mimeType string, data []byte) ([]byte, error) {
+	return crypto.Sign(crypto.Keccak256(data), s.key)
+}
+
+// SignDataFn is a signer callback function to request the signing of typed data
+// by a backing account, e.g. an account of an external signer.
+type SignDataFn func(account accounts.Account, mimeType string, data []byte) ([]byte, error)
+
+// accountSigner is a Signer forwarding the signing requests to an account backend.
+type accountSigner struct {
+	account accounts.Account
+	signFn  SignDataFn
+}
+
+// NewAccountSigner creates a signer sealing with the given account of an account
+// backend, such as the external signer.
+func NewAccountSigner(account accounts.Account, signFn SignDataFn) Signer {
+	return &accountSigner{account: account, signFn: signFn}
+}
+
+// Address implements Signer, returning the address of the backing account.
+func (s *accountSigner) Address() common.Address {
+	return s.account.Address
+}
+
+// SignData implements Signer, requesting the signature from the backing account.
+func (s *accountSigner) SignData(mimeType string, data []byte) ([]byte, error) {
+	sig, err := s.signFn(s.account, mimeType, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(sig) != 65 {
+		return nil, errInvalidSignature
+	}
+	// Signers return V as 27/28 according to the yellow paper, the engines expect 0/1
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	return sig, nil
+}
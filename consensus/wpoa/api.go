@@ -119,4 +119,62 @@ func (api *API) GetManagersAtHash(hash common.Hash) ([]common.Address, error) {
 		return nil, err
 	}
 	return snap.managers(), nil
-}
\ No newline at end of file
+}
+
+// GetLiveness retrieves the turns produced and missed by the signers since the
+// last checkpoint before the specified block.
+func (api *API) GetLiveness(number *rpc.BlockNumber) (map[common.Address]consensus.Liveness, error) {
+	// Retrieve the requested block number (or current if none requested)
+	var header *types.Header
+	if number == nil || *number == rpc.LatestBlockNumber {
+		header = api.chain.CurrentHeader()
+	} else {
+		header = api.chain.GetHeaderByNumber(uint64(number.Int64()))
+	}
+	// Ensure we have an actually valid block and return the records from its snapshot
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	snap, err := api.poa.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return snap.Liveness, nil
+}
+
+// Status is the sealing status of the proof-of-authority scheme at the chain head.
+type Status struct {
+	Signers  []common.Address `json:"signers"`  // Authorized signers in ascending order
+	Managers []common.Address `json:"managers"` // Authorized managers in ascending order
+	InTurn   common.Address   `json:"inturn"`   // Signer in turn to seal the next block
+	Sealer   common.Address   `json:"sealer"`   // Account sealing blocks on the local node
+}
+
+// GetStatus retrieves the signers and managers at the chain head, along with the
+// signer in turn to seal the next block.
+func (api *API) GetStatus() (*Status, error) {
+	header := api.chain.CurrentHeader()
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	snap, err := api.poa.snapshot(api.chain, header.Number.Uint64(), header.Hash(), nil)
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{
+		Signers:  snap.signers(),
+		Managers: snap.managers(),
+	}
+	next := header.Number.Uint64() + 1
+	for _, signer := range status.Signers {
+		if snap.inturn(next, signer) {
+			status.InTurn = signer
+			break
+		}
+	}
+	api.poa.lock.RLock()
+	status.Sealer = api.poa.signer
+	api.poa.lock.RUnlock()
+
+	return status, nil
+}
@@ -53,21 +53,15 @@ func (w *WPoa) processCustomTx(chain consensus.ChainReader, header *types.Header
 			continue
 		}
 
-		txData := string(tx.Data())
-		if len(txData) < len(wtxPrefix) || !strings.HasPrefix(txData, wtxPrefix+":") {
-			continue
-		}
-
-		txDataInfo := strings.Split(txData, ":")
-		//wtx:version:category:data
-		if len(txDataInfo) != 4 || txDataInfo[1] != wtxVersion  {
+		op := ParseCustomTx(tx.Data())
+		if op == nil {
 			continue
 		}
 
 		change = true
 
-		address := common.HexToAddress(txDataInfo[3])
-		switch txDataInfo[2] {
+		address := op.Address
+		switch op.Category {
 		case wtxCategoryAddSigner:
 			if !snap.isSigner(address) {
 				//snap.Signers[address] = struct {}{}
@@ -99,3 +93,40 @@ func (w *WPoa) processCustomTx(chain consensus.ChainReader, header *types.Header
 
 	return nil, nil
 }
+
+// CustomTx is a management operation carried in the data of a transaction sent
+// by a manager, encoded as wtx:version:category:data.
+type CustomTx struct {
+	Category string         // Operation to apply, e.g. AddSigner
+	Address  common.Address // Signer or manager the operation applies to
+}
+
+// IsCustomTx reports whether the transaction data is meant as a management
+// operation, regardless of whether it is a well formed one.
+func IsCustomTx(data []byte) bool {
+	return strings.HasPrefix(string(data), wtxPrefix+":")
+}
+
+// ParseCustomTx decodes the management operation in the transaction data, or
+// returns nil if the data isn't an operation of the supported version.
+func ParseCustomTx(data []byte) *CustomTx {
+	if !IsCustomTx(data) {
+		return nil
+	}
+	//wtx:version:category:data
+	txDataInfo := strings.Split(string(data), ":")
+	if len(txDataInfo) != 4 || txDataInfo[1] != wtxVersion {
+		return nil
+	}
+	return &CustomTx{Category: txDataInfo[2], Address: common.HexToAddress(txDataInfo[3])}
+}
+
+// Known reports whether the operation is one the consensus engine acts upon,
+// unknown ones are ignored.
+func (op *CustomTx) Known() bool {
+	switch op.Category {
+	case wtxCategoryAddSigner, wtxCategoryRemoveSigner, wtxCategoryAddManager, wtxCategoryRemoveManager:
+		return true
+	}
+	return false
+}
@@ -23,11 +23,22 @@ import (
 
 	"github.com/hashicorp/golang-lru"
 	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/consensus"
 	"github.com/bcos-one/BCOS/core/types"
 	"github.com/bcos-one/BCOS/ethdb"
+	"github.com/bcos-one/BCOS/metrics"
 	"github.com/bcos-one/BCOS/params"
 )
 
+var (
+	signerAddedMeter   = metrics.NewRegisteredMeter("consensus/wpoa/signers/added", nil)   // Signers authorized by the applied headers
+	signerRemovedMeter = metrics.NewRegisteredMeter("consensus/wpoa/signers/removed", nil) // Signers discarded by the applied headers
+	signerGauge        = metrics.NewRegisteredGauge("consensus/wpoa/signers", nil)         // Number of signers of the latest snapshot
+
+	producedMeter  = metrics.NewRegisteredMeter("consensus/wpoa/turns/produced", nil)  // Blocks sealed by the signer in turn
+	outOfTurnMeter = metrics.NewRegisteredMeter("consensus/wpoa/turns/outofturn", nil) // Blocks sealed by a signer out of turn
+	missedMeter    = metrics.NewRegisteredMeter("consensus/wpoa/turns/missed", nil)    // Turns missed by the signer in turn
+)
 
 // Snapshot is the state of the authorization voting at a given point in time.
 type Snapshot struct {
@@ -39,6 +50,8 @@ type Snapshot struct {
 	Managers map[common.Address]struct{} `json:"managers"` // set of authorized manager at this moment
 	Signers  map[common.Address]struct{} `json:"signers"` // Set of authorized signers at this moment
 	Recents  map[uint64]common.Address   `json:"recents"` // Set of recent signers for spam protections
+
+	Liveness map[common.Address]consensus.Liveness `json:"liveness"` // Turns produced and missed by the signers since the checkpoint
 }
 
 // signers implements the sort interface to allow sorting a list of addresses
@@ -60,6 +73,7 @@ func newSnapshot(config *params.WPoaConfig, sigcache *lru.ARCCache, number uint6
 		Managers: make(map[common.Address]struct{}),
 		Signers:  make(map[common.Address]struct{}),
 		Recents:  make(map[uint64]common.Address),
+		Liveness: make(map[common.Address]consensus.Liveness),
 	}
 	for _, signer := range signers {
 		snap.Signers[signer] = struct{}{}
@@ -105,6 +119,7 @@ func (s *Snapshot) copy() *Snapshot {
 		Managers:  make(map[common.Address]struct{}),
 		Signers:   make(map[common.Address]struct{}),
 		Recents:   make(map[uint64]common.Address),
+		Liveness:  make(map[common.Address]consensus.Liveness),
 	}
 	for signer := range s.Signers {
 		cpy.Signers[signer] = struct{}{}
@@ -115,6 +130,9 @@ func (s *Snapshot) copy() *Snapshot {
 	for block, signer := range s.Recents {
 		cpy.Recents[block] = signer
 	}
+	for signer, record := range s.Liveness {
+		cpy.Liveness[signer] = record
+	}
 
 	return cpy
 }
@@ -167,6 +185,7 @@ func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
 				return nil, errUnauthorized
 			}
 		}
+		snap.account(header, signer)
 		snap.Recents[number] = signer
 
 
@@ -177,6 +196,9 @@ func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
 			}
 
 			for _, signer := range headExtra.Signers {
+				if _, ok := snap.Signers[signer]; !ok {
+					signerAddedMeter.Mark(1)
+				}
 				snap.Signers[signer] = struct{}{}
 			}
 
@@ -189,6 +211,9 @@ func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
 			}
 
 			for _, signer := range headExtra.DiscardSigners {
+				if _, ok := snap.Signers[signer]; ok {
+					signerRemovedMeter.Mark(1)
+				}
 				delete(snap.Signers, signer)
 
 				// Signer list shrunk, delete any leftover recent caches
@@ -209,6 +234,33 @@ func (s *Snapshot) apply(headers []*types.Header) (*Snapshot, error) {
 	return snap, nil
 }
 
+// account updates the liveness records with the header sealed by the signer. The
+// difficulty marks whether the block was sealed in turn, otherwise the signer in
+// turn is charged with a missed turn.
+func (s *Snapshot) account(header *types.Header, signer common.Address) {
+	record := s.Liveness[signer]
+	if header.Difficulty.Cmp(diffInTurn) == 0 {
+		record.Produced++
+		s.Liveness[signer] = record
+		producedMeter.Mark(1)
+		return
+	}
+	record.OutOfTurn++
+	s.Liveness[signer] = record
+	outOfTurnMeter.Mark(1)
+
+	number := header.Number.Uint64()
+	for _, inturn := range s.signers() {
+		if s.inturn(number, inturn) {
+			record := s.Liveness[inturn]
+			record.Missed++
+			s.Liveness[inturn] = record
+			missedMeter.Mark(1)
+			break
+		}
+	}
+}
+
 // signers retrieves the list of authorized signers in ascending order.
 func (s *Snapshot) signers() []common.Address {
 	sigs := make([]common.Address, 0, len(s.Signers))
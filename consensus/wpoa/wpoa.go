@@ -19,7 +19,6 @@ package wpoa
 
 import (
 	"bytes"
-	"crypto/ecdsa"
 	"errors"
 	"github.com/hashicorp/golang-lru"
 	"math/big"
@@ -37,6 +36,7 @@ import (
 	"github.com/bcos-one/BCOS/crypto/sha3"
 	"github.com/bcos-one/BCOS/ethdb"
 	"github.com/bcos-one/BCOS/log"
+	"github.com/bcos-one/BCOS/metrics"
 	"github.com/bcos-one/BCOS/params"
 	"github.com/bcos-one/BCOS/rlp"
 	"github.com/bcos-one/BCOS/rpc"
@@ -142,8 +142,15 @@ type SignerFn func(accounts.Account, []byte) ([]byte, error)
 // or not), which could be abused to produce different hashes for the same header.
 func sigHash(header *types.Header) (hash common.Hash) {
 	hasher := sha3.NewKeccak256()
+	hasher.Write(sigData(header))
+	hasher.Sum(hash[:0])
+	return hash
+}
 
-	rlp.Encode(hasher, []interface{}{
+// sigData returns the RLP encoding of the header fields covered by the signature,
+// which is the data handed to the signer to seal the header.
+func sigData(header *types.Header) []byte {
+	data, _ := rlp.EncodeToBytes([]interface{}{
 		header.ParentHash,
 		header.UncleHash,
 		header.Coinbase,
@@ -160,8 +167,7 @@ func sigHash(header *types.Header) (hash common.Hash) {
 		header.MixDigest,
 		header.Nonce,
 	})
-	hasher.Sum(hash[:0])
-	return hash
+	return data
 }
 
 // ecrecover extracts the Ethereum account address from a signed header.
@@ -197,14 +203,14 @@ type WPoa struct {
 	recents    *lru.ARCCache // Snapshots for recent block to speed up reorgs
 	signatures *lru.ARCCache // Signatures of recent blocks to speed up mining
 
-	privateKey *ecdsa.PrivateKey //nodeKey
-	signer     common.Address    // public address of the signing nodekey
-	lock       sync.RWMutex      // Protects the signer fields
+	sealer consensus.Signer // Signer sealing the headers, holding the node key or forwarding to an external signer
+	signer common.Address   // public address of the sealing account
+	lock   sync.RWMutex     // Protects the signer fields
 }
 
 // New creates a bcos proof-of-authority consensus engine with the initial
 // signers set to the ones provided by the user.
-func New(config *params.WPoaConfig, privateKey *ecdsa.PrivateKey, db ethdb.Database) *WPoa {
+func New(config *params.WPoaConfig, sealer consensus.Signer, db ethdb.Database) *WPoa {
 	// Set any missing consensus parameters to their defaults
 	conf := *config
 	if conf.Epoch == 0 {
@@ -220,16 +226,17 @@ func New(config *params.WPoaConfig, privateKey *ecdsa.PrivateKey, db ethdb.Datab
 		recents:    recents,
 		signatures: signatures,
 
-		privateKey: privateKey,
-		signer: 	crypto.PubkeyToAddress(privateKey.PublicKey),
+		sealer: sealer,
+		signer: sealer.Address(),
 	}
 }
 
-func (w *WPoa)sign (hashData []byte) ([]byte, error) {
+// sign requests the sealer to sign the header, returning the seal.
+func (w *WPoa) sign(header *types.Header) ([]byte, error) {
 	w.lock.Lock()
 	defer w.lock.Unlock()
 
-	return crypto.Sign(hashData, w.privateKey)
+	return w.sealer.SignData(accounts.MimetypeWPoaHeader, sigData(header))
 }
 
 // Author implements consensus.Engine, returning the Ethereum address recovered
@@ -446,6 +453,10 @@ func (w *WPoa) snapshot(chain consensus.ChainReader, number uint64, hash common.
 		return nil, err
 	}
 	w.recents.Add(snap.Hash, snap)
+	signerGauge.Update(int64(len(snap.Signers)))
+	for signer, record := range snap.Liveness {
+		metrics.GetOrRegisterGauge("consensus/wpoa/missed/"+signer.Hex(), nil).Update(int64(record.Missed))
+	}
 
 	// If we've generated a new checkpoint snapshot, save to disk
 	if snap.Number%checkpointInterval == 0 && len(headers) > 0 {
@@ -686,7 +697,7 @@ func setHeaderExtra(headExtra *types.WPoaExtra, header *types.Header) error {
 
 // writeCommittedSeals writes the extra-data field of a block header with given committed seals.
 func (w *WPoa) writeExtraSeal(header *types.Header) error {
-	sighash, err := w.sign(sigHash(header).Bytes())
+	sighash, err := w.sign(header)
 	if err != nil {
 		return err
 	}
@@ -21,12 +21,14 @@ import (
 	"io/ioutil"
 	"math/big"
 	"os"
+	"runtime"
 	"testing"
 
 	"github.com/bcos-one/BCOS/common"
 	"github.com/bcos-one/BCOS/common/math"
 	"github.com/bcos-one/BCOS/consensus/ethash"
 	"github.com/bcos-one/BCOS/core/rawdb"
+	"github.com/bcos-one/BCOS/core/state"
 	"github.com/bcos-one/BCOS/core/types"
 	"github.com/bcos-one/BCOS/core/vm"
 	"github.com/bcos-one/BCOS/crypto"
@@ -86,7 +88,7 @@ func genValueTx(nbytes int) func(int, *BlockGen) {
 		toaddr := common.Address{}
 		data := make([]byte, nbytes)
 		gas, _ := IntrinsicGas(data, false, false)
-		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(benchRootAddr), toaddr, big.NewInt(1), gas, nil, data), types.HomesteadSigner{}, benchRootKey)
+		tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(benchRootAddr), toaddr, nil, big.NewInt(1), gas, nil, data), types.HomesteadSigner{}, benchRootKey)
 		gen.AddTx(tx)
 	}
 }
@@ -122,6 +124,7 @@ func genTxRing(naccounts int) func(int, *BlockGen) {
 			tx := types.NewTransaction(
 				gen.TxNonce(ringAddrs[from]),
 				ringAddrs[to],
+				nil,
 				benchRootFunds,
 				params.TxGas,
 				nil,
@@ -184,6 +187,81 @@ func benchInsertChain(b *testing.B, disk bool, gen func(int, *BlockGen)) {
 	}
 }
 
+func BenchmarkProcess_transfers_sequential(b *testing.B) {
+	benchProcess(b, 0, nil)
+}
+func BenchmarkProcess_transfers_parallel8(b *testing.B) {
+	benchProcess(b, 8, nil)
+}
+func BenchmarkProcess_transfers_parallelMaxProcs(b *testing.B) {
+	benchProcess(b, runtime.GOMAXPROCS(0), nil)
+}
+func BenchmarkProcess_compute_sequential(b *testing.B) {
+	benchProcess(b, 0, benchComputeCode)
+}
+func BenchmarkProcess_compute_parallelMaxProcs(b *testing.B) {
+	benchProcess(b, runtime.GOMAXPROCS(0), benchComputeCode)
+}
+
+// benchComputeCode hashes a memory word 1024 times over, keeping the processor
+// busy without touching the state.
+var benchComputeCode = common.Hex2Bytes("6104005b6020600020600052600190038060035700")
+
+// benchProcess measures the state processing of a block filled with transactions
+// of independent senders, using the given number of parallel workers. Without
+// code the transactions are transfers to fresh accounts, otherwise every sender
+// calls its own copy of the code.
+//
+// The MaxProcs variants use a worker per processor, run them with -cpu 1,2,4,8 to
+// see the processing scale; a single worker processes sequentially.
+func benchProcess(b *testing.B, workers int, code []byte) {
+	var (
+		db      = ethdb.NewMemDatabase()
+		alloc   = make(GenesisAlloc)
+		signer  = types.HomesteadSigner{}
+		senders = ringAddrs[:500]
+		gas     = params.TxGas
+	)
+	if code != nil {
+		senders, gas = ringAddrs[:200], 200000
+	}
+	for j, addr := range senders {
+		alloc[addr] = GenesisAccount{Balance: benchRootFunds}
+		if code != nil {
+			alloc[common.BigToAddress(big.NewInt(int64(0x20000+j)))] = GenesisAccount{Balance: new(big.Int), Code: code}
+		}
+	}
+	gspec := Genesis{Config: params.TestChainConfig, GasLimit: uint64(len(senders)) * gas * 2, Alloc: alloc}
+	genesis := gspec.MustCommit(db)
+
+	// Generate a single block of independent transactions
+	blocks, _ := GenerateChain(gspec.Config, genesis, ethash.NewFaker(), db, 1, func(i int, gen *BlockGen) {
+		for j, addr := range senders {
+			to := common.BigToAddress(big.NewInt(int64(0x10000 + j)))
+			if code != nil {
+				to = common.BigToAddress(big.NewInt(int64(0x20000 + j)))
+			}
+			tx, _ := types.SignTx(types.NewTransaction(gen.TxNonce(addr), to, nil, big.NewInt(1), gas, big.NewInt(1), nil), signer, ringKeys[j])
+			gen.AddTx(tx)
+		}
+	})
+	chain, _ := NewBlockChain(db, nil, gspec.Config, ethash.NewFaker(), vm.Config{}, nil)
+	defer chain.Stop()
+
+	// Time the processing of the block on top of the genesis state
+	processor := NewStateProcessor(gspec.Config, chain, chain.Engine())
+	cfg := vm.Config{ParallelTxs: workers}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		statedb, _ := state.New(genesis.Root(), chain.StateCache())
+		if _, _, _, err := processor.Process(blocks[0], statedb, cfg); err != nil {
+			b.Fatalf("process error: %v", err)
+		}
+	}
+}
+
 func BenchmarkChainRead_header_10k(b *testing.B) {
 	benchReadChain(b, false, 10000)
 }
@@ -18,12 +18,14 @@
 package core
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"math"
 	"math/big"
 	mrand "math/rand"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -34,11 +36,13 @@ import (
 	"github.com/bcos-one/BCOS/consensus"
 	"github.com/bcos-one/BCOS/core/rawdb"
 	"github.com/bcos-one/BCOS/core/state"
+	"github.com/bcos-one/BCOS/core/state/snapshot"
 	"github.com/bcos-one/BCOS/core/types"
 	"github.com/bcos-one/BCOS/core/vm"
 	"github.com/bcos-one/BCOS/crypto"
 	"github.com/bcos-one/BCOS/ethdb"
 	"github.com/bcos-one/BCOS/event"
+	"github.com/bcos-one/BCOS/expansions/token"
 	"github.com/bcos-one/BCOS/log"
 	"github.com/bcos-one/BCOS/metrics"
 	"github.com/bcos-one/BCOS/params"
@@ -72,6 +76,9 @@ type CacheConfig struct {
 	Disabled      bool          // Whether to disable trie write caching (archive node)
 	TrieNodeLimit int           // Memory limit (MB) at which to flush the current in-memory trie to disk
 	TrieTimeLimit time.Duration // Time limit after which to flush the current in-memory trie to disk
+
+	AncientThreshold uint64 // Number of recent blocks kept in the key-value store, older ones are frozen (0 = disabled)
+	Snapshot         bool   // Whether to maintain a flat state snapshot for fast state reads
 }
 
 // BlockChain represents the canonical chain given a database with a genesis
@@ -114,6 +121,7 @@ type BlockChain struct {
 	currentFastBlock atomic.Value // Current head of the fast-sync chain (may be above the block chain!)
 
 	stateCache    state.Database // State database to reuse between imports (contains state cache)
+	snaps         *snapshot.Tree // Flat state snapshot of the recent states (nil if disabled)
 	bodyCache     *lru.Cache     // Cache for the most recent block bodies
 	bodyRLPCache  *lru.Cache     // Cache for the most recent block bodies in RLP encoded format
 	receiptsCache *lru.Cache     // Cache for the most recent receipts per block
@@ -197,8 +205,16 @@ func NewBlockChain(db ethdb.Database, cacheConfig *CacheConfig, chainConfig *par
 			}
 		}
 	}
+	// Load the flat state snapshot, it regenerates in the background if needed
+	if cacheConfig.Snapshot {
+		bc.snaps = snapshot.New(bc.db, bc.stateCache.TrieDB(), bc.CurrentBlock().Root())
+	}
 	// Take ownership of this particular state
 	go bc.update()
+	if ancients, ok := bc.db.(rawdb.AncientStore); ok && cacheConfig.AncientThreshold > 0 {
+		bc.wg.Add(1)
+		go bc.freeze(ancients)
+	}
 	return bc, nil
 }
 
@@ -282,6 +298,15 @@ func (bc *BlockChain) SetHead(head uint64) error {
 	bc.hc.SetHead(head, delFn)
 	currentHeader := bc.hc.CurrentHeader()
 
+	// Drop any frozen blocks above the new head
+	if ancients, ok := bc.db.(rawdb.AncientStore); ok {
+		if frozen, _ := ancients.Ancients(); frozen > currentHeader.Number.Uint64()+1 {
+			if err := ancients.TruncateAncients(currentHeader.Number.Uint64() + 1); err != nil {
+				log.Error("Failed to truncate ancient store", "err", err)
+			}
+		}
+	}
+
 	// Clear out any stale content from the caches
 	bc.bodyCache.Purge()
 	bc.bodyRLPCache.Purge()
@@ -316,7 +341,14 @@ func (bc *BlockChain) SetHead(head uint64) error {
 	rawdb.WriteHeadBlockHash(bc.db, currentBlock.Hash())
 	rawdb.WriteHeadFastBlockHash(bc.db, currentFastBlock.Hash())
 
-	return bc.loadLastState()
+	if err := bc.loadLastState(); err != nil {
+		return err
+	}
+	// The snapshot can't be rewound, regenerate it for the new head
+	if bc.snaps != nil {
+		bc.snaps.Rebuild(bc.CurrentBlock().Root())
+	}
+	return nil
 }
 
 // FastSyncCommitHead sets the current head block to the one defined by the hash
@@ -395,7 +427,12 @@ func (bc *BlockChain) State() (*state.StateDB, error) {
 
 // StateAt returns a new mutable state based on a particular point in time.
 func (bc *BlockChain) StateAt(root common.Hash) (*state.StateDB, error) {
-	return state.New(root, bc.stateCache)
+	return state.NewWithSnapshot(root, bc.stateCache, bc.snaps)
+}
+
+// StateCache returns the caching database underpinning the blockchain instance.
+func (bc *BlockChain) StateCache() state.Database {
+	return bc.stateCache
 }
 
 // Reset purges the entire blockchain, restoring it to its genesis state.
@@ -676,6 +713,12 @@ func (bc *BlockChain) Stop() {
 
 	bc.wg.Wait()
 
+	// Persist the snapshot diff layers, so they don't need to be regenerated
+	if bc.snaps != nil {
+		if err := bc.snaps.Journal(bc.CurrentBlock().Root()); err != nil {
+			log.Error("Failed to journal state snapshot", "err", err)
+		}
+	}
 	// Ensure the state of a recent block is also stored to disk before exiting.
 	// We're writing three different states to catch different restart scenarios:
 	//  - HEAD:     So we don't need to reprocess any blocks in the general case
@@ -899,6 +942,51 @@ func (bc *BlockChain) WriteBlockWithoutState(block *types.Block, td *big.Int) (e
 	return nil
 }
 
+// tokenBlockDiff collects the token balance and supply changes of a block from the
+// committed state it was processed on, while the state of its parent is still
+// available. It returns nil if the changes cannot be captured.
+func (bc *BlockChain) tokenBlockDiff(block *types.Block, statedb *state.StateDB) *rawdb.TokenBlockDiff {
+	config := bc.chainConfig.ExpansionsConfig
+	if !config.IsTokenEnabled(block.Number()) {
+		return nil
+	}
+	changes, ok := statedb.TokenBalanceDiff()
+	if !ok {
+		return nil
+	}
+	diff := &rawdb.TokenBlockDiff{Balances: make([]rawdb.TokenBalanceDiff, 0, len(changes))}
+	if len(changes) == 0 {
+		return diff
+	}
+	parent := bc.GetHeader(block.ParentHash(), block.NumberU64()-1)
+	if parent == nil {
+		return nil
+	}
+	prevState, err := state.New(parent.Root, bc.stateCache)
+	if err != nil {
+		return nil
+	}
+	// Supplies only move together with some balance, check the touched tokens
+	touched := make(map[common.Address]bool)
+	for _, change := range changes {
+		diff.Balances = append(diff.Balances, rawdb.TokenBalanceDiff{Holder: change.Holder, Token: change.Token, Prev: change.Prev, Value: change.Value})
+		if touched[change.Token] {
+			continue
+		}
+		touched[change.Token] = true
+
+		prev := token.NewTokenObject(config.TokenStorage, change.Token, prevState).GetSupply()
+		value := token.NewTokenObject(config.TokenStorage, change.Token, statedb).GetSupply()
+		if prev.Cmp(value) != 0 {
+			diff.Supplies = append(diff.Supplies, rawdb.TokenSupplyDiff{Token: change.Token, Prev: prev, Value: value})
+		}
+	}
+	sort.Slice(diff.Supplies, func(i, j int) bool {
+		return bytes.Compare(diff.Supplies[i].Token[:], diff.Supplies[j].Token[:]) < 0
+	})
+	return diff
+}
+
 // WriteBlockWithState writes the block and all associated state to the database.
 func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.Receipt, state *state.StateDB) (status WriteStatus, err error) {
 	bc.wg.Add(1)
@@ -927,6 +1015,8 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 	if err != nil {
 		return NonStatTy, err
 	}
+	tokenDiff := bc.tokenBlockDiff(block, state)
+
 	triedb := bc.stateCache.TrieDB()
 
 	// If we're running an archive node, always flush
@@ -979,6 +1069,9 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 	// Write other block data using a batch.
 	batch := bc.db.NewBatch()
 	rawdb.WriteReceipts(batch, block.Hash(), block.NumberU64(), receipts)
+	if tokenDiff != nil {
+		rawdb.WriteTokenBlockDiff(batch, block.Hash(), block.NumberU64(), tokenDiff)
+	}
 
 	// If the total difficulty is higher than our known, add it to the canonical chain
 	// Second clause in the if statement reduces the vulnerability to selfish mining.
@@ -1020,6 +1113,16 @@ func (bc *BlockChain) WriteBlockWithState(block *types.Block, receipts []*types.
 	// Set new head.
 	if status == CanonStatTy {
 		bc.insert(block)
+
+		// Flatten the snapshot diffs no longer covered by the in-memory tries, or
+		// start over if the new head has none (reorg below the disk layer)
+		if bc.snaps != nil {
+			if bc.snaps.Snapshot(block.Root()) == nil {
+				bc.snaps.Rebuild(block.Root())
+			} else if err := bc.snaps.Cap(block.Root(), triesInMemory-1); err != nil {
+				log.Warn("Failed to cap state snapshot", "root", block.Root(), "err", err)
+			}
+		}
 	}
 	bc.futureBlocks.Remove(block.Hash())
 	return status, nil
@@ -1175,7 +1278,7 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []interface{}, []*ty
 		} else {
 			parent = chain[i-1]
 		}
-		state, err := state.New(parent.Root(), bc.stateCache)
+		state, err := state.NewWithSnapshot(parent.Root(), bc.stateCache, bc.snaps)
 		if err != nil {
 			return i, events, coalescedLogs, err
 		}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"time"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/core/rawdb"
+	"github.com/bcos-one/BCOS/log"
+)
+
+const (
+	// freezerRecheckInterval is the frequency to check the key-value store for
+	// chain progression that might permit new blocks to be frozen.
+	freezerRecheckInterval = time.Minute
+
+	// freezerBatchLimit is the maximum number of blocks to freeze in one batch
+	// before doing an fsync and deleting them from the key-value store.
+	freezerBatchLimit = 30000
+)
+
+// freeze is a background loop that periodically moves canonical chain data older
+// than the ancient threshold out of the key-value store into the ancient store.
+func (bc *BlockChain) freeze(ancients rawdb.AncientStore) {
+	defer bc.wg.Done()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+		case <-bc.quit:
+			return
+		}
+		wait := freezerRecheckInterval
+		if full, err := bc.freezeBatch(ancients); err != nil {
+			log.Error("Failed to freeze ancient blocks", "err", err)
+		} else if full {
+			wait = 0 // More blocks are waiting, keep going
+		}
+		timer.Reset(wait)
+	}
+}
+
+// freezeBatch moves the next batch of immutable blocks into the ancient store,
+// reporting whether the batch limit was hit.
+func (bc *BlockChain) freezeBatch(ancients rawdb.AncientStore) (bool, error) {
+	head := bc.CurrentBlock().NumberU64()
+	if head < bc.cacheConfig.AncientThreshold {
+		return false, nil
+	}
+	frozen, err := ancients.Ancients()
+	if err != nil {
+		return false, err
+	}
+	limit := head - bc.cacheConfig.AncientThreshold + 1
+	if limit <= frozen {
+		return false, nil
+	}
+	if limit-frozen > freezerBatchLimit {
+		limit = frozen + freezerBatchLimit
+	}
+	var (
+		start  = time.Now()
+		hashes []common.Hash
+	)
+	for number := frozen; number < limit; number++ {
+		// Abort if the chain is shutting down
+		select {
+		case <-bc.quit:
+			limit = number
+		default:
+		}
+		if number == limit {
+			break
+		}
+		hash := rawdb.ReadCanonicalHash(bc.db, number)
+		var (
+			header   = rawdb.ReadHeaderRLP(bc.db, hash, number)
+			body     = rawdb.ReadBodyRLP(bc.db, hash, number)
+			receipts = rawdb.ReadReceiptsRLP(bc.db, hash, number)
+			td       = rawdb.ReadTdRLP(bc.db, hash, number)
+		)
+		if hash == (common.Hash{}) || len(header) == 0 || len(body) == 0 || len(receipts) == 0 || len(td) == 0 {
+			log.Error("Missing chain data to freeze", "number", number, "hash", hash)
+			limit = number
+			break
+		}
+		if err := ancients.AppendAncient(number, hash.Bytes(), header, body, receipts, td); err != nil {
+			return false, err
+		}
+		hashes = append(hashes, hash)
+	}
+	if len(hashes) == 0 {
+		return false, nil
+	}
+	if err := ancients.Sync(); err != nil {
+		return false, err
+	}
+	// The blocks are safe on disk, wipe them from the key-value store. Side chain
+	// blocks at frozen heights can never become canonical anymore.
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	if now, _ := ancients.Ancients(); now < limit {
+		// The chain was rewound below the batch meanwhile
+		return false, nil
+	}
+	batch := bc.db.NewBatch()
+	for i, hash := range hashes {
+		number := frozen + uint64(i)
+		for _, h := range rawdb.ReadAllHashes(bc.db, number) {
+			if h == hash {
+				rawdb.DeleteBlockWithoutNumber(batch, h, number)
+			} else {
+				rawdb.DeleteBlock(batch, h, number)
+			}
+		}
+		rawdb.DeleteCanonicalHash(batch, number)
+	}
+	if err := batch.Write(); err != nil {
+		return false, err
+	}
+	log.Info("Moved blocks into the ancient store", "from", frozen, "to", limit-1, "elapsed", common.PrettyDuration(time.Since(start)))
+	return limit-frozen == freezerBatchLimit, nil
+}
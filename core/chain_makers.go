@@ -27,6 +27,7 @@ import (
 	"github.com/bcos-one/BCOS/core/types"
 	"github.com/bcos-one/BCOS/core/vm"
 	"github.com/bcos-one/BCOS/ethdb"
+	"github.com/bcos-one/BCOS/expansions"
 	"github.com/bcos-one/BCOS/params"
 )
 
@@ -191,6 +192,9 @@ func GenerateChain(config *params.ChainConfig, parent *types.Block, engine conse
 		if config.DAOForkSupport && config.DAOForkBlock != nil && config.DAOForkBlock.Cmp(b.header.Number) == 0 {
 			misc.ApplyDAOHardFork(statedb)
 		}
+		if config.ExpansionsConfig != nil {
+			expansions.NewExpansions(config).ApplyForks(statedb, b.header.Number)
+		}
 		// Execute any user modifications to the block
 		if gen != nil {
 			gen(i, b)
@@ -23,6 +23,7 @@ import (
 	"github.com/bcos-one/BCOS/consensus"
 	"github.com/bcos-one/BCOS/core/types"
 	"github.com/bcos-one/BCOS/core/vm"
+	"github.com/bcos-one/BCOS/expansions/token"
 )
 
 // ChainContext supports retrieving headers and consensus parameters from the
@@ -45,18 +46,19 @@ func NewEVMContext(msg Message, header *types.Header, chain ChainContext, author
 		beneficiary = *author
 	}
 	return vm.Context{
-		CanTransfer:      CanTransfer,
-		Transfer:         Transfer,
-		CanTransferToken: CanTransferToken,
-		TransferToken:    TransferToken,
-		GetHash:          GetHashFn(header, chain),
-		Origin:           msg.From(),
-		Coinbase:         beneficiary,
-		BlockNumber:      new(big.Int).Set(header.Number),
-		Time:             new(big.Int).Set(header.Time),
-		Difficulty:       new(big.Int).Set(header.Difficulty),
-		GasLimit:         header.GasLimit,
-		GasPrice:         new(big.Int).Set(msg.GasPrice()),
+		CanTransfer:          CanTransfer,
+		Transfer:             Transfer,
+		CanTransferToken:     CanTransferToken,
+		TransferToken:        TransferToken,
+		TokenTransferAllowed: TokenTransferAllowed,
+		GetHash:              GetHashFn(header, chain),
+		Origin:               msg.From(),
+		Coinbase:             beneficiary,
+		BlockNumber:          new(big.Int).Set(header.Number),
+		Time:                 new(big.Int).Set(header.Time),
+		Difficulty:           new(big.Int).Set(header.Difficulty),
+		GasLimit:             header.GasLimit,
+		GasPrice:             new(big.Int).Set(msg.GasPrice()),
 	}
 }
 
@@ -108,4 +110,10 @@ func CanTransferToken(db vm.StateDB, addr common.Address, token common.Address,
 func TransferToken(db vm.StateDB, sender, recipient common.Address, token common.Address, amount *big.Int) {
 	db.SubTokenBalance(sender, token, amount)
 	db.AddTokenBalance(recipient, token, amount)
-}
\ No newline at end of file
+}
+
+// TokenTransferAllowed checks whether the token kept in the given token storage
+// is neither paused nor frozen for the sender or the recipient.
+func TokenTransferAllowed(db vm.StateDB, storage, id, sender, recipient common.Address) bool {
+	return token.NewTokenObject(storage, id, db).CanTransfer(sender, recipient)
+}
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"sync"
+
+	"github.com/bcos-one/BCOS/core/state"
+	"github.com/bcos-one/BCOS/core/types"
+	"github.com/bcos-one/BCOS/core/vm"
+)
+
+// speculation is the outcome of optimistically executing a transaction on a copy
+// of the state, before the transactions preceding it in the block were applied.
+type speculation struct {
+	state  *state.StateDB   // Copy of the state holding the changes
+	msg    types.Message    // Message derived from the transaction
+	gas    uint64           // Gas used by the transaction
+	failed bool             // Whether the execution failed
+	token  bool             // Whether the gas was paid in tokens
+	err    error            // Error invalidating the transaction, if any
+	reads  *state.AccessSet // State read by the transaction
+	writes *state.AccessSet // State modified by the transaction
+}
+
+// parallel reports whether the transactions of a block are executed in parallel.
+// Only the post-Byzantium rules are supported, where no intermediate roots are
+// needed and empty accounts are deleted after every transaction.
+func (p *StateProcessor) parallel(block *types.Block, cfg vm.Config) bool {
+	return cfg.ParallelTxs > 1 && !cfg.Debug && len(block.Transactions()) > 2 &&
+		p.config.IsByzantium(block.Number()) && p.config.IsEIP158(block.Number())
+}
+
+// processParallel applies the transactions of a block by optimistically executing
+// them in parallel on copies of the state, each recording the accounts, storage
+// slots and token balances it read. The speculations are validated in block order:
+// if nothing a transaction read was modified by the ones before it, its changes
+// are merged into the state, otherwise it is executed again on the state itself.
+// The resulting state and receipts are identical to sequential processing.
+func (p *StateProcessor) processParallel(block *types.Block, statedb *state.StateDB, gp *GasPool, usedGas *uint64, cfg vm.Config) (types.Receipts, []*types.Log, error) {
+	var (
+		txs      = block.Transactions()
+		header   = block.Header()
+		receipts = make(types.Receipts, 0, len(txs))
+		allLogs  []*types.Log
+	)
+	// The first transaction sees the uncommitted changes of the block preparations,
+	// the others are speculated on the state finalised after it
+	statedb.Prepare(txs[0].Hash(), block.Hash(), 0)
+	receipt, _, err := ApplyTransaction(p.config, p.bc, nil, gp, statedb, header, txs[0], usedGas, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	receipts = append(receipts, receipt)
+	allLogs = append(allLogs, receipt.Logs...)
+
+	var (
+		specs   = make([]*speculation, len(txs))
+		pending sync.WaitGroup
+		workers = make(chan struct{}, cfg.ParallelTxs)
+	)
+	for i := 1; i < len(txs); i++ {
+		pending.Add(1)
+		workers <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-workers
+				pending.Done()
+			}()
+			specs[i] = p.speculate(block, header, statedb, i, cfg)
+		}(i)
+	}
+	pending.Wait()
+
+	// Validate and apply the speculations in order, collecting everything modified
+	// since they were made
+	written := state.NewAccessSet()
+	for i := 1; i < len(txs); i++ {
+		var (
+			tx     = txs[i]
+			spec   = specs[i]
+			msg    types.Message
+			gas    uint64
+			failed bool
+			token  bool
+		)
+		specs[i] = nil
+
+		statedb.Prepare(tx.Hash(), block.Hash(), i)
+		if spec.err == nil && gp.Gas() >= tx.Gas() && !spec.reads.Conflicts(written) {
+			statedb.MergeSpeculation(spec.state)
+			if err := gp.SubGas(spec.gas); err != nil {
+				return nil, nil, err
+			}
+			msg, gas, failed, token = spec.msg, spec.gas, spec.failed, spec.token
+			written.Merge(spec.writes)
+		} else {
+			statedb.StartAccessTracking()
+			msg, gas, failed, token, err = applyTransaction(p.config, p.bc, nil, gp, statedb, header, tx, cfg)
+			if err != nil {
+				statedb.StopAccessTracking()
+				return nil, nil, err
+			}
+			_, writes := statedb.AccessSets()
+			statedb.StopAccessTracking()
+			written.Merge(writes)
+		}
+		statedb.Finalise(true)
+		*usedGas += gas
+
+		receipt := newReceipt(nil, failed, token, *usedGas, gas, tx, msg, statedb)
+		receipts = append(receipts, receipt)
+		allLogs = append(allLogs, receipt.Logs...)
+	}
+	return receipts, allLogs, nil
+}
+
+// speculate executes the i-th transaction of the block on a copy of the state.
+// The gas pool of the copy is the whole block, the validation takes care of the
+// gas used by the preceding transactions.
+func (p *StateProcessor) speculate(block *types.Block, header *types.Header, statedb *state.StateDB, i int, cfg vm.Config) *speculation {
+	tx := block.Transactions()[i]
+
+	spec := &speculation{state: statedb.Speculate()}
+	spec.state.Prepare(tx.Hash(), block.Hash(), i)
+
+	gp := new(GasPool).AddGas(block.GasLimit())
+	spec.msg, spec.gas, spec.failed, spec.token, spec.err = applyTransaction(p.config, p.bc, nil, gp, spec.state, header, tx, cfg)
+	if spec.err == nil {
+		spec.err = spec.state.Error()
+	}
+	spec.reads, spec.writes = spec.state.AccessSets()
+	return spec
+}
@@ -0,0 +1,158 @@
+package core
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bcos-one/BCOS/accounts/abi"
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/consensus/ethash"
+	"github.com/bcos-one/BCOS/core/rawdb"
+	"github.com/bcos-one/BCOS/core/state"
+	"github.com/bcos-one/BCOS/core/types"
+	"github.com/bcos-one/BCOS/core/vm"
+	"github.com/bcos-one/BCOS/crypto"
+	"github.com/bcos-one/BCOS/ethdb"
+	"github.com/bcos-one/BCOS/params"
+)
+
+// Tests that processing blocks in parallel yields exactly the state and receipts
+// of the sequential processing they were generated with, for independent as well
+// as conflicting native transfers, token transfers, token operations and contract
+// storage updates.
+func TestParallelProcessing(t *testing.T) {
+	var (
+		storage  = common.HexToAddress("0x1000")
+		counter  = common.HexToAddress("0x2000")
+		coinbase = common.HexToAddress("0x3000")
+		accounts = 40
+
+		// PUSH1 0 SLOAD PUSH1 1 ADD PUSH1 0 SSTORE STOP
+		counterCode = common.FromHex("0x60005460010160005500")
+	)
+	config := *params.TestChainConfig
+	config.ExpansionsConfig = &params.ExpansionsConfig{TokenSupport: true, TokenStorage: storage}
+	signer := types.NewEIP155Signer(config.ChainID)
+
+	alloc := GenesisAlloc{counter: {Code: counterCode, Balance: new(big.Int)}}
+	for _, addr := range ringAddrs[:accounts] {
+		alloc[addr] = GenesisAccount{Balance: benchRootFunds}
+	}
+	gspec := &Genesis{Config: &config, GasLimit: 50000000, Alloc: alloc}
+
+	issuer, _ := abi.JSON(strings.NewReader(`[{"name":"issue","type":"function","inputs":[{"name":"name","type":"string"},{"name":"manager","type":"address"},{"name":"beneficiary","type":"address"},{"name":"supply","type":"uint256"},{"name":"canIncrease","type":"bool"},{"name":"canburn","type":"bool"}]}]`))
+	issue := func(gen *BlockGen, from int, name string) common.Address {
+		input, err := issuer.Pack("issue", name, ringAddrs[from], ringAddrs[from], big.NewInt(1000000), true, true)
+		if err != nil {
+			t.Fatalf("failed to pack token issue: %v", err)
+		}
+		nonce := gen.TxNonce(ringAddrs[from])
+		tx, err := types.SignTx(types.NewTransaction(nonce, storage, nil, new(big.Int), 500000, big.NewInt(1), input), signer, ringKeys[from])
+		if err != nil {
+			t.Fatalf("failed to sign token issue: %v", err)
+		}
+		gen.AddTx(tx)
+		return crypto.CreateAddress(ringAddrs[from], nonce)
+	}
+	send := func(gen *BlockGen, from int, to common.Address, token *common.Address, amount int64, price int64, data []byte) {
+		tx, err := types.SignTx(types.NewTransaction(gen.TxNonce(ringAddrs[from]), to, token, big.NewInt(amount), 100000, big.NewInt(price), data), signer, ringKeys[from])
+		if err != nil {
+			t.Fatalf("failed to sign transaction: %v", err)
+		}
+		gen.AddTx(tx)
+	}
+	var token common.Address
+
+	db := ethdb.NewMemDatabase()
+	genesis := gspec.MustCommit(db)
+	blocks, _ := GenerateChain(&config, genesis, ethash.NewFaker(), db, 3, func(i int, gen *BlockGen) {
+		gen.SetCoinbase(coinbase)
+		switch i {
+		case 0:
+			// Issue a token and hand it out
+			token = issue(gen, 0, "gold")
+			for j := 1; j < accounts; j++ {
+				send(gen, 0, ringAddrs[j], &token, 1000, 1, nil)
+			}
+		default:
+			// Independent native and token transfers to fresh accounts
+			for j := 0; j < accounts; j++ {
+				to := common.BigToAddress(big.NewInt(int64(0x10000 + i*accounts + j)))
+				if j%2 == 0 {
+					send(gen, j, to, nil, 1, int64(j%3), nil)
+				} else {
+					send(gen, j, to, &token, 1, 1, nil)
+				}
+			}
+			// Conflicting transfers: same sender, same recipient, transfers among
+			// the senders themselves and a shared contract counter
+			for j := 0; j < 5; j++ {
+				send(gen, 1, ringAddrs[2], &token, 1, 1, nil)
+				send(gen, 3+j, coinbase, nil, 1, 1, nil)
+				send(gen, 10+j, ringAddrs[11+j], nil, 1, 0, nil)
+				send(gen, 20+j, counter, nil, 0, 1, nil)
+			}
+			// Token operations update the shared token storage
+			issue(gen, 30+i, "silver")
+		}
+	})
+	// Import the chain into a parallel processing chain, the block validation
+	// checks the state roots, receipts and gas used
+	seqdb, pardb := ethdb.NewMemDatabase(), ethdb.NewMemDatabase()
+	gspec.MustCommit(seqdb)
+	gspec.MustCommit(pardb)
+
+	seqchain, _ := NewBlockChain(seqdb, nil, &config, ethash.NewFaker(), vm.Config{}, nil)
+	defer seqchain.Stop()
+	parchain, _ := NewBlockChain(pardb, nil, &config, ethash.NewFaker(), vm.Config{ParallelTxs: 4}, nil)
+	defer parchain.Stop()
+
+	if n, err := seqchain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to process block %d sequentially: %v", n, err)
+	}
+	if n, err := parchain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to process block %d in parallel: %v", n, err)
+	}
+	for _, block := range blocks {
+		seq := rawdb.ReadReceipts(seqdb, block.Hash(), block.NumberU64())
+		par := rawdb.ReadReceipts(pardb, block.Hash(), block.NumberU64())
+		if !reflect.DeepEqual(seq, par) {
+			t.Errorf("block %d: receipts mismatch", block.NumberU64())
+		}
+	}
+	statedb, _ := parchain.State()
+	if balance := statedb.GetTokenBalance(ringAddrs[2], token); balance.Cmp(big.NewInt(1000+10)) != 0 {
+		t.Errorf("token balance mismatch: have %v, want %v", balance, 1010)
+	}
+	if count := statedb.GetState(counter, common.Hash{}); count.Big().Int64() != 10 {
+		t.Errorf("counter mismatch: have %v, want %v", count.Big(), 10)
+	}
+	// The token changes captured while processing must match the state diffs
+	for _, block := range blocks {
+		seq := rawdb.ReadTokenBlockDiff(seqdb, block.Hash(), block.NumberU64())
+		par := rawdb.ReadTokenBlockDiff(pardb, block.Hash(), block.NumberU64())
+		if seq == nil || !reflect.DeepEqual(seq, par) {
+			t.Fatalf("block %d: token diff mismatch: sequential %v, parallel %v", block.NumberU64(), seq, par)
+		}
+		parent := seqchain.GetHeaderByHash(block.ParentHash())
+		changes, err := state.TokenBalanceChanges(seqchain.stateCache, parent.Root, block.Root())
+		if err != nil {
+			t.Fatalf("block %d: failed to diff states: %v", block.NumberU64(), err)
+		}
+		if len(seq.Balances) != len(changes) {
+			t.Fatalf("block %d: balance change count mismatch: have %d, want %d", block.NumberU64(), len(seq.Balances), len(changes))
+		}
+		for i, change := range changes {
+			have := seq.Balances[i]
+			if have.Holder != change.Holder || have.Token != change.Token || have.Prev.Cmp(change.Prev) != 0 || have.Value.Cmp(change.Value) != 0 {
+				t.Errorf("block %d, change %d: mismatch: have %+v, want %+v", block.NumberU64(), i, have, change)
+			}
+		}
+		// Every block issues a single token
+		if len(seq.Supplies) != 1 || seq.Supplies[0].Prev.Sign() != 0 || seq.Supplies[0].Value.Cmp(big.NewInt(1000000)) != 0 {
+			t.Errorf("block %d: supply changes mismatch: %+v", block.NumberU64(), seq.Supplies)
+		}
+	}
+}
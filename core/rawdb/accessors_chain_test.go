@@ -289,6 +289,7 @@ func TestBlockReceiptStorage(t *testing.T) {
 		TxHash:          common.BytesToHash([]byte{0x22, 0x22}),
 		ContractAddress: common.BytesToAddress([]byte{0x02, 0x22, 0x22}),
 		GasUsed:         222222,
+		TokenGas:        true,
 	}
 	receipts := []*types.Receipt{receipt1, receipt2}
 
@@ -309,6 +310,9 @@ func TestBlockReceiptStorage(t *testing.T) {
 			if !bytes.Equal(rlpHave, rlpWant) {
 				t.Fatalf("receipt #%d: receipt mismatch: have %v, want %v", i, rs[i], receipts[i])
 			}
+			if rs[i].TokenGas != receipts[i].TokenGas {
+				t.Fatalf("receipt #%d: token gas mismatch: have %v, want %v", i, rs[i].TokenGas, receipts[i].TokenGas)
+			}
 		}
 	}
 	// Delete the receipt slice and check purge
@@ -0,0 +1,126 @@
+package rawdb
+
+import (
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/log"
+)
+
+// ReadSnapshotRoot retrieves the root of the state the persisted flat snapshot
+// belongs to, or an empty hash if there is no snapshot.
+func ReadSnapshotRoot(db DatabaseReader) common.Hash {
+	data, _ := db.Get(snapshotRootKey)
+	if len(data) != common.HashLength {
+		return common.Hash{}
+	}
+	return common.BytesToHash(data)
+}
+
+// WriteSnapshotRoot stores the root of the state the persisted flat snapshot
+// belongs to.
+func WriteSnapshotRoot(db DatabaseWriter, root common.Hash) {
+	if err := db.Put(snapshotRootKey, root.Bytes()); err != nil {
+		log.Crit("Failed to store snapshot root", "err", err)
+	}
+}
+
+// DeleteSnapshotRoot deletes the snapshot root, marking the persisted flat
+// snapshot as unusable.
+func DeleteSnapshotRoot(db DatabaseDeleter) {
+	if err := db.Delete(snapshotRootKey); err != nil {
+		log.Crit("Failed to remove snapshot root", "err", err)
+	}
+}
+
+// ReadAccountSnapshot retrieves the snapshot entry of an account trie leaf.
+func ReadAccountSnapshot(db DatabaseReader, hash common.Hash) []byte {
+	data, _ := db.Get(accountSnapshotKey(hash))
+	return data
+}
+
+// WriteAccountSnapshot stores the snapshot entry of an account trie leaf.
+func WriteAccountSnapshot(db DatabaseWriter, hash common.Hash, entry []byte) {
+	if err := db.Put(accountSnapshotKey(hash), entry); err != nil {
+		log.Crit("Failed to store account snapshot", "err", err)
+	}
+}
+
+// DeleteAccountSnapshot removes the snapshot entry of an account trie leaf.
+func DeleteAccountSnapshot(db DatabaseDeleter, hash common.Hash) {
+	if err := db.Delete(accountSnapshotKey(hash)); err != nil {
+		log.Crit("Failed to delete account snapshot", "err", err)
+	}
+}
+
+// ReadStorageSnapshot retrieves the snapshot entry of a storage trie leaf.
+func ReadStorageSnapshot(db DatabaseReader, accountHash, storageHash common.Hash) []byte {
+	data, _ := db.Get(storageSnapshotKey(accountHash, storageHash))
+	return data
+}
+
+// WriteStorageSnapshot stores the snapshot entry of a storage trie leaf.
+func WriteStorageSnapshot(db DatabaseWriter, accountHash, storageHash common.Hash, entry []byte) {
+	if err := db.Put(storageSnapshotKey(accountHash, storageHash), entry); err != nil {
+		log.Crit("Failed to store storage snapshot", "err", err)
+	}
+}
+
+// DeleteStorageSnapshot removes the snapshot entry of a storage trie leaf.
+func DeleteStorageSnapshot(db DatabaseDeleter, accountHash, storageHash common.Hash) {
+	if err := db.Delete(storageSnapshotKey(accountHash, storageHash)); err != nil {
+		log.Crit("Failed to delete storage snapshot", "err", err)
+	}
+}
+
+// ReadTokenSnapshot retrieves the snapshot entry of a token balance trie leaf.
+func ReadTokenSnapshot(db DatabaseReader, accountHash, tokenHash common.Hash) []byte {
+	data, _ := db.Get(tokenSnapshotKey(accountHash, tokenHash))
+	return data
+}
+
+// WriteTokenSnapshot stores the snapshot entry of a token balance trie leaf.
+func WriteTokenSnapshot(db DatabaseWriter, accountHash, tokenHash common.Hash, entry []byte) {
+	if err := db.Put(tokenSnapshotKey(accountHash, tokenHash), entry); err != nil {
+		log.Crit("Failed to store token snapshot", "err", err)
+	}
+}
+
+// DeleteTokenSnapshot removes the snapshot entry of a token balance trie leaf.
+func DeleteTokenSnapshot(db DatabaseDeleter, accountHash, tokenHash common.Hash) {
+	if err := db.Delete(tokenSnapshotKey(accountHash, tokenHash)); err != nil {
+		log.Crit("Failed to delete token snapshot", "err", err)
+	}
+}
+
+// ReadSnapshotJournal retrieves the serialized in-memory diff layers saved at
+// the last shutdown.
+func ReadSnapshotJournal(db DatabaseReader) []byte {
+	data, _ := db.Get(snapshotJournalKey)
+	return data
+}
+
+// WriteSnapshotJournal stores the serialized in-memory diff layers.
+func WriteSnapshotJournal(db DatabaseWriter, journal []byte) {
+	if err := db.Put(snapshotJournalKey, journal); err != nil {
+		log.Crit("Failed to store snapshot journal", "err", err)
+	}
+}
+
+// DeleteSnapshotJournal deletes the serialized in-memory diff layers.
+func DeleteSnapshotJournal(db DatabaseDeleter) {
+	if err := db.Delete(snapshotJournalKey); err != nil {
+		log.Crit("Failed to remove snapshot journal", "err", err)
+	}
+}
+
+// ReadSnapshotGenerator retrieves the serialized snapshot generation progress.
+func ReadSnapshotGenerator(db DatabaseReader) []byte {
+	data, _ := db.Get(snapshotGeneratorKey)
+	return data
+}
+
+// WriteSnapshotGenerator stores the serialized snapshot generation progress.
+func WriteSnapshotGenerator(db DatabaseWriter, generator []byte) {
+	if err := db.Put(snapshotGeneratorKey, generator); err != nil {
+		log.Crit("Failed to store snapshot generator", "err", err)
+	}
+}
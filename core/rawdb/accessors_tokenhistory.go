@@ -0,0 +1,197 @@
+package rawdb
+
+import (
+	"math/big"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/log"
+	"github.com/bcos-one/BCOS/rlp"
+)
+
+// TokenHistoryEntry is a single point of a token balance or supply time series,
+// holding the value before and after the block with the given number was applied.
+type TokenHistoryEntry struct {
+	Number uint64
+	Prev   *big.Int
+	Value  *big.Int
+}
+
+// TokenHistorySection lists every series written while indexing a section, so
+// the section can be dropped again on reorgs or when it falls out of the
+// pruning horizon.
+type TokenHistorySection struct {
+	Balances []TokenHolder
+	Supplies []common.Address
+	Gaps     []uint64 // Blocks whose changes are missing as their states were pruned
+}
+
+// TokenBlockDiff holds the token balance and supply changes of a block, captured
+// while the block was processed so that they outlive the states of the block.
+type TokenBlockDiff struct {
+	Balances []TokenBalanceDiff
+	Supplies []TokenSupplyDiff
+}
+
+// TokenBalanceDiff is a token balance of a single holder changed by a block.
+type TokenBalanceDiff struct {
+	Holder common.Address
+	Token  common.Address
+	Prev   *big.Int
+	Value  *big.Int
+}
+
+// TokenSupplyDiff is the supply of a token changed by a block.
+type TokenSupplyDiff struct {
+	Token common.Address
+	Prev  *big.Int
+	Value *big.Int
+}
+
+// ReadTokenBlockDiff retrieves the token changes captured while processing a
+// block, or nil if they were not captured.
+func ReadTokenBlockDiff(db DatabaseReader, hash common.Hash, number uint64) *TokenBlockDiff {
+	data, _ := db.Get(tokenBlockDiffKey(number, hash))
+	if len(data) == 0 {
+		return nil
+	}
+	diff := new(TokenBlockDiff)
+	if err := rlp.DecodeBytes(data, diff); err != nil {
+		log.Error("Invalid token block diff RLP", "hash", hash, "err", err)
+		return nil
+	}
+	return diff
+}
+
+// WriteTokenBlockDiff stores the token changes captured while processing a block.
+func WriteTokenBlockDiff(db DatabaseWriter, hash common.Hash, number uint64, diff *TokenBlockDiff) {
+	data, err := rlp.EncodeToBytes(diff)
+	if err != nil {
+		log.Crit("Failed to encode token block diff", "err", err)
+	}
+	if err := db.Put(tokenBlockDiffKey(number, hash), data); err != nil {
+		log.Crit("Failed to store token block diff", "err", err)
+	}
+}
+
+// DeleteTokenBlockDiff removes the token changes captured for a block.
+func DeleteTokenBlockDiff(db DatabaseDeleter, hash common.Hash, number uint64) {
+	if err := db.Delete(tokenBlockDiffKey(number, hash)); err != nil {
+		log.Crit("Failed to delete token block diff", "err", err)
+	}
+}
+
+// ReadTokenBalanceHistory retrieves the balance changes of a token holder within
+// the given index section.
+func ReadTokenBalanceHistory(db DatabaseReader, token, holder common.Address, section uint64) []TokenHistoryEntry {
+	return readTokenHistory(db, tokenBalanceHistoryKey(token, holder, section))
+}
+
+// WriteTokenBalanceHistory stores the balance changes of a token holder within
+// the given index section.
+func WriteTokenBalanceHistory(db DatabaseWriter, token, holder common.Address, section uint64, entries []TokenHistoryEntry) {
+	writeTokenHistory(db, tokenBalanceHistoryKey(token, holder, section), entries)
+}
+
+// DeleteTokenBalanceHistory removes the balance changes of a token holder within
+// the given index section.
+func DeleteTokenBalanceHistory(db DatabaseDeleter, token, holder common.Address, section uint64) {
+	if err := db.Delete(tokenBalanceHistoryKey(token, holder, section)); err != nil {
+		log.Crit("Failed to delete token balance history", "err", err)
+	}
+}
+
+// ReadTokenSupplyHistory retrieves the supply changes of a token within the given
+// index section.
+func ReadTokenSupplyHistory(db DatabaseReader, token common.Address, section uint64) []TokenHistoryEntry {
+	return readTokenHistory(db, tokenSupplyHistoryKey(token, section))
+}
+
+// WriteTokenSupplyHistory stores the supply changes of a token within the given
+// index section.
+func WriteTokenSupplyHistory(db DatabaseWriter, token common.Address, section uint64, entries []TokenHistoryEntry) {
+	writeTokenHistory(db, tokenSupplyHistoryKey(token, section), entries)
+}
+
+// DeleteTokenSupplyHistory removes the supply changes of a token within the given
+// index section.
+func DeleteTokenSupplyHistory(db DatabaseDeleter, token common.Address, section uint64) {
+	if err := db.Delete(tokenSupplyHistoryKey(token, section)); err != nil {
+		log.Crit("Failed to delete token supply history", "err", err)
+	}
+}
+
+// ReadTokenHistorySection retrieves the list of series written for an index
+// section, or nil if the section was never indexed.
+func ReadTokenHistorySection(db DatabaseReader, section uint64) *TokenHistorySection {
+	data, _ := db.Get(tokenHistorySectionKey(section))
+	if len(data) == 0 {
+		return nil
+	}
+	index := new(TokenHistorySection)
+	if err := rlp.DecodeBytes(data, index); err != nil {
+		log.Error("Invalid token history section RLP", "section", section, "err", err)
+		return nil
+	}
+	return index
+}
+
+// WriteTokenHistorySection stores the list of series written for an index section.
+func WriteTokenHistorySection(db DatabaseWriter, section uint64, index *TokenHistorySection) {
+	data, err := rlp.EncodeToBytes(index)
+	if err != nil {
+		log.Crit("Failed to encode token history section", "err", err)
+	}
+	if err := db.Put(tokenHistorySectionKey(section), data); err != nil {
+		log.Crit("Failed to store token history section", "err", err)
+	}
+}
+
+// DeleteTokenHistorySection removes the series list of an index section.
+func DeleteTokenHistorySection(db DatabaseDeleter, section uint64) {
+	if err := db.Delete(tokenHistorySectionKey(section)); err != nil {
+		log.Crit("Failed to delete token history section", "err", err)
+	}
+}
+
+// ReadTokenHistoryTail retrieves the oldest token history section that has not
+// been pruned yet.
+func ReadTokenHistoryTail(db DatabaseReader) uint64 {
+	var tail uint64
+
+	enc, _ := db.Get(tokenHistoryTailKey)
+	rlp.DecodeBytes(enc, &tail)
+
+	return tail
+}
+
+// WriteTokenHistoryTail stores the oldest token history section that has not
+// been pruned yet.
+func WriteTokenHistoryTail(db DatabaseWriter, tail uint64) {
+	enc, _ := rlp.EncodeToBytes(tail)
+	if err := db.Put(tokenHistoryTailKey, enc); err != nil {
+		log.Crit("Failed to store token history tail", "err", err)
+	}
+}
+
+func readTokenHistory(db DatabaseReader, key []byte) []TokenHistoryEntry {
+	data, _ := db.Get(key)
+	if len(data) == 0 {
+		return nil
+	}
+	var entries []TokenHistoryEntry
+	if err := rlp.DecodeBytes(data, &entries); err != nil {
+		log.Error("Invalid token history RLP", "key", common.Bytes2Hex(key), "err", err)
+		return nil
+	}
+	return entries
+}
+
+func writeTokenHistory(db DatabaseWriter, key []byte, entries []TokenHistoryEntry) {
+	data, err := rlp.EncodeToBytes(entries)
+	if err != nil {
+		log.Crit("Failed to encode token history", "err", err)
+	}
+	if err := db.Put(key, data); err != nil {
+		log.Crit("Failed to store token history", "err", err)
+	}
+}
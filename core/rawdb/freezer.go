@@ -0,0 +1,405 @@
+package rawdb
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/core/types"
+	"github.com/bcos-one/BCOS/ethdb"
+	"github.com/bcos-one/BCOS/log"
+	"github.com/bcos-one/BCOS/rlp"
+)
+
+// The ancient store tables, one per kind of frozen chain data.
+const (
+	freezerHeaderTable     = "headers"  // RLP encoded canonical headers
+	freezerHashTable       = "hashes"   // Canonical block hashes
+	freezerBodiesTable     = "bodies"   // RLP encoded canonical block bodies
+	freezerReceiptTable    = "receipts" // RLP encoded canonical block receipts in storage form
+	freezerDifficultyTable = "diffs"    // RLP encoded canonical block total difficulties
+)
+
+// freezerTables lists the ancient store tables in the order items are appended.
+var freezerTables = []string{freezerHeaderTable, freezerHashTable, freezerBodiesTable, freezerReceiptTable, freezerDifficultyTable}
+
+// errUnknownTable is returned if the user attempts to read from an ancient table
+// that does not exist.
+var errUnknownTable = errors.New("unknown ancient table")
+
+// AncientStat describes the on-disk usage of a single ancient table.
+type AncientStat struct {
+	Kind  string // Kind of data stored in the table
+	Items uint64 // Number of items stored in the table
+	Size  uint64 // Bytes the table occupies on disk
+}
+
+// freezer is an append-only store of canonical chain data that is old enough to
+// be immutable. Every block frozen has exactly one item in each table, so the
+// item index doubles as the block number.
+type freezer struct {
+	dir    string
+	tables map[string]*freezerTable
+
+	frozen uint64       // Number of blocks already frozen
+	lock   sync.RWMutex // Mutex protecting the frozen counter across tables
+}
+
+// newFreezer opens the ancient store in the given directory, creating it if it
+// does not exist yet. Tables left with differing lengths by an unclean shutdown
+// are truncated to the shortest one.
+func newFreezer(dir string) (*freezer, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	f := &freezer{
+		dir:    dir,
+		tables: make(map[string]*freezerTable),
+	}
+	for _, name := range freezerTables {
+		table, err := newFreezerTable(dir, name)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		f.tables[name] = table
+	}
+	if err := f.repair(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	log.Info("Opened ancient database", "path", dir, "frozen", f.frozen)
+	return f, nil
+}
+
+// repair truncates all tables to the length of the shortest one.
+func (f *freezer) repair() error {
+	min := uint64(0)
+	for i, name := range freezerTables {
+		if items := f.tables[name].Items(); i == 0 || items < min {
+			min = items
+		}
+	}
+	for _, table := range f.tables {
+		if err := table.Truncate(min); err != nil {
+			return err
+		}
+	}
+	f.frozen = min
+	return nil
+}
+
+// HasAncient returns an indicator whether the specified ancient data exists.
+func (f *freezer) HasAncient(kind string, number uint64) (bool, error) {
+	if _, ok := f.tables[kind]; !ok {
+		return false, errUnknownTable
+	}
+	return number < f.frozenItems(), nil
+}
+
+// Ancient retrieves an ancient binary blob from the append-only store.
+func (f *freezer) Ancient(kind string, number uint64) ([]byte, error) {
+	table, ok := f.tables[kind]
+	if !ok {
+		return nil, errUnknownTable
+	}
+	if number >= f.frozenItems() {
+		return nil, errOutOfBounds
+	}
+	return table.Retrieve(number)
+}
+
+// Ancients returns the number of blocks frozen in the ancient store.
+func (f *freezer) Ancients() (uint64, error) {
+	return f.frozenItems(), nil
+}
+
+func (f *freezer) frozenItems() uint64 {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.frozen
+}
+
+// AppendAncient appends all the data of the next block to the ancient store. On
+// failure every table is rolled back to the previous block.
+func (f *freezer) AppendAncient(number uint64, hash, header, body, receipts, td []byte) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if number != f.frozen {
+		return errOutOrderInsertion
+	}
+	blobs := map[string][]byte{
+		freezerHashTable:       hash,
+		freezerHeaderTable:     header,
+		freezerBodiesTable:     body,
+		freezerReceiptTable:    receipts,
+		freezerDifficultyTable: td,
+	}
+	for _, name := range freezerTables {
+		if err := f.tables[name].Append(number, blobs[name]); err != nil {
+			for _, table := range f.tables {
+				if rerr := table.Truncate(f.frozen); rerr != nil {
+					log.Error("Failed to roll back ancient table", "table", table.name, "err", rerr)
+				}
+			}
+			return err
+		}
+	}
+	f.frozen++
+	return nil
+}
+
+// TruncateAncients discards all frozen blocks from the given number onwards.
+func (f *freezer) TruncateAncients(items uint64) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if items >= f.frozen {
+		return nil
+	}
+	for _, table := range f.tables {
+		if err := table.Truncate(items); err != nil {
+			return err
+		}
+	}
+	f.frozen = items
+	return nil
+}
+
+// Sync flushes all the ancient tables to disk.
+func (f *freezer) Sync() error {
+	for _, name := range freezerTables {
+		if err := f.tables[name].Sync(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AncientStats returns the disk usage of all the ancient tables.
+func (f *freezer) AncientStats() []AncientStat {
+	stats := make([]AncientStat, 0, len(freezerTables))
+	for _, name := range freezerTables {
+		table := f.tables[name]
+		stats = append(stats, AncientStat{Kind: name, Items: table.Items(), Size: table.Size()})
+	}
+	return stats
+}
+
+// Close terminates the ancient store, closing all the table files.
+func (f *freezer) Close() error {
+	var errs []error
+	for _, table := range f.tables {
+		if err := table.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// freezerdb is a database wrapper that combines a key-value store holding recent
+// data with an ancient store holding the immutable part of the chain.
+type freezerdb struct {
+	ethdb.Database
+	*freezer
+}
+
+// NewDatabaseWithFreezer wraps a key-value database with an ancient store in the
+// given directory. The chain accessors of this package transparently fall back to
+// the ancient store for data no longer present in the key-value store.
+func NewDatabaseWithFreezer(db ethdb.Database, ancient string) (ethdb.Database, error) {
+	frdb, err := newFreezer(ancient)
+	if err != nil {
+		return nil, err
+	}
+	return &freezerdb{Database: db, freezer: frdb}, nil
+}
+
+// Close closes both the ancient store and the key-value database.
+func (db *freezerdb) Close() {
+	if err := db.freezer.Close(); err != nil {
+		log.Error("Failed to close ancient database", "err", err)
+	}
+	db.Database.Close()
+}
+
+// InspectAncients returns the disk usage of the ancient tables of a database, or
+// nil if the database has no ancient store.
+func InspectAncients(db ethdb.Database) []AncientStat {
+	frdb, ok := db.(*freezerdb)
+	if !ok {
+		return nil
+	}
+	return frdb.AncientStats()
+}
+
+// CopyAncients appends the blocks frozen in the ancient store of src, but not yet
+// in the one of dst, to the ancient store of dst. It returns the number of blocks
+// copied.
+func CopyAncients(dst, src ethdb.Database) (uint64, error) {
+	from, ok := src.(*freezerdb)
+	if !ok {
+		return 0, nil
+	}
+	frozen, _ := from.Ancients()
+	to, ok := dst.(*freezerdb)
+	if !ok {
+		if frozen == 0 {
+			return 0, nil
+		}
+		return 0, errors.New("destination database has no ancient store")
+	}
+	start, _ := to.Ancients()
+	for number := start; number < frozen; number++ {
+		blobs := make(map[string][]byte, len(freezerTables))
+		for _, kind := range freezerTables {
+			blob, err := from.Ancient(kind, number)
+			if err != nil {
+				return number - start, err
+			}
+			blobs[kind] = blob
+		}
+		if err := to.AppendAncient(number, blobs[freezerHashTable], blobs[freezerHeaderTable], blobs[freezerBodiesTable], blobs[freezerReceiptTable], blobs[freezerDifficultyTable]); err != nil {
+			return number - start, err
+		}
+	}
+	if err := to.Sync(); err != nil {
+		return 0, err
+	}
+	if frozen < start {
+		return 0, nil
+	}
+	return frozen - start, nil
+}
+
+// RepairAncients verifies the frozen part of the chain, truncating the ancient
+// store at the first block with inconsistent data, and wipes any leftover copy
+// of frozen blocks from the key-value store. Copies are only deleted once the
+// truncated ancient store is synced to disk, so blocks dropped from it are never
+// lost from both stores. It returns the number of blocks still frozen after the
+// repair.
+func RepairAncients(db ethdb.Database) (uint64, error) {
+	frdb, ok := db.(*freezerdb)
+	if !ok {
+		return 0, errors.New("database has no ancient store")
+	}
+	frozen, _ := frdb.Ancients()
+
+	var (
+		parent common.Hash
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for number := uint64(0); number < frozen; number++ {
+		hash, err := verifyAncient(frdb, number, parent)
+		if err != nil {
+			log.Warn("Truncating inconsistent ancient data", "number", number, "err", err)
+			if err := frdb.TruncateAncients(number); err != nil {
+				return 0, err
+			}
+			frozen = number
+			break
+		}
+		parent = hash
+
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Verifying ancient data", "number", number, "frozen", frozen, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := frdb.Sync(); err != nil {
+		return 0, err
+	}
+	// Frozen blocks must only be present in the ancient store
+	batch := db.NewBatch()
+	for number := uint64(0); number < frozen; number++ {
+		blob, err := frdb.Ancient(freezerHashTable, number)
+		if err != nil {
+			return 0, err
+		}
+		hash := common.BytesToHash(blob)
+		for _, h := range ReadAllHashes(db, number) {
+			if h == hash {
+				DeleteBlockWithoutNumber(batch, h, number)
+			} else {
+				DeleteBlock(batch, h, number)
+			}
+		}
+		DeleteCanonicalHash(batch, number)
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return 0, err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Wiping frozen key-value data", "number", number, "frozen", frozen, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return 0, err
+	}
+	return frozen, nil
+}
+
+// verifyAncient checks that all the data frozen for a block is well formed and
+// belongs together, returning the block hash.
+func verifyAncient(db *freezerdb, number uint64, parent common.Hash) (common.Hash, error) {
+	blob, err := db.Ancient(freezerHashTable, number)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	hash := common.BytesToHash(blob)
+
+	header := new(types.Header)
+	if blob, err = db.Ancient(freezerHeaderTable, number); err != nil {
+		return common.Hash{}, err
+	}
+	if err := rlp.DecodeBytes(blob, header); err != nil {
+		return common.Hash{}, fmt.Errorf("invalid header: %v", err)
+	}
+	if header.Hash() != hash || header.Number.Uint64() != number {
+		return common.Hash{}, fmt.Errorf("header mismatch: have #%d [%x…], want #%d [%x…]", header.Number, header.Hash().Bytes()[:4], number, hash[:4])
+	}
+	if number > 0 && header.ParentHash != parent {
+		return common.Hash{}, fmt.Errorf("parent mismatch: have %x, want %x", header.ParentHash, parent)
+	}
+	body := new(types.Body)
+	if blob, err = db.Ancient(freezerBodiesTable, number); err != nil {
+		return common.Hash{}, err
+	}
+	if err := rlp.DecodeBytes(blob, body); err != nil {
+		return common.Hash{}, fmt.Errorf("invalid body: %v", err)
+	}
+	if types.DeriveSha(types.Transactions(body.Transactions)) != header.TxHash || types.CalcUncleHash(body.Uncles) != header.UncleHash {
+		return common.Hash{}, errors.New("body mismatch")
+	}
+	var receipts []*types.ReceiptForStorage
+	if blob, err = db.Ancient(freezerReceiptTable, number); err != nil {
+		return common.Hash{}, err
+	}
+	if err := rlp.DecodeBytes(blob, &receipts); err != nil {
+		return common.Hash{}, fmt.Errorf("invalid receipts: %v", err)
+	}
+	if len(receipts) != len(body.Transactions) {
+		return common.Hash{}, fmt.Errorf("receipt count mismatch: have %d, want %d", len(receipts), len(body.Transactions))
+	}
+	td := new(big.Int)
+	if blob, err = db.Ancient(freezerDifficultyTable, number); err != nil {
+		return common.Hash{}, err
+	}
+	if err := rlp.DecodeBytes(blob, td); err != nil {
+		return common.Hash{}, fmt.Errorf("invalid total difficulty: %v", err)
+	}
+	return hash, nil
+}
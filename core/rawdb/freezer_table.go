@@ -0,0 +1,242 @@
+package rawdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// indexEntrySize is the size of a single index entry, the big endian end offset
+// of an item within the data file.
+const indexEntrySize = 8
+
+var (
+	// errOutOfBounds is returned if the item requested is not contained within the
+	// ancient table.
+	errOutOfBounds = errors.New("out of bounds")
+
+	// errOutOrderInsertion is returned if the user attempts to append an item out
+	// of order into an ancient table.
+	errOutOrderInsertion = errors.New("the append operation is out-order")
+
+	// errClosed is returned if an operation attempts to use a closed ancient table.
+	errClosed = errors.New("ancient store closed")
+)
+
+// freezerTable is an append-only flat file table of a single kind of ancient
+// data. Items are stored back to back in a data file, with a companion index
+// file holding the end offset of every item.
+type freezerTable struct {
+	name  string
+	data  *os.File // Data file holding the items back to back
+	index *os.File // Index file holding the end offsets of the items
+
+	items uint64 // Number of items stored in the table
+	head  uint64 // Size of the data file, the end offset of the last item
+
+	lock sync.RWMutex // Mutex protecting the files and counters
+}
+
+// newFreezerTable opens the given table in the ancient directory, creating it if
+// needed, and repairs any inconsistency left by an unclean shutdown.
+func newFreezerTable(dir string, name string) (*freezerTable, error) {
+	data, err := os.OpenFile(filepath.Join(dir, name+".dat"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	index, err := os.OpenFile(filepath.Join(dir, name+".idx"), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		data.Close()
+		return nil, err
+	}
+	table := &freezerTable{
+		name:  name,
+		data:  data,
+		index: index,
+	}
+	if err := table.repair(); err != nil {
+		table.Close()
+		return nil, err
+	}
+	return table, nil
+}
+
+// repair drops any partially written item, truncating the index to whole entries
+// and the data file to the end of the last fully indexed item.
+func (t *freezerTable) repair() error {
+	stat, err := t.index.Stat()
+	if err != nil {
+		return err
+	}
+	items := uint64(stat.Size()) / indexEntrySize
+
+	stat, err = t.data.Stat()
+	if err != nil {
+		return err
+	}
+	size := uint64(stat.Size())
+
+	var head uint64
+	for ; items > 0; items-- {
+		if head, err = t.offset(items - 1); err != nil {
+			return err
+		}
+		if head <= size {
+			break
+		}
+		head = 0
+	}
+	return t.truncateFiles(items, head)
+}
+
+// offset returns the end offset of the given item within the data file.
+func (t *freezerTable) offset(item uint64) (uint64, error) {
+	buf := make([]byte, indexEntrySize)
+	if _, err := t.index.ReadAt(buf, int64(item*indexEntrySize)); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(buf), nil
+}
+
+// truncateFiles cuts both files down to the given item count and data size.
+func (t *freezerTable) truncateFiles(items uint64, head uint64) error {
+	if err := t.index.Truncate(int64(items * indexEntrySize)); err != nil {
+		return err
+	}
+	if err := t.data.Truncate(int64(head)); err != nil {
+		return err
+	}
+	t.items, t.head = items, head
+	return nil
+}
+
+// Items returns the number of items stored in the table.
+func (t *freezerTable) Items() uint64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.items
+}
+
+// Size returns the total number of bytes the table occupies on disk.
+func (t *freezerTable) Size() uint64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.head + t.items*indexEntrySize
+}
+
+// Retrieve looks up the data at the given item index.
+func (t *freezerTable) Retrieve(item uint64) ([]byte, error) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.index == nil {
+		return nil, errClosed
+	}
+	if item >= t.items {
+		return nil, errOutOfBounds
+	}
+	var start uint64
+	if item > 0 {
+		var err error
+		if start, err = t.offset(item - 1); err != nil {
+			return nil, err
+		}
+	}
+	end, err := t.offset(item)
+	if err != nil {
+		return nil, err
+	}
+	blob := make([]byte, end-start)
+	if _, err := t.data.ReadAt(blob, int64(start)); err != nil {
+		return nil, err
+	}
+	return blob, nil
+}
+
+// Append injects a binary blob at the end of the table. The item number is only
+// used to ensure items are appended in order.
+func (t *freezerTable) Append(item uint64, blob []byte) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil {
+		return errClosed
+	}
+	if item != t.items {
+		return errOutOrderInsertion
+	}
+	if _, err := t.data.WriteAt(blob, int64(t.head)); err != nil {
+		return err
+	}
+	end := make([]byte, indexEntrySize)
+	binary.BigEndian.PutUint64(end, t.head+uint64(len(blob)))
+	if _, err := t.index.WriteAt(end, int64(t.items*indexEntrySize)); err != nil {
+		return err
+	}
+	t.items++
+	t.head += uint64(len(blob))
+	return nil
+}
+
+// Truncate discards all items from the given index onwards.
+func (t *freezerTable) Truncate(items uint64) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil {
+		return errClosed
+	}
+	if items >= t.items {
+		return nil
+	}
+	var head uint64
+	if items > 0 {
+		var err error
+		if head, err = t.offset(items - 1); err != nil {
+			return err
+		}
+	}
+	return t.truncateFiles(items, head)
+}
+
+// Sync pushes any pending data from memory out to disk.
+func (t *freezerTable) Sync() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil {
+		return errClosed
+	}
+	if err := t.data.Sync(); err != nil {
+		return err
+	}
+	return t.index.Sync()
+}
+
+// Close closes all the table files.
+func (t *freezerTable) Close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var errs []error
+	if t.data != nil {
+		if err := t.data.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if t.index != nil {
+		if err := t.index.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	t.data, t.index = nil, nil
+
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package rawdb
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/core/types"
+	"github.com/bcos-one/BCOS/ethdb"
+	"github.com/bcos-one/BCOS/rlp"
+)
+
+// Tests that a table drops partially written items when reopened.
+func TestFreezerTableRepair(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	table, err := newFreezerTable(dir, "test")
+	if err != nil {
+		t.Fatalf("failed to open table: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if err := table.Append(uint64(i), bytes.Repeat([]byte{byte(i)}, i+1)); err != nil {
+			t.Fatalf("failed to append item %d: %v", i, err)
+		}
+	}
+	if err := table.Append(5, nil); err != errOutOrderInsertion {
+		t.Fatalf("out of order append error mismatch: have %v, want %v", err, errOutOrderInsertion)
+	}
+	table.Close()
+
+	// Cut the data of the last item short, simulating a crash mid-write
+	if err := os.Truncate(filepath.Join(dir, "test.dat"), 4); err != nil {
+		t.Fatal(err)
+	}
+	if table, err = newFreezerTable(dir, "test"); err != nil {
+		t.Fatalf("failed to reopen table: %v", err)
+	}
+	defer table.Close()
+
+	if items := table.Items(); items != 2 {
+		t.Fatalf("item count mismatch after repair: have %d, want 2", items)
+	}
+	if blob, err := table.Retrieve(1); err != nil || !bytes.Equal(blob, []byte{1, 1}) {
+		t.Fatalf("item mismatch after repair: have %x, %v", blob, err)
+	}
+	if _, err := table.Retrieve(2); err != errOutOfBounds {
+		t.Fatalf("repaired item error mismatch: have %v, want %v", err, errOutOfBounds)
+	}
+}
+
+// Tests that the chain accessors transparently read frozen blocks, and that a
+// repair truncates the ancient store at inconsistent data.
+func TestAncientReads(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := NewDatabaseWithFreezer(ethdb.NewMemDatabase(), dir)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	var blocks []*types.Block
+	parent := common.Hash{}
+	for i := 0; i < 3; i++ {
+		block := types.NewBlock(&types.Header{ParentHash: parent, Number: big.NewInt(int64(i)), Extra: []byte("test block")}, nil, nil, nil)
+		WriteBlock(db, block)
+		WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+		WriteReceipts(db, block.Hash(), block.NumberU64(), nil)
+		WriteTd(db, block.Hash(), block.NumberU64(), big.NewInt(int64(i+1)))
+
+		blocks = append(blocks, block)
+		parent = block.Hash()
+	}
+	// Freeze the blocks and wipe them from the key-value store
+	ancients := db.(AncientStore)
+	for _, block := range blocks {
+		hash, number := block.Hash(), block.NumberU64()
+		header, _ := rlp.EncodeToBytes(block.Header())
+		td, _ := rlp.EncodeToBytes(big.NewInt(int64(number + 1)))
+		if number == 2 {
+			header = []byte{0xc0} // Corrupt header, dropped by the repair
+		}
+		if err := ancients.AppendAncient(number, hash.Bytes(), header, ReadBodyRLP(db, hash, number), ReadReceiptsRLP(db, hash, number), td); err != nil {
+			t.Fatalf("failed to freeze block %d: %v", number, err)
+		}
+		if number < 2 {
+			DeleteBlockWithoutNumber(db, hash, number)
+			DeleteCanonicalHash(db, number)
+		}
+	}
+	for _, block := range blocks[:2] {
+		hash, number := block.Hash(), block.NumberU64()
+		if have := ReadCanonicalHash(db, number); have != hash {
+			t.Fatalf("block %d: canonical hash mismatch: have %x, want %x", number, have, hash)
+		}
+		if have := ReadBlock(db, hash, number); have == nil || have.Hash() != hash {
+			t.Fatalf("block %d: frozen block not found", number)
+		}
+		if !HasHeader(db, hash, number) || !HasBody(db, hash, number) {
+			t.Fatalf("block %d: frozen data reported missing", number)
+		}
+		if td := ReadTd(db, hash, number); td == nil || td.Uint64() != number+1 {
+			t.Fatalf("block %d: total difficulty mismatch: have %v", number, td)
+		}
+		if HasHeader(db, common.Hash{0x01}, number) {
+			t.Fatalf("block %d: unknown hash found in ancient store", number)
+		}
+	}
+	frozen, err := RepairAncients(db)
+	if err != nil {
+		t.Fatalf("failed to repair ancient store: %v", err)
+	}
+	if frozen != 2 {
+		t.Fatalf("frozen blocks mismatch after repair: have %d, want 2", frozen)
+	}
+	if have, _ := db.(AncientStore).Ancients(); have != 2 {
+		t.Fatalf("corrupt block survived the repair")
+	}
+	// Blocks dropped from the ancient store must keep their key-value copy
+	if have := ReadBlock(db, blocks[2].Hash(), 2); have == nil || have.Hash() != blocks[2].Hash() {
+		t.Fatalf("truncated block lost from the key-value store")
+	}
+	if have := ReadCanonicalHash(db, 2); have != blocks[2].Hash() {
+		t.Fatalf("truncated block canonical hash lost: have %x", have)
+	}
+}
+
+// Tests that frozen blocks are copied between ancient stores.
+func TestCopyAncients(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	src, err := NewDatabaseWithFreezer(ethdb.NewMemDatabase(), filepath.Join(dir, "src"))
+	if err != nil {
+		t.Fatalf("failed to open source database: %v", err)
+	}
+	defer src.Close()
+	dst, err := NewDatabaseWithFreezer(ethdb.NewMemDatabase(), filepath.Join(dir, "dst"))
+	if err != nil {
+		t.Fatalf("failed to open destination database: %v", err)
+	}
+	defer dst.Close()
+
+	var blocks []*types.Block
+	parent := common.Hash{}
+	for i := 0; i < 3; i++ {
+		block := types.NewBlock(&types.Header{ParentHash: parent, Number: big.NewInt(int64(i)), Extra: []byte("test block")}, nil, nil, nil)
+		header, _ := rlp.EncodeToBytes(block.Header())
+		body, _ := rlp.EncodeToBytes(block.Body())
+		receipts, _ := rlp.EncodeToBytes([]*types.ReceiptForStorage{})
+		td, _ := rlp.EncodeToBytes(big.NewInt(int64(i + 1)))
+		if err := src.(AncientStore).AppendAncient(uint64(i), block.Hash().Bytes(), header, body, receipts, td); err != nil {
+			t.Fatalf("failed to freeze block %d: %v", i, err)
+		}
+		blocks = append(blocks, block)
+		parent = block.Hash()
+	}
+	copied, err := CopyAncients(dst, src)
+	if err != nil {
+		t.Fatalf("failed to copy ancients: %v", err)
+	}
+	if copied != 3 {
+		t.Fatalf("copied blocks mismatch: have %d, want 3", copied)
+	}
+	for _, block := range blocks {
+		if have := ReadBlock(dst, block.Hash(), block.NumberU64()); have == nil || have.Hash() != block.Hash() {
+			t.Fatalf("block %d: copied block not found", block.NumberU64())
+		}
+	}
+	// Copying into a database without an ancient store must fail loudly
+	if _, err := CopyAncients(ethdb.NewMemDatabase(), src); err == nil {
+		t.Fatalf("copy into database without ancient store succeeded")
+	}
+}
@@ -16,6 +16,8 @@
 
 package rawdb
 
+import "github.com/bcos-one/BCOS/ethdb"
+
 // DatabaseReader wraps the Has and Get method of a backing data store.
 type DatabaseReader interface {
 	Has(key []byte) (bool, error)
@@ -31,3 +33,39 @@ type DatabaseWriter interface {
 type DatabaseDeleter interface {
 	Delete(key []byte) error
 }
+
+// DatabaseIteratee wraps the NewIteratorWithPrefix method of a backing data store.
+type DatabaseIteratee interface {
+	NewIteratorWithPrefix(prefix []byte) ethdb.Iterator
+}
+
+// AncientReader wraps the read methods of an ancient store of immutable chain data.
+type AncientReader interface {
+	// HasAncient returns an indicator whether the specified ancient data exists.
+	HasAncient(kind string, number uint64) (bool, error)
+
+	// Ancient retrieves an ancient binary blob from the append-only store.
+	Ancient(kind string, number uint64) ([]byte, error)
+
+	// Ancients returns the number of blocks frozen in the ancient store.
+	Ancients() (uint64, error)
+}
+
+// AncientWriter wraps the write methods of an ancient store of immutable chain data.
+type AncientWriter interface {
+	// AppendAncient appends all the data of the next block to the ancient store.
+	AppendAncient(number uint64, hash, header, body, receipts, td []byte) error
+
+	// TruncateAncients discards all frozen blocks from the given number onwards.
+	TruncateAncients(items uint64) error
+
+	// Sync flushes all the ancient data to disk.
+	Sync() error
+}
+
+// AncientStore is a database with an ancient store attached.
+type AncientStore interface {
+	ethdb.Database
+	AncientReader
+	AncientWriter
+}
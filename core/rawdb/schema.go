@@ -56,8 +56,31 @@ var (
 	preimagePrefix = []byte("secure-key-")      // preimagePrefix + hash -> preimage
 	configPrefix   = []byte("ethereum-config-") // config prefix for the db
 
+	tokenBalanceHistoryPrefix = []byte("tb") // tokenBalanceHistoryPrefix + token + holder + section (uint64 big endian) -> balance changes
+	tokenSupplyHistoryPrefix  = []byte("ts") // tokenSupplyHistoryPrefix + token + section (uint64 big endian) -> supply changes
+	tokenHistorySectionPrefix = []byte("tk") // tokenHistorySectionPrefix + section (uint64 big endian) -> series written in the section
+	tokenBlockDiffPrefix      = []byte("td") // tokenBlockDiffPrefix + num (uint64 big endian) + hash -> token changes of the block
+
+	// tokenHistoryTailKey tracks the oldest token history section not yet pruned.
+	tokenHistoryTailKey = []byte("TokenHistoryTail")
+
+	// snapshotRootKey tracks the state root of the persisted flat state snapshot.
+	snapshotRootKey = []byte("SnapshotRoot")
+
+	// snapshotJournalKey tracks the in-memory diff layers across restarts.
+	snapshotJournalKey = []byte("SnapshotJournal")
+
+	// snapshotGeneratorKey tracks the progress of the snapshot generation.
+	snapshotGeneratorKey = []byte("SnapshotGenerator")
+
+	// Flat state snapshot prefixes (keyed by the hashes used in the state tries).
+	SnapshotAccountPrefix = []byte("a") // SnapshotAccountPrefix + account hash -> account trie value
+	SnapshotStoragePrefix = []byte("o") // SnapshotStoragePrefix + account hash + storage hash -> storage trie value
+	SnapshotTokenPrefix   = []byte("m") // SnapshotTokenPrefix + account hash + token hash -> token balance trie value
+
 	// Chain index prefixes (use `i` + single byte to avoid mixing data types).
-	BloomBitsIndexPrefix = []byte("iB") // BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
+	BloomBitsIndexPrefix    = []byte("iB") // BloomBitsIndexPrefix is the data table of a chain indexer to track its progress
+	TokenHistoryIndexPrefix = []byte("iT") // TokenHistoryIndexPrefix is the data table of the token history indexer
 
 	preimageCounter    = metrics.NewRegisteredCounter("db/preimage/total", nil)
 	preimageHitCounter = metrics.NewRegisteredCounter("db/preimage/hits", nil)
@@ -71,6 +94,12 @@ type TxLookupEntry struct {
 	Index      uint64
 }
 
+// TokenHolder identifies the token balance series of a single account.
+type TokenHolder struct {
+	Token  common.Address
+	Holder common.Address
+}
+
 // encodeBlockNumber encodes a block number as big endian uint64
 func encodeBlockNumber(number uint64) []byte {
 	enc := make([]byte, 8)
@@ -132,3 +161,39 @@ func preimageKey(hash common.Hash) []byte {
 func configKey(hash common.Hash) []byte {
 	return append(configPrefix, hash.Bytes()...)
 }
+
+// tokenBalanceHistoryKey = tokenBalanceHistoryPrefix + token + holder + section (uint64 big endian)
+func tokenBalanceHistoryKey(token, holder common.Address, section uint64) []byte {
+	key := append(append(append([]byte{}, tokenBalanceHistoryPrefix...), token.Bytes()...), holder.Bytes()...)
+	return append(key, encodeBlockNumber(section)...)
+}
+
+// tokenSupplyHistoryKey = tokenSupplyHistoryPrefix + token + section (uint64 big endian)
+func tokenSupplyHistoryKey(token common.Address, section uint64) []byte {
+	return append(append(append([]byte{}, tokenSupplyHistoryPrefix...), token.Bytes()...), encodeBlockNumber(section)...)
+}
+
+// tokenHistorySectionKey = tokenHistorySectionPrefix + section (uint64 big endian)
+func tokenHistorySectionKey(section uint64) []byte {
+	return append(append([]byte{}, tokenHistorySectionPrefix...), encodeBlockNumber(section)...)
+}
+
+// tokenBlockDiffKey = tokenBlockDiffPrefix + num (uint64 big endian) + hash
+func tokenBlockDiffKey(number uint64, hash common.Hash) []byte {
+	return append(append(append([]byte{}, tokenBlockDiffPrefix...), encodeBlockNumber(number)...), hash.Bytes()...)
+}
+
+// accountSnapshotKey = SnapshotAccountPrefix + account hash
+func accountSnapshotKey(hash common.Hash) []byte {
+	return append(append([]byte{}, SnapshotAccountPrefix...), hash.Bytes()...)
+}
+
+// storageSnapshotKey = SnapshotStoragePrefix + account hash + storage hash
+func storageSnapshotKey(accountHash, storageHash common.Hash) []byte {
+	return append(append(append([]byte{}, SnapshotStoragePrefix...), accountHash.Bytes()...), storageHash.Bytes()...)
+}
+
+// tokenSnapshotKey = SnapshotTokenPrefix + account hash + token hash
+func tokenSnapshotKey(accountHash, tokenHash common.Hash) []byte {
+	return append(append(append([]byte{}, SnapshotTokenPrefix...), accountHash.Bytes()...), tokenHash.Bytes()...)
+}
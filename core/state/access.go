@@ -0,0 +1,394 @@
+package state
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/core/types"
+)
+
+// AccessSet is a set of accounts, storage slots and token balances read or
+// written by a transaction. Token operations keep their data in the storage of
+// the TokenStorage account, so those slots are tracked like any other.
+type AccessSet struct {
+	accounts map[common.Address]struct{}
+	storage  map[common.Address]map[common.Hash]struct{}
+	tokens   map[common.Address]map[common.Address]struct{}
+
+	// caches holds the accounts whose emptiness depended on the storage slots and
+	// token balances loaded so far (reads), or whose loaded items changed (writes).
+	caches map[common.Address]struct{}
+
+	// wiped holds the accounts whose storage was iterated as a whole (reads), or
+	// whose storage and token balances were replaced as a whole (writes).
+	wiped map[common.Address]struct{}
+}
+
+// NewAccessSet creates an empty access set.
+func NewAccessSet() *AccessSet {
+	return &AccessSet{
+		accounts: make(map[common.Address]struct{}),
+		storage:  make(map[common.Address]map[common.Hash]struct{}),
+		tokens:   make(map[common.Address]map[common.Address]struct{}),
+		caches:   make(map[common.Address]struct{}),
+		wiped:    make(map[common.Address]struct{}),
+	}
+}
+
+func (set *AccessSet) addAccount(addr common.Address) {
+	set.accounts[addr] = struct{}{}
+}
+
+func (set *AccessSet) addStorage(addr common.Address, key common.Hash) {
+	set.accounts[addr] = struct{}{}
+	if set.storage[addr] == nil {
+		set.storage[addr] = make(map[common.Hash]struct{})
+	}
+	set.storage[addr][key] = struct{}{}
+}
+
+func (set *AccessSet) addToken(addr common.Address, token common.Address) {
+	set.accounts[addr] = struct{}{}
+	if set.tokens[addr] == nil {
+		set.tokens[addr] = make(map[common.Address]struct{})
+	}
+	set.tokens[addr][token] = struct{}{}
+}
+
+// Conflicts reports whether any item of the read set was modified by the given
+// write set.
+func (set *AccessSet) Conflicts(written *AccessSet) bool {
+	for addr := range set.accounts {
+		if _, ok := written.accounts[addr]; ok {
+			return true
+		}
+	}
+	for addr, keys := range set.storage {
+		if _, ok := written.wiped[addr]; ok {
+			return true
+		}
+		for key := range keys {
+			if _, ok := written.storage[addr][key]; ok {
+				return true
+			}
+		}
+	}
+	for addr, tokens := range set.tokens {
+		if _, ok := written.wiped[addr]; ok {
+			return true
+		}
+		for token := range tokens {
+			if _, ok := written.tokens[addr][token]; ok {
+				return true
+			}
+		}
+	}
+	for addr := range set.caches {
+		if _, ok := written.caches[addr]; ok {
+			return true
+		}
+	}
+	for addr := range set.wiped {
+		if _, ok := written.wiped[addr]; ok || len(written.storage[addr]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Merge adds all the items of another access set to this one.
+func (set *AccessSet) Merge(other *AccessSet) {
+	for addr := range other.accounts {
+		set.addAccount(addr)
+	}
+	for addr, keys := range other.storage {
+		for key := range keys {
+			set.addStorage(addr, key)
+		}
+	}
+	for addr, tokens := range other.tokens {
+		for token := range tokens {
+			set.addToken(addr, token)
+		}
+	}
+	for addr := range other.caches {
+		set.caches[addr] = struct{}{}
+	}
+	for addr := range other.wiped {
+		set.wiped[addr] = struct{}{}
+	}
+}
+
+// accessTracker records the state accessed by a transaction. Balances and token
+// balances only ever credited, but never read, are kept apart: crediting commutes
+// with any other change, so e.g. the fees paid to the coinbase don't make every
+// transaction depend on its predecessors.
+type accessTracker struct {
+	reads        *AccessSet
+	existed      map[common.Address]bool                        // Whether the account existed when first accessed
+	created      map[common.Address]*stateObject                // Accounts created on empty tries
+	credits      map[common.Address]*big.Int                    // Balances before the first credit
+	tokenCredits map[common.Address]map[common.Address]*big.Int // Token balances before the first credit
+}
+
+func newAccessTracker() *accessTracker {
+	return &accessTracker{
+		reads:        NewAccessSet(),
+		existed:      make(map[common.Address]bool),
+		created:      make(map[common.Address]*stateObject),
+		credits:      make(map[common.Address]*big.Int),
+		tokenCredits: make(map[common.Address]map[common.Address]*big.Int),
+	}
+}
+
+// creditOnly reports whether the account was only credited, never read.
+func (t *accessTracker) creditOnly(addr common.Address) bool {
+	_, read := t.reads.accounts[addr]
+	return !read
+}
+
+// fresh reports whether the object was created by the tracked transaction, so
+// none of its items could have been loaded by anyone else.
+func (t *accessTracker) fresh(obj *stateObject) bool {
+	return t.created[obj.address] == obj
+}
+
+// vacuous reports whether the object was created and emptied again by the
+// tracked transaction, leaving the state unchanged.
+func (t *accessTracker) vacuous(obj *stateObject) bool {
+	return t.fresh(obj) && !t.existed[obj.address] && (obj.suicided || obj.empty())
+}
+
+// StartAccessTracking starts recording the state accessed by the transactions
+// applied from now on.
+func (self *StateDB) StartAccessTracking() {
+	self.access = newAccessTracker()
+}
+
+// StopAccessTracking stops recording the accessed state.
+func (self *StateDB) StopAccessTracking() {
+	self.access = nil
+}
+
+// Speculate creates an independent copy of the state for optimistically
+// executing a transaction, which records the accessed state. Unlike Copy, all
+// the live objects are copied with their caches, as the emptiness of accounts
+// depends on the loaded items. The state must be finalised and must not be
+// modified while speculating.
+func (self *StateDB) Speculate() *StateDB {
+	state := &StateDB{
+		db:                self.db,
+		trie:              self.db.CopyTrie(self.trie),
+		snaps:             self.snaps,
+		snap:              self.snap,
+		stateObjects:      make(map[common.Address]*stateObject, len(self.stateObjects)),
+		stateObjectsDirty: make(map[common.Address]struct{}),
+		logs:              make(map[common.Hash][]*types.Log),
+		preimages:         make(map[common.Hash][]byte),
+		journal:           newJournal(),
+		access:            newAccessTracker(),
+	}
+	for addr, object := range self.stateObjects {
+		state.stateObjects[addr] = object.deepCopy(state)
+	}
+	return state
+}
+
+// AccessSets returns the state read and written by the transaction applied since
+// the tracking started. It must be called before the state is finalised.
+func (self *StateDB) AccessSets() (reads, writes *AccessSet) {
+	t := self.access
+	writes = NewAccessSet()
+	for addr := range self.journal.dirties {
+		obj := self.stateObjects[addr]
+		if obj == nil {
+			continue
+		}
+		// Evaluating the emptiness for finalisation might record another read
+		deleted := obj.suicided || obj.empty()
+		if t.vacuous(obj) {
+			continue
+		}
+		writes.addAccount(addr)
+		writes.caches[addr] = struct{}{}
+		if deleted || t.fresh(obj) {
+			writes.wiped[addr] = struct{}{}
+		}
+		for key := range obj.dirtyStorage {
+			writes.addStorage(addr, key)
+		}
+		for token := range obj.dirtyTokenBalance {
+			writes.addToken(addr, token)
+		}
+	}
+	// Loading storage slots and token balances changes the emptiness of accounts
+	for addr := range t.reads.storage {
+		writes.caches[addr] = struct{}{}
+	}
+	for addr := range t.reads.tokens {
+		writes.caches[addr] = struct{}{}
+	}
+	for addr := range t.tokenCredits {
+		writes.caches[addr] = struct{}{}
+	}
+	return t.reads, writes
+}
+
+// MergeSpeculation applies the changes of a transaction speculatively executed
+// on a copy made by Speculate, as if the transaction was applied to this state.
+// The result is only exact if the state read by the transaction wasn't modified
+// since the copy was made.
+func (self *StateDB) MergeSpeculation(spec *StateDB) {
+	t := spec.access
+	for addr := range spec.journal.dirties {
+		obj := spec.stateObjects[addr]
+		if obj == nil {
+			continue
+		}
+		switch {
+		case t.vacuous(obj):
+			// Recreate the touched account, finalising deletes it again
+			newobj, _ := self.createObject(addr)
+			newobj.touch()
+
+		case t.creditOnly(addr):
+			// Credits apply on top of whatever the account holds now
+			if prev, ok := t.credits[addr]; ok {
+				if diff := new(big.Int).Sub(obj.Balance(), prev); diff.Sign() != 0 {
+					self.AddBalance(addr, diff)
+				}
+			}
+			for token, prev := range t.tokenCredits[addr] {
+				if diff := new(big.Int).Sub(obj.TokenBalance(spec.db, token), prev); diff.Sign() != 0 {
+					self.AddTokenBalance(addr, token, diff)
+				}
+			}
+			if self.journal.dirties[addr] == 0 {
+				self.GetOrNewStateObject(addr).touch()
+			}
+
+		default:
+			dst := self.getStateObject(addr)
+			if t.fresh(obj) || dst == nil {
+				dst, _ = self.createObject(addr)
+			}
+			if dst.Balance().Cmp(obj.Balance()) != 0 {
+				dst.SetBalance(new(big.Int).Set(obj.Balance()))
+			}
+			if dst.Nonce() != obj.Nonce() {
+				dst.SetNonce(obj.Nonce())
+			}
+			if !bytes.Equal(dst.CodeHash(), obj.CodeHash()) {
+				dst.SetCode(common.BytesToHash(obj.CodeHash()), obj.Code(spec.db))
+			}
+			if *dst.TokenSupport() != *obj.TokenSupport() {
+				dst.SetTokenSupport(*obj.TokenSupport())
+			}
+			for key, value := range obj.dirtyStorage {
+				dst.SetState(self.db, key, value)
+			}
+			for token, amount := range obj.dirtyTokenBalance {
+				dst.SetTokenBalance(self.db, token, new(big.Int).Set(amount))
+			}
+			if obj.suicided && !dst.suicided {
+				self.journal.append(suicideChange{
+					account:     &addr,
+					prev:        false,
+					prevbalance: new(big.Int).Set(dst.Balance()),
+				})
+				dst.markSuicided()
+			}
+			if self.journal.dirties[addr] == 0 {
+				dst.touch()
+			}
+		}
+	}
+	// Load the same storage slots and token balances, they affect the emptiness
+	for addr := range t.reads.accounts {
+		obj, dst := spec.stateObjects[addr], self.stateObjects[addr]
+		if obj == nil || obj.deleted || dst == nil || dst.deleted {
+			continue
+		}
+		for key := range obj.originStorage {
+			if _, ok := dst.originStorage[key]; !ok {
+				dst.GetCommittedState(self.db, key)
+			}
+		}
+		for token := range obj.originTokenBalance {
+			if _, ok := dst.originTokenBalance[token]; !ok {
+				dst.GetCommittedTokenBalance(self.db, token)
+			}
+		}
+	}
+	for _, log := range spec.logs[spec.thash] {
+		cpy := *log
+		self.AddLog(&cpy)
+	}
+	for hash, preimage := range spec.preimages {
+		self.AddPreimage(hash, preimage)
+	}
+}
+
+// readAccount records a read of the account fields.
+func (self *StateDB) readAccount(addr common.Address) {
+	if self.access != nil {
+		self.access.reads.addAccount(addr)
+	}
+}
+
+// readStorage records a read of a storage slot.
+func (self *StateDB) readStorage(addr common.Address, key common.Hash) {
+	if self.access != nil {
+		self.access.reads.addStorage(addr, key)
+	}
+}
+
+// readToken records a read of a token balance.
+func (self *StateDB) readToken(addr common.Address, token common.Address) {
+	if self.access != nil {
+		self.access.reads.addToken(addr, token)
+	}
+}
+
+// readEmptiness records that the emptiness of the object was evaluated. Unless
+// the account fields decide it, it depends on the loaded items.
+func (self *StateDB) readEmptiness(obj *stateObject) {
+	if self.access != nil && !self.access.fresh(obj) {
+		self.access.reads.caches[obj.address] = struct{}{}
+	}
+}
+
+// creditBalance records a credit of the balance of the object. Crediting nothing
+// only touches the account, which depends on its emptiness.
+func (self *StateDB) creditBalance(obj *stateObject, amount *big.Int) {
+	if self.access == nil {
+		return
+	}
+	if amount.Sign() == 0 {
+		self.access.reads.addAccount(obj.address)
+		return
+	}
+	if _, ok := self.access.credits[obj.address]; !ok {
+		self.access.credits[obj.address] = new(big.Int).Set(obj.Balance())
+	}
+}
+
+// creditToken records a credit of a token balance of the object.
+func (self *StateDB) creditToken(obj *stateObject, token common.Address, amount *big.Int) {
+	if self.access == nil {
+		return
+	}
+	if amount.Sign() == 0 {
+		self.access.reads.addAccount(obj.address)
+		return
+	}
+	credits := self.access.tokenCredits[obj.address]
+	if credits == nil {
+		credits = make(map[common.Address]*big.Int)
+		self.access.tokenCredits[obj.address] = credits
+	}
+	if _, ok := credits[token]; !ok {
+		credits[token] = new(big.Int).Set(obj.TokenBalance(self.db, token))
+	}
+}
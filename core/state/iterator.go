@@ -26,12 +26,13 @@ import (
 )
 
 // NodeIterator is an iterator to traverse the entire state trie post-order,
-// including all of the contract code and contract state tries.
+// including all of the contract code, contract state and token balance tries.
 type NodeIterator struct {
 	state *StateDB // State being iterated
 
 	stateIt trie.NodeIterator // Primary iterator for the global state trie
 	dataIt  trie.NodeIterator // Secondary iterator for the data trie of a contract
+	tokenIt trie.NodeIterator // Secondary iterator for the token balance trie of an account
 
 	accountHash common.Hash // Hash of the node containing the account
 	codeHash    common.Hash // Hash of the contract source code
@@ -86,6 +87,16 @@ func (it *NodeIterator) step() error {
 		}
 		return nil
 	}
+	// If we had token balance nodes previously, step through those next
+	if it.tokenIt != nil {
+		if cont := it.tokenIt.Next(true); !cont {
+			if it.tokenIt.Error() != nil {
+				return it.tokenIt.Error()
+			}
+			it.tokenIt = nil
+		}
+		return nil
+	}
 	// If we had source code previously, discard that
 	if it.code != nil {
 		it.code = nil
@@ -116,6 +127,14 @@ func (it *NodeIterator) step() error {
 	if !it.dataIt.Next(true) {
 		it.dataIt = nil
 	}
+	tokenTrie, err := it.state.db.OpenStorageTrie(common.BytesToHash(it.stateIt.LeafKey()), account.TokenBalanceRoot)
+	if err != nil {
+		return err
+	}
+	it.tokenIt = tokenTrie.NodeIterator(nil)
+	if !it.tokenIt.Next(true) {
+		it.tokenIt = nil
+	}
 	if !bytes.Equal(account.CodeHash, emptyCodeHash) {
 		it.codeHash = common.BytesToHash(account.CodeHash)
 		addrHash := common.BytesToHash(it.stateIt.LeafKey())
@@ -145,6 +164,11 @@ func (it *NodeIterator) retrieve() bool {
 		if it.Parent == (common.Hash{}) {
 			it.Parent = it.accountHash
 		}
+	case it.tokenIt != nil:
+		it.Hash, it.Parent = it.tokenIt.Hash(), it.tokenIt.Parent()
+		if it.Parent == (common.Hash{}) {
+			it.Parent = it.accountHash
+		}
 	case it.code != nil:
 		it.Hash, it.Parent = it.codeHash, it.accountHash
 	case it.stateIt != nil:
@@ -18,6 +18,7 @@ package state
 
 import (
 	"bytes"
+	"math/big"
 	"testing"
 
 	"github.com/bcos-one/BCOS/common"
@@ -60,3 +61,38 @@ func TestNodeIteratorCoverage(t *testing.T) {
 		}
 	}
 }
+
+// Tests that the node iterator also walks the token balance tries of accounts.
+func TestNodeIteratorTokenCoverage(t *testing.T) {
+	diskdb := ethdb.NewMemDatabase()
+	db := NewDatabase(diskdb)
+
+	state, _ := New(common.Hash{}, db)
+	for i := byte(0); i < 16; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		state.AddBalance(addr, big.NewInt(int64(i)+1))
+		state.AddTokenBalance(addr, common.BytesToAddress([]byte{0xff, i}), big.NewInt(int64(i)+1))
+	}
+	root, _ := state.Commit(false)
+	if err := db.TrieDB().Commit(root, false); err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	state, err := New(root, db)
+	if err != nil {
+		t.Fatalf("failed to create state trie at %x: %v", root, err)
+	}
+	hashes := make(map[common.Hash]struct{})
+	for it := NewNodeIterator(state); it.Next(); {
+		if it.Hash != (common.Hash{}) {
+			hashes[it.Hash] = struct{}{}
+		}
+	}
+	for _, key := range diskdb.Keys() {
+		if bytes.HasPrefix(key, []byte("secure-key-")) {
+			continue
+		}
+		if _, ok := hashes[common.BytesToHash(key)]; !ok {
+			t.Errorf("state entry not reported %x", key)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Package pruner implements offline pruning of stale state data.
+package pruner
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/core/state"
+	"github.com/bcos-one/BCOS/crypto"
+	"github.com/bcos-one/BCOS/ethdb"
+	"github.com/bcos-one/BCOS/log"
+)
+
+// ErrInterrupted is returned if the pruning was aborted before completion.
+var ErrInterrupted = errors.New("pruning interrupted")
+
+// logInterval is the time between two progress reports.
+const logInterval = 8 * time.Second
+
+// Pruner deletes all the state trie nodes and contract codes from a database that
+// are not reachable from a set of retained state roots. It must only be run while
+// no node is using the database.
+type Pruner struct {
+	db    ethdb.Database
+	roots []common.Hash
+}
+
+// NewPruner creates a pruner retaining the states of the given roots.
+func NewPruner(db ethdb.Database, roots []common.Hash) *Pruner {
+	return &Pruner{
+		db:    db,
+		roots: roots,
+	}
+}
+
+// Prune marks every trie node and contract code reachable from the retained roots,
+// including the storage and token balance tries of all accounts, then sweeps the
+// rest of the state data from the database.
+//
+// Nothing is deleted before the marking completed, and the sweep only deletes
+// unreachable entries, so interrupting the pruning at any point leaves the
+// retained states intact and the pruning can simply be restarted.
+func (p *Pruner) Prune(interrupt <-chan struct{}) error {
+	marked, err := p.mark(interrupt)
+	if err != nil {
+		return err
+	}
+	return p.sweep(marked, interrupt)
+}
+
+// mark collects the hashes of all the state entries reachable from the roots.
+func (p *Pruner) mark(interrupt <-chan struct{}) (map[common.Hash]struct{}, error) {
+	var (
+		marked = make(map[common.Hash]struct{})
+		sdb    = state.NewDatabase(p.db)
+		start  = time.Now()
+		logged = time.Now()
+	)
+	for _, root := range p.roots {
+		statedb, err := state.New(root, sdb)
+		if err != nil {
+			return nil, err
+		}
+		it := state.NewNodeIterator(statedb)
+		for it.Next() {
+			if it.Hash != (common.Hash{}) {
+				marked[it.Hash] = struct{}{}
+			}
+			if time.Since(logged) > logInterval {
+				select {
+				case <-interrupt:
+					return nil, ErrInterrupted
+				default:
+				}
+				log.Info("Marking state entries", "root", root, "marked", len(marked), "elapsed", common.PrettyDuration(time.Since(start)))
+				logged = time.Now()
+			}
+		}
+		if it.Error != nil {
+			return nil, it.Error
+		}
+	}
+	log.Info("Marked reachable state entries", "roots", len(p.roots), "marked", len(marked), "elapsed", common.PrettyDuration(time.Since(start)))
+	return marked, nil
+}
+
+// sweep deletes all the state entries not marked as reachable. State entries are
+// recognised by being keyed by the hash of their value.
+func (p *Pruner) sweep(marked map[common.Hash]struct{}, interrupt <-chan struct{}) error {
+	var (
+		batch   = p.db.NewBatch()
+		start   = time.Now()
+		logged  = time.Now()
+		checked int
+		deleted int
+		size    common.StorageSize
+	)
+	it := p.db.NewIteratorWithPrefix(nil)
+	defer it.Release()
+
+	for it.Next() {
+		checked++
+
+		key, value := it.Key(), it.Value()
+		if len(key) != common.HashLength {
+			continue
+		}
+		if _, ok := marked[common.BytesToHash(key)]; ok {
+			continue
+		}
+		if crypto.Keccak256Hash(value) != common.BytesToHash(key) {
+			continue
+		}
+		if err := batch.Delete(key); err != nil {
+			return err
+		}
+		deleted++
+		size += common.StorageSize(len(key) + len(value))
+
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		if time.Since(logged) > logInterval {
+			select {
+			case <-interrupt:
+				if err := batch.Write(); err != nil {
+					return err
+				}
+				log.Warn("Pruning interrupted, retained states are intact", "deleted", deleted, "size", size)
+				return ErrInterrupted
+			default:
+			}
+			log.Info("Pruning state data", "checked", checked, "deleted", deleted, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return err
+	}
+	if err := batch.Write(); err != nil {
+		return err
+	}
+	log.Info("Pruned state data", "deleted", deleted, "size", size, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	// Reclaim the disk space of the deleted entries
+	start = time.Now()
+	log.Info("Compacting database")
+	if err := p.db.Compact(nil, nil); err != nil {
+		return err
+	}
+	log.Info("Compacted database", "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
@@ -0,0 +1,82 @@
+package pruner
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/core/state"
+	"github.com/bcos-one/BCOS/ethdb"
+)
+
+// Tests that pruning retains the complete state of the given root, including
+// storage and token balance tries, and deletes the stale entries.
+func TestPrune(t *testing.T) {
+	var (
+		diskdb = ethdb.NewMemDatabase()
+		sdb    = state.NewDatabase(diskdb)
+		token  = common.HexToAddress("0xff")
+	)
+	commit := func(statedb *state.StateDB) common.Hash {
+		root, err := statedb.Commit(false)
+		if err != nil {
+			t.Fatalf("failed to commit state: %v", err)
+		}
+		if err := sdb.TrieDB().Commit(root, false); err != nil {
+			t.Fatalf("failed to flush state: %v", err)
+		}
+		return root
+	}
+	statedb, _ := state.New(common.Hash{}, sdb)
+	for i := byte(1); i <= 16; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		statedb.AddBalance(addr, big.NewInt(int64(i)))
+		statedb.AddTokenBalance(addr, token, big.NewInt(int64(i)))
+		statedb.SetState(addr, common.Hash{i}, common.Hash{i})
+	}
+	stale := commit(statedb)
+
+	statedb, _ = state.New(stale, sdb)
+	for i := byte(1); i <= 16; i += 2 {
+		addr := common.BytesToAddress([]byte{i})
+		statedb.SubTokenBalance(addr, token, big.NewInt(1))
+		statedb.SetState(addr, common.Hash{i}, common.Hash{i, i})
+	}
+	root := commit(statedb)
+	diskdb.Put([]byte("unrelated"), []byte("data"))
+
+	before := diskdb.Len()
+	if err := NewPruner(diskdb, []common.Hash{root}).Prune(nil); err != nil {
+		t.Fatalf("failed to prune state: %v", err)
+	}
+	if diskdb.Len() >= before {
+		t.Fatalf("no state entries pruned: have %d, before %d", diskdb.Len(), before)
+	}
+	if ok, _ := diskdb.Has([]byte("unrelated")); !ok {
+		t.Fatalf("non-state entry pruned")
+	}
+	if ok, _ := diskdb.Has(stale.Bytes()); ok {
+		t.Fatalf("stale state root survived pruning")
+	}
+	// Every entry of the retained state must still be present
+	statedb, err := state.New(root, state.NewDatabase(diskdb))
+	if err != nil {
+		t.Fatalf("retained state missing: %v", err)
+	}
+	it := state.NewNodeIterator(statedb)
+	for it.Next() {
+	}
+	if it.Error != nil {
+		t.Fatalf("retained state incomplete: %v", it.Error)
+	}
+	for i := byte(1); i <= 16; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		want := int64(i)
+		if i%2 == 1 {
+			want--
+		}
+		if balance := statedb.GetTokenBalance(addr, token); balance.Int64() != want {
+			t.Fatalf("account %d: token balance mismatch: have %v, want %d", i, balance, want)
+		}
+	}
+}
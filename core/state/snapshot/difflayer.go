@@ -0,0 +1,122 @@
+package snapshot
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/bcos-one/BCOS/common"
+)
+
+// diffLayer represents a collection of modifications made to a state snapshot
+// after running a block on top. It contains the modified accounts and, for every
+// account, the modified storage slots and token balances.
+//
+// The goal of a diff layer is to act as a journal, tracking recent modifications
+// made to the state, that have not yet graduated into a semi-immutable state.
+type diffLayer struct {
+	parent snapshot    // Parent snapshot modified by this one, never nil
+	root   common.Hash // Root hash to which this snapshot diff belongs to
+	stale  uint32      // Signals that the layer became stale (state progressed)
+
+	destructSet map[common.Hash]struct{}               // Keyed markers for deleted (and potentially recreated) accounts
+	accountData map[common.Hash][]byte                 // Keyed accounts for direct retrieval (empty means deleted)
+	storageData map[common.Hash]map[common.Hash][]byte // Keyed storage slots for direct retrieval, one per account (empty means deleted)
+	tokenData   map[common.Hash]map[common.Hash][]byte // Keyed token balances for direct retrieval, one per account (empty means deleted)
+
+	lock sync.RWMutex
+}
+
+// newDiffLayer creates a new diff on top of an existing snapshot, whether that's
+// a low level persistent database or a hierarchical diff already.
+func newDiffLayer(parent snapshot, root common.Hash, destructs map[common.Hash]struct{}, accounts map[common.Hash][]byte, storage, tokens map[common.Hash]map[common.Hash][]byte) *diffLayer {
+	if destructs == nil {
+		destructs = make(map[common.Hash]struct{})
+	}
+	if accounts == nil {
+		accounts = make(map[common.Hash][]byte)
+	}
+	if storage == nil {
+		storage = make(map[common.Hash]map[common.Hash][]byte)
+	}
+	if tokens == nil {
+		tokens = make(map[common.Hash]map[common.Hash][]byte)
+	}
+	return &diffLayer{
+		parent:      parent,
+		root:        root,
+		destructSet: destructs,
+		accountData: accounts,
+		storageData: storage,
+		tokenData:   tokens,
+	}
+}
+
+// Root returns the root hash for which this snapshot was made.
+func (dl *diffLayer) Root() common.Hash {
+	return dl.root
+}
+
+// Parent returns the subsequent layer of a diff layer.
+func (dl *diffLayer) Parent() snapshot {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	return dl.parent
+}
+
+// Stale return whether this layer has become stale (was flattened across) or if
+// it's still live.
+func (dl *diffLayer) Stale() bool {
+	return atomic.LoadUint32(&dl.stale) != 0
+}
+
+// AccountRLP directly retrieves the account trie value of an account, falling
+// back to the parent layers if this one doesn't modify it.
+func (dl *diffLayer) AccountRLP(hash common.Hash) ([]byte, error) {
+	if dl.Stale() {
+		return nil, ErrSnapshotStale
+	}
+	if data, ok := dl.accountData[hash]; ok {
+		return data, nil
+	}
+	if _, destructed := dl.destructSet[hash]; destructed {
+		return nil, nil
+	}
+	return dl.Parent().AccountRLP(hash)
+}
+
+// Storage directly retrieves the storage trie value of a storage slot, falling
+// back to the parent layers if this one doesn't modify it.
+func (dl *diffLayer) Storage(accountHash, storageHash common.Hash) ([]byte, error) {
+	return dl.lookup(accountHash, storageHash, dl.storageData, Snapshot.Storage)
+}
+
+// TokenBalance directly retrieves the token balance trie value of a token,
+// falling back to the parent layers if this one doesn't modify it.
+func (dl *diffLayer) TokenBalance(accountHash, tokenHash common.Hash) ([]byte, error) {
+	return dl.lookup(accountHash, tokenHash, dl.tokenData, Snapshot.TokenBalance)
+}
+
+// lookup retrieves an item of an account level trie from the given diff set,
+// deferring to the same accessor of the parent layer if the item is unmodified.
+// Items of destructed accounts not set anew are deleted.
+func (dl *diffLayer) lookup(accountHash, hash common.Hash, set map[common.Hash]map[common.Hash][]byte, parent func(Snapshot, common.Hash, common.Hash) ([]byte, error)) ([]byte, error) {
+	if dl.Stale() {
+		return nil, ErrSnapshotStale
+	}
+	if items, ok := set[accountHash]; ok {
+		if data, ok := items[hash]; ok {
+			return data, nil
+		}
+	}
+	if _, destructed := dl.destructSet[accountHash]; destructed {
+		return nil, nil
+	}
+	return parent(dl.Parent(), accountHash, hash)
+}
+
+// Update creates a new layer on top of the existing snapshot diff tree with
+// the specified data items.
+func (dl *diffLayer) Update(blockRoot common.Hash, destructs map[common.Hash]struct{}, accounts map[common.Hash][]byte, storage, tokens map[common.Hash]map[common.Hash][]byte) *diffLayer {
+	return newDiffLayer(dl, blockRoot, destructs, accounts, storage, tokens)
+}
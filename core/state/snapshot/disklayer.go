@@ -0,0 +1,180 @@
+package snapshot
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/core/rawdb"
+	"github.com/bcos-one/BCOS/ethdb"
+	"github.com/bcos-one/BCOS/log"
+	"github.com/bcos-one/BCOS/trie"
+)
+
+// diskLayer is a low level persistent snapshot built on top of a key-value store.
+type diskLayer struct {
+	diskdb ethdb.Database // Key-value store containing the base snapshot
+	triedb *trie.Database // Trie node cache for reconstruction purposes
+	root   common.Hash    // Root hash of the base snapshot
+	stale  bool           // Signals that the layer became stale (state progressed)
+
+	genMarker []byte                    // Last account covered by the generator (nil = done)
+	genAbort  chan chan *generatorStats // Notification channel to abort generating the snapshot in this layer
+
+	lock sync.RWMutex
+}
+
+// Root returns root hash for which this snapshot was made.
+func (dl *diskLayer) Root() common.Hash {
+	return dl.root
+}
+
+// Parent always returns nil as there's no layer below the disk.
+func (dl *diskLayer) Parent() snapshot {
+	return nil
+}
+
+// Stale return whether this layer has become stale (was flattened across) or if
+// it's still live.
+func (dl *diskLayer) Stale() bool {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	return dl.stale
+}
+
+// covered returns whether the data of the given account was already generated.
+// The caller must hold the read lock.
+func (dl *diskLayer) covered(accountHash common.Hash) error {
+	if dl.stale {
+		return ErrSnapshotStale
+	}
+	if dl.genMarker != nil && bytes.Compare(accountHash[:], dl.genMarker) > 0 {
+		return ErrNotCoveredYet
+	}
+	return nil
+}
+
+// AccountRLP directly retrieves the account trie value of an account.
+func (dl *diskLayer) AccountRLP(hash common.Hash) ([]byte, error) {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	if err := dl.covered(hash); err != nil {
+		return nil, err
+	}
+	return rawdb.ReadAccountSnapshot(dl.diskdb, hash), nil
+}
+
+// Storage directly retrieves the storage trie value of a storage slot.
+func (dl *diskLayer) Storage(accountHash, storageHash common.Hash) ([]byte, error) {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	if err := dl.covered(accountHash); err != nil {
+		return nil, err
+	}
+	return rawdb.ReadStorageSnapshot(dl.diskdb, accountHash, storageHash), nil
+}
+
+// TokenBalance directly retrieves the token balance trie value of a token.
+func (dl *diskLayer) TokenBalance(accountHash, tokenHash common.Hash) ([]byte, error) {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	if err := dl.covered(accountHash); err != nil {
+		return nil, err
+	}
+	return rawdb.ReadTokenSnapshot(dl.diskdb, accountHash, tokenHash), nil
+}
+
+// Update creates a new layer on top of the existing snapshot diff tree with
+// the specified data items.
+func (dl *diskLayer) Update(blockRoot common.Hash, destructs map[common.Hash]struct{}, accounts map[common.Hash][]byte, storage, tokens map[common.Hash]map[common.Hash][]byte) *diffLayer {
+	return newDiffLayer(dl, blockRoot, destructs, accounts, storage, tokens)
+}
+
+// diffToDisk merges a bottom-most diff into the persistent disk layer underneath
+// it, returning a new disk layer. The old disk layer and the diff become stale.
+// Items not yet covered by a running generation are skipped, the generator will
+// pick them up from the new state.
+//
+// The generator of the base layer must not be running.
+func diffToDisk(base *diskLayer, bottom *diffLayer) *diskLayer {
+	// Hold the write lock until the data is flushed, so readers of the old layer
+	// never observe the new state
+	base.lock.Lock()
+	defer base.lock.Unlock()
+
+	base.stale = true
+	atomic.StoreUint32(&bottom.stale, 1)
+
+	batch := base.diskdb.NewBatch()
+	covered := func(hash common.Hash) bool {
+		return base.genMarker == nil || bytes.Compare(hash[:], base.genMarker) <= 0
+	}
+	for hash := range bottom.destructSet {
+		if !covered(hash) {
+			continue
+		}
+		rawdb.DeleteAccountSnapshot(batch, hash)
+		wipeAccount(base.diskdb, batch, hash)
+	}
+	for hash, data := range bottom.accountData {
+		if !covered(hash) {
+			continue
+		}
+		if len(data) > 0 {
+			rawdb.WriteAccountSnapshot(batch, hash, data)
+		} else {
+			rawdb.DeleteAccountSnapshot(batch, hash)
+		}
+	}
+	for accountHash, slots := range bottom.storageData {
+		if !covered(accountHash) {
+			continue
+		}
+		for storageHash, data := range slots {
+			if len(data) > 0 {
+				rawdb.WriteStorageSnapshot(batch, accountHash, storageHash, data)
+			} else {
+				rawdb.DeleteStorageSnapshot(batch, accountHash, storageHash)
+			}
+		}
+	}
+	for accountHash, balances := range bottom.tokenData {
+		if !covered(accountHash) {
+			continue
+		}
+		for tokenHash, data := range balances {
+			if len(data) > 0 {
+				rawdb.WriteTokenSnapshot(batch, accountHash, tokenHash, data)
+			} else {
+				rawdb.DeleteTokenSnapshot(batch, accountHash, tokenHash)
+			}
+		}
+	}
+	rawdb.WriteSnapshotRoot(batch, bottom.root)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write flattened snapshot", "err", err)
+	}
+	return &diskLayer{
+		diskdb:    base.diskdb,
+		triedb:    base.triedb,
+		root:      bottom.root,
+		genMarker: base.genMarker,
+	}
+}
+
+// wipeAccount deletes all the storage slots and token balances of an account
+// from the snapshot.
+func wipeAccount(db ethdb.Database, batch ethdb.Batch, accountHash common.Hash) {
+	for _, prefix := range [][]byte{rawdb.SnapshotStoragePrefix, rawdb.SnapshotTokenPrefix} {
+		it := db.NewIteratorWithPrefix(append(append([]byte{}, prefix...), accountHash.Bytes()...))
+		for it.Next() {
+			batch.Delete(common.CopyBytes(it.Key()))
+		}
+		it.Release()
+	}
+}
@@ -0,0 +1,298 @@
+package snapshot
+
+import (
+	"bytes"
+	"math/big"
+	"time"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/core/rawdb"
+	"github.com/bcos-one/BCOS/ethdb"
+	"github.com/bcos-one/BCOS/log"
+	"github.com/bcos-one/BCOS/rlp"
+	"github.com/bcos-one/BCOS/trie"
+)
+
+// emptyRoot is the known root hash of an empty trie.
+var emptyRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+
+// generatorLogInterval is the time between two generation progress reports.
+const generatorLogInterval = 8 * time.Second
+
+// account mirrors the consensus encoding of state.Account, which can't be
+// imported here without an import cycle.
+type account struct {
+	Nonce            uint64
+	Balance          *big.Int
+	Root             common.Hash
+	TokenBalanceRoot common.Hash
+	CodeHash         []byte
+	TokenSupport     common.Address
+}
+
+// generatorStats is a collection of statistics gathered by the snapshot generator
+// for logging purposes.
+type generatorStats struct {
+	start    time.Time // Timestamp when generation started
+	accounts uint64    // Number of accounts indexed
+	slots    uint64    // Number of storage slots indexed
+	tokens   uint64    // Number of token balances indexed
+}
+
+// log creates a contextual log with the given message and the context pulled
+// from the internally maintained statistics.
+func (gs *generatorStats) log(msg string, root common.Hash, marker []byte) {
+	ctx := []interface{}{
+		"root", root, "accounts", gs.accounts, "slots", gs.slots, "tokens", gs.tokens,
+	}
+	if len(marker) > 0 {
+		ctx = append(ctx, "at", common.BytesToHash(marker))
+	}
+	ctx = append(ctx, "elapsed", common.PrettyDuration(time.Since(gs.start)))
+	log.Info(msg, ctx...)
+}
+
+// journalGenerator is the persisted progress of a snapshot generation.
+type journalGenerator struct {
+	Done     bool
+	Marker   []byte
+	Accounts uint64
+	Slots    uint64
+	Tokens   uint64
+}
+
+// journalProgress persists the generator progress into the database.
+func journalProgress(db ethdb.Putter, marker []byte, stats *generatorStats) {
+	entry := journalGenerator{
+		Done:   marker == nil,
+		Marker: marker,
+	}
+	if stats != nil {
+		entry.Accounts, entry.Slots, entry.Tokens = stats.accounts, stats.slots, stats.tokens
+	}
+	blob, err := rlp.EncodeToBytes(entry)
+	if err != nil {
+		panic(err) // Cannot happen, here to catch dev errors
+	}
+	rawdb.WriteSnapshotGenerator(db, blob)
+}
+
+// generateSnapshot regenerates a brand new snapshot based on an existing state
+// database and head block asynchronously. The snapshot is returned immediately
+// and generation is continued in the background until done.
+func generateSnapshot(diskdb ethdb.Database, triedb *trie.Database, root common.Hash) *diskLayer {
+	stats := &generatorStats{start: time.Now()}
+
+	batch := diskdb.NewBatch()
+	rawdb.WriteSnapshotRoot(batch, root)
+	journalProgress(batch, []byte{}, stats)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write initialized state marker", "err", err)
+	}
+	base := &diskLayer{
+		diskdb:    diskdb,
+		triedb:    triedb,
+		root:      root,
+		genMarker: []byte{}, // Initialized but empty
+		genAbort:  make(chan chan *generatorStats),
+	}
+	go base.generate(stats)
+	return base
+}
+
+// generate is a background thread that iterates over the state, storage and
+// token balance tries of the layer and writes the flat snapshot entries. The
+// accounts are processed in order, any data beyond the marker left over by an
+// interrupted run is wiped first.
+func (dl *diskLayer) generate(stats *generatorStats) {
+	if stats == nil {
+		stats = &generatorStats{start: time.Now()}
+	}
+	if err := wipeSnapshot(dl.diskdb, dl.genMarker); err != nil {
+		log.Error("Failed to wipe stale snapshot data", "err", err)
+		dl.waitAbort(stats)
+		return
+	}
+	accTrie, err := trie.New(dl.root, dl.triedb)
+	if err != nil {
+		// The state is not available (e.g. garbage collected meanwhile), wait for
+		// the next flattening to restart the generation from a newer root
+		log.Warn("Snapshot generation stalled, state missing", "root", dl.root, "err", err)
+		dl.waitAbort(stats)
+		return
+	}
+	start, ok := nextHash(dl.genMarker)
+	if !ok {
+		dl.finish(stats)
+		return
+	}
+	var (
+		batch  = dl.diskdb.NewBatch()
+		marker = dl.genMarker
+		logged = time.Now()
+	)
+	// checkpoint flushes the generated data and advances the marker
+	checkpoint := func() {
+		journalProgress(batch, marker, stats)
+		if err := batch.Write(); err != nil {
+			log.Crit("Failed to write snapshot data", "err", err)
+		}
+		batch.Reset()
+
+		dl.lock.Lock()
+		dl.genMarker = marker
+		dl.lock.Unlock()
+	}
+	accIt := trie.NewIterator(accTrie.NodeIterator(start))
+	for accIt.Next() {
+		accountHash := common.BytesToHash(accIt.Key)
+
+		var acc account
+		if err := rlp.DecodeBytes(accIt.Value, &acc); err != nil {
+			log.Crit("Invalid account encountered during snapshot creation", "err", err)
+		}
+		// Index the account level tries first. Flushing a partially indexed account
+		// is harmless, the marker only moves past completely indexed ones.
+		if err := dl.generateTrie(batch, acc.Root, func(hash common.Hash, value []byte) {
+			rawdb.WriteStorageSnapshot(batch, accountHash, hash, value)
+			stats.slots++
+		}); err != nil {
+			log.Warn("Snapshot generation stalled, storage missing", "root", dl.root, "account", accountHash, "err", err)
+			checkpoint()
+			dl.waitAbort(stats)
+			return
+		}
+		if err := dl.generateTrie(batch, acc.TokenBalanceRoot, func(hash common.Hash, value []byte) {
+			rawdb.WriteTokenSnapshot(batch, accountHash, hash, value)
+			stats.tokens++
+		}); err != nil {
+			log.Warn("Snapshot generation stalled, token balances missing", "root", dl.root, "account", accountHash, "err", err)
+			checkpoint()
+			dl.waitAbort(stats)
+			return
+		}
+		rawdb.WriteAccountSnapshot(batch, accountHash, common.CopyBytes(accIt.Value))
+		stats.accounts++
+		marker = accountHash.Bytes()
+
+		// Persist the progress regularly and on abort requests
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			checkpoint()
+		}
+		select {
+		case abort := <-dl.genAbort:
+			checkpoint()
+			abort <- stats
+			return
+		default:
+		}
+		if time.Since(logged) > generatorLogInterval {
+			stats.log("Generating state snapshot", dl.root, marker)
+			logged = time.Now()
+		}
+	}
+	if accIt.Err != nil {
+		log.Warn("Snapshot generation stalled, state missing", "root", dl.root, "err", accIt.Err)
+		checkpoint()
+		dl.waitAbort(stats)
+		return
+	}
+	checkpoint()
+	dl.finish(stats)
+}
+
+// generateTrie iterates over all the leaves of an account level trie, flushing
+// the batch whenever it grows too large.
+func (dl *diskLayer) generateTrie(batch ethdb.Batch, root common.Hash, onleaf func(hash common.Hash, value []byte)) error {
+	if root == emptyRoot || root == (common.Hash{}) {
+		return nil
+	}
+	tr, err := trie.New(root, dl.triedb)
+	if err != nil {
+		return err
+	}
+	it := trie.NewIterator(tr.NodeIterator(nil))
+	for it.Next() {
+		onleaf(common.BytesToHash(it.Key), common.CopyBytes(it.Value))
+
+		if batch.ValueSize() > ethdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to write snapshot data", "err", err)
+			}
+			batch.Reset()
+		}
+	}
+	return it.Err
+}
+
+// finish marks the generation done and services abort requests until the layer
+// is discarded.
+func (dl *diskLayer) finish(stats *generatorStats) {
+	journalProgress(dl.diskdb, nil, stats)
+
+	dl.lock.Lock()
+	dl.genMarker = nil
+	dl.lock.Unlock()
+
+	stats.log("Generated state snapshot", dl.root, nil)
+	abort := <-dl.genAbort
+	abort <- nil
+}
+
+// waitAbort blocks a failed generation until an abort request arrives, which
+// restarts it from the current marker on a newer layer if possible.
+func (dl *diskLayer) waitAbort(stats *generatorStats) {
+	abort := <-dl.genAbort
+	abort <- stats
+}
+
+// wipeSnapshot deletes all the snapshot data of the accounts beyond the marker.
+// The keys are checked for their exact length, as the trie nodes stored in the
+// same keyspace might share the single byte prefixes.
+func wipeSnapshot(db ethdb.Database, marker []byte) error {
+	batch := db.NewBatch()
+	for prefix, keylen := range map[string]int{
+		string(rawdb.SnapshotAccountPrefix): 1 + common.HashLength,
+		string(rawdb.SnapshotStoragePrefix): 1 + 2*common.HashLength,
+		string(rawdb.SnapshotTokenPrefix):   1 + 2*common.HashLength,
+	} {
+		it := db.NewIteratorWithPrefix([]byte(prefix))
+		for it.Next() {
+			key := it.Key()
+			if len(key) != keylen || bytes.Compare(key[1:1+common.HashLength], marker) <= 0 {
+				continue
+			}
+			batch.Delete(common.CopyBytes(key))
+			if batch.ValueSize() > ethdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					it.Release()
+					return err
+				}
+				batch.Reset()
+			}
+		}
+		err := it.Error()
+		it.Release()
+		if err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// nextHash returns the smallest account hash after the given marker, or false
+// if the marker was the last possible hash. An empty marker starts at the first
+// account.
+func nextHash(marker []byte) ([]byte, bool) {
+	if len(marker) == 0 {
+		return []byte{}, true
+	}
+	next := common.CopyBytes(marker)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			return next, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,159 @@
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/core/rawdb"
+	"github.com/bcos-one/BCOS/ethdb"
+	"github.com/bcos-one/BCOS/log"
+	"github.com/bcos-one/BCOS/rlp"
+	"github.com/bcos-one/BCOS/trie"
+)
+
+// journalAccount is an account entry in a diffLayer's disk journal.
+type journalAccount struct {
+	Hash common.Hash
+	Blob []byte
+}
+
+// journalItems is a list of storage slots or token balances of an account in a
+// diffLayer's disk journal.
+type journalItems struct {
+	Hash common.Hash
+	Keys []common.Hash
+	Vals [][]byte
+}
+
+// journalLayer is a diffLayer in the disk journal.
+type journalLayer struct {
+	Root      common.Hash
+	Destructs []common.Hash
+	Accounts  []journalAccount
+	Storage   []journalItems
+	Tokens    []journalItems
+}
+
+// journal is the persisted form of the in-memory diff layers, belonging to the
+// disk layer of the given root.
+type journal struct {
+	Disk   common.Hash
+	Layers []journalLayer
+}
+
+// encodeJournal serializes the given diff layers, ordered from the disk layer
+// upwards.
+func encodeJournal(disk common.Hash, diffs []*diffLayer) ([]byte, error) {
+	enc := journal{Disk: disk}
+	for _, diff := range diffs {
+		layer := journalLayer{Root: diff.root}
+		for hash := range diff.destructSet {
+			layer.Destructs = append(layer.Destructs, hash)
+		}
+		for hash, blob := range diff.accountData {
+			layer.Accounts = append(layer.Accounts, journalAccount{Hash: hash, Blob: blob})
+		}
+		layer.Storage = encodeItems(diff.storageData)
+		layer.Tokens = encodeItems(diff.tokenData)
+
+		enc.Layers = append(enc.Layers, layer)
+	}
+	return rlp.EncodeToBytes(enc)
+}
+
+// encodeItems flattens the account level items of a diff layer.
+func encodeItems(set map[common.Hash]map[common.Hash][]byte) []journalItems {
+	var list []journalItems
+	for accountHash, items := range set {
+		entry := journalItems{Hash: accountHash}
+		for hash, blob := range items {
+			entry.Keys = append(entry.Keys, hash)
+			entry.Vals = append(entry.Vals, blob)
+		}
+		list = append(list, entry)
+	}
+	return list
+}
+
+// decodeItems restores the account level items of a diff layer.
+func decodeItems(list []journalItems) map[common.Hash]map[common.Hash][]byte {
+	set := make(map[common.Hash]map[common.Hash][]byte)
+	for _, entry := range list {
+		items := make(map[common.Hash][]byte)
+		for i, hash := range entry.Keys {
+			items[hash] = entry.Vals[i]
+		}
+		set[entry.Hash] = items
+	}
+	return set
+}
+
+// loadSnapshot loads a pre-existing state snapshot backed by a key-value store,
+// restoring the diff layers of the journal and resuming an interrupted generation.
+// The journal is deleted once read, so an unclean shutdown later on discards the
+// diff layers.
+func loadSnapshot(diskdb ethdb.Database, triedb *trie.Database, root common.Hash) (snapshot, error) {
+	blob := rawdb.ReadSnapshotJournal(diskdb)
+	if len(blob) > 0 {
+		rawdb.DeleteSnapshotJournal(diskdb)
+	}
+	// Retrieve the disk layer and the generation progress
+	baseRoot := rawdb.ReadSnapshotRoot(diskdb)
+	if baseRoot == (common.Hash{}) {
+		return nil, errors.New("missing or corrupted snapshot")
+	}
+	var generator journalGenerator
+	if err := rlp.DecodeBytes(rawdb.ReadSnapshotGenerator(diskdb), &generator); err != nil {
+		return nil, fmt.Errorf("failed to load snapshot progress: %v", err)
+	}
+	base := &diskLayer{
+		diskdb: diskdb,
+		triedb: triedb,
+		root:   baseRoot,
+	}
+	if !generator.Done {
+		base.genMarker = generator.Marker
+		if base.genMarker == nil {
+			base.genMarker = []byte{}
+		}
+	}
+	// Restore the diff layers on top, if they belong to the disk layer
+	var snap snapshot = base
+	if len(blob) > 0 {
+		var dec journal
+		if err := rlp.DecodeBytes(blob, &dec); err != nil {
+			return nil, fmt.Errorf("failed to decode snapshot journal: %v", err)
+		}
+		if dec.Disk == baseRoot {
+			for _, layer := range dec.Layers {
+				destructs := make(map[common.Hash]struct{})
+				for _, hash := range layer.Destructs {
+					destructs[hash] = struct{}{}
+				}
+				accounts := make(map[common.Hash][]byte)
+				for _, entry := range layer.Accounts {
+					accounts[entry.Hash] = entry.Blob
+				}
+				snap = newDiffLayer(snap, layer.Root, destructs, accounts, decodeItems(layer.Storage), decodeItems(layer.Tokens))
+			}
+		} else {
+			log.Warn("Discarding snapshot journal of other disk layer", "have", dec.Disk, "want", baseRoot)
+		}
+	}
+	if snap.Root() != root {
+		return nil, fmt.Errorf("head doesn't match snapshot: have %#x, want %#x", snap.Root(), root)
+	}
+	// Everything loaded correctly, resume any suspended operations
+	if base.genMarker != nil {
+		base.genAbort = make(chan chan *generatorStats)
+		go base.generate(&generatorStats{
+			start:    time.Now(),
+			accounts: generator.Accounts,
+			slots:    generator.Slots,
+			tokens:   generator.Tokens,
+		})
+	}
+	return snap, nil
+}
@@ -0,0 +1,300 @@
+// Package snapshot implements a flat key-value snapshot of the state, keeping
+// accounts, storage slots and token balances readable without walking the tries.
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/core/rawdb"
+	"github.com/bcos-one/BCOS/ethdb"
+	"github.com/bcos-one/BCOS/log"
+	"github.com/bcos-one/BCOS/trie"
+)
+
+var (
+	// ErrSnapshotStale is returned from data accessors if the underlying snapshot
+	// layer had been invalidated due to the chain progressing forward far enough
+	// to not maintain the layer's original state.
+	ErrSnapshotStale = errors.New("snapshot stale")
+
+	// ErrNotCoveredYet is returned from data accessors if the underlying snapshot
+	// is being generated currently and the requested data item is not yet in the
+	// range of accounts covered.
+	ErrNotCoveredYet = errors.New("not covered yet")
+
+	// errSnapshotCycle is returned if a snapshot is attempted to be inserted
+	// that forms a cycle in the snapshot tree.
+	errSnapshotCycle = errors.New("snapshot cycle")
+)
+
+// Snapshot represents the functionality supported by a snapshot storage layer.
+// All the data is keyed by the hashes used in the state tries and returned in
+// the encoding of the trie leaves. An empty result means the item doesn't exist.
+type Snapshot interface {
+	// Root returns the root hash for which this snapshot was made.
+	Root() common.Hash
+
+	// AccountRLP directly retrieves the account trie value of an account.
+	AccountRLP(hash common.Hash) ([]byte, error)
+
+	// Storage directly retrieves the storage trie value of a storage slot.
+	Storage(accountHash, storageHash common.Hash) ([]byte, error)
+
+	// TokenBalance directly retrieves the token balance trie value of a token.
+	TokenBalance(accountHash, tokenHash common.Hash) ([]byte, error)
+}
+
+// snapshot is the internal version of the snapshot data layer that supports
+// additional methods compared to the public API.
+type snapshot interface {
+	Snapshot
+
+	// Parent returns the subsequent layer of a snapshot, or nil if the base was
+	// reached.
+	Parent() snapshot
+
+	// Update creates a new layer on top of the existing snapshot diff tree with
+	// the specified data items. Deleted items are marked by empty values.
+	Update(blockRoot common.Hash, destructs map[common.Hash]struct{}, accounts map[common.Hash][]byte, storage, tokens map[common.Hash]map[common.Hash][]byte) *diffLayer
+
+	// Stale return whether this layer has become stale (was flattened across) or
+	// if it's still live.
+	Stale() bool
+}
+
+// Tree is an Ethereum state snapshot tree. It consists of one persistent base
+// layer backed by a key-value store, on top of which arbitrarily many in-memory
+// diff layers are topped. The memory diffs can form a tree with branching, but
+// the disk layer is singleton and common to all. If a reorg goes deeper than the
+// disk layer, everything needs to be deleted.
+//
+// The goal of the state snapshot is to allow direct access to account, storage
+// and token balance data, avoiding expensive multi-level trie lookups.
+type Tree struct {
+	diskdb ethdb.Database           // Persistent database to store the snapshot
+	triedb *trie.Database           // In-memory cache to access the trie through
+	layers map[common.Hash]snapshot // Collection of all known layers
+	lock   sync.RWMutex
+}
+
+// New attempts to load an already existing snapshot from a persistent key-value
+// store (with a number of memory layers from a journal), ensuring that the head
+// of the snapshot matches the expected one.
+//
+// If the snapshot is missing or inconsistent, the entirety is deleted and will
+// be reconstructed from scratch based on the tries in the key-value store, on a
+// background thread.
+func New(diskdb ethdb.Database, triedb *trie.Database, root common.Hash) *Tree {
+	snap := &Tree{
+		diskdb: diskdb,
+		triedb: triedb,
+		layers: make(map[common.Hash]snapshot),
+	}
+	head, err := loadSnapshot(diskdb, triedb, root)
+	if err != nil {
+		log.Warn("Failed to load snapshot, regenerating", "err", err)
+		snap.Rebuild(root)
+		return snap
+	}
+	for head != nil {
+		snap.layers[head.Root()] = head
+		head = head.Parent()
+	}
+	return snap
+}
+
+// Snapshot retrieves a snapshot belonging to the given block root, or nil if no
+// snapshot is maintained for that block.
+func (t *Tree) Snapshot(blockRoot common.Hash) Snapshot {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if snap, ok := t.layers[blockRoot]; ok {
+		return snap
+	}
+	return nil
+}
+
+// Update adds a new snapshot into the tree, if that can be linked to an existing
+// old parent. It is disallowed to insert a disk layer (the origin of all).
+func (t *Tree) Update(blockRoot common.Hash, parentRoot common.Hash, destructs map[common.Hash]struct{}, accounts map[common.Hash][]byte, storage, tokens map[common.Hash]map[common.Hash][]byte) error {
+	// Reject noop updates to avoid self-loops in the snapshot tree. This is a
+	// special case that can only happen for empty blocks without rewards.
+	if blockRoot == parentRoot {
+		return errSnapshotCycle
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if _, ok := t.layers[blockRoot]; ok {
+		return nil // Already known, e.g. a reimported block
+	}
+	parent, ok := t.layers[parentRoot]
+	if !ok {
+		return fmt.Errorf("parent [%#x] snapshot missing", parentRoot)
+	}
+	t.layers[blockRoot] = parent.Update(blockRoot, destructs, accounts, storage, tokens)
+	return nil
+}
+
+// Cap traverses downwards the snapshot tree from a head block hash until the
+// number of allowed diff layers are crossed. All layers beyond the permitted
+// number are flattened downwards into the disk layer, and any layer not built
+// on top of the new disk layer is dropped.
+func (t *Tree) Cap(root common.Hash, layers int) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	snap, ok := t.layers[root]
+	if !ok {
+		return fmt.Errorf("snapshot [%#x] missing", root)
+	}
+	diff, ok := snap.(*diffLayer)
+	if !ok {
+		return nil // Nothing to flatten below the disk layer
+	}
+	// Find the lowest diff layer to retain, bailing out if there's nothing to flatten
+	for i := 0; i < layers-1; i++ {
+		parent, ok := diff.Parent().(*diffLayer)
+		if !ok {
+			return nil
+		}
+		diff = parent
+	}
+	bottom, ok := diff.Parent().(*diffLayer)
+	if !ok {
+		return nil
+	}
+	// Collect the layers to flatten, from the disk layer upwards
+	var flatten []*diffLayer
+	for layer := snapshot(bottom); ; layer = layer.Parent() {
+		child, ok := layer.(*diffLayer)
+		if !ok {
+			break
+		}
+		flatten = append([]*diffLayer{child}, flatten...)
+	}
+	base := flatten[0].Parent().(*diskLayer)
+
+	// Hold the snapshot generation while the disk layer is being modified
+	var stats *generatorStats
+	if base.genAbort != nil {
+		abort := make(chan *generatorStats)
+		base.genAbort <- abort
+		stats = <-abort
+	}
+	for _, layer := range flatten {
+		base = diffToDisk(base, layer)
+	}
+	if base.genMarker != nil {
+		base.genAbort = make(chan chan *generatorStats)
+		go base.generate(stats)
+	}
+	diff.lock.Lock()
+	diff.parent = base
+	diff.lock.Unlock()
+
+	// Drop all the layers not descending from the new disk layer
+	for hash, layer := range t.layers {
+		if hash == base.root {
+			continue
+		}
+		ancestor := layer
+		for {
+			parent := ancestor.Parent()
+			if parent == nil {
+				break
+			}
+			ancestor = parent
+		}
+		if ancestor != snapshot(base) {
+			if diff, ok := layer.(*diffLayer); ok {
+				atomic.StoreUint32(&diff.stale, 1)
+			}
+			delete(t.layers, hash)
+		}
+	}
+	t.layers[base.root] = base
+	return nil
+}
+
+// Rebuild wipes all available snapshot data from the persistent database and
+// discards all caches and diff layers. Afterwards, it starts a new snapshot
+// generator with the given root hash.
+func (t *Tree) Rebuild(root common.Hash) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// Stop any running generator and invalidate all the layers
+	for _, layer := range t.layers {
+		switch layer := layer.(type) {
+		case *diskLayer:
+			if layer.genAbort != nil {
+				abort := make(chan *generatorStats)
+				layer.genAbort <- abort
+				<-abort
+				layer.genAbort = nil
+			}
+			layer.lock.Lock()
+			layer.stale = true
+			layer.lock.Unlock()
+
+		case *diffLayer:
+			atomic.StoreUint32(&layer.stale, 1)
+		}
+	}
+	// Start generating a new snapshot from scratch on a background thread
+	log.Info("Rebuilding state snapshot", "root", root)
+	t.layers = map[common.Hash]snapshot{
+		root: generateSnapshot(t.diskdb, t.triedb, root),
+	}
+}
+
+// Journal commits an entire diff hierarchy to disk into a single journal entry
+// and stops the background generation. This is meant to be used during shutdown
+// to persist the snapshot without flattening everything down (bad for reorgs).
+func (t *Tree) Journal(root common.Hash) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	snap, ok := t.layers[root]
+	if !ok {
+		return fmt.Errorf("snapshot [%#x] missing", root)
+	}
+	// Collect the diff layers from the disk layer upwards
+	var diffs []*diffLayer
+	for layer := snap; ; layer = layer.Parent() {
+		diff, ok := layer.(*diffLayer)
+		if !ok {
+			break
+		}
+		diffs = append([]*diffLayer{diff}, diffs...)
+	}
+	var base *diskLayer
+	if len(diffs) > 0 {
+		base = diffs[0].Parent().(*diskLayer)
+	} else {
+		base = snap.(*diskLayer)
+	}
+	// Stop the generator, it persists its progress when aborted
+	if base.genAbort != nil {
+		abort := make(chan *generatorStats)
+		base.genAbort <- abort
+		<-abort
+		base.genAbort = nil
+	}
+	if base.Stale() {
+		return ErrSnapshotStale
+	}
+	blob, err := encodeJournal(base.root, diffs)
+	if err != nil {
+		return err
+	}
+	rawdb.WriteSnapshotJournal(t.diskdb, blob)
+	log.Info("Journalled state snapshot", "disk", base.root, "layers", len(diffs))
+	return nil
+}
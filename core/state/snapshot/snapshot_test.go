@@ -0,0 +1,224 @@
+package snapshot
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/core/rawdb"
+	"github.com/bcos-one/BCOS/crypto"
+	"github.com/bcos-one/BCOS/ethdb"
+	"github.com/bcos-one/BCOS/rlp"
+	"github.com/bcos-one/BCOS/trie"
+)
+
+// testState is a small state with accounts, storage slots and token balances
+// committed into a trie database.
+type testState struct {
+	diskdb ethdb.Database
+	triedb *trie.Database
+	root   common.Hash
+
+	accounts map[common.Hash][]byte
+	storage  map[common.Hash]map[common.Hash][]byte
+	tokens   map[common.Hash]map[common.Hash][]byte
+}
+
+// newTestState creates the tries of a handful of accounts, every other one with
+// storage slots and token balances.
+func newTestState(t *testing.T) *testState {
+	diskdb := ethdb.NewMemDatabase()
+	s := &testState{
+		diskdb:   diskdb,
+		triedb:   trie.NewDatabase(diskdb),
+		accounts: make(map[common.Hash][]byte),
+		storage:  make(map[common.Hash]map[common.Hash][]byte),
+		tokens:   make(map[common.Hash]map[common.Hash][]byte),
+	}
+	// subtrie fills a secure trie with the given number of items
+	subtrie := func(items int, seed byte) (common.Hash, map[common.Hash][]byte) {
+		tr, _ := trie.NewSecure(common.Hash{}, s.triedb, 0)
+		leaves := make(map[common.Hash][]byte)
+		for i := 1; i <= items; i++ {
+			key := common.Hash{seed, byte(i)}
+			value, _ := rlp.EncodeToBytes(big.NewInt(int64(seed) * int64(i)))
+			tr.Update(key[:], value)
+			leaves[crypto.Keccak256Hash(key[:])] = value
+		}
+		root, err := tr.Commit(nil)
+		if err != nil {
+			t.Fatalf("failed to commit subtrie: %v", err)
+		}
+		return root, leaves
+	}
+	accTrie, _ := trie.NewSecure(common.Hash{}, s.triedb, 0)
+	for i := byte(1); i <= 8; i++ {
+		addr := common.BytesToAddress([]byte{i})
+		acc := account{Nonce: uint64(i), Balance: big.NewInt(int64(i)), Root: emptyRoot, TokenBalanceRoot: emptyRoot, CodeHash: crypto.Keccak256(nil)}
+		hash := crypto.Keccak256Hash(addr[:])
+		if i%2 == 0 {
+			acc.Root, s.storage[hash] = subtrie(int(i), i)
+			acc.TokenBalanceRoot, s.tokens[hash] = subtrie(2, i+100)
+		}
+		blob, _ := rlp.EncodeToBytes(acc)
+		accTrie.Update(addr[:], blob)
+		s.accounts[hash] = blob
+	}
+	root, err := accTrie.Commit(nil)
+	if err != nil {
+		t.Fatalf("failed to commit account trie: %v", err)
+	}
+	if err := s.triedb.Commit(root, false); err != nil {
+		t.Fatalf("failed to flush tries: %v", err)
+	}
+	s.root = root
+	return s
+}
+
+// waitGeneration blocks until the disk layer of the tree finished generating.
+func waitGeneration(t *testing.T, snaps *Tree) *diskLayer {
+	for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+		snaps.lock.RLock()
+		for _, layer := range snaps.layers {
+			if disk, ok := layer.(*diskLayer); ok {
+				disk.lock.RLock()
+				done := disk.genMarker == nil
+				disk.lock.RUnlock()
+				if done {
+					snaps.lock.RUnlock()
+					return disk
+				}
+			}
+		}
+		snaps.lock.RUnlock()
+	}
+	t.Fatalf("snapshot generation timed out")
+	return nil
+}
+
+// checkSnapshot verifies that a snapshot layer serves the given state.
+func checkSnapshot(t *testing.T, snap Snapshot, accounts map[common.Hash][]byte, storage, tokens map[common.Hash]map[common.Hash][]byte) {
+	for hash, want := range accounts {
+		if have, err := snap.AccountRLP(hash); err != nil || !bytes.Equal(have, want) {
+			t.Errorf("account %x: have %x, %v, want %x", hash, have, err, want)
+		}
+	}
+	for accountHash, slots := range storage {
+		for hash, want := range slots {
+			if have, err := snap.Storage(accountHash, hash); err != nil || !bytes.Equal(have, want) {
+				t.Errorf("account %x slot %x: have %x, %v, want %x", accountHash, hash, have, err, want)
+			}
+		}
+	}
+	for accountHash, balances := range tokens {
+		for hash, want := range balances {
+			if have, err := snap.TokenBalance(accountHash, hash); err != nil || !bytes.Equal(have, want) {
+				t.Errorf("account %x token %x: have %x, %v, want %x", accountHash, hash, have, err, want)
+			}
+		}
+	}
+}
+
+// Tests that a missing snapshot is generated from the tries in the background,
+// covering accounts, storage slots and token balances.
+func TestGeneration(t *testing.T) {
+	state := newTestState(t)
+
+	snaps := New(state.diskdb, state.triedb, state.root)
+	if snaps.Snapshot(state.root) == nil {
+		t.Fatalf("snapshot of the head missing")
+	}
+	waitGeneration(t, snaps)
+	checkSnapshot(t, snaps.Snapshot(state.root), state.accounts, state.storage, state.tokens)
+
+	if root := rawdb.ReadSnapshotRoot(state.diskdb); root != state.root {
+		t.Fatalf("persisted snapshot root mismatch: have %x, want %x", root, state.root)
+	}
+	// Data of unknown items must be reported missing, not uncovered
+	if blob, err := snaps.Snapshot(state.root).AccountRLP(common.Hash{0xff}); err != nil || len(blob) != 0 {
+		t.Fatalf("unknown account: have %x, %v", blob, err)
+	}
+}
+
+// Tests that diff layers shadow the layers below, that capping flattens them
+// into the disk layer and that the journal restores them after a restart.
+func TestDiffLayers(t *testing.T) {
+	state := newTestState(t)
+
+	snaps := New(state.diskdb, state.triedb, state.root)
+	waitGeneration(t, snaps)
+
+	var (
+		changed   = crypto.Keccak256Hash(common.BytesToAddress([]byte{1}).Bytes())
+		destroyed = crypto.Keccak256Hash(common.BytesToAddress([]byte{2}).Bytes())
+		slot      = crypto.Keccak256Hash(common.Hash{2, 1}.Bytes())
+		token     = crypto.Keccak256Hash(common.Hash{102, 1}.Bytes())
+		root1     = common.Hash{0x01}
+		root2     = common.Hash{0x02}
+	)
+	// Layer 1 modifies an account, layer 2 destroys one with storage and tokens
+	if err := snaps.Update(root1, state.root, nil, map[common.Hash][]byte{changed: []byte("changed")}, nil, nil); err != nil {
+		t.Fatalf("failed to create diff layer 1: %v", err)
+	}
+	if err := snaps.Update(root2, root1, map[common.Hash]struct{}{destroyed: {}}, nil, nil, nil); err != nil {
+		t.Fatalf("failed to create diff layer 2: %v", err)
+	}
+	if err := snaps.Update(common.Hash{0x03}, common.Hash{0xff}, nil, nil, nil, nil); err == nil {
+		t.Fatalf("diff layer without parent accepted")
+	}
+	check := func(snap Snapshot) {
+		if blob, _ := snap.AccountRLP(changed); string(blob) != "changed" {
+			t.Errorf("changed account mismatch: have %q", blob)
+		}
+		if blob, _ := snap.AccountRLP(destroyed); len(blob) != 0 {
+			t.Errorf("destroyed account still present: %x", blob)
+		}
+		if blob, _ := snap.Storage(destroyed, slot); len(blob) != 0 {
+			t.Errorf("destroyed storage still present: %x", blob)
+		}
+		if blob, _ := snap.TokenBalance(destroyed, token); len(blob) != 0 {
+			t.Errorf("destroyed token balance still present: %x", blob)
+		}
+	}
+	check(snaps.Snapshot(root2))
+	checkSnapshot(t, snaps.Snapshot(state.root), state.accounts, state.storage, state.tokens)
+
+	// Flatten everything below the topmost layer and check the result
+	base := snaps.Snapshot(state.root)
+	if err := snaps.Cap(root2, 1); err != nil {
+		t.Fatalf("failed to cap snapshot tree: %v", err)
+	}
+	if _, err := base.AccountRLP(changed); err != ErrSnapshotStale {
+		t.Fatalf("flattened disk layer error mismatch: have %v, want %v", err, ErrSnapshotStale)
+	}
+	if snaps.Snapshot(state.root) != nil {
+		t.Fatalf("flattened disk layer still in the tree")
+	}
+	if _, ok := snaps.Snapshot(root1).(*diskLayer); !ok {
+		t.Fatalf("bottom diff layer not flattened into disk")
+	}
+	if blob := rawdb.ReadAccountSnapshot(state.diskdb, changed); string(blob) != "changed" {
+		t.Fatalf("flattened account mismatch: have %q", blob)
+	}
+	check(snaps.Snapshot(root2))
+
+	// Journal the remaining diff layer and reload the tree
+	if err := snaps.Journal(root2); err != nil {
+		t.Fatalf("failed to journal snapshot: %v", err)
+	}
+	snaps = New(state.diskdb, state.triedb, root2)
+	if _, ok := snaps.Snapshot(root2).(*diffLayer); !ok {
+		t.Fatalf("journalled diff layer not restored")
+	}
+	check(snaps.Snapshot(root2))
+
+	// Without journal (unclean shutdown) the snapshot is regenerated from scratch
+	snaps = New(state.diskdb, state.triedb, state.root)
+	waitGeneration(t, snaps)
+	checkSnapshot(t, snaps.Snapshot(state.root), state.accounts, state.storage, state.tokens)
+	if blob := rawdb.ReadAccountSnapshot(state.diskdb, changed); !bytes.Equal(blob, state.accounts[changed]) {
+		t.Fatalf("stale flattened account survived regeneration: %q", blob)
+	}
+}
@@ -109,14 +109,17 @@ type stateObject struct {
 	dirtyCode bool // true if the code was updated
 	suicided  bool
 	deleted   bool
+	created   bool // true if the account was created on empty tries since the last commit
 }
 
 // empty returns whether the account is considered empty.
 func (self *stateObject) empty() bool {
-	return self.data.Nonce == 0 &&
-		self.data.Balance.Sign() == 0 &&
-		bytes.Equal(self.data.CodeHash, emptyCodeHash) &&
-		len(self.dirtyTokenBalance) == 0 && len(self.originTokenBalance) == 0 &&
+	if self.data.Nonce != 0 || self.data.Balance.Sign() != 0 || !bytes.Equal(self.data.CodeHash, emptyCodeHash) {
+		return false
+	}
+	// Beyond the account fields, the items loaded so far decide
+	self.db.readEmptiness(self)
+	return len(self.dirtyTokenBalance) == 0 && len(self.originTokenBalance) == 0 &&
 		len(self.originStorage) == 0 && len(self.dirtyStorage) == 0
 }
 
@@ -221,11 +224,21 @@ func (self *stateObject) GetCommittedState(db Database, key common.Hash) common.
 	if cached {
 		return value
 	}
-	// Otherwise load the value from the database
-	enc, err := self.getTrie(db).TryGet(key[:])
-	if err != nil {
-		self.setError(err)
-		return common.Hash{}
+	// Otherwise load the value from the snapshot, or the trie if the snapshot
+	// can't serve it. Created accounts don't share the storage of the snapshot.
+	var (
+		enc []byte
+		err error
+	)
+	snap := self.db.snap
+	if snap != nil && !self.created {
+		enc, err = snap.Storage(self.addrHash, crypto.Keccak256Hash(key[:]))
+	}
+	if snap == nil || self.created || err != nil {
+		if enc, err = self.getTrie(db).TryGet(key[:]); err != nil {
+			self.setError(err)
+			return common.Hash{}
+		}
 	}
 	if len(enc) > 0 {
 		_, content, _, err := rlp.Split(enc)
@@ -261,6 +274,7 @@ func (self *stateObject) setState(key, value common.Hash) {
 // updateTrie writes cached storage modifications into the object's storage trie.
 func (self *stateObject) updateTrie(db Database) Trie {
 	tr := self.getTrie(db)
+	storage := self.snapshotItems(self.db.snapStorage)
 	for key, value := range self.dirtyStorage {
 		delete(self.dirtyStorage, key)
 
@@ -272,15 +286,61 @@ func (self *stateObject) updateTrie(db Database) Trie {
 
 		if (value == common.Hash{}) {
 			self.setError(tr.TryDelete(key[:]))
+			if storage != nil {
+				storage[crypto.Keccak256Hash(key[:])] = nil
+			}
 			continue
 		}
 		// Encoding []byte cannot fail, ok to ignore the error.
 		v, _ := rlp.EncodeToBytes(bytes.TrimLeft(value[:], "\x00"))
 		self.setError(tr.TryUpdate(key[:], v))
+		if storage != nil {
+			storage[crypto.Keccak256Hash(key[:])] = v
+		}
 	}
 	return tr
 }
 
+// snapshotItems returns the collected snapshot changes of the account from the
+// given set, or nil if the state has no snapshot.
+func (self *stateObject) snapshotItems(set map[common.Hash]map[common.Hash][]byte) map[common.Hash][]byte {
+	if set == nil {
+		return nil
+	}
+	items := set[self.addrHash]
+	if items == nil {
+		items = make(map[common.Hash][]byte)
+		set[self.addrHash] = items
+	}
+	return items
+}
+
+// snapshotStorage returns the snapshot entries of all the storage slots written
+// since the account was created.
+func (self *stateObject) snapshotStorage() map[common.Hash][]byte {
+	storage := make(map[common.Hash][]byte)
+	for key, value := range self.originStorage {
+		if (value != common.Hash{}) {
+			v, _ := rlp.EncodeToBytes(bytes.TrimLeft(value[:], "\x00"))
+			storage[crypto.Keccak256Hash(key[:])] = v
+		}
+	}
+	return storage
+}
+
+// snapshotTokenBalances returns the snapshot entries of all the token balances
+// written since the account was created.
+func (self *stateObject) snapshotTokenBalances() map[common.Hash][]byte {
+	balances := make(map[common.Hash][]byte)
+	for key, value := range self.originTokenBalance {
+		if value.Sign() != 0 {
+			v, _ := rlp.EncodeToBytes(value)
+			balances[crypto.Keccak256Hash(key[:])] = v
+		}
+	}
+	return balances
+}
+
 // UpdateRoot sets the trie root to the current root hash of
 func (self *stateObject) updateRoot(db Database) {
 	self.updateTrie(db)
@@ -305,6 +365,7 @@ func (self *stateObject) CommitTrie(db Database) error {
 
 func (self *stateObject) updateTokenBalance(db Database) Trie {
 	tr := self.getTokenBalanceTrie(db)
+	balances := self.snapshotItems(self.db.snapTokens)
 	for key, value := range self.dirtyTokenBalance {
 		delete(self.dirtyTokenBalance, key)
 
@@ -320,6 +381,9 @@ func (self *stateObject) updateTokenBalance(db Database) Trie {
 		// Encoding []byte cannot fail, ok to ignore the error.
 		v, _ := rlp.EncodeToBytes(value)
 		self.setError(tr.TryUpdate(key[:], v))
+		if balances != nil {
+			balances[crypto.Keccak256Hash(key[:])] = v
+		}
 	}
 
 	return tr
@@ -397,6 +461,7 @@ func (self *stateObject) deepCopy(db *StateDB) *stateObject {
 	stateObject.suicided = self.suicided
 	stateObject.dirtyCode = self.dirtyCode
 	stateObject.deleted = self.deleted
+	stateObject.created = self.created
 	return stateObject
 }
 
@@ -505,11 +570,21 @@ func (self *stateObject) GetCommittedTokenBalance(db Database, key common.Addres
 		return amount
 	}
 
-	// Otherwise load the value from the database
-	enc, err := self.getTokenBalanceTrie(db).TryGet(key[:])
-	if err != nil {
-		self.setError(err)
-		return common.Big0
+	// Otherwise load the value from the snapshot, or the trie if the snapshot
+	// can't serve it. Created accounts don't share the balances of the snapshot.
+	var (
+		enc []byte
+		err error
+	)
+	snap := self.db.snap
+	if snap != nil && !self.created {
+		enc, err = snap.TokenBalance(self.addrHash, crypto.Keccak256Hash(key[:]))
+	}
+	if snap == nil || self.created || err != nil {
+		if enc, err = self.getTokenBalanceTrie(db).TryGet(key[:]); err != nil {
+			self.setError(err)
+			return common.Big0
+		}
 	}
 	if len(enc) > 0 {
 		var value big.Int
@@ -555,6 +630,7 @@ func (self *stateObject) SetTokenBalance(db Database, key common.Address, amount
 	if prev.Cmp(amount) == 0 {
 		return
 	}
+	self.db.recordTokenChange(self.address, key, prev)
 
 	self.db.journal.append(tokenChange{
 		account: &self.address,
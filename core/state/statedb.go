@@ -24,6 +24,7 @@ import (
 	"sort"
 
 	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/core/state/snapshot"
 	"github.com/bcos-one/BCOS/core/types"
 	"github.com/bcos-one/BCOS/crypto"
 	"github.com/bcos-one/BCOS/log"
@@ -60,6 +61,16 @@ type StateDB struct {
 	db   Database
 	trie Trie
 
+	// Flat state snapshot consulted before the tries, and the changes collected
+	// for the snapshot diff layer of the next commit. All nil if there is no
+	// snapshot of the state root.
+	snaps         *snapshot.Tree
+	snap          snapshot.Snapshot
+	snapDestructs map[common.Hash]struct{}
+	snapAccounts  map[common.Hash][]byte
+	snapStorage   map[common.Hash]map[common.Hash][]byte
+	snapTokens    map[common.Hash]map[common.Hash][]byte
+
 	// This map holds 'live' objects, which will get modified while processing a state transition.
 	stateObjects      map[common.Address]*stateObject
 	stateObjectsDirty map[common.Address]struct{}
@@ -86,6 +97,14 @@ type StateDB struct {
 	journal        *journal
 	validRevisions []revision
 	nextRevisionId int
+
+	// Recorder of the accessed state, nil unless tracking.
+	access *accessTracker
+
+	// Token balances before their first modification, and whether balances
+	// were dropped along with their accounts, to capture the token changes.
+	tokenPrevs    map[common.Address]map[common.Address]*big.Int
+	tokenDiffLost bool
 }
 
 // Create a new state from a given trie.
@@ -105,6 +124,33 @@ func New(root common.Hash, db Database) (*StateDB, error) {
 	}, nil
 }
 
+// NewWithSnapshot creates a new state from a given trie, serving reads from the
+// snapshot tree if it maintains the state of the root.
+func NewWithSnapshot(root common.Hash, db Database, snaps *snapshot.Tree) (*StateDB, error) {
+	statedb, err := New(root, db)
+	if err != nil {
+		return nil, err
+	}
+	statedb.snaps = snaps
+	statedb.openSnapshot(root)
+	return statedb, nil
+}
+
+// openSnapshot looks up the snapshot of the given root and clears the snapshot
+// changes collected so far.
+func (self *StateDB) openSnapshot(root common.Hash) {
+	self.snap, self.snapDestructs, self.snapAccounts, self.snapStorage, self.snapTokens = nil, nil, nil, nil, nil
+	if self.snaps == nil {
+		return
+	}
+	if self.snap = self.snaps.Snapshot(root); self.snap != nil {
+		self.snapDestructs = make(map[common.Hash]struct{})
+		self.snapAccounts = make(map[common.Hash][]byte)
+		self.snapStorage = make(map[common.Hash]map[common.Hash][]byte)
+		self.snapTokens = make(map[common.Hash]map[common.Hash][]byte)
+	}
+}
+
 // setError remembers the first non-nil error it is called with.
 func (self *StateDB) setError(err error) {
 	if self.dbErr == nil {
@@ -132,6 +178,9 @@ func (self *StateDB) Reset(root common.Hash) error {
 	self.logs = make(map[common.Hash][]*types.Log)
 	self.logSize = 0
 	self.preimages = make(map[common.Hash][]byte)
+	self.tokenPrevs = nil
+	self.tokenDiffLost = false
+	self.openSnapshot(root)
 	self.clearJournalAndRefund()
 	return nil
 }
@@ -193,17 +242,20 @@ func (self *StateDB) SubRefund(gas uint64) {
 // Exist reports whether the given account address exists in the state.
 // Notably this also returns true for suicided accounts.
 func (self *StateDB) Exist(addr common.Address) bool {
+	self.readAccount(addr)
 	return self.getStateObject(addr) != nil
 }
 
 // Empty returns whether the state object is either non-existent
 // or empty according to the EIP161 specification (balance = nonce = code = 0)
 func (self *StateDB) Empty(addr common.Address) bool {
+	self.readAccount(addr)
 	so := self.getStateObject(addr)
 	return so == nil || so.empty()
 }
 
 func (self *StateDB) GetTokenBalance(addr common.Address, token common.Address) *big.Int {
+	self.readToken(addr, token)
 	stateObject := self.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.TokenBalance(self.db, token)
@@ -214,6 +266,7 @@ func (self *StateDB) GetTokenBalance(addr common.Address, token common.Address)
 
 // Retrieve the balance from the given address or 0 if object not found
 func (self *StateDB) GetBalance(addr common.Address) *big.Int {
+	self.readAccount(addr)
 	stateObject := self.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.Balance()
@@ -222,6 +275,7 @@ func (self *StateDB) GetBalance(addr common.Address) *big.Int {
 }
 
 func (self *StateDB) GetNonce(addr common.Address) uint64 {
+	self.readAccount(addr)
 	stateObject := self.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.Nonce()
@@ -231,6 +285,7 @@ func (self *StateDB) GetNonce(addr common.Address) uint64 {
 }
 
 func (self *StateDB) GetCode(addr common.Address) []byte {
+	self.readAccount(addr)
 	stateObject := self.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.Code(self.db)
@@ -239,6 +294,7 @@ func (self *StateDB) GetCode(addr common.Address) []byte {
 }
 
 func (self *StateDB) GetCodeSize(addr common.Address) int {
+	self.readAccount(addr)
 	stateObject := self.getStateObject(addr)
 	if stateObject == nil {
 		return 0
@@ -254,6 +310,7 @@ func (self *StateDB) GetCodeSize(addr common.Address) int {
 }
 
 func (self *StateDB) GetCodeHash(addr common.Address) common.Hash {
+	self.readAccount(addr)
 	stateObject := self.getStateObject(addr)
 	if stateObject == nil {
 		return common.Hash{}
@@ -262,6 +319,7 @@ func (self *StateDB) GetCodeHash(addr common.Address) common.Hash {
 }
 
 func (self *StateDB) GetTokenSupport(addr common.Address) *common.Address {
+	self.readAccount(addr)
 	stateObject := self.getStateObject(addr)
 	if stateObject == nil {
 		return nil
@@ -279,6 +337,7 @@ func (self *StateDB) GetTokenSupport(addr common.Address) *common.Address {
 
 // GetState retrieves a value from the given account's storage trie.
 func (self *StateDB) GetState(addr common.Address, hash common.Hash) common.Hash {
+	self.readStorage(addr, hash)
 	stateObject := self.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.GetState(self.db, hash)
@@ -306,6 +365,7 @@ func (self *StateDB) GetStorageProof(a common.Address, key common.Hash) ([][]byt
 
 // GetCommittedState retrieves a value from the given account's committed storage trie.
 func (self *StateDB) GetCommittedState(addr common.Address, hash common.Hash) common.Hash {
+	self.readStorage(addr, hash)
 	stateObject := self.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.GetCommittedState(self.db, hash)
@@ -325,12 +385,14 @@ func (self *StateDB) StorageTrie(addr common.Address) Trie {
 	if stateObject == nil {
 		return nil
 	}
-	cpy := stateObject.deepCopy(self)
+	// Detach the copy from the snapshot changes, it is never committed
+	cpy := stateObject.deepCopy(&StateDB{db: self.db})
 	return cpy.updateTrie(self.db)
 }
 
 
 func (self *StateDB) HasSuicided(addr common.Address) bool {
+	self.readAccount(addr)
 	stateObject := self.getStateObject(addr)
 	if stateObject != nil {
 		return stateObject.suicided
@@ -346,12 +408,14 @@ func (self *StateDB) HasSuicided(addr common.Address) bool {
 func (self *StateDB) AddBalance(addr common.Address, amount *big.Int) {
 	stateObject := self.GetOrNewStateObject(addr)
 	if stateObject != nil {
+		self.creditBalance(stateObject, amount)
 		stateObject.AddBalance(amount)
 	}
 }
 
 // SubBalance subtracts amount from the account associated with addr.
 func (self *StateDB) SubBalance(addr common.Address, amount *big.Int) {
+	self.readAccount(addr)
 	stateObject := self.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SubBalance(amount)
@@ -359,6 +423,7 @@ func (self *StateDB) SubBalance(addr common.Address, amount *big.Int) {
 }
 
 func (self *StateDB) SetBalance(addr common.Address, amount *big.Int) {
+	self.readAccount(addr)
 	stateObject := self.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SetBalance(amount)
@@ -368,11 +433,13 @@ func (self *StateDB) SetBalance(addr common.Address, amount *big.Int) {
 func (self *StateDB) AddTokenBalance(addr common.Address, token common.Address, amount *big.Int) {
 	stateObject := self.GetOrNewStateObject(addr)
 	if stateObject != nil {
+		self.creditToken(stateObject, token, amount)
 		stateObject.AddTokenBalance(self.db, token, amount)
 	}
 }
 
 func (self *StateDB) SubTokenBalance(addr common.Address, token common.Address, amount *big.Int) {
+	self.readToken(addr, token)
 	stateObject := self.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SubTokenBalance(self.db, token, amount)
@@ -380,6 +447,7 @@ func (self *StateDB) SubTokenBalance(addr common.Address, token common.Address,
 }
 
 func (self *StateDB) SetTokenBalance(addr common.Address, token common.Address, amount *big.Int) {
+	self.readToken(addr, token)
 	stateObject := self.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SetTokenBalance(self.db, token, amount)
@@ -387,6 +455,7 @@ func (self *StateDB) SetTokenBalance(addr common.Address, token common.Address,
 }
 
 func (self *StateDB) SetNonce(addr common.Address, nonce uint64) {
+	self.readAccount(addr)
 	stateObject := self.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SetNonce(nonce)
@@ -394,6 +463,7 @@ func (self *StateDB) SetNonce(addr common.Address, nonce uint64) {
 }
 
 func (self *StateDB) SetCode(addr common.Address, code []byte) {
+	self.readAccount(addr)
 	stateObject := self.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SetCode(crypto.Keccak256Hash(code), code)
@@ -401,6 +471,7 @@ func (self *StateDB) SetCode(addr common.Address, code []byte) {
 }
 
 func (self *StateDB) SetTokenSupport(addr common.Address, token common.Address) {
+	self.readAccount(addr)
 	stateObject := self.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SetTokenSupport(token)
@@ -408,6 +479,7 @@ func (self *StateDB) SetTokenSupport(addr common.Address, token common.Address)
 }
 
 func (self *StateDB) SetState(addr common.Address, key, value common.Hash) {
+	self.readStorage(addr, key)
 	stateObject := self.GetOrNewStateObject(addr)
 	if stateObject != nil {
 		stateObject.SetState(self.db, key, value)
@@ -420,6 +492,7 @@ func (self *StateDB) SetState(addr common.Address, key, value common.Hash) {
 // The account's state object is still available until the state is committed,
 // getStateObject will return a non-nil account after Suicide.
 func (self *StateDB) Suicide(addr common.Address) bool {
+	self.readAccount(addr)
 	stateObject := self.getStateObject(addr)
 	if stateObject == nil {
 		return false
@@ -447,18 +520,44 @@ func (self *StateDB) updateStateObject(stateObject *stateObject) {
 		panic(fmt.Errorf("can't encode object at %x: %v", addr[:], err))
 	}
 	self.setError(self.trie.TryUpdate(addr[:], data))
+
+	if self.snapAccounts != nil {
+		self.snapAccounts[stateObject.addrHash] = data
+		if stateObject.created {
+			// The account was (re)created on empty tries, drop anything the snapshot
+			// held before and keep only what the new account itself wrote
+			self.snapDestructs[stateObject.addrHash] = struct{}{}
+			self.snapStorage[stateObject.addrHash] = stateObject.snapshotStorage()
+			self.snapTokens[stateObject.addrHash] = stateObject.snapshotTokenBalances()
+		}
+	}
 }
 
 // deleteStateObject removes the given object from the state trie.
 func (self *StateDB) deleteStateObject(stateObject *stateObject) {
+	self.recordTokenDrop(stateObject)
 	stateObject.deleted = true
 	addr := stateObject.Address()
 	self.setError(self.trie.TryDelete(addr[:]))
+
+	if self.snapAccounts != nil {
+		self.snapDestructs[stateObject.addrHash] = struct{}{}
+		delete(self.snapAccounts, stateObject.addrHash)
+		delete(self.snapStorage, stateObject.addrHash)
+		delete(self.snapTokens, stateObject.addrHash)
+	}
 }
 
 // Retrieve a state object given by the address. Returns nil if not found.
 func (self *StateDB) getStateObject(addr common.Address) (stateObject *stateObject) {
 	// Prefer 'live' objects.
+	if self.access != nil {
+		defer func() {
+			if _, ok := self.access.existed[addr]; !ok {
+				self.access.existed[addr] = stateObject != nil
+			}
+		}()
+	}
 	if obj := self.stateObjects[addr]; obj != nil {
 		if obj.deleted {
 			return nil
@@ -466,8 +565,17 @@ func (self *StateDB) getStateObject(addr common.Address) (stateObject *stateObje
 		return obj
 	}
 
-	// Load the object from the database.
-	enc, err := self.trie.TryGet(addr[:])
+	// Load the object from the snapshot, or the trie if the snapshot can't serve it.
+	var (
+		enc []byte
+		err error
+	)
+	if self.snap != nil {
+		enc, err = self.snap.AccountRLP(crypto.Keccak256Hash(addr[:]))
+	}
+	if self.snap == nil || err != nil {
+		enc, err = self.trie.TryGet(addr[:])
+	}
 	if len(enc) == 0 {
 		self.setError(err)
 		return nil
@@ -502,9 +610,14 @@ func (self *StateDB) createObject(addr common.Address) (newobj, prev *stateObjec
 	prev = self.getStateObject(addr)
 	newobj = newObject(self, addr, Account{})
 	newobj.setNonce(0) // sets the object to dirty
+	newobj.created = true
+	if self.access != nil {
+		self.access.created[addr] = newobj
+	}
 	if prev == nil {
 		self.journal.append(createObjectChange{account: &addr})
 	} else {
+		self.recordTokenDrop(prev)
 		self.journal.append(resetObjectChange{prev: prev})
 	}
 	self.setStateObject(newobj)
@@ -522,6 +635,7 @@ func (self *StateDB) createObject(addr common.Address) (newobj, prev *stateObjec
 //
 // Carrying over the balance ensures that Ether doesn't disappear.
 func (self *StateDB) CreateAccount(addr common.Address) {
+	self.readAccount(addr)
 	new, prev := self.createObject(addr)
 	if prev != nil {
 		new.setBalance(prev.data.Balance)
@@ -529,6 +643,10 @@ func (self *StateDB) CreateAccount(addr common.Address) {
 }
 
 func (db *StateDB) ForEachStorage(addr common.Address, cb func(key, value common.Hash) bool) {
+	if db.access != nil {
+		db.access.reads.addAccount(addr)
+		db.access.reads.wiped[addr] = struct{}{}
+	}
 	so := db.getStateObject(addr)
 	if so == nil {
 		return
@@ -558,6 +676,22 @@ func (self *StateDB) Copy() *StateDB {
 		logSize:           self.logSize,
 		preimages:         make(map[common.Hash][]byte),
 		journal:           newJournal(),
+		snaps:             self.snaps,
+		snap:              self.snap,
+		tokenPrevs:        copyTokenPrevs(self.tokenPrevs),
+		tokenDiffLost:     self.tokenDiffLost,
+	}
+	if self.snap != nil {
+		state.snapDestructs = make(map[common.Hash]struct{}, len(self.snapDestructs))
+		for hash := range self.snapDestructs {
+			state.snapDestructs[hash] = struct{}{}
+		}
+		state.snapAccounts = make(map[common.Hash][]byte, len(self.snapAccounts))
+		for hash, data := range self.snapAccounts {
+			state.snapAccounts[hash] = data
+		}
+		state.snapStorage = copySnapshotItems(self.snapStorage)
+		state.snapTokens = copySnapshotItems(self.snapTokens)
 	}
 	// Copy the dirty states, logs, and preimages
 	for addr := range self.journal.dirties {
@@ -594,6 +728,19 @@ func (self *StateDB) Copy() *StateDB {
 	return state
 }
 
+// copySnapshotItems copies the collected storage or token balance changes of the
+// snapshot. The values are never modified in place, so they are shared.
+func copySnapshotItems(set map[common.Hash]map[common.Hash][]byte) map[common.Hash]map[common.Hash][]byte {
+	cpy := make(map[common.Hash]map[common.Hash][]byte, len(set))
+	for accountHash, items := range set {
+		cpy[accountHash] = make(map[common.Hash][]byte, len(items))
+		for hash, data := range items {
+			cpy[accountHash][hash] = data
+		}
+	}
+	return cpy
+}
+
 // Snapshot returns an identifier for the current revision of the state.
 func (self *StateDB) Snapshot() int {
 	id := self.nextRevisionId
@@ -703,6 +850,7 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (root common.Hash, err error)
 			}
 			// Update the object in the main account trie.
 			s.updateStateObject(stateObject)
+			stateObject.created = false
 		}
 		delete(s.stateObjectsDirty, addr)
 	}
@@ -725,6 +873,15 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (root common.Hash, err error)
 		}
 		return nil
 	})
+	// Hand the changes to the snapshot tree and continue on the new layer
+	if err == nil && s.snap != nil {
+		if parent := s.snap.Root(); parent != root {
+			if err := s.snaps.Update(root, parent, s.snapDestructs, s.snapAccounts, s.snapStorage, s.snapTokens); err != nil {
+				log.Warn("Failed to update snapshot tree", "from", parent, "to", root, "err", err)
+			}
+		}
+		s.openSnapshot(root)
+	}
 	log.Debug("Trie cache stats after commit", "misses", trie.CacheMisses(), "unloads", trie.CacheUnloads())
 	return root, err
 }
\ No newline at end of file
@@ -31,7 +31,9 @@ import (
 	check "gopkg.in/check.v1"
 
 	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/core/state/snapshot"
 	"github.com/bcos-one/BCOS/core/types"
+	"github.com/bcos-one/BCOS/crypto"
 	"github.com/bcos-one/BCOS/ethdb"
 )
 
@@ -435,3 +437,90 @@ func TestCopyOfCopy(t *testing.T) {
 		t.Fatalf("2nd copy fail, expected 42, got %v", got)
 	}
 }
+
+// Tests that committing a state backed by a flat snapshot produces a diff layer
+// that serves the same accounts, storage slots and token balances as the tries,
+// including those of destructed and recreated accounts.
+func TestFlatSnapshotCommit(t *testing.T) {
+	var (
+		diskdb   = ethdb.NewMemDatabase()
+		sdb      = NewDatabase(diskdb)
+		token    = common.HexToAddress("0xff")
+		a, b, c  = common.HexToAddress("0x0a"), common.HexToAddress("0x0b"), common.HexToAddress("0x0c")
+		k1, k2   = common.Hash{0x01}, common.Hash{0x02}
+		v1, v2   = common.Hash{0x11}, common.Hash{0x22}
+		accounts = []common.Address{a, b, c}
+	)
+	state, _ := New(common.Hash{}, sdb)
+	for _, addr := range accounts {
+		state.AddBalance(addr, big.NewInt(1))
+		state.SetState(addr, k1, v1)
+		state.AddTokenBalance(addr, token, big.NewInt(5))
+	}
+	root0, _ := state.Commit(false)
+	if err := sdb.TrieDB().Commit(root0, false); err != nil {
+		t.Fatalf("failed to flush state: %v", err)
+	}
+	snaps := snapshot.New(diskdb, sdb.TrieDB(), root0)
+
+	// Modify, destruct and recreate accounts across two transactions
+	state, _ = NewWithSnapshot(root0, sdb, snaps)
+	state.SetState(a, k1, common.Hash{})
+	state.SetState(a, k2, v2)
+	state.SubTokenBalance(a, token, big.NewInt(2))
+	state.Suicide(b)
+	state.Suicide(c)
+	state.Finalise(false)
+
+	state.CreateAccount(c)
+	state.SetState(c, k2, v2)
+	state.AddBalance(c, big.NewInt(3))
+	if have := state.GetState(c, k1); have != (common.Hash{}) {
+		t.Fatalf("recreated account inherited storage: %x", have)
+	}
+	root1, err := state.Commit(false)
+	if err != nil {
+		t.Fatalf("failed to commit state: %v", err)
+	}
+	snap := snaps.Snapshot(root1)
+	if snap == nil {
+		t.Fatalf("no snapshot diff layer created")
+	}
+	// Every changed item must match the tries
+	tries, _ := New(root1, sdb)
+	for _, addr := range accounts {
+		addrHash := crypto.Keccak256Hash(addr[:])
+		want, _ := tries.trie.TryGet(addr[:])
+		if have, err := snap.AccountRLP(addrHash); err != nil || !bytes.Equal(have, want) {
+			t.Errorf("account %x: have %x, %v, want %x", addr, have, err, want)
+		}
+		obj := tries.getStateObject(addr)
+		for _, key := range []common.Hash{k1, k2} {
+			var want []byte
+			if obj != nil {
+				want, _ = obj.getTrie(sdb).TryGet(key[:])
+			}
+			if have, err := snap.Storage(addrHash, crypto.Keccak256Hash(key[:])); err != nil || !bytes.Equal(have, want) {
+				t.Errorf("account %x slot %x: have %x, %v, want %x", addr, key, have, err, want)
+			}
+		}
+	}
+	// Reads through the snapshot must match reads through the tries
+	state, _ = NewWithSnapshot(root1, sdb, snaps)
+	for _, addr := range accounts {
+		if have, want := state.GetBalance(addr), tries.GetBalance(addr); have.Cmp(want) != 0 {
+			t.Errorf("account %x: balance mismatch: have %v, want %v", addr, have, want)
+		}
+		if have, want := state.GetTokenBalance(addr, token), tries.GetTokenBalance(addr, token); have.Cmp(want) != 0 {
+			t.Errorf("account %x: token balance mismatch: have %v, want %v", addr, have, want)
+		}
+		for _, key := range []common.Hash{k1, k2} {
+			if have, want := state.GetState(addr, key), tries.GetState(addr, key); have != want {
+				t.Errorf("account %x slot %x: have %x, want %x", addr, key, have, want)
+			}
+		}
+	}
+	if balance := state.GetTokenBalance(a, token); balance.Int64() != 3 {
+		t.Fatalf("token balance mismatch: have %v, want 3", balance)
+	}
+}
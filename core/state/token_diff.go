@@ -0,0 +1,221 @@
+package state
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/core/types"
+	"github.com/bcos-one/BCOS/crypto"
+	"github.com/bcos-one/BCOS/rlp"
+	"github.com/bcos-one/BCOS/trie"
+)
+
+// TokenBalanceChange is a token balance of a single holder that differs between
+// two state roots.
+type TokenBalanceChange struct {
+	Holder common.Address
+	Token  common.Address
+	Prev   *big.Int
+	Value  *big.Int
+}
+
+// TokenBalanceDiff returns every token balance modified through the state since it
+// was opened, ordered by holder and token. The changes are only complete if ok is
+// true. Otherwise accounts holding tokens were destroyed or recreated, dropping
+// their balances at once, and the tries have to be diffed instead.
+func (self *StateDB) TokenBalanceDiff() (changes []TokenBalanceChange, ok bool) {
+	if self.tokenDiffLost {
+		return nil, false
+	}
+	for holder, prevs := range self.tokenPrevs {
+		for token, prev := range prevs {
+			value := new(big.Int)
+			if obj := self.getStateObject(holder); obj != nil {
+				value.Set(obj.TokenBalance(self.db, token))
+			}
+			if prev.Cmp(value) == 0 {
+				continue
+			}
+			changes = append(changes, TokenBalanceChange{Holder: holder, Token: token, Prev: new(big.Int).Set(prev), Value: value})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		if c := bytes.Compare(changes[i].Holder[:], changes[j].Holder[:]); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(changes[i].Token[:], changes[j].Token[:]) < 0
+	})
+	return changes, true
+}
+
+// recordTokenChange remembers the balance a holder had of a token before it was
+// first modified through the state. Modifications that end up reverted or offset
+// are kept, they fall out when the balance turns out unchanged.
+func (self *StateDB) recordTokenChange(holder, token common.Address, prev *big.Int) {
+	if self.tokenPrevs == nil {
+		self.tokenPrevs = make(map[common.Address]map[common.Address]*big.Int)
+	}
+	prevs := self.tokenPrevs[holder]
+	if prevs == nil {
+		prevs = make(map[common.Address]*big.Int)
+		self.tokenPrevs[holder] = prevs
+	}
+	if _, ok := prevs[token]; !ok {
+		prevs[token] = new(big.Int).Set(prev)
+	}
+}
+
+// recordTokenDrop notes an account being destroyed or recreated. Any token it
+// holds vanishes without the balances being modified one by one.
+func (self *StateDB) recordTokenDrop(obj *stateObject) {
+	if root := obj.data.TokenBalanceRoot; root != (common.Hash{}) && root != types.EmptyRootHash {
+		self.tokenDiffLost = true
+		return
+	}
+	for _, amount := range obj.dirtyTokenBalance {
+		if amount.Sign() != 0 {
+			self.tokenDiffLost = true
+			return
+		}
+	}
+}
+
+// copyTokenPrevs copies the token balances recorded before their first
+// modification. The balances are never modified in place, so they are shared.
+func copyTokenPrevs(set map[common.Address]map[common.Address]*big.Int) map[common.Address]map[common.Address]*big.Int {
+	if set == nil {
+		return nil
+	}
+	cpy := make(map[common.Address]map[common.Address]*big.Int, len(set))
+	for holder, prevs := range set {
+		cpy[holder] = make(map[common.Address]*big.Int, len(prevs))
+		for token, prev := range prevs {
+			cpy[holder][token] = prev
+		}
+	}
+	return cpy
+}
+
+// TokenBalanceChanges returns every token balance that differs between the two
+// state roots, ordered by holder and token. Only the parts of the account and
+// token balance tries that differ are visited.
+func TokenBalanceChanges(db Database, parent, root common.Hash) ([]TokenBalanceChange, error) {
+	oldTr, err := db.OpenTrie(parent)
+	if err != nil {
+		return nil, err
+	}
+	newTr, err := db.OpenTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := changedTrieKeys(oldTr, newTr)
+	if err != nil {
+		return nil, err
+	}
+	var changes []TokenBalanceChange
+	for _, addr := range accounts {
+		oldAcc, err := readTrieAccount(oldTr, addr)
+		if err != nil {
+			return nil, err
+		}
+		newAcc, err := readTrieAccount(newTr, addr)
+		if err != nil {
+			return nil, err
+		}
+		if oldAcc.TokenBalanceRoot == newAcc.TokenBalanceRoot {
+			continue
+		}
+		addrHash := crypto.Keccak256Hash(addr)
+		oldTok, err := db.OpenStorageTrie(addrHash, oldAcc.TokenBalanceRoot)
+		if err != nil {
+			return nil, err
+		}
+		newTok, err := db.OpenStorageTrie(addrHash, newAcc.TokenBalanceRoot)
+		if err != nil {
+			return nil, err
+		}
+		tokens, err := changedTrieKeys(oldTok, newTok)
+		if err != nil {
+			return nil, err
+		}
+		for _, token := range tokens {
+			prev, err := readTrieTokenBalance(oldTok, token)
+			if err != nil {
+				return nil, err
+			}
+			value, err := readTrieTokenBalance(newTok, token)
+			if err != nil {
+				return nil, err
+			}
+			if prev.Cmp(value) == 0 {
+				continue
+			}
+			changes = append(changes, TokenBalanceChange{
+				Holder: common.BytesToAddress(addr),
+				Token:  common.BytesToAddress(token),
+				Prev:   prev,
+				Value:  value,
+			})
+		}
+	}
+	return changes, nil
+}
+
+// changedTrieKeys returns the sorted preimages of all leaves that were added,
+// modified or removed between the two tries.
+func changedTrieKeys(a, b Trie) ([][]byte, error) {
+	seen := make(map[string]struct{})
+	keys := make([][]byte, 0)
+
+	for _, pair := range [][2]Trie{{a, b}, {b, a}} {
+		diff, _ := trie.NewDifferenceIterator(pair[0].NodeIterator(nil), pair[1].NodeIterator(nil))
+		it := trie.NewIterator(diff)
+		for it.Next() {
+			if _, ok := seen[string(it.Key)]; ok {
+				continue
+			}
+			seen[string(it.Key)] = struct{}{}
+
+			preimage := pair[1].GetKey(it.Key)
+			if preimage == nil {
+				preimage = pair[0].GetKey(it.Key)
+			}
+			if preimage == nil {
+				continue
+			}
+			keys = append(keys, common.CopyBytes(preimage))
+		}
+		if it.Err != nil {
+			return nil, it.Err
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+	return keys, nil
+}
+
+func readTrieAccount(tr Trie, addr []byte) (Account, error) {
+	var account Account
+
+	enc, err := tr.TryGet(addr)
+	if err != nil || len(enc) == 0 {
+		return account, err
+	}
+	err = rlp.DecodeBytes(enc, &account)
+	return account, err
+}
+
+func readTrieTokenBalance(tr Trie, token []byte) (*big.Int, error) {
+	enc, err := tr.TryGet(token)
+	if err != nil {
+		return nil, err
+	}
+	value := new(big.Int)
+	if len(enc) > 0 {
+		if err := rlp.DecodeBytes(enc, value); err != nil {
+			return nil, err
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,125 @@
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/ethdb"
+)
+
+func TestTokenBalanceChanges(t *testing.T) {
+	var (
+		db    = NewDatabase(ethdb.NewMemDatabase())
+		alice = common.HexToAddress("0x01")
+		bob   = common.HexToAddress("0x02")
+		token = common.HexToAddress("0xaa")
+	)
+	commit := func(state *StateDB) common.Hash {
+		root, err := state.Commit(true)
+		if err != nil {
+			t.Fatalf("failed to commit state: %v", err)
+		}
+		if err := db.TrieDB().Commit(root, false); err != nil {
+			t.Fatalf("failed to commit trie: %v", err)
+		}
+		return root
+	}
+	state, _ := New(common.Hash{}, db)
+	state.AddTokenBalance(alice, token, big.NewInt(100))
+	state.AddBalance(bob, big.NewInt(5))
+	issued := commit(state)
+
+	state, _ = New(issued, db)
+	state.SubTokenBalance(alice, token, big.NewInt(40))
+	state.AddTokenBalance(bob, token, big.NewInt(40))
+	transferred := commit(state)
+
+	tests := []struct {
+		parent, root common.Hash
+		want         []TokenBalanceChange
+	}{
+		{common.Hash{}, issued, []TokenBalanceChange{
+			{Holder: alice, Token: token, Prev: big.NewInt(0), Value: big.NewInt(100)},
+		}},
+		{issued, transferred, []TokenBalanceChange{
+			{Holder: alice, Token: token, Prev: big.NewInt(100), Value: big.NewInt(60)},
+			{Holder: bob, Token: token, Prev: big.NewInt(0), Value: big.NewInt(40)},
+		}},
+		{transferred, issued, []TokenBalanceChange{
+			{Holder: alice, Token: token, Prev: big.NewInt(60), Value: big.NewInt(100)},
+			{Holder: bob, Token: token, Prev: big.NewInt(40), Value: big.NewInt(0)},
+		}},
+		{issued, issued, nil},
+	}
+	for i, tt := range tests {
+		changes, err := TokenBalanceChanges(db, tt.parent, tt.root)
+		if err != nil {
+			t.Fatalf("test %d: failed to diff states: %v", i, err)
+		}
+		if len(changes) != len(tt.want) {
+			t.Fatalf("test %d: change count mismatch: have %d, want %d", i, len(changes), len(tt.want))
+		}
+		for j, change := range changes {
+			want := tt.want[j]
+			if change.Holder != want.Holder || change.Token != want.Token || change.Prev.Cmp(want.Prev) != 0 || change.Value.Cmp(want.Value) != 0 {
+				t.Errorf("test %d, change %d: mismatch: have %+v, want %+v", i, j, change, want)
+			}
+		}
+	}
+}
+
+func TestTokenBalanceDiff(t *testing.T) {
+	var (
+		db    = NewDatabase(ethdb.NewMemDatabase())
+		alice = common.HexToAddress("0x01")
+		bob   = common.HexToAddress("0x02")
+		carol = common.HexToAddress("0x03")
+		token = common.HexToAddress("0xaa")
+	)
+	state, _ := New(common.Hash{}, db)
+	state.AddTokenBalance(alice, token, big.NewInt(100))
+	state.AddBalance(carol, big.NewInt(1))
+	state.AddTokenBalance(carol, token, big.NewInt(10))
+	root, _ := state.Commit(true)
+
+	state, _ = New(root, db)
+	state.SubTokenBalance(alice, token, big.NewInt(40))
+	state.AddTokenBalance(bob, token, big.NewInt(40))
+	state.IntermediateRoot(true)
+
+	// Offsetting modifications leave no change behind
+	state.AddTokenBalance(carol, token, big.NewInt(5))
+	state.SubTokenBalance(carol, token, big.NewInt(5))
+	state.SubTokenBalance(bob, token, big.NewInt(10))
+	state.AddTokenBalance(bob, token, big.NewInt(10))
+	state.Commit(true)
+
+	want := []TokenBalanceChange{
+		{Holder: alice, Token: token, Prev: big.NewInt(100), Value: big.NewInt(60)},
+		{Holder: bob, Token: token, Prev: big.NewInt(0), Value: big.NewInt(40)},
+	}
+	check := func(state *StateDB) {
+		changes, ok := state.TokenBalanceDiff()
+		if !ok {
+			t.Fatalf("token diff incomplete")
+		}
+		if len(changes) != len(want) {
+			t.Fatalf("change count mismatch: have %d, want %d", len(changes), len(want))
+		}
+		for i, change := range changes {
+			if change.Holder != want[i].Holder || change.Token != want[i].Token || change.Prev.Cmp(want[i].Prev) != 0 || change.Value.Cmp(want[i].Value) != 0 {
+				t.Errorf("change %d: mismatch: have %+v, want %+v", i, change, want[i])
+			}
+		}
+	}
+	check(state)
+	check(state.Copy())
+
+	// Destroying a token holder drops its balances at once
+	state.Suicide(carol)
+	state.Commit(true)
+	if _, ok := state.TokenBalanceDiff(); ok {
+		t.Fatalf("token diff complete despite destroyed holder")
+	}
+}
@@ -17,6 +17,8 @@
 package core
 
 import (
+	"math/big"
+
 	"github.com/bcos-one/BCOS/common"
 	"github.com/bcos-one/BCOS/consensus"
 	"github.com/bcos-one/BCOS/consensus/misc"
@@ -25,9 +27,13 @@ import (
 	"github.com/bcos-one/BCOS/core/vm"
 	"github.com/bcos-one/BCOS/crypto"
 	"github.com/bcos-one/BCOS/expansions"
+	"github.com/bcos-one/BCOS/metrics"
 	"github.com/bcos-one/BCOS/params"
 )
 
+// tokenGasMeter meters the gas paid in tokens instead of ether by the processed blocks.
+var tokenGasMeter = metrics.NewRegisteredMeter("chain/tokengas", nil)
+
 // StateProcessor is a basic Processor, which takes care of transitioning
 // state from one point to another.
 //
@@ -66,68 +72,130 @@ func (p *StateProcessor) Process(block *types.Block, statedb *state.StateDB, cfg
 	if p.config.DAOForkSupport && p.config.DAOForkBlock != nil && p.config.DAOForkBlock.Cmp(block.Number()) == 0 {
 		misc.ApplyDAOHardFork(statedb)
 	}
-	// Iterate over and process the individual transactions
-	for i, tx := range block.Transactions() {
-		statedb.Prepare(tx.Hash(), block.Hash(), i)
-		receipt, _, err := ApplyTransaction(p.config, p.bc, nil, gp, statedb, header, tx, usedGas, cfg)
-		if err != nil {
+	if p.config.ExpansionsConfig != nil {
+		expansions.NewExpansions(p.config).ApplyForks(statedb, block.Number())
+	}
+	// Iterate over and process the individual transactions, optimistically in
+	// parallel if enabled
+	if p.parallel(block, cfg) {
+		var err error
+		if receipts, allLogs, err = p.processParallel(block, statedb, gp, usedGas, cfg); err != nil {
 			return nil, nil, 0, err
 		}
+	} else {
+		for i, tx := range block.Transactions() {
+			statedb.Prepare(tx.Hash(), block.Hash(), i)
+			receipt, _, err := ApplyTransaction(p.config, p.bc, nil, gp, statedb, header, tx, usedGas, cfg)
+			if err != nil {
+				return nil, nil, 0, err
+			}
 
-		receipts = append(receipts, receipt)
-		allLogs = append(allLogs, receipt.Logs...)
+			receipts = append(receipts, receipt)
+			allLogs = append(allLogs, receipt.Logs...)
+		}
 	}
+	p.meterTokenGas(receipts)
+
 	// Finalize the block, applying any consensus engine specific extras (e.g. block rewards)
 	p.engine.Finalize(p.bc, header, statedb, block.Transactions(), block.Uncles(), receipts)
 
 	return receipts, allLogs, *usedGas, nil
 }
 
+// meterTokenGas accounts the gas paid in tokens by the transactions of a processed
+// block, as decided against the state each transaction was executed on.
+func (p *StateProcessor) meterTokenGas(receipts types.Receipts) {
+	if !metrics.Enabled {
+		return
+	}
+	for _, receipt := range receipts {
+		if receipt.TokenGas {
+			tokenGasMeter.Mark(int64(receipt.GasUsed))
+		}
+	}
+}
+
 // ApplyTransaction attempts to apply a transaction to the given state database
 // and uses the input parameters for its environment. It returns the receipt
 // for the transaction, gas used and an error if the transaction failed,
 // indicating the block was invalid.
 func ApplyTransaction(config *params.ChainConfig, bc ChainContext, author *common.Address, gp *GasPool, statedb *state.StateDB, header *types.Header, tx *types.Transaction, usedGas *uint64, cfg vm.Config) (*types.Receipt, uint64, error) {
-	msg, err := tx.AsMessage(types.MakeSigner(config, header.Number))
+	msg, gas, failed, tokenGas, err := applyTransaction(config, bc, author, gp, statedb, header, tx, cfg)
 	if err != nil {
 		return nil, 0, err
 	}
+	// Update the state with pending changes
+	var root []byte
+	if config.IsByzantium(header.Number) {
+		statedb.Finalise(true)
+	} else {
+		root = statedb.IntermediateRoot(config.IsEIP158(header.Number)).Bytes()
+	}
+	*usedGas += gas
+
+	return newReceipt(root, failed, tokenGas, *usedGas, gas, tx, msg, statedb), gas, err
+}
+
+// ValidateTxType checks that the envelope of a transaction is allowed in the block
+// with the given number. Typed token and replacement transactions are only accepted
+// from their fork on, where the legacy encoding of token transactions becomes invalid.
+func ValidateTxType(config *params.ChainConfig, tx *types.Transaction, num *big.Int) error {
+	if !config.IsTokenTx(num) {
+		if tx.Type() != types.LegacyTxType {
+			return types.ErrTxTypeNotSupported
+		}
+		return nil
+	}
+	if tx.LegacyToken() {
+		return types.ErrLegacyTokenTx
+	}
+	return nil
+}
+
+// applyTransaction executes a transaction on the given state database without
+// finalising the changes. It returns the message of the transaction, the gas
+// used, whether the execution failed and whether the gas was paid in tokens.
+func applyTransaction(config *params.ChainConfig, bc ChainContext, author *common.Address, gp *GasPool, statedb *state.StateDB, header *types.Header, tx *types.Transaction, cfg vm.Config) (types.Message, uint64, bool, bool, error) {
+	if err := ValidateTxType(config, tx, header.Number); err != nil {
+		return types.Message{}, 0, false, false, err
+	}
+	msg, err := tx.AsMessage(types.MakeSigner(config, header.Number))
+	if err != nil {
+		return msg, 0, false, false, err
+	}
 	// Create a new context to be used in the EVM environment
 	context := NewEVMContext(msg, header, bc, author)
 	// Create a new environment which holds all relevant information
 	// about the transaction and calling mechanisms.
 	vmenv := vm.NewEVM(context, statedb, config, cfg)
 	// Apply the transaction to the current state (included in the env)
-	_, gas, failed, err := ApplyMessage(vmenv, msg, gp)
+	st := NewStateTransition(vmenv, msg, gp)
+	_, gas, failed, err := st.TransitionDb()
 	if err != nil {
-		return nil, 0, err
+		return msg, 0, false, false, err
 	}
 	if !failed && config.ExpansionsConfig != nil {
 		exp := expansions.NewExpansions(config)
-		exp.ApplyMessage(statedb, &msg)
+		exp.ApplyMessage(statedb, &msg, header.Number)
 	}
+	return msg, gas, failed, st.useTokenGas, nil
+}
 
-	// Update the state with pending changes
-	var root []byte
-	if config.IsByzantium(header.Number) {
-		statedb.Finalise(true)
-	} else {
-		root = statedb.IntermediateRoot(config.IsEIP158(header.Number)).Bytes()
-	}
-	*usedGas += gas
-
-	// Create a new receipt for the transaction, storing the intermediate root and gas used by the tx
-	// based on the eip phase, we're passing whether the root touch-delete accounts.
-	receipt := types.NewReceipt(root, failed, *usedGas)
+// newReceipt creates the receipt of an applied transaction, storing the
+// intermediate root and gas used by the tx. Based on the eip phase, we're
+// passing whether the root touch-delete accounts.
+func newReceipt(root []byte, failed bool, tokenGas bool, cumulativeGasUsed uint64, gas uint64, tx *types.Transaction, msg types.Message, statedb *state.StateDB) *types.Receipt {
+	receipt := types.NewReceipt(root, failed, cumulativeGasUsed)
 	receipt.TxHash = tx.Hash()
 	receipt.GasUsed = gas
+	receipt.TokenGas = tokenGas
 	// if the transaction created a contract, store the creation address in the receipt.
 	if msg.To() == nil {
-		receipt.ContractAddress = crypto.CreateAddress(vmenv.Context.Origin, tx.Nonce())
+		receipt.ContractAddress = crypto.CreateAddress(msg.From(), tx.Nonce())
 	}
 	// Set the receipt logs and create a bloom for filtering
 	receipt.Logs = statedb.GetLogs(tx.Hash())
 	receipt.Bloom = types.CreateBloom(types.Receipts{receipt})
 
-	return receipt, gas, err
+	return receipt
 }
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"github.com/bcos-one/BCOS/core/state"
 	"github.com/bcos-one/BCOS/expansions/management"
+	"github.com/bcos-one/BCOS/expansions/token"
 	"math"
 	"math/big"
 
@@ -127,22 +128,26 @@ func NewStateTransition(evm *vm.EVM, msg Message, gp *GasPool) *StateTransition
 		useTokenGas: false,
 	}
 
-	config := st.evm.ChainConfig().ExpansionsConfig
-	if config != nil && config.ManageSupport && st.token != nil {
+	if st.token != nil {
 		db, ok := st.state.(*state.StateDB)
 		if !ok {
 			return st
 		}
-
-		manageObj := management.NewManageObj(config.ManageStorage, st.msg.From(), db)
-		if manageObj.IsTokenInWhiteList(*st.token) {
-			st.useTokenGas = true
-		}
+		st.useTokenGas = PaysGasInToken(st.evm.ChainConfig().ExpansionsConfig, evm.BlockNumber, db, st.msg.From(), *st.token)
 	}
 
 	return st
 }
 
+// PaysGasInToken reports whether a message sent by from pays its gas in the
+// token, which requires the token to be whitelisted by the management storage.
+func PaysGasInToken(config *params.ExpansionsConfig, number *big.Int, db *state.StateDB, from, token common.Address) bool {
+	if !config.IsManageEnabled(number) {
+		return false
+	}
+	return management.NewManageObj(config.ManageStorage, from, db).IsTokenInWhiteList(token)
+}
+
 // ApplyMessage computes the new state by applying the given message
 // against the old state within the environment.
 //
@@ -176,6 +181,12 @@ func (st *StateTransition) buyGas() error {
 
 	var balance *big.Int
 	if st.token != nil && st.useTokenGas {
+		// Token gas moves the token from the sender to the coinbase, so it is
+		// subject to the same compliance controls as any other token transfer.
+		config := st.evm.ChainConfig().ExpansionsConfig
+		if config.IsTokenEnabled(st.evm.BlockNumber) && !token.NewTokenObject(config.TokenStorage, *st.token, st.state).CanTransfer(st.msg.From(), st.evm.Coinbase) {
+			return vm.ErrTokenFrozen
+		}
 		balance = st.state.GetTokenBalance(st.msg.From(), *st.token)
 	} else {
 		balance = st.state.GetBalance(st.msg.From())
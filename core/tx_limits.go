@@ -0,0 +1,123 @@
+package core
+
+import (
+	"errors"
+	"time"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/metrics"
+)
+
+var (
+	// ErrSenderRateLimited is returned if a remote sender submits transactions
+	// faster than the pool admits from a single account.
+	ErrSenderRateLimited = errors.New("sender rate limit exceeded")
+
+	// ErrPeerRateLimited is returned if a peer relays transactions faster than
+	// the pool admits from a single peer.
+	ErrPeerRateLimited = errors.New("peer rate limit exceeded")
+
+	// ErrGasQuotaExceeded is returned if a transaction would take its sender over
+	// the gas quota assigned to it in the management storage for the current epoch.
+	ErrGasQuotaExceeded = errors.New("gas quota exceeded")
+)
+
+var (
+	// Metrics for the transactions rejected by the admission limits
+	senderLimitedCounter = metrics.NewRegisteredCounter("txpool/limited/sender", nil)
+	peerLimitedCounter   = metrics.NewRegisteredCounter("txpool/limited/peer", nil)
+	quotaLimitedCounter  = metrics.NewRegisteredCounter("txpool/limited/quota", nil)
+)
+
+// tokenBucket is the admission allowance of a single sender or peer.
+type tokenBucket struct {
+	tokens  float64   // Number of transactions that may be admitted right away
+	updated time.Time // Time the tokens were last refilled
+}
+
+// rateLimiter admits transactions per key at a sustained rate, allowing bursts of
+// a limited size. A nil limiter admits everything.
+type rateLimiter struct {
+	rate    float64                 // Number of transactions admitted per second
+	burst   float64                 // Maximum number of transactions admitted at once
+	buckets map[string]*tokenBucket // Allowance of every key seen recently
+}
+
+// newRateLimiter creates a limiter for the given rate and burst, or nil if the
+// rate is unlimited.
+func newRateLimiter(rate float64, burst uint64) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	return &rateLimiter{
+		rate:    rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+	}
+}
+
+// allow reports whether a transaction of the given key may be admitted at the
+// given time, consuming a token if it does.
+func (l *rateLimiter) allow(key string, now time.Time) bool {
+	if l == nil {
+		return true
+	}
+	bucket := l.buckets[key]
+	if bucket == nil {
+		bucket = &tokenBucket{tokens: l.burst, updated: now}
+		l.buckets[key] = bucket
+	}
+	if elapsed := now.Sub(bucket.updated); elapsed > 0 {
+		bucket.tokens += elapsed.Seconds() * l.rate
+		if bucket.tokens > l.burst {
+			bucket.tokens = l.burst
+		}
+		bucket.updated = now
+	}
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// expire drops the buckets that refilled completely by the given time, as they
+// are indistinguishable from fresh ones.
+func (l *rateLimiter) expire(now time.Time) {
+	if l == nil {
+		return
+	}
+	for key, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.updated).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// gasQuotas tracks the gas the senders submitted in the current quota epoch.
+type gasQuotas struct {
+	epoch uint64                    // Quota epoch the used gas belongs to
+	used  map[common.Address]uint64 // Gas of the transactions admitted per sender
+}
+
+// charge accounts the given gas to the sender in the given epoch, starting over
+// if the epoch changed. It reports false without charging anything if the gas
+// exceeds the remaining quota of the sender.
+func (q *gasQuotas) charge(from common.Address, gas, quota, epoch uint64) bool {
+	if q.used == nil || q.epoch != epoch {
+		q.epoch, q.used = epoch, make(map[common.Address]uint64)
+	}
+	used := q.used[from]
+	if used+gas < used || used+gas > quota {
+		return false
+	}
+	q.used[from] = used + gas
+	return true
+}
+
+// refund returns gas charged earlier to the sender.
+func (q *gasQuotas) refund(from common.Address, gas uint64) {
+	if used, ok := q.used[from]; ok && used >= gas {
+		q.used[from] = used - gas
+	}
+}
@@ -261,6 +261,21 @@ func (l *txList) Add(tx *types.Transaction, priceBump uint64) (bool, *types.Tran
 		}
 	}
 	// Otherwise overwrite the old transaction with the current one
+	l.put(tx)
+	return true, old
+}
+
+// Replace inserts a new transaction into the list regardless of its price,
+// returning any previous transaction with the same nonce it overwrote. It is
+// meant for explicit replacements on chains where prices can't be bumped.
+func (l *txList) Replace(tx *types.Transaction) *types.Transaction {
+	old := l.txs.Get(tx.Nonce())
+	l.put(tx)
+	return old
+}
+
+// put inserts a transaction into the list, updating the cost and gas thresholds.
+func (l *txList) put(tx *types.Transaction) {
 	l.txs.Put(tx)
 	if cost := tx.Cost(); l.costcap.Cmp(cost) < 0 {
 		l.costcap = cost
@@ -268,7 +283,6 @@ func (l *txList) Add(tx *types.Transaction, priceBump uint64) (bool, *types.Tran
 	if gas := tx.Gas(); l.gascap < gas {
 		l.gascap = gas
 	}
-	return true, old
 }
 
 // Forward removes all transactions from the list with a nonce lower than the
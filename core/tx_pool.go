@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/bcos-one/BCOS/expansions/management"
+	"github.com/bcos-one/BCOS/expansions/token"
 	"math"
 	"math/big"
 	"sort"
@@ -57,6 +58,11 @@ var (
 	// with a different one without the required price bump.
 	ErrReplaceUnderpriced = errors.New("replacement transaction underpriced")
 
+	// ErrReplaceNotAllowed is returned if a transaction is attempted to be replaced
+	// explicitly on a chain where gas prices aren't fixed to zero, so the price can
+	// be bumped instead.
+	ErrReplaceNotAllowed = errors.New("explicit replacement requires zero gas prices")
+
 	// ErrInsufficientFunds is returned if the total cost of executing a transaction
 	// is higher than the balance of the user's account.
 	ErrInsufficientFunds = errors.New("insufficient funds for gas * price + value")
@@ -83,6 +89,10 @@ var (
 	ErrInvalidGasPriceZero   = errors.New("gas price is 0")
 	ErrInvalidGasPriceTooLow = errors.New("gas price is too low")
 	ErrInvalidGasFeeLow      = errors.New("gasPrice * gas is too low")
+
+	// ErrTokenFrozen is returned if a transaction moves a paused token, or moves a
+	// token out of or into a frozen account.
+	ErrTokenFrozen = token.ErrTokenFrozen
 )
 
 var (
@@ -144,6 +154,12 @@ type TxPoolConfig struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	SenderRate  float64          // Transactions per second admitted from a remote sender (0 = unlimited)
+	SenderBurst uint64           // Number of transactions admitted from a remote sender at once
+	PeerRate    float64          // Transactions per second admitted from a single peer (0 = unlimited)
+	PeerBurst   uint64           // Number of transactions admitted from a single peer at once
+	Exempt      []common.Address // Senders exempt from the rate limits and gas quotas
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -179,6 +195,14 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool price bump", "provided", conf.PriceBump, "updated", DefaultTxPoolConfig.PriceBump)
 		conf.PriceBump = DefaultTxPoolConfig.PriceBump
 	}
+	if conf.SenderRate > 0 && conf.SenderBurst < 1 {
+		log.Warn("Sanitizing invalid txpool sender burst", "provided", conf.SenderBurst, "updated", 1)
+		conf.SenderBurst = 1
+	}
+	if conf.PeerRate > 0 && conf.PeerBurst < 1 {
+		log.Warn("Sanitizing invalid txpool peer burst", "provided", conf.PeerBurst, "updated", 1)
+		conf.PeerBurst = 1
+	}
 	return conf
 }
 
@@ -204,10 +228,16 @@ type TxPool struct {
 	currentState  *state.StateDB      // Current state in the blockchain head
 	pendingState  *state.ManagedState // Pending state tracking virtual nonces
 	currentMaxGas uint64              // Current gas limit for transaction caps
+	pendingNumber *big.Int            // Number of the block pending transactions get included in
 
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *txJournal  // Journal of local transaction to back up to disk
 
+	exempt  *accountSet  // Set of senders exempt from the admission limits
+	senders *rateLimiter // Admission rate limits of the remote senders
+	peers   *rateLimiter // Admission rate limits of the relaying peers
+	quotas  gasQuotas    // Gas submitted by the senders in the current quota epoch
+
 	pending map[common.Address]*txList   // All currently processable transactions
 	queue   map[common.Address]*txList   // Queued but non-processable transactions
 	beats   map[common.Address]time.Time // Last heartbeat from each known account
@@ -237,12 +267,18 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		all:         newTxLookup(),
 		chainHeadCh: make(chan ChainHeadEvent, chainHeadChanSize),
 		gasPrice:    new(big.Int).SetUint64(config.PriceLimit),
+		senders:     newRateLimiter(config.SenderRate, config.SenderBurst),
+		peers:       newRateLimiter(config.PeerRate, config.PeerBurst),
 	}
 	pool.locals = newAccountSet(pool.signer)
 	for _, addr := range config.Locals {
 		log.Info("Setting new local account", "address", addr)
 		pool.locals.add(addr)
 	}
+	pool.exempt = newAccountSet(pool.signer)
+	for _, addr := range config.Exempt {
+		pool.exempt.add(addr)
+	}
 	pool.priced = newTxPricedList(pool.all)
 	pool.reset(nil, chain.CurrentBlock().Header())
 
@@ -334,6 +370,10 @@ func (pool *TxPool) loop() {
 					}
 				}
 			}
+			// Forget the rate limits of senders and peers that went quiet
+			now := time.Now()
+			pool.senders.expire(now)
+			pool.peers.expire(now)
 			pool.mu.Unlock()
 
 			// Handle local transaction journal rotation
@@ -420,6 +460,7 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	pool.currentState = statedb
 	pool.pendingState = state.ManageState(statedb)
 	pool.currentMaxGas = newHead.GasLimit
+	pool.pendingNumber = new(big.Int).Add(newHead.Number, common.Big1)
 
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
@@ -582,6 +623,10 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	if tx.Value().Sign() < 0 {
 		return ErrNegativeValue
 	}
+	// Typed transactions need to be activated, which retires legacy token ones
+	if err := ValidateTxType(pool.chainconfig, tx, pool.pendingNumber); err != nil {
+		return err
+	}
 
 	//log.Trace("Validate transaction chainID", "chainID", tx.ChainId(), "config id", pool.chainconfig.ChainID)
 	//if pool.chainconfig.ChainID.Cmp(tx.ChainId()) != 0 {
@@ -623,7 +668,15 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	config := pool.chainconfig.ExpansionsConfig
 	if tx.Token() != nil && config != nil{
 		manageObj := management.NewManageObj(config.ManageStorage, from, pool.currentState)
-		if config.ManageSupport && manageObj.IsTokenInWhiteList(*tx.Token()) {
+		tokenGas := config.IsManageEnabled(pool.pendingNumber) && manageObj.IsTokenInWhiteList(*tx.Token())
+
+		if config.IsTokenEnabled(pool.pendingNumber) && (tokenGas || tx.Value().Sign() > 0) {
+			tokenObj := token.NewTokenObject(config.TokenStorage, *tx.Token(), pool.currentState)
+			if tokenObj.IsPaused() || tokenObj.IsFrozen(from) || (tx.To() != nil && tokenObj.IsFrozen(*tx.To())) {
+				return ErrTokenFrozen
+			}
+		}
+		if tokenGas {
 			if pool.currentState.GetTokenBalance(from, *tx.Token()).Cmp(tx.Cost()) < 0 {
 				return ErrInsufficientFunds
 			}
@@ -660,6 +713,9 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 // If a newly added transaction is marked as local, its sending account will be
 // whitelisted, preventing any associated transaction from being dropped out of
 // the pool due to pricing constraints.
+//
+// If the sender marked the transaction as an explicit replacement, it overwrites
+// any pooled one with the same nonce without a price bump.
 func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 	// If the transaction is already known, discard it
 	hash := tx.Hash()
@@ -673,6 +729,14 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		invalidTxCounter.Inc(1)
 		return false, err
 	}
+	from, _ := types.Sender(pool.signer, tx) // already validated
+	replace := tx.Replaces()
+	if replace {
+		if err := pool.validateReplace(tx); err != nil {
+			log.Trace("Discarding invalid replacement", "hash", hash, "err", err)
+			return false, err
+		}
+	}
 	// If the transaction pool is full, discard underpriced transactions
 	if uint64(pool.all.Count()) >= pool.config.GlobalSlots+pool.config.GlobalQueue {
 		// If the new transaction is underpriced, don't accept it
@@ -690,10 +754,9 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		}
 	}
 	// If the transaction is replacing an already pending one, do directly
-	from, _ := types.Sender(pool.signer, tx) // already validated
 	if list := pool.pending[from]; list != nil && list.Overlaps(tx) {
 		// Nonce already pending, check if required price bump is met
-		inserted, old := list.Add(tx, pool.config.PriceBump)
+		inserted, old := pool.addToList(list, tx, replace)
 		if !inserted {
 			pendingDiscardCounter.Inc(1)
 			return false, ErrReplaceUnderpriced
@@ -716,7 +779,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 		return old != nil, nil
 	}
 	// New transaction isn't replacing a pending one, push into queue
-	replace, err := pool.enqueueTx(hash, tx)
+	replaced, err := pool.enqueueTx(hash, tx, replace)
 	if err != nil {
 		return false, err
 	}
@@ -730,19 +793,39 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 	pool.journalTx(from, tx)
 
 	log.Trace("Pooled new future transaction", "hash", hash, "from", from, "to", tx.To())
-	return replace, nil
+	return replaced, nil
+}
+
+// validateReplace checks whether a transaction may explicitly replace a pooled
+// one, which is only needed and allowed if all gas prices are zero. Pools that
+// haven't seen the replaced transaction accept the replacement as a plain one.
+func (pool *TxPool) validateReplace(tx *types.Transaction) error {
+	if config := pool.chainconfig.GasFeeConfig; config == nil || !config.IsGaspriceZero {
+		return ErrReplaceNotAllowed
+	}
+	return nil
+}
+
+// addToList inserts a transaction into a pending or queued list. A transaction
+// with the same nonce is overwritten if the price bump is met, or regardless of
+// the price if the transaction explicitly replaces it.
+func (pool *TxPool) addToList(list *txList, tx *types.Transaction, replace bool) (bool, *types.Transaction) {
+	if replace {
+		return true, list.Replace(tx)
+	}
+	return list.Add(tx, pool.config.PriceBump)
 }
 
 // enqueueTx inserts a new transaction into the non-executable transaction queue.
 //
 // Note, this method assumes the pool lock is held!
-func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, error) {
+func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction, replace bool) (bool, error) {
 	// Try to insert the transaction into the future queue
 	from, _ := types.Sender(pool.signer, tx) // already validated
 	if pool.queue[from] == nil {
 		pool.queue[from] = newTxList(false)
 	}
-	inserted, old := pool.queue[from].Add(tx, pool.config.PriceBump)
+	inserted, old := pool.addToList(pool.queue[from], tx, replace)
 	if !inserted {
 		// An older transaction was better, discard this
 		queuedDiscardCounter.Inc(1)
@@ -823,7 +906,7 @@ func (pool *TxPool) AddLocal(tx *types.Transaction) error {
 // sender is not among the locally tracked ones, full pricing constraints will
 // apply.
 func (pool *TxPool) AddRemote(tx *types.Transaction) error {
-	return pool.addTx(tx, false)
+	return pool.addRemotes("", []*types.Transaction{tx})[0]
 }
 
 // AddLocals enqueues a batch of transactions into the pool if they are valid,
@@ -837,7 +920,97 @@ func (pool *TxPool) AddLocals(txs []*types.Transaction) []error {
 // If the senders are not among the locally tracked ones, full pricing constraints
 // will apply.
 func (pool *TxPool) AddRemotes(txs []*types.Transaction) []error {
-	return pool.addTxs(txs, false)
+	return pool.addRemotes("", txs)
+}
+
+// AddRemotesFrom enqueues a batch of transactions relayed by the given peer into
+// the pool if they are valid. Besides the limits of remote transactions, the rate
+// limit of the peer applies.
+func (pool *TxPool) AddRemotesFrom(peer string, txs []*types.Transaction) []error {
+	return pool.addRemotes(peer, txs)
+}
+
+// addRemotes enqueues a batch of remote transactions into the pool if they are
+// valid and within the admission limits of their senders and relaying peer.
+func (pool *TxPool) addRemotes(peer string, txs []*types.Transaction) []error {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	var (
+		errs     = make([]error, len(txs))
+		admitted = make([]*types.Transaction, 0, len(txs))
+		indexes  = make([]int, 0, len(txs))
+		charged  = make([]bool, 0, len(txs))
+	)
+	for i, tx := range txs {
+		var charge bool
+		if charge, errs[i] = pool.admit(peer, tx); errs[i] == nil {
+			admitted = append(admitted, tx)
+			indexes = append(indexes, i)
+			charged = append(charged, charge)
+		}
+	}
+	for i, err := range pool.addTxsLocked(admitted, false) {
+		errs[indexes[i]] = err
+		if err != nil && charged[i] {
+			from, _ := types.Sender(pool.signer, admitted[i])
+			pool.quotas.refund(from, admitted[i].Gas())
+		}
+	}
+	return errs
+}
+
+// admit checks a remote transaction against the admission limits, consuming the
+// allowance of its sender and relaying peer. It returns whether the gas of the
+// transaction was charged to the gas quota of the sender, to be refunded if the
+// pool rejects the transaction after all.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) admit(peer string, tx *types.Transaction) (bool, error) {
+	// Known transactions are rejected by the pool anyway, don't let transactions
+	// relayed by multiple peers consume the allowance of their sender
+	if pool.all.Get(tx.Hash()) != nil {
+		return false, nil
+	}
+	now := time.Now()
+	if peer != "" && !pool.peers.allow(peer, now) {
+		peerLimitedCounter.Inc(1)
+		return false, ErrPeerRateLimited
+	}
+	// Invalid senders are rejected by the validation, locals are never limited
+	from, err := types.Sender(pool.signer, tx)
+	if err != nil || pool.locals.contains(from) || pool.exempt.contains(from) {
+		return false, nil
+	}
+	if !pool.senders.allow(string(from.Bytes()), now) {
+		senderLimitedCounter.Inc(1)
+		return false, ErrSenderRateLimited
+	}
+	quota, epoch := pool.gasQuota(from)
+	if quota == 0 {
+		return false, nil
+	}
+	if !pool.quotas.charge(from, tx.Gas(), quota, epoch) {
+		quotaLimitedCounter.Inc(1)
+		return false, ErrGasQuotaExceeded
+	}
+	return true, nil
+}
+
+// gasQuota returns the gas quota of the sender recorded in the management storage
+// along with the current quota epoch, or zero if the sender is not limited.
+func (pool *TxPool) gasQuota(from common.Address) (uint64, uint64) {
+	config := pool.chainconfig.ExpansionsConfig
+	if config == nil || !config.IsGasQuotaEnabled(pool.pendingNumber) {
+		return 0, 0
+	}
+	manageObj := management.NewManageObj(config.ManageStorage, from, pool.currentState)
+
+	length := manageObj.QuotaEpoch()
+	if length == 0 {
+		return 0, 0
+	}
+	return manageObj.GasQuota(from), pool.pendingNumber.Uint64() / length
 }
 
 // addTx enqueues a single transaction into the pool if it is valid.
@@ -942,7 +1115,7 @@ func (pool *TxPool) removeTx(hash common.Hash, outofbound bool) {
 			}
 			// Postpone any invalidated transactions
 			for _, tx := range invalids {
-				pool.enqueueTx(tx.Hash(), tx)
+				pool.enqueueTx(tx.Hash(), tx, false)
 			}
 			// Update the account nonce if needed
 			if nonce := tx.Nonce(); pool.pendingState.GetNonce(addr) > nonce {
@@ -1181,14 +1354,14 @@ func (pool *TxPool) demoteUnexecutables() {
 		for _, tx := range invalids {
 			hash := tx.Hash()
 			log.Trace("Demoting pending transaction", "hash", hash)
-			pool.enqueueTx(hash, tx)
+			pool.enqueueTx(hash, tx, false)
 		}
 		// If there's a gap in front, alert (should never happen) and postpone all transactions
 		if list.Len() > 0 && list.txs.Get(nonce) == nil {
 			for _, tx := range list.Cap(0) {
 				hash := tx.Hash()
 				log.Error("Demoting invalidated transaction", "hash", hash)
-				pool.enqueueTx(hash, tx)
+				pool.enqueueTx(hash, tx, false)
 			}
 		}
 		// Delete the entire queue entry if it became empty.
@@ -32,6 +32,7 @@ import (
 	"github.com/bcos-one/BCOS/crypto"
 	"github.com/bcos-one/BCOS/ethdb"
 	"github.com/bcos-one/BCOS/event"
+	"github.com/bcos-one/BCOS/expansions/management"
 	"github.com/bcos-one/BCOS/params"
 )
 
@@ -73,7 +74,15 @@ func transaction(nonce uint64, gaslimit uint64, key *ecdsa.PrivateKey) *types.Tr
 }
 
 func pricedTransaction(nonce uint64, gaslimit uint64, gasprice *big.Int, key *ecdsa.PrivateKey) *types.Transaction {
-	tx, _ := types.SignTx(types.NewTransaction(nonce, common.Address{}, big.NewInt(100), gaslimit, gasprice, nil), types.HomesteadSigner{}, key)
+	tx, _ := types.SignTx(types.NewTransaction(nonce, common.Address{}, nil, big.NewInt(100), gaslimit, gasprice, nil), types.HomesteadSigner{}, key)
+	return tx
+}
+
+// replacementTransaction creates a transaction marked as explicit replacement by
+// its sender.
+func replacementTransaction(nonce uint64, gaslimit uint64, gasprice *big.Int, key *ecdsa.PrivateKey) *types.Transaction {
+	tx := types.NewTransaction(nonce, common.Address{}, nil, big.NewInt(100), gaslimit, gasprice, nil).AsReplacement()
+	tx, _ = types.SignTx(tx, types.NewEIP155Signer(params.TestChainConfig.ChainID), key)
 	return tx
 }
 
@@ -126,7 +135,7 @@ func validateEvents(events chan NewTxsEvent, count int) error {
 		case ev := <-events:
 			received = append(received, ev.Txs...)
 		case <-time.After(time.Second):
-			return fmt.Errorf("event #%d not fired", received)
+			return fmt.Errorf("event #%d not fired", len(received))
 		}
 	}
 	if len(received) > count {
@@ -269,7 +278,7 @@ func TestTransactionQueue(t *testing.T) {
 	from, _ := deriveSender(tx)
 	pool.currentState.AddBalance(from, big.NewInt(1000))
 	pool.lockedReset(nil, nil)
-	pool.enqueueTx(tx.Hash(), tx)
+	pool.enqueueTx(tx.Hash(), tx, false)
 
 	pool.promoteExecutables([]common.Address{from})
 	if len(pool.pending) != 1 {
@@ -279,7 +288,7 @@ func TestTransactionQueue(t *testing.T) {
 	tx = transaction(1, 100, key)
 	from, _ = deriveSender(tx)
 	pool.currentState.SetNonce(from, 2)
-	pool.enqueueTx(tx.Hash(), tx)
+	pool.enqueueTx(tx.Hash(), tx, false)
 	pool.promoteExecutables([]common.Address{from})
 	if _, ok := pool.pending[from].txs.items[tx.Nonce()]; ok {
 		t.Error("expected transaction to be in tx pool")
@@ -299,9 +308,9 @@ func TestTransactionQueue(t *testing.T) {
 	pool.currentState.AddBalance(from, big.NewInt(1000))
 	pool.lockedReset(nil, nil)
 
-	pool.enqueueTx(tx1.Hash(), tx1)
-	pool.enqueueTx(tx2.Hash(), tx2)
-	pool.enqueueTx(tx3.Hash(), tx3)
+	pool.enqueueTx(tx1.Hash(), tx1, false)
+	pool.enqueueTx(tx2.Hash(), tx2, false)
+	pool.enqueueTx(tx3.Hash(), tx3, false)
 
 	pool.promoteExecutables([]common.Address{from})
 
@@ -319,7 +328,7 @@ func TestTransactionNegativeValue(t *testing.T) {
 	pool, key := setupTxPool()
 	defer pool.Stop()
 
-	tx, _ := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(-1), 100, big.NewInt(1), nil), types.HomesteadSigner{}, key)
+	tx, _ := types.SignTx(types.NewTransaction(0, common.Address{}, nil, big.NewInt(-1), 100, big.NewInt(1), nil), types.HomesteadSigner{}, key)
 	from, _ := deriveSender(tx)
 	pool.currentState.AddBalance(from, big.NewInt(1))
 	if err := pool.AddRemote(tx); err != ErrNegativeValue {
@@ -373,9 +382,9 @@ func TestTransactionDoubleNonce(t *testing.T) {
 	resetState()
 
 	signer := types.HomesteadSigner{}
-	tx1, _ := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(100), 100000, big.NewInt(1), nil), signer, key)
-	tx2, _ := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(100), 1000000, big.NewInt(2), nil), signer, key)
-	tx3, _ := types.SignTx(types.NewTransaction(0, common.Address{}, big.NewInt(100), 1000000, big.NewInt(1), nil), signer, key)
+	tx1, _ := types.SignTx(types.NewTransaction(0, common.Address{}, nil, big.NewInt(100), 100000, big.NewInt(1), nil), signer, key)
+	tx2, _ := types.SignTx(types.NewTransaction(0, common.Address{}, nil, big.NewInt(100), 1000000, big.NewInt(2), nil), signer, key)
+	tx3, _ := types.SignTx(types.NewTransaction(0, common.Address{}, nil, big.NewInt(100), 1000000, big.NewInt(1), nil), signer, key)
 
 	// Add the first two transaction, ensure higher priced stays only
 	if replace, err := pool.add(tx1, false); err != nil || replace {
@@ -477,9 +486,9 @@ func TestTransactionDropping(t *testing.T) {
 	pool.promoteTx(account, tx0.Hash(), tx0)
 	pool.promoteTx(account, tx1.Hash(), tx1)
 	pool.promoteTx(account, tx2.Hash(), tx2)
-	pool.enqueueTx(tx10.Hash(), tx10)
-	pool.enqueueTx(tx11.Hash(), tx11)
-	pool.enqueueTx(tx12.Hash(), tx12)
+	pool.enqueueTx(tx10.Hash(), tx10, false)
+	pool.enqueueTx(tx11.Hash(), tx11, false)
+	pool.enqueueTx(tx12.Hash(), tx12, false)
 
 	// Check that pre and post validations leave the pool as is
 	if pool.pending[account].Len() != 3 {
@@ -1567,6 +1576,87 @@ func TestTransactionReplacement(t *testing.T) {
 	}
 }
 
+// Tests that on chains with zero gas prices pooled transactions can be replaced
+// explicitly, and that explicit replacements are rejected on any other chain.
+func TestTransactionZeroPriceReplacement(t *testing.T) {
+	t.Parallel()
+
+	// Ensure explicit replacements are refused if prices can be bumped
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	if err := pool.AddLocal(pricedTransaction(0, 100000, big.NewInt(1), key)); err != nil {
+		t.Fatalf("failed to add priced transaction: %v", err)
+	}
+	if err := pool.AddLocal(replacementTransaction(0, 100001, big.NewInt(1), key)); err != ErrReplaceNotAllowed {
+		t.Fatalf("priced replacement error mismatch: have %v, want %v", err, ErrReplaceNotAllowed)
+	}
+	// Create a zero gas price pool to test the explicit replacements with
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := *params.TestChainConfig
+	config.GasFeeConfig = &params.GasFeeConfig{IsGaspriceZero: true}
+
+	pool = NewTxPool(testTxPoolConfig, &config, blockchain)
+	defer pool.Stop()
+
+	events := make(chan NewTxsEvent, 32)
+	sub := pool.txFeed.Subscribe(events)
+	defer sub.Unsubscribe()
+
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	// Add a pending and a queued transaction, neither replaceable by price
+	pending, queued := pricedTransaction(0, 100000, common.Big0, key), pricedTransaction(2, 100000, common.Big0, key)
+	if err := pool.AddLocal(pending); err != nil {
+		t.Fatalf("failed to add pending transaction: %v", err)
+	}
+	if err := pool.AddLocal(queued); err != nil {
+		t.Fatalf("failed to add queued transaction: %v", err)
+	}
+	if err := pool.AddLocal(pricedTransaction(0, 100001, common.Big0, key)); err != ErrReplaceUnderpriced {
+		t.Fatalf("implicit pending replacement error mismatch: have %v, want %v", err, ErrReplaceUnderpriced)
+	}
+	if err := validateEvents(events, 1); err != nil {
+		t.Fatalf("original event firing failed: %v", err)
+	}
+	// Replace both explicitly and ensure the originals are gone, remote pools
+	// honour the replacement marker signed by the sender too
+	replacements := []*types.Transaction{
+		replacementTransaction(0, 100001, common.Big0, key),
+		replacementTransaction(2, 100001, common.Big0, key),
+	}
+	if err := pool.AddLocal(replacements[0]); err != nil {
+		t.Fatalf("failed to replace local transaction: %v", err)
+	}
+	if err := pool.AddRemote(replacements[1]); err != nil {
+		t.Fatalf("failed to replace remote transaction: %v", err)
+	}
+	if err := validateEvents(events, 1); err != nil {
+		t.Fatalf("replacement event firing failed: %v", err)
+	}
+	for i, tx := range []*types.Transaction{pending, queued} {
+		if pool.Get(tx.Hash()) != nil {
+			t.Errorf("original transaction %d still pooled", i)
+		}
+		if pool.Get(replacements[i].Hash()) == nil {
+			t.Errorf("replacement transaction %d not pooled", i)
+		}
+	}
+	// Ensure replacements of unknown nonces are pooled as plain transactions
+	if err := pool.AddRemote(replacementTransaction(1, 100000, common.Big0, key)); err != nil {
+		t.Fatalf("failed to add replacement of unknown transaction: %v", err)
+	}
+	if pending, queued := pool.Stats(); pending != 3 || queued != 0 {
+		t.Fatalf("pooled transactions mismatched: have %d/%d, want %d/%d", pending, queued, 3, 0)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // Tests that local transactions are journaled to disk, but remote transactions
 // get discarded between restarts.
 func TestTransactionJournaling(t *testing.T)         { testTransactionJournaling(t, false) }
@@ -1680,6 +1770,70 @@ func testTransactionJournaling(t *testing.T, nolocals bool) {
 	pool.Stop()
 }
 
+// Tests that explicit replacements survive restarts, overwriting the journaled
+// transactions they replaced.
+func TestTransactionJournalingReplacement(t *testing.T) {
+	t.Parallel()
+
+	// Create a temporary file for the journal
+	file, err := ioutil.TempFile("", "")
+	if err != nil {
+		t.Fatalf("failed to create temporary journal: %v", err)
+	}
+	journal := file.Name()
+	defer os.Remove(journal)
+
+	// Clean up the temporary file, we only need the path for now
+	file.Close()
+	os.Remove(journal)
+
+	// Create the original zero gas price pool and replace a local transaction
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.Journal = journal
+
+	chainconfig := *params.TestChainConfig
+	chainconfig.GasFeeConfig = &params.GasFeeConfig{IsGaspriceZero: true}
+
+	pool := NewTxPool(config, &chainconfig, blockchain)
+
+	key, _ := crypto.GenerateKey()
+	statedb.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+	pool.lockedReset(nil, nil)
+
+	original, replacement := pricedTransaction(0, 100000, common.Big0, key), replacementTransaction(0, 100001, common.Big0, key)
+	if err := pool.AddLocal(original); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	if err := pool.AddLocal(pricedTransaction(1, 100000, common.Big0, key)); err != nil {
+		t.Fatalf("failed to add local transaction: %v", err)
+	}
+	if err := pool.AddLocal(replacement); err != nil {
+		t.Fatalf("failed to replace local transaction: %v", err)
+	}
+	pool.Stop()
+
+	// Restart the pool and ensure the replacement is loaded in place of the original
+	blockchain = &testBlockChain{statedb, 1000000, new(event.Feed)}
+	pool = NewTxPool(config, &chainconfig, blockchain)
+	defer pool.Stop()
+
+	if pending, queued := pool.Stats(); pending != 2 || queued != 0 {
+		t.Fatalf("pooled transactions mismatched: have %d/%d, want %d/%d", pending, queued, 2, 0)
+	}
+	if pool.Get(original.Hash()) != nil {
+		t.Errorf("replaced transaction reloaded")
+	}
+	if pool.Get(replacement.Hash()) == nil {
+		t.Errorf("replacement transaction not reloaded")
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // TestTransactionStatusCheck tests that the pool can correctly retrieve the
 // pending status of individual transactions.
 func TestTransactionStatusCheck(t *testing.T) {
@@ -1736,6 +1890,163 @@ func TestTransactionStatusCheck(t *testing.T) {
 	}
 }
 
+// Tests that remote senders and relaying peers are rate limited, while local and
+// exempt senders as well as already known transactions are not.
+func TestTransactionRateLimiting(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	keys := make([]*ecdsa.PrivateKey, 8)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+		statedb.AddBalance(crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000))
+	}
+	config := testTxPoolConfig
+	config.SenderRate, config.SenderBurst = 0.001, 2
+	config.PeerRate, config.PeerBurst = 0.001, 3
+	config.Exempt = []common.Address{crypto.PubkeyToAddress(keys[1].PublicKey)}
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	// A remote sender may submit a burst of transactions, resubmissions of known
+	// ones are not counted
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		if err := pool.AddRemote(transaction(nonce, 100000, keys[0])); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", nonce, err)
+		}
+	}
+	if err := pool.AddRemote(transaction(0, 100000, keys[0])); err == nil || err == ErrSenderRateLimited {
+		t.Fatalf("known transaction error mismatch: have %v", err)
+	}
+	if err := pool.AddRemote(transaction(2, 100000, keys[0])); err != ErrSenderRateLimited {
+		t.Fatalf("rate limit error mismatch: have %v, want %v", err, ErrSenderRateLimited)
+	}
+	// Exempt and local senders are not limited
+	for nonce := uint64(0); nonce < 5; nonce++ {
+		if err := pool.AddRemote(transaction(nonce, 100000, keys[1])); err != nil {
+			t.Fatalf("failed to add exempt transaction %d: %v", nonce, err)
+		}
+		if err := pool.AddLocal(transaction(nonce, 100000, keys[2])); err != nil {
+			t.Fatalf("failed to add local transaction %d: %v", nonce, err)
+		}
+	}
+	// A peer may relay a burst of transactions, regardless of their senders
+	txs := make([]*types.Transaction, 0, 5)
+	for _, key := range keys[3:] {
+		txs = append(txs, transaction(0, 100000, key))
+	}
+	errs := pool.AddRemotesFrom("peer", txs)
+	for i, err := range errs {
+		if i < 3 && err != nil {
+			t.Errorf("failed to add relayed transaction %d: %v", i, err)
+		}
+		if i >= 3 && err != ErrPeerRateLimited {
+			t.Errorf("relayed transaction %d error mismatch: have %v, want %v", i, err, ErrPeerRateLimited)
+		}
+	}
+	if errs := pool.AddRemotesFrom("other", txs[3:]); errs[0] != nil || errs[1] != nil {
+		t.Fatalf("failed to add transactions relayed by another peer: %v", errs)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that the gas quotas recorded in the management storage limit the gas a
+// sender may submit per epoch.
+func TestTransactionGasQuotas(t *testing.T) {
+	t.Parallel()
+
+	var (
+		admin   = common.HexToAddress("0x01")
+		storage = common.HexToAddress("0x2000")
+	)
+	chainconfig := *params.TestChainConfig
+	chainconfig.ExpansionsConfig = &params.ExpansionsConfig{ManageSupport: true, ManageStorage: storage, GasQuotaBlock: common.Big1}
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	limited, _ := crypto.GenerateKey()
+	unlimited, _ := crypto.GenerateKey()
+	statedb.AddBalance(crypto.PubkeyToAddress(limited.PublicKey), big.NewInt(1000000))
+	statedb.AddBalance(crypto.PubkeyToAddress(unlimited.PublicKey), big.NewInt(1000000))
+
+	// Assign a quota of two transactions per 10 blocks to the limited sender
+	management.SetManager(storage, admin, statedb)
+	manage := func(input []byte) {
+		msg := types.NewMessage(admin, &storage, 0, new(big.Int), 100000, new(big.Int), input, false)
+		if err := management.ApplyManageOp(chainconfig.ExpansionsConfig, statedb, &msg, common.Big1); err != nil {
+			t.Fatalf("failed to apply management operation: %v", err)
+		}
+	}
+	addr := crypto.PubkeyToAddress(limited.PublicKey)
+	manage(append(append(common.FromHex("0xd14254c5"), addr.Hash().Bytes()...), common.BigToHash(big.NewInt(200000)).Bytes()...))
+	manage(append(common.FromHex("0xf9962d93"), common.BigToHash(big.NewInt(10)).Bytes()...))
+
+	pool := NewTxPool(testTxPoolConfig, &chainconfig, blockchain)
+	defer pool.Stop()
+
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		if err := pool.AddRemote(transaction(nonce, 100000, limited)); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", nonce, err)
+		}
+	}
+	if err := pool.AddRemote(transaction(2, 100000, limited)); err != ErrGasQuotaExceeded {
+		t.Fatalf("quota error mismatch: have %v, want %v", err, ErrGasQuotaExceeded)
+	}
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		if err := pool.AddRemote(transaction(nonce, 100000, unlimited)); err != nil {
+			t.Fatalf("failed to add unlimited transaction %d: %v", nonce, err)
+		}
+	}
+	// The quota is available again in the next epoch
+	pool.mu.Lock()
+	pool.pendingNumber = big.NewInt(10)
+	pool.mu.Unlock()
+
+	if err := pool.AddRemote(transaction(2, 100000, limited)); err != nil {
+		t.Fatalf("failed to add transaction in the next epoch: %v", err)
+	}
+}
+
+// Tests that legacy token transactions are accepted on chains without typed token
+// transactions, and only rejected once those are activated.
+func TestTransactionLegacyToken(t *testing.T) {
+	t.Parallel()
+
+	id := common.HexToAddress("0x3000")
+	for _, block := range []*big.Int{nil, common.Big0} {
+		chainconfig := *params.TestChainConfig
+		chainconfig.TokenTxBlock = block
+		chainconfig.ExpansionsConfig = &params.ExpansionsConfig{TokenSupport: true, TokenStorage: common.HexToAddress("0x1000")}
+
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+		blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+		key, _ := crypto.GenerateKey()
+		statedb.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+		statedb.AddTokenBalance(crypto.PubkeyToAddress(key.PublicKey), id, big.NewInt(1000))
+
+		pool := NewTxPool(testTxPoolConfig, &chainconfig, blockchain)
+
+		tx := types.NewTransaction(0, common.Address{1}, &id, big.NewInt(100), 100000, big.NewInt(1), nil).AsLegacy()
+		tx, _ = types.SignTx(tx, types.HomesteadSigner{}, key)
+
+		want := error(nil)
+		if block != nil {
+			want = types.ErrLegacyTokenTx
+		}
+		if err := pool.AddRemote(tx); err != want {
+			t.Errorf("token tx block %v: legacy token transaction error mismatch: have %v, want %v", block, err, want)
+		}
+		pool.Stop()
+	}
+}
+
 // Benchmarks the speed of validating the contents of the pending queue of the
 // transaction pool.
 func BenchmarkPendingDemotion100(b *testing.B)   { benchmarkPendingDemotion(b, 100) }
@@ -1777,7 +2088,7 @@ func benchmarkFuturePromotion(b *testing.B, size int) {
 
 	for i := 0; i < size; i++ {
 		tx := transaction(uint64(1+i), 100000, key)
-		pool.enqueueTx(tx.Hash(), tx)
+		pool.enqueueTx(tx.Hash(), tx, false)
 	}
 	// Benchmark the speed of pool validation
 	b.ResetTimer()
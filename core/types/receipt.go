@@ -55,6 +55,7 @@ type Receipt struct {
 	TxHash          common.Hash    `json:"transactionHash" gencodec:"required"`
 	ContractAddress common.Address `json:"contractAddress"`
 	GasUsed         uint64         `json:"gasUsed" gencodec:"required"`
+	TokenGas        bool           `json:"tokenGas"`
 }
 
 type receiptMarshaling struct {
@@ -80,6 +81,7 @@ type receiptStorageRLP struct {
 	ContractAddress   common.Address
 	Logs              []*LogForStorage
 	GasUsed           uint64
+	TokenGas          []bool `rlp:"tail"` // Only present if the gas was paid in tokens
 }
 
 // NewReceipt creates a barebone transaction receipt, copying the init fields.
@@ -168,6 +170,9 @@ func (r *ReceiptForStorage) EncodeRLP(w io.Writer) error {
 	for i, log := range r.Logs {
 		enc.Logs[i] = (*LogForStorage)(log)
 	}
+	if r.TokenGas {
+		enc.TokenGas = []bool{true}
+	}
 	return rlp.Encode(w, enc)
 }
 
@@ -189,6 +194,7 @@ func (r *ReceiptForStorage) DecodeRLP(s *rlp.Stream) error {
 	}
 	// Assign the implementation fields
 	r.TxHash, r.ContractAddress, r.GasUsed = dec.TxHash, dec.ContractAddress, dec.GasUsed
+	r.TokenGas = len(dec.TokenGas) > 0 && dec.TokenGas[0]
 	return nil
 }
 
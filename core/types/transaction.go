@@ -17,11 +17,14 @@
 package types
 
 import (
+	"bytes"
 	"container/heap"
 	"errors"
 	"io"
 	"math/big"
+	"sort"
 	"sync/atomic"
+	"time"
 
 	"github.com/bcos-one/BCOS/common"
 	"github.com/bcos-one/BCOS/common/hexutil"
@@ -32,45 +35,38 @@ import (
 //go:generate gencodec -type txdata -field-override txdataMarshaling -out gen_tx_json.go
 
 var (
-	ErrInvalidSig = errors.New("invalid transaction v, r, s values")
+	ErrInvalidSig         = errors.New("invalid transaction v, r, s values")
+	ErrTxTypeNotSupported = errors.New("transaction type not supported")
+	ErrLegacyTokenTx      = errors.New("legacy transaction carries a token")
+	errEmptyTypedTx       = errors.New("empty typed transaction bytes")
+)
+
+// Transaction types of the envelope. Legacy transactions are encoded as a bare
+// RLP list, every other type as its type byte followed by the RLP encoded payload.
+// Signers cover the type byte of typed transactions, so a transaction cannot be
+// reinterpreted as a different kind.
+//
+// Replacement transactions share the payload of token transactions, the type
+// marks them as explicitly replacing the pooled transaction of their sender with
+// the same nonce regardless of the price bump.
+const (
+	LegacyTxType = iota
+	TokenTxType
+	ReplaceTxType
 )
 
 type Transaction struct {
 	data txdata
+	time time.Time // Time the transaction was first seen locally
+
 	// caches
 	hash atomic.Value
 	size atomic.Value
 	from atomic.Value
 }
 
-type TransactionWithoutToken struct {
-	data txdataWithoutToken
-}
-
-type txdataWithoutToken struct {
-	AccountNonce uint64          `json:"nonce"    gencodec:"required"`
-	Price        *big.Int        `json:"gasPrice" gencodec:"required"`
-	GasLimit     uint64          `json:"gas"      gencodec:"required"`
-	Recipient    *common.Address `json:"to"       rlp:"nil"` // nil means contract creation
-	Amount       *big.Int        `json:"value"    gencodec:"required"`
-	Payload      []byte          `json:"input"    gencodec:"required"`
-
-	// Signature values
-	V *big.Int `json:"v" gencodec:"required"`
-	R *big.Int `json:"r" gencodec:"required"`
-	S *big.Int `json:"s" gencodec:"required"`
-
-	// This is only used when marshaling to JSON.
-	Hash *common.Hash `json:"hash" rlp:"-"`
-}
-
-func (tx *TransactionWithoutToken) DecodeRLP(s *rlp.Stream) error {
-	err := s.Decode(&tx.data)
-
-	return err
-}
-
 type txdata struct {
+	Type         uint8           `json:"type"     rlp:"-"`
 	AccountNonce uint64          `json:"nonce"    gencodec:"required"`
 	Price        *big.Int        `json:"gasPrice" gencodec:"required"`
 	GasLimit     uint64          `json:"gas"      gencodec:"required"`
@@ -88,7 +84,20 @@ type txdata struct {
 	Hash *common.Hash `json:"hash" rlp:"-"`
 }
 
+// ethTxdata is the payload of legacy transactions created by Ethereum tooling,
+// which lack the token field.
+type ethTxdata struct {
+	AccountNonce uint64
+	Price        *big.Int
+	GasLimit     uint64
+	Recipient    *common.Address `rlp:"nil"`
+	Amount       *big.Int
+	Payload      []byte
+	V, R, S      *big.Int
+}
+
 type txdataMarshaling struct {
+	Type         hexutil.Uint64
 	AccountNonce hexutil.Uint64
 	Price        *hexutil.Big
 	GasLimit     hexutil.Uint64
@@ -108,23 +117,6 @@ func NewContractCreation(nonce uint64, token *common.Address, amount *big.Int, g
 	return newTransaction(nonce, nil, token, amount, gasLimit, gasPrice, data)
 }
 
-func NewTransactionCompatible(tx *TransactionWithoutToken) *Transaction {
-	return &Transaction{
-		data: txdata{
-			AccountNonce: tx.data.AccountNonce,
-			Price:        tx.data.Price,
-			GasLimit:     tx.data.GasLimit,
-			Recipient:    tx.data.Recipient,
-			Token:        nil,
-			Amount:       tx.data.Amount,
-			Payload:      tx.data.Payload,
-			V:            tx.data.V,
-			R:            tx.data.R,
-			S:            tx.data.S,
-		},
-	}
-}
-
 func newTransaction(nonce uint64, to *common.Address, token *common.Address, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte) *Transaction {
 	if len(data) > 0 {
 		data = common.CopyBytes(data)
@@ -148,8 +140,11 @@ func newTransaction(nonce uint64, to *common.Address, token *common.Address, amo
 	if gasPrice != nil {
 		d.Price.Set(gasPrice)
 	}
+	if token != nil {
+		d.Type = TokenTxType
+	}
 
-	return &Transaction{data: d}
+	return &Transaction{data: d, time: time.Now()}
 }
 
 // ChainId returns which chain id this transaction was signed for (if at all)
@@ -171,20 +166,156 @@ func isProtectedV(V *big.Int) bool {
 	return true
 }
 
-// EncodeRLP implements rlp.Encoder
+// Type returns the envelope type of the transaction.
+func (tx *Transaction) Type() uint8 {
+	return tx.data.Type
+}
+
+// Replaces returns whether the sender marked the transaction as an explicit
+// replacement of the pooled one with the same nonce.
+func (tx *Transaction) Replaces() bool {
+	return tx.data.Type == ReplaceTxType
+}
+
+// AsReplacement returns an unsigned copy of the transaction marked as explicit
+// replacement. The marker is covered by the signature, so pools across the
+// network can honour it.
+func (tx *Transaction) AsReplacement() *Transaction {
+	cpy := &Transaction{data: tx.data, time: tx.time}
+	cpy.data.Type = ReplaceTxType
+	cpy.data.V, cpy.data.R, cpy.data.S = new(big.Int), new(big.Int), new(big.Int)
+	return cpy
+}
+
+// AsLegacy returns an unsigned copy of the transaction in the legacy envelope, as
+// token transactions are sent before the typed token transactions are activated.
+func (tx *Transaction) AsLegacy() *Transaction {
+	cpy := &Transaction{data: tx.data, time: tx.time}
+	cpy.data.Type = LegacyTxType
+	cpy.data.V, cpy.data.R, cpy.data.S = new(big.Int), new(big.Int), new(big.Int)
+	return cpy
+}
+
+// LegacyToken returns whether the transaction is a legacy one carrying a token,
+// which its signature does not cover.
+func (tx *Transaction) LegacyToken() bool {
+	return tx.data.Type == LegacyTxType && tx.data.Token != nil
+}
+
+// EncodeRLP implements rlp.Encoder. Typed transactions are embedded into RLP
+// structures as a string holding their envelope.
 func (tx *Transaction) EncodeRLP(w io.Writer) error {
-	return rlp.Encode(w, &tx.data)
+	if tx.Type() == LegacyTxType {
+		return rlp.Encode(w, &tx.data)
+	}
+	enc, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return rlp.Encode(w, enc)
 }
 
 // DecodeRLP implements rlp.Decoder
 func (tx *Transaction) DecodeRLP(s *rlp.Stream) error {
-	_, size, _ := s.Kind()
-	err := s.Decode(&tx.data)
-	if err == nil {
-		tx.size.Store(common.StorageSize(rlp.ListSize(size)))
+	kind, _, err := s.Kind()
+	if err != nil {
+		return err
+	}
+	if kind == rlp.List {
+		raw, err := s.Raw()
+		if err != nil {
+			return err
+		}
+		return tx.decodeLegacy(raw)
+	}
+	enc, err := s.Bytes()
+	if err != nil {
+		return err
+	}
+	return tx.decodeTyped(enc)
+}
+
+// MarshalBinary returns the canonical envelope encoding of the transaction, as
+// used by raw transaction RPCs and signed transaction dumps.
+func (tx *Transaction) MarshalBinary() ([]byte, error) {
+	payload, err := rlp.EncodeToBytes(&tx.data)
+	if err != nil || tx.Type() == LegacyTxType {
+		return payload, err
+	}
+	return append([]byte{tx.Type()}, payload...), nil
+}
+
+// UnmarshalBinary decodes the canonical envelope encoding of a transaction.
+// Legacy encodings carrying a token are decoded as well, it is up to the
+// transaction validation rules to reject them once typed token transactions
+// are activated.
+func (tx *Transaction) UnmarshalBinary(b []byte) error {
+	if len(b) > 0 && b[0] > 0x7f {
+		return tx.decodeLegacy(b)
+	}
+	return tx.decodeTyped(b)
+}
+
+// decodeLegacy decodes a legacy transaction list, which has the token field
+// unless it was created by Ethereum tooling. Blocks from before the typed token
+// transactions may still carry tokens in legacy transactions, so they are decoded
+// here and rejected by the transaction validation rules instead.
+func (tx *Transaction) decodeLegacy(b []byte) error {
+	content, _, err := rlp.SplitList(b)
+	if err != nil {
+		return err
+	}
+	fields, err := rlp.CountValues(content)
+	if err != nil {
+		return err
+	}
+	var data txdata
+	if fields == 9 {
+		var eth ethTxdata
+		if err := rlp.DecodeBytes(b, &eth); err != nil {
+			return err
+		}
+		data = txdata{
+			AccountNonce: eth.AccountNonce,
+			Price:        eth.Price,
+			GasLimit:     eth.GasLimit,
+			Recipient:    eth.Recipient,
+			Amount:       eth.Amount,
+			Payload:      eth.Payload,
+			V:            eth.V,
+			R:            eth.R,
+			S:            eth.S,
+		}
+	} else if err := rlp.DecodeBytes(b, &data); err != nil {
+		return err
+	}
+	tx.setDecoded(data, len(b))
+	return nil
+}
+
+// decodeTyped decodes the envelope of a typed transaction.
+func (tx *Transaction) decodeTyped(b []byte) error {
+	if len(b) == 0 {
+		return errEmptyTypedTx
+	}
+	if b[0] != TokenTxType && b[0] != ReplaceTxType {
+		return ErrTxTypeNotSupported
 	}
+	var data txdata
+	if err := rlp.DecodeBytes(b[1:], &data); err != nil {
+		return err
+	}
+	data.Type = b[0]
+
+	tx.setDecoded(data, len(b))
+	return nil
+}
 
-	return err
+// setDecoded sets the payload of a freshly decoded transaction, dropping any
+// caches left over from what the transaction held before.
+func (tx *Transaction) setDecoded(data txdata, size int) {
+	*tx = Transaction{data: data, time: time.Now()}
+	tx.size.Store(common.StorageSize(size))
 }
 
 // MarshalJSON encodes the web3 RPC transaction format.
@@ -201,6 +332,9 @@ func (tx *Transaction) UnmarshalJSON(input []byte) error {
 	if err := dec.UnmarshalJSON(input); err != nil {
 		return err
 	}
+	if dec.Type > ReplaceTxType {
+		return ErrTxTypeNotSupported
+	}
 
 	withSignature := dec.V.Sign() != 0 || dec.R.Sign() != 0 || dec.S.Sign() != 0
 	if withSignature {
@@ -216,7 +350,7 @@ func (tx *Transaction) UnmarshalJSON(input []byte) error {
 		}
 	}
 
-	*tx = Transaction{data: dec}
+	*tx = Transaction{data: dec, time: time.Now()}
 	return nil
 }
 
@@ -228,6 +362,11 @@ func (tx *Transaction) Nonce() uint64      { return tx.data.AccountNonce }
 func (tx *Transaction) CheckNonce() bool   { return true }
 func (tx *Transaction) Token() *common.Address      { return tx.data.Token }
 
+// Time returns the time the transaction was first seen locally, i.e. when it was
+// created or decoded from the network. The pool keeps the first copy it accepts,
+// so for pooled transactions this is their arrival time.
+func (tx *Transaction) Time() time.Time { return tx.time }
+
 // To returns the recipient address of the transaction.
 // It returns nil if the transaction is a contract creation.
 func (tx *Transaction) To() *common.Address {
@@ -244,7 +383,12 @@ func (tx *Transaction) Hash() common.Hash {
 	if hash := tx.hash.Load(); hash != nil {
 		return hash.(common.Hash)
 	}
-	v := rlpHash(tx)
+	var v common.Hash
+	if tx.Type() == LegacyTxType {
+		v = rlpHash(tx)
+	} else {
+		v = prefixedRlpHash(tx.Type(), &tx.data)
+	}
 	tx.hash.Store(v)
 	return v
 }
@@ -257,6 +401,9 @@ func (tx *Transaction) Size() common.StorageSize {
 	}
 	c := writeCounter(0)
 	rlp.Encode(&c, &tx.data)
+	if tx.Type() != LegacyTxType {
+		c++
+	}
 	tx.size.Store(common.StorageSize(c))
 	return common.StorageSize(c)
 }
@@ -290,7 +437,7 @@ func (tx *Transaction) WithSignature(signer Signer, sig []byte) (*Transaction, e
 	if err != nil {
 		return nil, err
 	}
-	cpy := &Transaction{data: tx.data}
+	cpy := &Transaction{data: tx.data, time: tx.time}
 	cpy.data.R, cpy.data.S, cpy.data.V = r, s, v
 	return cpy, nil
 }
@@ -388,16 +535,7 @@ type TransactionsByPriceAndNonce struct {
 // if after providing it to the constructor.
 func NewTransactionsByPriceAndNonce(signer Signer, txs map[common.Address]Transactions) *TransactionsByPriceAndNonce {
 	// Initialize a price based heap with the head transactions
-	heads := make(TxByPrice, 0, len(txs))
-	for from, accTxs := range txs {
-		heads = append(heads, accTxs[0])
-		// Ensure the sender address is from the signer
-		acc, _ := Sender(signer, accTxs[0])
-		txs[acc] = accTxs[1:]
-		if from != acc {
-			delete(txs, from)
-		}
-	}
+	heads := TxByPrice(splitHeads(signer, txs))
 	heap.Init(&heads)
 
 	// Assemble and return the transaction set
@@ -434,6 +572,169 @@ func (t *TransactionsByPriceAndNonce) Pop() {
 	heap.Pop(&t.heads)
 }
 
+// OrderedTransactions is a set of pending transactions from multiple accounts
+// that can be iterated in a policy defined order, while honouring the nonces of
+// every account.
+type OrderedTransactions interface {
+	// Peek returns the next transaction in order, nil if the set is exhausted.
+	Peek() *Transaction
+
+	// Shift replaces the current head with the next one from the same account.
+	Shift()
+
+	// Pop removes the current head along with all subsequent transactions from
+	// the same account.
+	Pop()
+}
+
+// splitHeads removes the lowest nonce transaction of every account from the given
+// per account nonce-sorted lists and returns them, rekeying the lists by the
+// sender derived from the signer.
+func splitHeads(signer Signer, txs map[common.Address]Transactions) Transactions {
+	heads := make(Transactions, 0, len(txs))
+	for from, accTxs := range txs {
+		heads = append(heads, accTxs[0])
+		// Ensure the sender address is from the signer
+		acc, _ := Sender(signer, accTxs[0])
+		txs[acc] = accTxs[1:]
+		if from != acc {
+			delete(txs, from)
+		}
+	}
+	return heads
+}
+
+// TxByTime implements both the sort and the heap interface, ordering transactions
+// by the time they were first seen. Transactions seen at the same time are ordered
+// by hash, so the order is the same for every iteration of the same set.
+type TxByTime Transactions
+
+func (s TxByTime) Len() int { return len(s) }
+func (s TxByTime) Less(i, j int) bool {
+	if !s[i].time.Equal(s[j].time) {
+		return s[i].time.Before(s[j].time)
+	}
+	hi, hj := s[i].Hash(), s[j].Hash()
+	return bytes.Compare(hi[:], hj[:]) < 0
+}
+func (s TxByTime) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+
+func (s *TxByTime) Push(x interface{}) {
+	*s = append(*s, x.(*Transaction))
+}
+
+func (s *TxByTime) Pop() interface{} {
+	old := *s
+	n := len(old)
+	x := old[n-1]
+	*s = old[0 : n-1]
+	return x
+}
+
+// TransactionsByArrivalAndNonce represents a set of transactions that can return
+// transactions in first-seen order, while supporting removing entire batches of
+// transactions for non-executable accounts. It is the fair order for chains where
+// all transactions pay the same gas price.
+type TransactionsByArrivalAndNonce struct {
+	txs    map[common.Address]Transactions // Per account nonce-sorted list of transactions
+	heads  TxByTime                        // Next transaction for each unique account (arrival heap)
+	signer Signer                          // Signer for the set of transactions
+}
+
+// NewTransactionsByArrivalAndNonce creates a transaction set that can retrieve
+// arrival sorted transactions in a nonce-honouring way.
+//
+// Note, the input map is reowned so the caller should not interact any more with
+// if after providing it to the constructor.
+func NewTransactionsByArrivalAndNonce(signer Signer, txs map[common.Address]Transactions) *TransactionsByArrivalAndNonce {
+	heads := TxByTime(splitHeads(signer, txs))
+	heap.Init(&heads)
+
+	return &TransactionsByArrivalAndNonce{
+		txs:    txs,
+		heads:  heads,
+		signer: signer,
+	}
+}
+
+// Peek returns the next transaction by arrival.
+func (t *TransactionsByArrivalAndNonce) Peek() *Transaction {
+	if len(t.heads) == 0 {
+		return nil
+	}
+	return t.heads[0]
+}
+
+// Shift replaces the current earliest head with the next one from the same account.
+func (t *TransactionsByArrivalAndNonce) Shift() {
+	acc, _ := Sender(t.signer, t.heads[0])
+	if txs, ok := t.txs[acc]; ok && len(txs) > 0 {
+		t.heads[0], t.txs[acc] = txs[0], txs[1:]
+		heap.Fix(&t.heads, 0)
+	} else {
+		heap.Pop(&t.heads)
+	}
+}
+
+// Pop removes the earliest transaction, *not* replacing it with the next one from
+// the same account.
+func (t *TransactionsByArrivalAndNonce) Pop() {
+	heap.Pop(&t.heads)
+}
+
+// TransactionsByAccountAndNonce represents a set of transactions that can return
+// one transaction per account in turn, while supporting removing entire batches
+// of transactions for non-executable accounts. The accounts take turns in the
+// order their first pending transactions were seen, so an account flooding the
+// pool cannot delay the others.
+type TransactionsByAccountAndNonce struct {
+	txs    map[common.Address]Transactions // Per account nonce-sorted list of transactions
+	heads  Transactions                    // Next transaction for each unique account (turn queue)
+	signer Signer                          // Signer for the set of transactions
+}
+
+// NewTransactionsByAccountAndNonce creates a transaction set that can retrieve
+// transactions account by account in a nonce-honouring way.
+//
+// Note, the input map is reowned so the caller should not interact any more with
+// if after providing it to the constructor.
+func NewTransactionsByAccountAndNonce(signer Signer, txs map[common.Address]Transactions) *TransactionsByAccountAndNonce {
+	heads := splitHeads(signer, txs)
+	sort.Sort(TxByTime(heads))
+
+	return &TransactionsByAccountAndNonce{
+		txs:    txs,
+		heads:  heads,
+		signer: signer,
+	}
+}
+
+// Peek returns the next transaction of the account in turn.
+func (t *TransactionsByAccountAndNonce) Peek() *Transaction {
+	if len(t.heads) == 0 {
+		return nil
+	}
+	return t.heads[0]
+}
+
+// Shift ends the turn of the current account, queueing its next transaction
+// behind all the other accounts.
+func (t *TransactionsByAccountAndNonce) Shift() {
+	acc, _ := Sender(t.signer, t.heads[0])
+	if txs, ok := t.txs[acc]; ok && len(txs) > 0 {
+		t.heads = append(t.heads[1:], txs[0])
+		t.txs[acc] = txs[1:]
+	} else {
+		t.heads = t.heads[1:]
+	}
+}
+
+// Pop removes the current account from the rotation, *not* queueing its next
+// transaction.
+func (t *TransactionsByAccountAndNonce) Pop() {
+	t.heads = t.heads[1:]
+}
+
 // Message is a fully derived transaction and implements core.Message
 //
 // NOTE: In a future PR this will be removed.
@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/bcos-one/BCOS/common"
 	"github.com/bcos-one/BCOS/crypto"
@@ -179,6 +180,107 @@ func TestTransactionPriceNonceSort(t *testing.T) {
 	}
 }
 
+// Tests that transactions can be correctly sorted by their arrival time, while
+// honouring the nonce ordering of every account.
+func TestTransactionArrivalNonceSort(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 5)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	signer := HomesteadSigner{}
+
+	// Generate fee-less transactions arriving interleaved across the accounts, the
+	// last account's higher nonces arriving before its lowest one
+	var (
+		groups = map[common.Address]Transactions{}
+		start  = time.Now()
+		want   Transactions
+	)
+	for i, key := range keys {
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		for nonce := 0; nonce < 5; nonce++ {
+			tx, _ := SignTx(NewTransaction(uint64(nonce), common.Address{}, nil, big.NewInt(100), 100, new(big.Int), nil), signer, key)
+			tx.time = start.Add(time.Duration(nonce*len(keys)+i) * time.Second)
+			groups[addr] = append(groups[addr], tx)
+		}
+	}
+	last := groups[crypto.PubkeyToAddress(keys[4].PublicKey)]
+	last[0].time = start.Add(time.Hour)
+
+	for nonce := 0; nonce < 5; nonce++ {
+		for i := 0; i < 4; i++ {
+			want = append(want, groups[crypto.PubkeyToAddress(keys[i].PublicKey)][nonce])
+		}
+	}
+	want = append(want, last...)
+
+	// Sort the transactions and cross check the arrival and nonce ordering
+	txset := NewTransactionsByArrivalAndNonce(signer, groups)
+
+	var txs Transactions
+	for tx := txset.Peek(); tx != nil; tx = txset.Peek() {
+		txs = append(txs, tx)
+		txset.Shift()
+	}
+	if len(txs) != len(want) {
+		t.Fatalf("transaction count mismatch: have %d, want %d", len(txs), len(want))
+	}
+	for i := range want {
+		if txs[i] != want[i] {
+			t.Errorf("transaction %d mismatch: have nonce %d, want nonce %d", i, txs[i].Nonce(), want[i].Nonce())
+		}
+	}
+}
+
+// Tests that transactions are handed out one per account in turn, the accounts
+// ordered by arrival, and that popping an account drops it from the rotation.
+func TestTransactionAccountNonceSort(t *testing.T) {
+	keys := make([]*ecdsa.PrivateKey, 4)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	signer := HomesteadSigner{}
+
+	// Account i has 4-i transactions and arrived after account i+1
+	var (
+		groups = map[common.Address]Transactions{}
+		accs   = make([]Transactions, len(keys))
+		start  = time.Now()
+	)
+	for i, key := range keys {
+		for nonce := 0; nonce < len(keys)-i; nonce++ {
+			tx, _ := SignTx(NewTransaction(uint64(nonce), common.Address{}, nil, big.NewInt(100), 100, new(big.Int), nil), signer, key)
+			tx.time = start.Add(time.Duration(len(keys)-i) * time.Second)
+			accs[i] = append(accs[i], tx)
+		}
+		groups[crypto.PubkeyToAddress(key.PublicKey)] = accs[i]
+	}
+	want := Transactions{
+		accs[3][0], accs[2][0], accs[1][0], accs[0][0],
+		accs[2][1], accs[1][1], accs[0][1], // account 0 is popped here
+		accs[1][2],
+	}
+	txset := NewTransactionsByAccountAndNonce(signer, groups)
+
+	var txs Transactions
+	for tx := txset.Peek(); tx != nil; tx = txset.Peek() {
+		txs = append(txs, tx)
+		if tx == accs[0][1] {
+			txset.Pop()
+		} else {
+			txset.Shift()
+		}
+	}
+	if len(txs) != len(want) {
+		t.Fatalf("transaction count mismatch: have %d, want %d", len(txs), len(want))
+	}
+	for i := range want {
+		if txs[i] != want[i] {
+			t.Errorf("transaction %d mismatch", i)
+		}
+	}
+}
+
 // TestTransactionJSON tests serializing/de-serializing to/from JSON.
 func TestTransactionJSON(t *testing.T) {
 	key, err := crypto.GenerateKey()
@@ -226,3 +328,135 @@ func TestTransactionJSON(t *testing.T) {
 		}
 	}
 }
+
+// Tests that token transactions travel in the typed envelope, both standalone
+// and embedded into RLP lists, and that their signature covers the type byte
+// and the token.
+func TestTypedTransactionEnvelope(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	signer := NewEIP155Signer(common.Big1)
+	token := common.HexToAddress("0x1000")
+
+	tx, err := SignTx(NewTransaction(1, common.Address{1}, &token, common.Big1, 21000, common.Big0, nil), signer, key)
+	if err != nil {
+		t.Fatalf("could not sign transaction: %v", err)
+	}
+	if tx.Type() != TokenTxType {
+		t.Fatalf("type mismatch: have %d, want %d", tx.Type(), TokenTxType)
+	}
+	enc, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("could not encode transaction: %v", err)
+	}
+	if enc[0] != TokenTxType {
+		t.Fatalf("envelope type byte mismatch: have %#x", enc[0])
+	}
+	parsed := new(Transaction)
+	if err := parsed.UnmarshalBinary(enc); err != nil {
+		t.Fatalf("could not decode transaction: %v", err)
+	}
+	if from, err := Sender(signer, parsed); err != nil || from != addr || parsed.Hash() != tx.Hash() || *parsed.Token() != token {
+		t.Fatalf("decoded transaction mismatch: from %x (%v), hash %x", from, err, parsed.Hash())
+	}
+	// Typed transactions must survive being embedded into block bodies
+	blob, _ := rlp.EncodeToBytes(Transactions{tx, rightvrsTx})
+	var txs Transactions
+	if err := rlp.DecodeBytes(blob, &txs); err != nil {
+		t.Fatalf("could not decode transaction list: %v", err)
+	}
+	if txs[0].Hash() != tx.Hash() || txs[0].Type() != TokenTxType || txs[1].Hash() != rightvrsTx.Hash() {
+		t.Fatalf("transaction list mismatch")
+	}
+	// Swapping the token or the type must invalidate the signature
+	swapped := common.CopyBytes(enc)
+	copy(swapped[bytes.Index(swapped, token[:]):], common.Address{2}.Bytes())
+	if err := parsed.UnmarshalBinary(swapped); err != nil {
+		t.Fatalf("could not decode swapped transaction: %v", err)
+	}
+	if from, _ := Sender(signer, parsed); from == addr {
+		t.Fatalf("signature does not cover the token")
+	}
+	legacy := NewTransaction(1, common.Address{1}, nil, common.Big1, 21000, common.Big0, nil)
+	legacy.data.Token, legacy.data.V, legacy.data.R, legacy.data.S = &token, tx.data.V, tx.data.R, tx.data.S
+	if from, _ := Sender(signer, legacy); from == addr {
+		t.Fatalf("signature does not cover the type")
+	}
+	enc[0] = 0x7f
+	if err := parsed.UnmarshalBinary(enc); err != ErrTxTypeNotSupported {
+		t.Fatalf("unknown type error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+	if _, err := SignTx(NewTransaction(1, common.Address{1}, &token, common.Big1, 21000, common.Big0, nil), HomesteadSigner{}, key); err != ErrTxTypeNotSupported {
+		t.Fatalf("homestead signing error mismatch: have %v, want %v", err, ErrTxTypeNotSupported)
+	}
+}
+
+// Tests that a token inserted into a signed legacy transaction keeps its sender,
+// as the legacy signature does not cover tokens, so the validation rules have to
+// reject such transactions once typed token transactions are activated.
+func TestLegacyTokenInjection(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := NewEIP155Signer(common.Big1)
+	token := common.HexToAddress("0x1000")
+
+	tx, err := SignTx(NewTransaction(1, common.Address{1}, nil, common.Big1, 21000, common.Big0, nil), signer, key)
+	if err != nil {
+		t.Fatalf("could not sign transaction: %v", err)
+	}
+	injected := *tx
+	injected.data.Token = &token
+
+	enc, err := rlp.EncodeToBytes(&injected.data)
+	if err != nil {
+		t.Fatalf("could not encode transaction: %v", err)
+	}
+	parsed := new(Transaction)
+	if err := parsed.UnmarshalBinary(enc); err != nil || !parsed.LegacyToken() {
+		t.Fatalf("could not decode raw legacy token transaction: %v", err)
+	}
+	if from, err := Sender(signer, parsed); err != nil || from != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatalf("injected token changed the sender: have %x (%v)", from, err)
+	}
+	// Block bodies from before the typed transactions decode them the same way
+	if err := rlp.DecodeBytes(enc, parsed); err != nil || !parsed.LegacyToken() {
+		t.Fatalf("could not decode legacy token transaction: %v", err)
+	}
+}
+
+// Tests that the replacement marker is covered by the signature of the sender,
+// so it can neither be added to nor stripped from a signed transaction.
+func TestReplacementMarker(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := NewEIP155Signer(common.Big1)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	tx, err := SignTx(NewTransaction(1, common.Address{1}, nil, common.Big1, 21000, common.Big0, nil).AsReplacement(), signer, key)
+	if err != nil {
+		t.Fatalf("could not sign transaction: %v", err)
+	}
+	enc, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("could not encode transaction: %v", err)
+	}
+	if enc[0] != ReplaceTxType {
+		t.Fatalf("envelope type mismatch: have %d, want %d", enc[0], ReplaceTxType)
+	}
+	parsed := new(Transaction)
+	if err := parsed.UnmarshalBinary(enc); err != nil {
+		t.Fatalf("could not decode transaction: %v", err)
+	}
+	if !parsed.Replaces() || parsed.Hash() != tx.Hash() {
+		t.Fatalf("replacement not decoded")
+	}
+	if sender, err := Sender(signer, parsed); err != nil || sender != from {
+		t.Fatalf("sender mismatch: have %x, want %x (%v)", sender, from, err)
+	}
+	// Retyping the envelope must change the recovered sender
+	enc[0] = TokenTxType
+	if err := parsed.UnmarshalBinary(enc); err != nil {
+		t.Fatalf("could not decode retyped transaction: %v", err)
+	}
+	if sender, err := Sender(signer, parsed); err == nil && sender == from {
+		t.Fatalf("stripped replacement marker kept the sender")
+	}
+}
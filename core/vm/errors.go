@@ -28,4 +28,5 @@ var (
 	ErrContractAddressCollision = errors.New("contract address collision")
 	ErrNoCompatibleInterpreter  = errors.New("no compatible interpreter")
 	ErrUnsupportToken          = errors.New("unsupported token type")
+	ErrTokenFrozen             = errors.New("token paused or account frozen")
 )
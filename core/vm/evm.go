@@ -43,6 +43,9 @@ type (
 	CanTransferTokenFunc func(StateDB, common.Address, common.Address, *big.Int) bool
 	// TransferFunc is the signature of a transfer token function
 	TransferTokenFunc func(StateDB, common.Address, common.Address, common.Address, *big.Int)
+	// TokenTransferAllowedFunc is the signature of a token compliance guard
+	// function, given the token storage, the token, the sender and the recipient
+	TokenTransferAllowedFunc func(StateDB, common.Address, common.Address, common.Address, common.Address) bool
 )
 
 // run runs the given contract and takes care of running precompiles with a fallback to the byte code interpreter.
@@ -88,6 +91,9 @@ type Context struct {
 	CanTransferToken CanTransferTokenFunc
 	// Transfer transfers token from one account to the other
 	TransferToken TransferTokenFunc
+	// TokenTransferAllowed returns whether the compliance controls of
+	// the token let it move between the accounts
+	TokenTransferAllowed TokenTransferAllowedFunc
 
 	// Message information
 	Origin   common.Address // Provides information for ORIGIN
@@ -208,6 +214,9 @@ func (evm *EVM) Call(caller ContractRef, addr common.Address, input []byte, gas
 		if !evm.Context.CanTransferToken(evm.StateDB, caller.Address(), *tokenSupport, value) {
 			return nil, gas, ErrInsufficientBalance
 		}
+		if !evm.tokenTransferAllowed(caller.Address(), addr, *tokenSupport, value) {
+			return nil, gas, ErrTokenFrozen
+		}
 	} else {
 		if !evm.Context.CanTransfer(evm.StateDB, caller.Address(), value) {
 			return nil, gas, ErrInsufficientBalance
@@ -411,6 +420,9 @@ func (evm *EVM) create(caller ContractRef, codeAndHash *codeAndHash, gas uint64,
 		if !evm.CanTransferToken(evm.StateDB, caller.Address(), *token, value) {
 			return nil, common.Address{}, gas, ErrInsufficientBalance
 		}
+		if !evm.tokenTransferAllowed(caller.Address(), address, *token, value) {
+			return nil, common.Address{}, gas, ErrTokenFrozen
+		}
 	} else {
 		if !evm.CanTransfer(evm.StateDB, caller.Address(), value) {
 			return nil, common.Address{}, gas, ErrInsufficientBalance
@@ -508,6 +520,16 @@ func (evm *EVM) Create2(caller ContractRef, code []byte, gas uint64, endowment *
 	return evm.create(caller, codeAndHash, gas, token, endowment, contractAddr)
 }
 
+// tokenTransferAllowed reports whether the compliance controls of a token let
+// value move from sender to recipient.
+func (evm *EVM) tokenTransferAllowed(sender, recipient common.Address, id common.Address, value *big.Int) bool {
+	config := evm.chainConfig.ExpansionsConfig
+	if evm.Context.TokenTransferAllowed == nil || !config.IsTokenEnabled(evm.BlockNumber) || value.Sign() == 0 {
+		return true
+	}
+	return evm.Context.TokenTransferAllowed(evm.StateDB, config.TokenStorage, id, sender, recipient)
+}
+
 // ChainConfig returns the environment's chain configuration
 func (evm *EVM) ChainConfig() *params.ChainConfig { return evm.chainConfig }
 
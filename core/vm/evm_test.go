@@ -0,0 +1,61 @@
+package vm
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/core/state"
+	"github.com/bcos-one/BCOS/ethdb"
+	"github.com/bcos-one/BCOS/params"
+)
+
+// Tests that token transfers are refused with ErrTokenFrozen when the compliance
+// guard of the context denies them, and that the guard sees the token storage.
+func TestTokenTransferAllowed(t *testing.T) {
+	var (
+		storage = common.HexToAddress("0x1000")
+		id      = common.HexToAddress("0x2000")
+		sender  = common.HexToAddress("0x01")
+		frozen  = common.HexToAddress("0x02")
+		other   = common.HexToAddress("0x03")
+	)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	statedb.AddTokenBalance(sender, id, big.NewInt(100))
+
+	config := *params.TestChainConfig
+	config.ExpansionsConfig = &params.ExpansionsConfig{TokenSupport: true, TokenStorage: storage}
+
+	context := Context{
+		CanTransferToken: func(db StateDB, addr, token common.Address, amount *big.Int) bool {
+			return db.GetTokenBalance(addr, token).Cmp(amount) >= 0
+		},
+		TransferToken: func(db StateDB, from, to, token common.Address, amount *big.Int) {
+			db.SubTokenBalance(from, token, amount)
+			db.AddTokenBalance(to, token, amount)
+		},
+		TokenTransferAllowed: func(db StateDB, store, token, from, to common.Address) bool {
+			if store != storage || token != id {
+				t.Fatalf("guard called with storage %x and token %x", store, token)
+			}
+			return from != frozen && to != frozen
+		},
+		BlockNumber: new(big.Int),
+	}
+	evm := NewEVM(context, statedb, &config, Config{})
+
+	if _, _, err := evm.Call(AccountRef(sender), frozen, nil, 100000, &id, big.NewInt(10)); err != ErrTokenFrozen {
+		t.Fatalf("frozen transfer error mismatch: have %v, want %v", err, ErrTokenFrozen)
+	}
+	if _, _, err := evm.Call(AccountRef(sender), other, nil, 100000, &id, big.NewInt(10)); err != nil {
+		t.Fatalf("failed to transfer token: %v", err)
+	}
+	if balance := statedb.GetTokenBalance(other, id); balance.Cmp(big.NewInt(10)) != 0 {
+		t.Fatalf("recipient balance mismatch: have %v, want 10", balance)
+	}
+	// Without a guard the controls are not enforced
+	evm = NewEVM(Context{CanTransferToken: context.CanTransferToken, TransferToken: context.TransferToken, BlockNumber: new(big.Int)}, statedb, &config, Config{})
+	if _, _, err := evm.Call(AccountRef(sender), frozen, nil, 100000, &id, big.NewInt(10)); err != nil {
+		t.Fatalf("unguarded transfer failed: %v", err)
+	}
+}
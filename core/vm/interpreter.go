@@ -46,6 +46,10 @@ type Config struct {
 	EWASMInterpreter string
 	// Type of the EVM interpreter
 	EVMInterpreter string
+
+	// Number of workers executing the transactions of a block optimistically in
+	// parallel (0 or 1 = sequential)
+	ParallelTxs int
 }
 
 // Interpreter is used to run Ethereum based contracts and will utilise the
@@ -92,6 +92,89 @@ func (api *PublicMinerAPI) Mining() bool {
 	return api.e.IsMining()
 }
 
+// PublicTokenHistoryAPI provides access to the token balance and supply history
+// recorded by the token history indexer.
+type PublicTokenHistoryAPI struct {
+	e *Ethereum
+}
+
+// NewPublicTokenHistoryAPI creates a new token history API instance.
+func NewPublicTokenHistoryAPI(e *Ethereum) *PublicTokenHistoryAPI {
+	return &PublicTokenHistoryAPI{e}
+}
+
+// TokenHistoryPoint is a single change of a token balance or supply.
+type TokenHistoryPoint struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	Value       *hexutil.Big   `json:"value"`
+	Delta       *hexutil.Big   `json:"delta"`
+}
+
+// GetTokenBalanceHistory returns every change of the token balance of address
+// within the given block range. Blocks that have not been indexed yet are omitted.
+func (api *PublicTokenHistoryAPI) GetTokenBalanceHistory(address common.Address, token common.Address, from, to rpc.BlockNumber) ([]*TokenHistoryPoint, error) {
+	return api.history(from, to, func(section uint64) []rawdb.TokenHistoryEntry {
+		return rawdb.ReadTokenBalanceHistory(api.e.chainDb, token, address, section)
+	})
+}
+
+// GetTokenSupplyHistory returns every change of the supply of token within the
+// given block range. Blocks that have not been indexed yet are omitted.
+func (api *PublicTokenHistoryAPI) GetTokenSupplyHistory(token common.Address, from, to rpc.BlockNumber) ([]*TokenHistoryPoint, error) {
+	return api.history(from, to, func(section uint64) []rawdb.TokenHistoryEntry {
+		return rawdb.ReadTokenSupplyHistory(api.e.chainDb, token, section)
+	})
+}
+
+// history collects the entries of a single series within the block range from
+// the indexed sections covering it.
+func (api *PublicTokenHistoryAPI) history(from, to rpc.BlockNumber, read func(section uint64) []rawdb.TokenHistoryEntry) ([]*TokenHistoryPoint, error) {
+	indexer := api.e.tokenHistoryIndexer
+	if indexer == nil {
+		return nil, errors.New("token history indexing disabled")
+	}
+	sections, _, _ := indexer.Sections()
+	if sections == 0 {
+		return []*TokenHistoryPoint{}, nil
+	}
+	indexed := sections*params.TokenHistoryBlocks - 1
+
+	first, last := uint64(from.Int64()), uint64(to.Int64())
+	if from < 0 {
+		first = indexed
+	}
+	if to < 0 || last > indexed {
+		last = indexed
+	}
+	if first > last {
+		return []*TokenHistoryPoint{}, nil
+	}
+	if tail := rawdb.ReadTokenHistoryTail(api.e.chainDb) * params.TokenHistoryBlocks; first < tail {
+		return nil, fmt.Errorf("token history before block %d has been pruned", tail)
+	}
+	points := []*TokenHistoryPoint{}
+	for section := first / params.TokenHistoryBlocks; section <= last/params.TokenHistoryBlocks; section++ {
+		if index := rawdb.ReadTokenHistorySection(api.e.chainDb, section); index != nil {
+			for _, gap := range index.Gaps {
+				if gap >= first && gap <= last {
+					return nil, fmt.Errorf("token history of block %d unavailable, state was pruned", gap)
+				}
+			}
+		}
+		for _, entry := range read(section) {
+			if entry.Number < first || entry.Number > last {
+				continue
+			}
+			points = append(points, &TokenHistoryPoint{
+				BlockNumber: hexutil.Uint64(entry.Number),
+				Value:       (*hexutil.Big)(entry.Value),
+				Delta:       (*hexutil.Big)(new(big.Int).Sub(entry.Value, entry.Prev)),
+			})
+		}
+	}
+	return points, nil
+}
+
 // PrivateMinerAPI provides private RPC methods to control the miner.
 // These methods can be abused by external users and must be considered insecure for use by untrusted users.
 type PrivateMinerAPI struct {
@@ -351,7 +434,7 @@ type storageEntry struct {
 
 // StorageRangeAt returns the storage at the given block height and transaction index.
 func (api *PrivateDebugAPI) StorageRangeAt(ctx context.Context, blockHash common.Hash, txIndex int, contractAddress common.Address, keyStart hexutil.Bytes, maxResult int) (StorageRangeResult, error) {
-	_, _, statedb, err := api.computeTxEnv(blockHash, txIndex, 0)
+	_, _, statedb, err := api.computeTxEnv(ctx, blockHash, txIndex, 0)
 	if err != nil {
 		return StorageRangeResult{}, err
 	}
@@ -25,11 +25,13 @@ import (
 	"github.com/bcos-one/BCOS/crypto"
 	"github.com/bcos-one/BCOS/params/global"
 	"math/big"
+	"path/filepath"
 	"runtime"
 	"sync"
 	"sync/atomic"
 
 	"github.com/bcos-one/BCOS/accounts"
+	"github.com/bcos-one/BCOS/accounts/external"
 	"github.com/bcos-one/BCOS/common"
 	"github.com/bcos-one/BCOS/common/hexutil"
 	"github.com/bcos-one/BCOS/consensus"
@@ -88,6 +90,8 @@ type Ethereum struct {
 	bloomRequests chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
 	bloomIndexer  *core.ChainIndexer             // Bloom indexer operating during block imports
 
+	tokenHistoryIndexer *core.ChainIndexer // Token balance and supply history indexer, nil if disabled
+
 	APIBackend *EthAPIBackend
 
 	miner     *miner.Miner
@@ -115,10 +119,17 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 	if !config.SyncMode.IsValid() {
 		return nil, fmt.Errorf("invalid sync mode %d", config.SyncMode)
 	}
+	if !miner.ValidOrdering(config.MinerOrdering) {
+		return nil, fmt.Errorf("invalid transaction ordering %q", config.MinerOrdering)
+	}
 	if config.MinerGasPrice == nil || config.MinerGasPrice.Cmp(common.Big0) <= 0 {
 		log.Warn("Sanitizing invalid miner gas price", "provided", config.MinerGasPrice, "updated", DefaultConfig.MinerGasPrice)
 		config.MinerGasPrice = new(big.Int).Set(DefaultConfig.MinerGasPrice)
 	}
+	if config.AncientThreshold != 0 && config.AncientThreshold < params.MinAncientThreshold {
+		log.Warn("Sanitizing ancient threshold", "provided", config.AncientThreshold, "updated", params.MinAncientThreshold)
+		config.AncientThreshold = params.MinAncientThreshold
+	}
 	// Assemble the Ethereum object
 	chainDb, err := CreateDB(ctx, config, "chaindata")
 	if err != nil {
@@ -150,8 +161,9 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 		eth.etherbase = crypto.PubkeyToAddress(ctx.NodeKey().PublicKey)
 	}
 
+	// force to set the wpoa etherbase to the sealing account
 	if chainConfig.WPoa != nil {
-		eth.etherbase = crypto.PubkeyToAddress(ctx.NodeKey().PublicKey)
+		eth.etherbase = consensusSigner(ctx, config).Address()
 	}
 
 	log.Info("Initialising Ethereum protocol", "versions", ProtocolVersions, "network", config.NetworkId)
@@ -168,8 +180,9 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 			EnablePreimageRecording: config.EnablePreimageRecording,
 			EWASMInterpreter:        config.EWASMInterpreter,
 			EVMInterpreter:          config.EVMInterpreter,
+			ParallelTxs:             config.ParallelTxs,
 		}
-		cacheConfig = &core.CacheConfig{Disabled: config.NoPruning, TrieNodeLimit: config.TrieCache, TrieTimeLimit: config.TrieTimeout}
+		cacheConfig = &core.CacheConfig{Disabled: config.NoPruning, TrieNodeLimit: config.TrieCache, TrieTimeLimit: config.TrieTimeout, AncientThreshold: config.AncientThreshold, Snapshot: !config.NoSnapshot}
 	)
 	eth.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, eth.chainConfig, eth.engine, vmConfig, eth.shouldPreserve)
 	if err != nil {
@@ -183,6 +196,20 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 	}
 	eth.bloomIndexer.Start(eth.blockchain)
 
+	if config.TokenHistory {
+		if expansions := chainConfig.ExpansionsConfig; expansions != nil && expansions.TokenSupport {
+			horizon := config.TokenHistoryHorizon
+			if horizon != 0 && horizon < params.TokenHistoryBlocks {
+				log.Warn("Sanitizing token history horizon", "provided", horizon, "updated", params.TokenHistoryBlocks)
+				horizon = params.TokenHistoryBlocks
+			}
+			eth.tokenHistoryIndexer = NewTokenHistoryIndexer(chainDb, eth.blockchain.StateCache(), expansions.TokenStorage, params.TokenHistoryBlocks, params.TokenHistoryConfirms, horizon, !config.NoPruning)
+			eth.tokenHistoryIndexer.Start(eth.blockchain)
+		} else {
+			log.Warn("Token history requested without token support, disabling")
+		}
+	}
+
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = ctx.ResolvePath(config.TxPool.Journal)
 	}
@@ -192,7 +219,7 @@ func New(ctx *node.ServiceContext, config *Config) (*Ethereum, error) {
 		return nil, err
 	}
 
-	eth.miner = miner.New(eth, eth.chainConfig, eth.EventMux(), eth.engine, config.MinerRecommit, config.MinerGasFloor, config.MinerGasCeil, eth.isLocalBlock)
+	eth.miner = miner.New(eth, eth.chainConfig, eth.EventMux(), eth.engine, config.MinerRecommit, config.MinerGasFloor, config.MinerGasCeil, config.MinerOrdering, eth.isLocalBlock)
 	eth.miner.SetExtra(makeExtraData(config.MinerExtraData, eth.chainConfig.IsBcos))
 
 	eth.APIBackend = &EthAPIBackend{eth, nil}
@@ -231,9 +258,56 @@ func CreateDB(ctx *node.ServiceContext, config *Config, name string) (ethdb.Data
 	if db, ok := db.(*ethdb.LDBDatabase); ok {
 		db.Meter("eth/db/chaindata/")
 	}
+	// Attach the ancient store holding the immutable part of the chain
+	if path := ctx.ResolvePath(name); path != "" {
+		frdb, err := rawdb.NewDatabaseWithFreezer(db, filepath.Join(path, "ancient"))
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		return frdb, nil
+	}
 	return db, nil
 }
 
+// consensusSigner returns the signer sealing the blocks of wpoa and dbft validators.
+// If an external signer is configured, the etherbase account of it seals, which
+// may only be omitted if the signer exposes a single account. Otherwise the node
+// key does.
+func consensusSigner(ctx *node.ServiceContext, config *Config) consensus.Signer {
+	backends := ctx.AccountManager.Backends(external.ExternalBackendType)
+	if len(backends) == 0 {
+		return consensus.NewKeySigner(ctx.NodeKey())
+	}
+	var (
+		signers []*external.ExternalSigner
+		accnts  []accounts.Account
+	)
+	for _, backend := range backends {
+		for _, wallet := range backend.Wallets() {
+			signer, ok := wallet.(*external.ExternalSigner)
+			if !ok {
+				continue
+			}
+			for _, account := range signer.Accounts() {
+				if config.Etherbase == (common.Address{}) || account.Address == config.Etherbase {
+					signers, accnts = append(signers, signer), append(accnts, account)
+				}
+			}
+		}
+	}
+	switch {
+	case len(accnts) == 0 && config.Etherbase != (common.Address{}):
+		log.Crit("Etherbase not exposed by the external signer", "etherbase", config.Etherbase)
+	case len(accnts) == 0:
+		log.Warn("External signer exposes no accounts, sealing with the node key")
+		return consensus.NewKeySigner(ctx.NodeKey())
+	case len(accnts) > 1 && config.Etherbase == (common.Address{}):
+		log.Crit("External signer exposes multiple accounts, set the etherbase to choose the sealing one", "accounts", len(accnts))
+	}
+	return consensus.NewAccountSigner(accnts[0], signers[0].SignData)
+}
+
 // CreateConsensusEngine creates the required type of consensus engine instance for an Ethereum service
 func CreateConsensusEngine(ctx *node.ServiceContext, chainConfig *params.ChainConfig, config *Config, notify []string, noverify bool, db ethdb.Database) consensus.Engine {
 	// If proof-of-authority is requested, set it up
@@ -242,11 +316,14 @@ func CreateConsensusEngine(ctx *node.ServiceContext, chainConfig *params.ChainCo
 	}
 
 	if chainConfig.Dbft != nil {
-		return dbftBackend.New(chainConfig.Dbft, ctx.NodeKey(), db)
+		if len(ctx.AccountManager.Backends(external.ExternalBackendType)) > 0 && chainConfig.Dbft.SealDomainBlock == nil {
+			log.Warn("External signers only sign domain separated dbft data, sealing needs the seal domain fork")
+		}
+		return dbftBackend.New(chainConfig.Dbft, consensusSigner(ctx, config), db)
 	}
 
 	if chainConfig.WPoa != nil {
-		return wpoa.New(chainConfig.WPoa, ctx.NodeKey(), db)
+		return wpoa.New(chainConfig.WPoa, consensusSigner(ctx, config), db)
 	}
 
 	// If Istanbul is requested, set it up
@@ -311,6 +388,11 @@ func (s *Ethereum) APIs() []rpc.API {
 			Version:   "1.0",
 			Service:   NewPublicMinerAPI(s),
 			Public:    true,
+		}, {
+			Namespace: "eth",
+			Version:   "1.0",
+			Service:   NewPublicTokenHistoryAPI(s),
+			Public:    true,
 		}, {
 			Namespace: "eth",
 			Version:   "1.0",
@@ -550,6 +632,9 @@ func (s *Ethereum) Start(srvr *p2p.Server) error {
 // Ethereum protocol.
 func (s *Ethereum) Stop() error {
 	s.bloomIndexer.Close()
+	if s.tokenHistoryIndexer != nil {
+		s.tokenHistoryIndexer.Close()
+	}
 	s.blockchain.Stop()
 	s.engine.Close()
 	s.protocolManager.Stop()
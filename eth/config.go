@@ -44,15 +44,17 @@ var DefaultConfig = Config{
 		DatasetsInMem:  1,
 		DatasetsOnDisk: 2,
 	},
-	NetworkId:     1,
-	LightPeers:    100,
-	DatabaseCache: 768,
-	TrieCache:     256,
-	TrieTimeout:   60 * time.Minute,
-	MinerGasFloor: 8000000,
-	MinerGasCeil:  8000000,
-	MinerGasPrice: big.NewInt(params.GWei),
-	MinerRecommit: 3 * time.Second,
+	NetworkId:        1,
+	LightPeers:       100,
+	DatabaseCache:    768,
+	TrieCache:        256,
+	TrieTimeout:      60 * time.Minute,
+	AncientThreshold: 90000,
+	MinerGasFloor:    8000000,
+	MinerGasCeil:     8000000,
+	MinerGasPrice:    big.NewInt(params.GWei),
+	MinerRecommit:    3 * time.Second,
+	MinerOrdering:    "price",
 
 	TxPool: core.DefaultTxPoolConfig,
 	GPO: gasprice.Config{
@@ -98,6 +100,8 @@ type Config struct {
 	DatabaseCache      int
 	TrieCache          int
 	TrieTimeout        time.Duration
+	AncientThreshold   uint64 // Number of recent blocks kept out of the ancient store (0 = disable freezing)
+	NoSnapshot         bool   // Disables the flat state snapshot used for fast state reads
 
 	// Mining-related options
 	Etherbase      common.Address `toml:",omitempty"`
@@ -108,6 +112,7 @@ type Config struct {
 	MinerGasPrice  *big.Int
 	MinerRecommit  time.Duration
 	MinerNoverify  bool
+	MinerOrdering  string // Ordering policy of pending transactions in mined blocks (price, fifo or roundrobin)
 
 	// Ethash options
 	Ethash ethash.Config
@@ -121,6 +126,10 @@ type Config struct {
 	// Enables tracking of SHA3 preimages in the VM
 	EnablePreimageRecording bool
 
+	// Token history options
+	TokenHistory        bool   // Enables the background token balance and supply history indexer
+	TokenHistoryHorizon uint64 // Number of recent blocks to retain token history for (0 = keep everything)
+
 	// Miscellaneous options
 	DocRoot string `toml:"-"`
 
@@ -128,6 +137,8 @@ type Config struct {
 	EWASMInterpreter string
 	// Type of the EVM interpreter ("" for default)
 	EVMInterpreter string
+	// Number of workers executing block transactions in parallel (0 = sequential)
+	ParallelTxs int
 
 	// Dbft engine options
 	Dbft params.DbftConfig
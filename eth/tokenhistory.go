@@ -0,0 +1,202 @@
+package eth
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/core"
+	"github.com/bcos-one/BCOS/core/rawdb"
+	"github.com/bcos-one/BCOS/core/state"
+	"github.com/bcos-one/BCOS/core/types"
+	"github.com/bcos-one/BCOS/ethdb"
+	"github.com/bcos-one/BCOS/expansions/token"
+	"github.com/bcos-one/BCOS/log"
+)
+
+const (
+	// tokenHistoryThrottling is the time to wait between processing two consecutive
+	// token history sections.
+	tokenHistoryThrottling = 10 * time.Millisecond
+)
+
+// TokenHistoryIndexer implements a core.ChainIndexer, recording the token balance
+// and supply changes of every canonical block so that their time series can be
+// served without re-reading historical states.
+type TokenHistoryIndexer struct {
+	db      ethdb.Database // database instance to write index data and metadata into
+	state   state.Database // state database to diff consecutive block states in
+	storage common.Address // address of the token storage holding token supplies
+	size    uint64         // section size to generate token history for
+	horizon uint64         // number of recent blocks to retain history for, 0 keeps everything
+	pruned  bool           // whether historical states are garbage collected by the node
+
+	section  uint64                                          // section number being processed currently
+	balances map[rawdb.TokenHolder][]rawdb.TokenHistoryEntry // balance changes collected for the section
+	supplies map[common.Address][]rawdb.TokenHistoryEntry    // supply changes collected for the section
+	gaps     []uint64                                        // blocks of the section whose states were pruned
+}
+
+// NewTokenHistoryIndexer returns a chain indexer that records the token balance
+// and supply history of the canonical chain. The changes are taken from the token
+// diffs captured while the blocks were processed. Blocks without one, like those
+// imported before the diffs were captured, fall back to diffing their states. On
+// an archive node a missing state fails the section so it is retried, while on a
+// pruning node such blocks are recorded as gaps that queries refuse to answer for.
+func NewTokenHistoryIndexer(db ethdb.Database, statedb state.Database, storage common.Address, size, confirms, horizon uint64, pruned bool) *core.ChainIndexer {
+	backend := &TokenHistoryIndexer{
+		db:      db,
+		state:   statedb,
+		storage: storage,
+		size:    size,
+		horizon: horizon,
+		pruned:  pruned,
+	}
+	table := ethdb.NewTable(db, string(rawdb.TokenHistoryIndexPrefix))
+
+	return core.NewChainIndexer(db, table, backend, size, confirms, tokenHistoryThrottling, "tokenhistory")
+}
+
+// Reset implements core.ChainIndexerBackend, starting a new token history section.
+func (t *TokenHistoryIndexer) Reset(ctx context.Context, section uint64, lastSectionHead common.Hash) error {
+	t.section = section
+	t.balances = make(map[rawdb.TokenHolder][]rawdb.TokenHistoryEntry)
+	t.supplies = make(map[common.Address][]rawdb.TokenHistoryEntry)
+	t.gaps = nil
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, collecting the token balance and
+// supply changes introduced by a new header, preferably from its captured diff.
+func (t *TokenHistoryIndexer) Process(ctx context.Context, header *types.Header) error {
+	number := header.Number.Uint64()
+
+	if diff := rawdb.ReadTokenBlockDiff(t.db, header.Hash(), number); diff != nil {
+		for _, change := range diff.Balances {
+			holder := rawdb.TokenHolder{Token: change.Token, Holder: change.Holder}
+			t.balances[holder] = append(t.balances[holder], rawdb.TokenHistoryEntry{Number: number, Prev: change.Prev, Value: change.Value})
+		}
+		for _, change := range diff.Supplies {
+			t.supplies[change.Token] = append(t.supplies[change.Token], rawdb.TokenHistoryEntry{Number: number, Prev: change.Prev, Value: change.Value})
+		}
+		return nil
+	}
+	var parentRoot common.Hash
+	if number > 0 {
+		parent := rawdb.ReadHeader(t.db, header.ParentHash, number-1)
+		if parent == nil {
+			return fmt.Errorf("missing parent header #%d [%x]", number-1, header.ParentHash)
+		}
+		parentRoot = parent.Root
+	}
+	if parentRoot == header.Root {
+		return nil
+	}
+	changes, err := state.TokenBalanceChanges(t.state, parentRoot, header.Root)
+	if err != nil {
+		if !t.pruned {
+			return fmt.Errorf("token history state of block #%d unavailable: %v", number, err)
+		}
+		if len(t.gaps) == 0 {
+			log.Warn("Token history state pruned, recording gap", "section", t.section, "number", number)
+		}
+		t.gaps = append(t.gaps, number)
+		return nil
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	tokens := make(map[common.Address]struct{})
+	for _, change := range changes {
+		holder := rawdb.TokenHolder{Token: change.Token, Holder: change.Holder}
+		t.balances[holder] = append(t.balances[holder], rawdb.TokenHistoryEntry{Number: number, Prev: change.Prev, Value: change.Value})
+		tokens[change.Token] = struct{}{}
+	}
+	// Supplies only move together with some balance, check the touched tokens
+	prevState, err := state.New(parentRoot, t.state)
+	if err != nil {
+		return err
+	}
+	currentState, err := state.New(header.Root, t.state)
+	if err != nil {
+		return err
+	}
+	for id := range tokens {
+		prev := token.NewTokenObject(t.storage, id, prevState).GetSupply()
+		value := token.NewTokenObject(t.storage, id, currentState).GetSupply()
+		if prev.Cmp(value) != 0 {
+			t.supplies[id] = append(t.supplies[id], rawdb.TokenHistoryEntry{Number: number, Prev: prev, Value: value})
+		}
+	}
+	return nil
+}
+
+// Commit implements core.ChainIndexerBackend, writing the collected series of the
+// section into the database and pruning sections that fell out of the horizon.
+func (t *TokenHistoryIndexer) Commit() error {
+	batch := t.db.NewBatch()
+
+	// Drop anything written for this section before a reorg
+	t.deleteSection(batch, t.section)
+
+	index := new(rawdb.TokenHistorySection)
+	for holder, entries := range t.balances {
+		rawdb.WriteTokenBalanceHistory(batch, holder.Token, holder.Holder, t.section, entries)
+		index.Balances = append(index.Balances, holder)
+	}
+	for id, entries := range t.supplies {
+		rawdb.WriteTokenSupplyHistory(batch, id, t.section, entries)
+		index.Supplies = append(index.Supplies, id)
+	}
+	sort.Slice(index.Balances, func(i, j int) bool {
+		if c := bytes.Compare(index.Balances[i].Token[:], index.Balances[j].Token[:]); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(index.Balances[i].Holder[:], index.Balances[j].Holder[:]) < 0
+	})
+	sort.Slice(index.Supplies, func(i, j int) bool {
+		return bytes.Compare(index.Supplies[i][:], index.Supplies[j][:]) < 0
+	})
+	index.Gaps = t.gaps
+	rawdb.WriteTokenHistorySection(batch, t.section, index)
+
+	t.prune(batch)
+	return batch.Write()
+}
+
+// prune deletes every section lying entirely below the pruning horizon.
+func (t *TokenHistoryIndexer) prune(batch ethdb.Batch) {
+	head := (t.section + 1) * t.size
+	if t.horizon == 0 || head <= t.horizon {
+		return
+	}
+	limit := (head - t.horizon) / t.size
+
+	tail := rawdb.ReadTokenHistoryTail(t.db)
+	if tail >= limit {
+		return
+	}
+	for ; tail < limit; tail++ {
+		t.deleteSection(batch, tail)
+	}
+	rawdb.WriteTokenHistoryTail(batch, tail)
+	log.Debug("Pruned token history", "tail", tail*t.size)
+}
+
+// deleteSection removes all series written for the given section.
+func (t *TokenHistoryIndexer) deleteSection(batch ethdb.Batch, section uint64) {
+	index := rawdb.ReadTokenHistorySection(t.db, section)
+	if index == nil {
+		return
+	}
+	for _, holder := range index.Balances {
+		rawdb.DeleteTokenBalanceHistory(batch, holder.Token, holder.Holder, section)
+	}
+	for _, id := range index.Supplies {
+		rawdb.DeleteTokenSupplyHistory(batch, id, section)
+	}
+	rawdb.DeleteTokenHistorySection(batch, section)
+}
@@ -28,7 +28,6 @@ import (
 	"github.com/bcos-one/BCOS/common"
 	"github.com/bcos-one/BCOS/common/hexutil"
 	"github.com/bcos-one/BCOS/core/types"
-	"github.com/bcos-one/BCOS/rlp"
 	"github.com/bcos-one/BCOS/rpc"
 )
 
@@ -336,6 +335,14 @@ func (ec *Client) BalanceAt(ctx context.Context, account common.Address, blockNu
 	return (*big.Int)(&result), err
 }
 
+// TokenBalanceAt returns the balance of the given account in the native token.
+// The block number can be nil, in which case the balance is taken from the latest known block.
+func (ec *Client) TokenBalanceAt(ctx context.Context, account common.Address, token common.Address, blockNumber *big.Int) (*big.Int, error) {
+	var result hexutil.Big
+	err := ec.c.CallContext(ctx, &result, "eth_getTokenBalance", account, token, toBlockNumArg(blockNumber))
+	return (*big.Int)(&result), err
+}
+
 // StorageAt returns the value of key in the contract storage of the given account.
 // The block number can be nil, in which case the value is taken from the latest known block.
 func (ec *Client) StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error) {
@@ -499,7 +506,7 @@ func (ec *Client) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64
 // If the transaction was a contract creation use the TransactionReceipt method to get the
 // contract address after the transaction has been mined.
 func (ec *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
-	data, err := rlp.EncodeToBytes(tx)
+	data, err := tx.MarshalBinary()
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,473 @@
+//go:build !js
+// +build !js
+
+package ethdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/log"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// boltFile is the name of the bbolt data file inside the database directory.
+	boltFile = "bolt.db"
+
+	// boltIteratorChunk is the number of entries an iterator loads per read
+	// transaction. Iterators never keep a transaction open between calls, as a
+	// lingering read transaction would block writers that need to grow the file.
+	boltIteratorChunk = 1024
+
+	// boltWriteBuffer is the size of the pending writes that triggers committing
+	// them to disk in a single transaction.
+	boltWriteBuffer = 4 * 1024 * 1024
+
+	// boltFlushInterval is the longest time writes stay pending before being
+	// committed to disk.
+	boltFlushInterval = time.Second
+)
+
+var (
+	boltBucket = []byte("ethdb")
+
+	// boltKeyMarker is prepended to every key, as bbolt refuses to store empty keys.
+	boltKeyMarker = byte('k')
+
+	errBoltNotFound = errors.New("not found")
+)
+
+// BoltDatabase is a pure-Go persistent database backed by bbolt. As a B+tree it
+// needs no background compaction, so sustained write bursts are never throttled
+// the way LevelDB stalls writes while compacting.
+//
+// Every bbolt transaction syncs the file to disk, so writes are not committed one
+// by one. They are collected into a pending batch instead, which is committed in
+// a single transaction once it grows large enough or gets old. Batches are always
+// committed in order, so after a crash the database holds a prefix of the writes,
+// the same way the LevelDB journal is replayed.
+type BoltDatabase struct {
+	fn string   // filename for reporting
+	db *bolt.DB // bbolt instance
+
+	lock    sync.Mutex           // Mutex protecting the pending writes
+	pending map[string]boltWrite // Writes not yet committed, keyed by bbolt key
+	size    int                  // Size of the pending writes
+	err     error                // Error of the last failed background commit, reported by the next write
+
+	quit chan chan error // Quit channel to stop the background committer
+
+	log log.Logger // Contextual logger tracking the database path
+}
+
+// NewBoltDatabase returns a bbolt wrapped object, storing its data in the given
+// directory.
+func NewBoltDatabase(file string) (*BoltDatabase, error) {
+	logger := log.New("database", file)
+
+	if err := os.MkdirAll(file, 0700); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(filepath.Join(file, boltFile), 0600, &bolt.Options{
+		Timeout:        time.Second,
+		NoFreelistSync: true,
+		FreelistType:   bolt.FreelistMapType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(boltBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	logger.Info("Opened bolt database")
+
+	bdb := &BoltDatabase{
+		fn:      file,
+		db:      db,
+		pending: make(map[string]boltWrite),
+		quit:    make(chan chan error),
+		log:     logger,
+	}
+	go bdb.loop()
+	return bdb, nil
+}
+
+// loop periodically commits the pending writes until the database is closed.
+func (db *BoltDatabase) loop() {
+	ticker := time.NewTicker(boltFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			db.lock.Lock()
+			if err := db.flush(); err != nil {
+				db.log.Error("Failed to commit pending writes", "err", err)
+				db.err = err
+			}
+			db.lock.Unlock()
+
+		case errc := <-db.quit:
+			db.lock.Lock()
+			errc <- db.flush()
+			db.lock.Unlock()
+			return
+		}
+	}
+}
+
+// write adds writes to the pending batch, committing it if it grew too large.
+//
+// Note, this method assumes the database lock is held!
+func (db *BoltDatabase) write(writes ...boltWrite) error {
+	if err := db.err; err != nil {
+		db.err = nil
+		return err
+	}
+	for _, w := range writes {
+		db.pending[string(w.key)] = w
+		db.size += len(w.key) + len(w.value)
+	}
+	if db.size >= boltWriteBuffer {
+		return db.flush()
+	}
+	return nil
+}
+
+// flush commits the pending writes to disk in a single transaction. A failed
+// commit keeps them pending to be retried.
+//
+// Note, this method assumes the database lock is held!
+func (db *BoltDatabase) flush() error {
+	if len(db.pending) == 0 {
+		return nil
+	}
+	err := db.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltBucket)
+		for _, w := range db.pending {
+			var err error
+			if w.delete {
+				err = bucket.Delete(w.key)
+			} else {
+				err = bucket.Put(w.key, w.value)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	db.pending, db.size = make(map[string]boltWrite), 0
+	return nil
+}
+
+// lookup returns the pending write of a key, if there is one.
+//
+// Note, this method assumes the database lock is held!
+func (db *BoltDatabase) lookup(key []byte) (boltWrite, bool) {
+	w, ok := db.pending[string(key)]
+	return w, ok
+}
+
+// boltKey converts a database key into the key stored in bbolt.
+func boltKey(key []byte) []byte {
+	return append([]byte{boltKeyMarker}, key...)
+}
+
+// Path returns the path to the database directory.
+func (db *BoltDatabase) Path() string {
+	return db.fn
+}
+
+// Put puts the given key / value to the queue
+func (db *BoltDatabase) Put(key []byte, value []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	return db.write(boltWrite{key: boltKey(key), value: common.CopyBytes(value)})
+}
+
+func (db *BoltDatabase) Has(key []byte) (bool, error) {
+	db.lock.Lock()
+	w, ok := db.lookup(boltKey(key))
+	db.lock.Unlock()
+
+	if ok {
+		return !w.delete, nil
+	}
+	var has bool
+	err := db.db.View(func(tx *bolt.Tx) error {
+		has = tx.Bucket(boltBucket).Get(boltKey(key)) != nil
+		return nil
+	})
+	return has, err
+}
+
+// Get returns the given key if it's present.
+func (db *BoltDatabase) Get(key []byte) ([]byte, error) {
+	db.lock.Lock()
+	w, ok := db.lookup(boltKey(key))
+	db.lock.Unlock()
+
+	if ok {
+		if w.delete {
+			return nil, errBoltNotFound
+		}
+		return common.CopyBytes(w.value), nil
+	}
+	var dat []byte
+	err := db.db.View(func(tx *bolt.Tx) error {
+		// Values are only valid for the life of the transaction, copy them out
+		v := tx.Bucket(boltBucket).Get(boltKey(key))
+		if v == nil {
+			return errBoltNotFound
+		}
+		dat = common.CopyBytes(v)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dat, nil
+}
+
+// Delete deletes the key from the queue and database
+func (db *BoltDatabase) Delete(key []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	return db.write(boltWrite{key: boltKey(key), delete: true})
+}
+
+// commit commits the pending writes, so that reads going to bbolt directly see
+// them.
+func (db *BoltDatabase) commit() error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	return db.flush()
+}
+
+// NewIteratorWithPrefix returns a iterator to iterate over subset of database content with a particular prefix.
+// Unlike LevelDB iterators it does not operate on a snapshot, entries written during
+// the iteration may or may not be returned.
+func (db *BoltDatabase) NewIteratorWithPrefix(prefix []byte) Iterator {
+	return &boltIterator{
+		db:     db.db,
+		prefix: boltKey(prefix),
+		seek:   boltKey(prefix),
+		pos:    -1,
+		err:    db.commit(),
+	}
+}
+
+// DeleteRange removes all keys in the range [start, limit).
+func (db *BoltDatabase) DeleteRange(start, limit []byte) error {
+	if err := db.commit(); err != nil {
+		return err
+	}
+	start = boltKey(start)
+	if limit != nil {
+		limit = boltKey(limit)
+	}
+	for {
+		var deleted int
+		err := db.db.Update(func(tx *bolt.Tx) error {
+			var (
+				bucket = tx.Bucket(boltBucket)
+				cursor = bucket.Cursor()
+				keys   [][]byte
+			)
+			// Deleting through a cursor skips entries, collect the keys first
+			for k, _ := cursor.Seek(start); k != nil && len(keys) < boltIteratorChunk; k, _ = cursor.Next() {
+				if limit != nil && bytes.Compare(k, limit) >= 0 {
+					break
+				}
+				keys = append(keys, common.CopyBytes(k))
+			}
+			for _, key := range keys {
+				if err := bucket.Delete(key); err != nil {
+					return err
+				}
+			}
+			deleted = len(keys)
+			return nil
+		})
+		if err != nil || deleted < boltIteratorChunk {
+			return err
+		}
+	}
+}
+
+// Compact is a no-op, bbolt reuses freed pages in place and never needs to merge
+// data files.
+func (db *BoltDatabase) Compact(start, limit []byte) error {
+	return nil
+}
+
+// Stat returns a particular internal stat of the database.
+func (db *BoltDatabase) Stat(property string) (string, error) {
+	if property != "bolt.stats" {
+		return "", fmt.Errorf("unknown property: %s", property)
+	}
+	if err := db.commit(); err != nil {
+		return "", err
+	}
+	stats := db.db.Stats()
+
+	var size int64
+	db.db.View(func(tx *bolt.Tx) error {
+		size = tx.Size()
+		return nil
+	})
+	return fmt.Sprintf("Size(MB):%.5f FreePages:%d PendingPages:%d FreeAlloc(MB):%.5f\nReadTx:%d OpenReadTx:%d Writes:%d WriteTime:%v",
+		float64(size)/1024/1024, stats.FreePageN, stats.PendingPageN, float64(stats.FreeAlloc)/1024/1024,
+		stats.TxN, stats.OpenTxN, stats.TxStats.Write, stats.TxStats.WriteTime), nil
+}
+
+func (db *BoltDatabase) Close() {
+	errc := make(chan error)
+	db.quit <- errc
+	if err := <-errc; err != nil {
+		db.log.Error("Failed to commit pending writes", "err", err)
+	}
+	if err := db.db.Close(); err == nil {
+		db.log.Info("Database closed")
+	} else {
+		db.log.Error("Failed to close database", "err", err)
+	}
+}
+
+func (db *BoltDatabase) NewBatch() Batch {
+	return &boltBatch{db: db}
+}
+
+type boltWrite struct {
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+// boltBatch collects writes to add them to the pending writes of the database at
+// once, so they are committed in the same transaction.
+type boltBatch struct {
+	db     *BoltDatabase
+	writes []boltWrite
+	size   int
+}
+
+func (b *boltBatch) Put(key, value []byte) error {
+	b.writes = append(b.writes, boltWrite{key: boltKey(key), value: common.CopyBytes(value)})
+	b.size += len(value)
+	return nil
+}
+
+func (b *boltBatch) Delete(key []byte) error {
+	b.writes = append(b.writes, boltWrite{key: boltKey(key), delete: true})
+	b.size += 1
+	return nil
+}
+
+func (b *boltBatch) Write() error {
+	b.db.lock.Lock()
+	defer b.db.lock.Unlock()
+
+	return b.db.write(b.writes...)
+}
+
+func (b *boltBatch) ValueSize() int {
+	return b.size
+}
+
+func (b *boltBatch) Reset() {
+	b.writes = b.writes[:0]
+	b.size = 0
+}
+
+// boltIterator walks a key prefix in chunks, each loaded by a short lived read
+// transaction.
+type boltIterator struct {
+	db     *bolt.DB
+	prefix []byte
+	seek   []byte // key to resume loading from
+	done   bool   // whether the last chunk was loaded
+
+	keys   [][]byte
+	values [][]byte
+	pos    int
+	err    error
+}
+
+func (it *boltIterator) Next() bool {
+	if it.pos+1 < len(it.keys) {
+		it.pos++
+		return true
+	}
+	if it.done || it.err != nil {
+		it.keys, it.values = nil, nil
+		return false
+	}
+	it.load()
+	it.pos = 0
+	return len(it.keys) > 0
+}
+
+// load reads the next chunk of entries starting at the resume key.
+func (it *boltIterator) load() {
+	it.keys, it.values = it.keys[:0], it.values[:0]
+	it.err = it.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(boltBucket).Cursor()
+
+		k, v := cursor.Seek(it.seek)
+		for ; k != nil && bytes.HasPrefix(k, it.prefix); k, v = cursor.Next() {
+			if len(it.keys) == boltIteratorChunk {
+				it.seek = common.CopyBytes(k)
+				return nil
+			}
+			it.keys = append(it.keys, common.CopyBytes(k[1:]))
+			it.values = append(it.values, common.CopyBytes(v))
+		}
+		it.done = true
+		return nil
+	})
+	if it.err != nil {
+		it.keys, it.values = nil, nil
+	}
+}
+
+func (it *boltIterator) Error() error {
+	return it.err
+}
+
+func (it *boltIterator) Key() []byte {
+	if it.pos < 0 || it.pos >= len(it.keys) {
+		return nil
+	}
+	return it.keys[it.pos]
+}
+
+func (it *boltIterator) Value() []byte {
+	if it.pos < 0 || it.pos >= len(it.keys) {
+		return nil
+	}
+	return it.values[it.pos]
+}
+
+func (it *boltIterator) Release() {
+	it.keys, it.values, it.done = nil, nil, true
+}
@@ -66,3 +66,23 @@ func (db *LDBDatabase) Meter(prefix string) {
 func (db *LDBDatabase) NewBatch() Batch {
 	return nil
 }
+
+func (db *LDBDatabase) NewIteratorWithPrefix(prefix []byte) Iterator {
+	return &memIterator{err: errNotSupported}
+}
+
+func (db *LDBDatabase) DeleteRange(start, limit []byte) error {
+	return errNotSupported
+}
+
+func (db *LDBDatabase) Compact(start, limit []byte) error {
+	return errNotSupported
+}
+
+func (db *LDBDatabase) Stat(property string) (string, error) {
+	return "", errNotSupported
+}
+
+func NewDatabase(engine string, file string, cache int, handles int) (Database, error) {
+	return nil, errNotSupported
+}
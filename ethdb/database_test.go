@@ -46,6 +46,22 @@ func newTestLDB() (*ethdb.LDBDatabase, func()) {
 	}
 }
 
+func newTestBoltDB() (*ethdb.BoltDatabase, func()) {
+	dirname, err := ioutil.TempDir(os.TempDir(), "ethdb_test_")
+	if err != nil {
+		panic("failed to create test file: " + err.Error())
+	}
+	db, err := ethdb.NewBoltDatabase(dirname)
+	if err != nil {
+		panic("failed to create test database: " + err.Error())
+	}
+
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dirname)
+	}
+}
+
 var test_values = []string{"", "a", "1251", "\x00123\x00"}
 
 func TestLDB_PutGet(t *testing.T) {
@@ -54,6 +70,12 @@ func TestLDB_PutGet(t *testing.T) {
 	testPutGet(db, t)
 }
 
+func TestBoltDB_PutGet(t *testing.T) {
+	db, remove := newTestBoltDB()
+	defer remove()
+	testPutGet(db, t)
+}
+
 func TestMemoryDB_PutGet(t *testing.T) {
 	testPutGet(ethdb.NewMemDatabase(), t)
 }
@@ -153,6 +175,12 @@ func TestLDB_ParallelPutGet(t *testing.T) {
 	testParallelPutGet(db, t)
 }
 
+func TestBoltDB_ParallelPutGet(t *testing.T) {
+	db, remove := newTestBoltDB()
+	defer remove()
+	testParallelPutGet(db, t)
+}
+
 func TestMemoryDB_ParallelPutGet(t *testing.T) {
 	testParallelPutGet(ethdb.NewMemDatabase(), t)
 }
@@ -212,3 +240,158 @@ func testParallelPutGet(db ethdb.Database, t *testing.T) {
 	}
 	pending.Wait()
 }
+
+func TestLDB_IterateDeleteRange(t *testing.T) {
+	db, remove := newTestLDB()
+	defer remove()
+	testIterateDeleteRange(db, t)
+}
+
+func TestBoltDB_IterateDeleteRange(t *testing.T) {
+	db, remove := newTestBoltDB()
+	defer remove()
+	testIterateDeleteRange(db, t)
+}
+
+func TestMemoryDB_IterateDeleteRange(t *testing.T) {
+	testIterateDeleteRange(ethdb.NewMemDatabase(), t)
+}
+
+func TestTable_IterateDeleteRange(t *testing.T) {
+	db := ethdb.NewMemDatabase()
+	db.Put([]byte("other"), []byte("value"))
+	testIterateDeleteRange(ethdb.NewTable(db, "t-"), t)
+
+	if ok, _ := db.Has([]byte("other")); !ok {
+		t.Fatalf("table operation touched keys outside the table")
+	}
+}
+
+func testIterateDeleteRange(db ethdb.Database, t *testing.T) {
+	t.Parallel()
+
+	keys := []string{"a1", "a2", "a3", "b1", "b2", "c1"}
+	for _, k := range keys {
+		if err := db.Put([]byte(k), []byte("v"+k)); err != nil {
+			t.Fatalf("put failed: %v", err)
+		}
+	}
+	collect := func(prefix string) []string {
+		it := db.NewIteratorWithPrefix([]byte(prefix))
+		defer it.Release()
+
+		var found []string
+		for it.Next() {
+			if !bytes.Equal(it.Value(), []byte("v"+string(it.Key()))) {
+				t.Fatalf("value mismatch for key %q: %q", it.Key(), it.Value())
+			}
+			found = append(found, string(it.Key()))
+		}
+		if err := it.Error(); err != nil {
+			t.Fatalf("iteration failed: %v", err)
+		}
+		return found
+	}
+	if found := collect("a"); fmt.Sprint(found) != "[a1 a2 a3]" {
+		t.Fatalf("prefix iteration mismatch: %v", found)
+	}
+	if found := collect(""); fmt.Sprint(found) != fmt.Sprint(keys) {
+		t.Fatalf("full iteration mismatch: %v", found)
+	}
+	if err := db.DeleteRange([]byte("a2"), []byte("b2")); err != nil {
+		t.Fatalf("range delete failed: %v", err)
+	}
+	if found := collect(""); fmt.Sprint(found) != "[a1 b2 c1]" {
+		t.Fatalf("keys mismatch after range delete: %v", found)
+	}
+	if err := db.DeleteRange([]byte("b"), nil); err != nil {
+		t.Fatalf("open range delete failed: %v", err)
+	}
+	if found := collect(""); fmt.Sprint(found) != "[a1]" {
+		t.Fatalf("keys mismatch after open range delete: %v", found)
+	}
+	if err := db.Compact(nil, nil); err != nil {
+		t.Fatalf("compaction failed: %v", err)
+	}
+}
+
+func TestNewDatabaseEngine(t *testing.T) {
+	dirname, err := ioutil.TempDir(os.TempDir(), "ethdb_test_")
+	if err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	defer os.RemoveAll(dirname)
+
+	db, err := ethdb.NewDatabase(ethdb.EngineBolt, dirname, 0, 0)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	db.Close()
+
+	if engine, err := ethdb.ReadEngine(dirname); err != nil || engine != ethdb.EngineBolt {
+		t.Fatalf("recorded engine mismatch: have %q, %v, want %q", engine, err, ethdb.EngineBolt)
+	}
+	if _, err := ethdb.NewDatabase(ethdb.EngineLevelDB, dirname, 0, 0); err == nil {
+		t.Fatalf("opened bolt database with leveldb engine")
+	}
+	db, err = ethdb.NewDatabase("", dirname, 0, 0)
+	if err != nil {
+		t.Fatalf("failed to reopen database: %v", err)
+	}
+	defer db.Close()
+
+	if _, ok := db.(*ethdb.BoltDatabase); !ok {
+		t.Fatalf("reopened database type mismatch: have %T", db)
+	}
+}
+
+// Tests that writes pending in the bolt database are visible right away and are
+// committed when the database is closed.
+func TestBoltDB_PendingWrites(t *testing.T) {
+	dirname, err := ioutil.TempDir(os.TempDir(), "ethdb_test_")
+	if err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+	defer os.RemoveAll(dirname)
+
+	db, err := ethdb.NewBoltDatabase(dirname)
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	db.Put([]byte("a"), []byte("1"))
+	db.Put([]byte("b"), []byte("2"))
+	db.Delete([]byte("a"))
+
+	batch := db.NewBatch()
+	batch.Put([]byte("c"), []byte("3"))
+	batch.Write()
+
+	if has, _ := db.Has([]byte("a")); has {
+		t.Fatalf("deleted pending key still present")
+	}
+	if value, err := db.Get([]byte("c")); err != nil || string(value) != "3" {
+		t.Fatalf("pending batch value mismatch: have %q, %v", value, err)
+	}
+	it := db.NewIteratorWithPrefix(nil)
+	var keys []string
+	for it.Next() {
+		keys = append(keys, string(it.Key()))
+	}
+	it.Release()
+	if fmt.Sprint(keys) != "[b c]" {
+		t.Fatalf("iterated keys mismatch: %v", keys)
+	}
+	db.Put([]byte("d"), []byte("4"))
+	db.Close()
+
+	if db, err = ethdb.NewBoltDatabase(dirname); err != nil {
+		t.Fatalf("failed to reopen database: %v", err)
+	}
+	defer db.Close()
+
+	for key, want := range map[string]string{"b": "2", "c": "3", "d": "4"} {
+		if value, err := db.Get([]byte(key)); err != nil || string(value) != want {
+			t.Fatalf("value of %s mismatch after reopen: have %q, %v, want %q", key, value, err, want)
+		}
+	}
+}
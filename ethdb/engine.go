@@ -0,0 +1,77 @@
+//go:build !js
+// +build !js
+
+package ethdb
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Supported persistent database engines.
+const (
+	EngineLevelDB = "leveldb"
+	EngineBolt    = "bolt"
+)
+
+// engineFile is the file inside a database directory recording the engine the
+// database was created with.
+const engineFile = "ENGINE"
+
+// ReadEngine returns the engine the database in the given directory was created
+// with, or an empty string if there is no database yet. Databases that predate the
+// engine record are LevelDB ones.
+func ReadEngine(file string) (string, error) {
+	blob, err := ioutil.ReadFile(filepath.Join(file, engineFile))
+	switch {
+	case err == nil:
+		return strings.TrimSpace(string(blob)), nil
+	case !os.IsNotExist(err):
+		return "", err
+	}
+	if _, err := os.Stat(filepath.Join(file, "CURRENT")); err == nil {
+		return EngineLevelDB, nil
+	}
+	return "", nil
+}
+
+// NewDatabase opens the persistent database in the given directory with the given
+// engine, creating it if it does not exist yet. An empty engine selects the one the
+// database was created with, or LevelDB for new databases. Opening a database with
+// a different engine than it was created with is refused.
+func NewDatabase(engine string, file string, cache int, handles int) (Database, error) {
+	recorded, err := ReadEngine(file)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case engine == "" && recorded == "":
+		engine = EngineLevelDB
+	case engine == "":
+		engine = recorded
+	case recorded != "" && recorded != engine:
+		return nil, fmt.Errorf("database %s was created with the %s engine, not %s", file, recorded, engine)
+	}
+	var db Database
+	switch engine {
+	case EngineLevelDB:
+		db, err = NewLDBDatabase(file, cache, handles)
+	case EngineBolt:
+		db, err = NewBoltDatabase(file)
+	default:
+		return nil, fmt.Errorf("unknown database engine %q", engine)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if recorded == "" {
+		if err := ioutil.WriteFile(filepath.Join(file, engineFile), []byte(engine+"\n"), 0600); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return db, nil
+}
@@ -30,6 +30,31 @@ type Deleter interface {
 	Delete(key []byte) error
 }
 
+// Iterator iterates over a database's key/value pairs in ascending key order.
+// It must be released after use, and cannot be used concurrently.
+type Iterator interface {
+	// Next moves the iterator to the next key/value pair. It returns whether the
+	// iterator is exhausted.
+	Next() bool
+
+	// Error returns any accumulated error. Exhausting all the key/value pairs
+	// is not considered to be an error.
+	Error() error
+
+	// Key returns the key of the current key/value pair, or nil if done. The
+	// caller should not modify the contents of the returned slice, and its
+	// contents may change on the next call to Next.
+	Key() []byte
+
+	// Value returns the value of the current key/value pair, or nil if done. The
+	// caller should not modify the contents of the returned slice, and its
+	// contents may change on the next call to Next.
+	Value() []byte
+
+	// Release releases associated resources.
+	Release()
+}
+
 // Database wraps all database operations. All methods are safe for concurrent use.
 type Database interface {
 	Putter
@@ -38,6 +63,22 @@ type Database interface {
 	Has(key []byte) (bool, error)
 	Close()
 	NewBatch() Batch
+
+	// NewIteratorWithPrefix returns an iterator over the subset of database
+	// content whose keys start with the given prefix.
+	NewIteratorWithPrefix(prefix []byte) Iterator
+
+	// DeleteRange removes all keys in the range [start, limit). A nil start is
+	// treated as a key before all keys, a nil limit as a key after all keys.
+	DeleteRange(start, limit []byte) error
+
+	// Compact flattens the underlying data store for the given key range. A nil
+	// start is treated as a key before all keys, a nil limit as a key after all
+	// keys.
+	Compact(start, limit []byte) error
+
+	// Stat returns a particular internal stat of the database.
+	Stat(property string) (string, error)
 }
 
 // Batch is a write-only database that commits changes to its host database
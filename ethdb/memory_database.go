@@ -17,7 +17,11 @@
 package ethdb
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/bcos-one/BCOS/common"
@@ -90,6 +94,63 @@ func (db *MemDatabase) Delete(key []byte) error {
 
 func (db *MemDatabase) Close() {}
 
+// NewIteratorWithPrefix returns an iterator over a snapshot of the database
+// content whose keys start with the given prefix.
+func (db *MemDatabase) NewIteratorWithPrefix(prefix []byte) Iterator {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	var (
+		pr     = string(prefix)
+		keys   = make([]string, 0, len(db.db))
+		values = make([][]byte, 0, len(db.db))
+	)
+	for key := range db.db {
+		if strings.HasPrefix(key, pr) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values = append(values, common.CopyBytes(db.db[key]))
+	}
+	return &memIterator{keys: keys, values: values, index: -1}
+}
+
+// DeleteRange removes all keys in the range [start, limit).
+func (db *MemDatabase) DeleteRange(start, limit []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	for key := range db.db {
+		if inRange([]byte(key), start, limit) {
+			delete(db.db, key)
+		}
+	}
+	return nil
+}
+
+// Compact is a no-op, there is nothing to flatten in memory.
+func (db *MemDatabase) Compact(start, limit []byte) error {
+	return nil
+}
+
+// Stat returns a particular internal stat of the database. The only supported
+// property is "memdb.stats", reporting the number and size of the entries.
+func (db *MemDatabase) Stat(property string) (string, error) {
+	if property != "memdb.stats" {
+		return "", errors.New("unknown property")
+	}
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	size := 0
+	for key, value := range db.db {
+		size += len(key) + len(value)
+	}
+	return fmt.Sprintf("Entries: %d, Size: %d bytes", len(db.db), size), nil
+}
+
 func (db *MemDatabase) NewBatch() Batch {
 	return &memBatch{db: db}
 }
@@ -141,3 +202,46 @@ func (b *memBatch) Reset() {
 	b.writes = b.writes[:0]
 	b.size = 0
 }
+
+// memIterator iterates over a sorted snapshot of a memory database.
+type memIterator struct {
+	keys   []string
+	values [][]byte
+	index  int
+	err    error
+}
+
+func (it *memIterator) Next() bool {
+	if it.err != nil || it.index >= len(it.keys) {
+		return false
+	}
+	it.index++
+	return it.index < len(it.keys)
+}
+
+func (it *memIterator) Error() error {
+	return it.err
+}
+
+func (it *memIterator) Key() []byte {
+	if it.index < 0 || it.index >= len(it.keys) {
+		return nil
+	}
+	return []byte(it.keys[it.index])
+}
+
+func (it *memIterator) Value() []byte {
+	if it.index < 0 || it.index >= len(it.keys) {
+		return nil
+	}
+	return it.values[it.index]
+}
+
+func (it *memIterator) Release() {
+	it.keys, it.values = nil, nil
+}
+
+// inRange reports whether key lies within [start, limit), nil bounds being open.
+func inRange(key, start, limit []byte) bool {
+	return bytes.Compare(key, start) >= 0 && (limit == nil || bytes.Compare(key, limit) < 0)
+}
@@ -49,3 +49,70 @@ func (dt *table) Delete(key []byte) error {
 func (dt *table) Close() {
 	// Do nothing; don't close the underlying DB.
 }
+
+// NewIteratorWithPrefix returns an iterator over the table content whose keys
+// start with the given prefix. The table prefix is stripped from the keys.
+func (dt *table) NewIteratorWithPrefix(prefix []byte) Iterator {
+	return &tableIterator{
+		it:     dt.db.NewIteratorWithPrefix(append([]byte(dt.prefix), prefix...)),
+		prefix: len(dt.prefix),
+	}
+}
+
+// DeleteRange removes all keys of the table in the range [start, limit).
+func (dt *table) DeleteRange(start, limit []byte) error {
+	start, limit = dt.bounds(start, limit)
+	return dt.db.DeleteRange(start, limit)
+}
+
+// Compact flattens the underlying data store for the given key range of the table.
+func (dt *table) Compact(start, limit []byte) error {
+	start, limit = dt.bounds(start, limit)
+	return dt.db.Compact(start, limit)
+}
+
+// Stat returns a particular internal stat of the underlying database.
+func (dt *table) Stat(property string) (string, error) {
+	return dt.db.Stat(property)
+}
+
+// bounds converts a key range of the table into a range of the underlying
+// database, limiting open ends to the table prefix.
+func (dt *table) bounds(start, limit []byte) ([]byte, []byte) {
+	start = append([]byte(dt.prefix), start...)
+	if limit != nil {
+		return start, append([]byte(dt.prefix), limit...)
+	}
+	// Find the smallest key greater than every key with the table prefix
+	limit = []byte(dt.prefix)
+	for i := len(limit) - 1; i >= 0; i-- {
+		if limit[i] < 0xff {
+			limit = append(limit[:i:i], limit[i]+1)
+			return start, limit
+		}
+	}
+	return start, nil
+}
+
+// tableIterator strips the table prefix from the keys of an iterator over the
+// underlying database.
+type tableIterator struct {
+	it     Iterator
+	prefix int
+}
+
+func (it *tableIterator) Next() bool { return it.it.Next() }
+
+func (it *tableIterator) Error() error { return it.it.Error() }
+
+func (it *tableIterator) Key() []byte {
+	key := it.it.Key()
+	if key == nil {
+		return nil
+	}
+	return key[it.prefix:]
+}
+
+func (it *tableIterator) Value() []byte { return it.it.Value() }
+
+func (it *tableIterator) Release() { it.it.Release() }
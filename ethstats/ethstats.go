@@ -33,6 +33,8 @@ import (
 	"github.com/bcos-one/BCOS/common"
 	"github.com/bcos-one/BCOS/common/mclock"
 	"github.com/bcos-one/BCOS/consensus"
+	"github.com/bcos-one/BCOS/consensus/dbft/backend"
+	"github.com/bcos-one/BCOS/consensus/wpoa"
 	"github.com/bcos-one/BCOS/core"
 	"github.com/bcos-one/BCOS/core/types"
 	"github.com/bcos-one/BCOS/eth"
@@ -670,6 +672,21 @@ type nodeStats struct {
 	Peers    int  `json:"peers"`
 	GasPrice int  `json:"gasPrice"`
 	Uptime   int  `json:"uptime"`
+
+	Consensus *consensusStats `json:"consensus,omitempty"`
+}
+
+// consensusStats is the sealing status of the consensus engine, reported only by
+// engines exposing their snapshot through their APIs.
+type consensusStats struct {
+	Engine     string           `json:"engine"`
+	Sealer     common.Address   `json:"sealer"`
+	InTurn     common.Address   `json:"inturn"`
+	Signers    []common.Address `json:"signers,omitempty"`
+	Managers   []common.Address `json:"managers,omitempty"`
+	Validators []common.Address `json:"validators,omitempty"`
+	Commits    int              `json:"commits"`
+	Missed     uint64           `json:"missed"`
 }
 
 // reportPending retrieves various stats about the node at the networking and
@@ -701,13 +718,14 @@ func (s *Service) reportStats(conn *websocket.Conn) error {
 	stats := map[string]interface{}{
 		"id": s.node,
 		"stats": &nodeStats{
-			Active:   true,
-			Mining:   mining,
-			Hashrate: hashrate,
-			Peers:    s.server.PeerCount(),
-			GasPrice: gasprice,
-			Syncing:  syncing,
-			Uptime:   100,
+			Active:    true,
+			Mining:    mining,
+			Hashrate:  hashrate,
+			Peers:     s.server.PeerCount(),
+			GasPrice:  gasprice,
+			Syncing:   syncing,
+			Uptime:    100,
+			Consensus: s.assembleConsensusStats(),
 		},
 	}
 	report := map[string][]interface{}{
@@ -715,3 +733,60 @@ func (s *Service) reportStats(conn *websocket.Conn) error {
 	}
 	return websocket.JSON.Send(conn, report)
 }
+
+// assembleConsensusStats retrieves the sealing status from the snapshot API of the
+// consensus engine, or nil if the engine does not expose one. Light clients do
+// not seal, so they don't report any.
+func (s *Service) assembleConsensusStats() *consensusStats {
+	if s.eth == nil || s.engine == nil {
+		return nil
+	}
+	return consensusStatsOf(s.engine.APIs(s.eth.BlockChain()))
+}
+
+// wpoaStatusAPI is the snapshot API of the wpoa engine.
+type wpoaStatusAPI interface {
+	GetStatus() (*wpoa.Status, error)
+}
+
+// dbftStatusAPI is the snapshot API of the dbft engine.
+type dbftStatusAPI interface {
+	GetStatus() (*backend.Status, error)
+}
+
+// consensusStatsOf assembles the consensus section of the node stats from the
+// first snapshot API among the APIs of a consensus engine.
+func consensusStatsOf(apis []rpc.API) *consensusStats {
+	for _, api := range apis {
+		switch api := api.Service.(type) {
+		case wpoaStatusAPI:
+			status, err := api.GetStatus()
+			if err != nil {
+				log.Debug("Failed to retrieve wpoa status", "err", err)
+				return nil
+			}
+			return &consensusStats{
+				Engine:   "wpoa",
+				Sealer:   status.Sealer,
+				InTurn:   status.InTurn,
+				Signers:  status.Signers,
+				Managers: status.Managers,
+			}
+		case dbftStatusAPI:
+			status, err := api.GetStatus()
+			if err != nil {
+				log.Debug("Failed to retrieve dbft status", "err", err)
+				return nil
+			}
+			return &consensusStats{
+				Engine:     "dbft",
+				Sealer:     status.Sealer,
+				InTurn:     status.InTurn,
+				Validators: status.Validators,
+				Commits:    status.Commits,
+				Missed:     status.Missed,
+			}
+		}
+	}
+	return nil
+}
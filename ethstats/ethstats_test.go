@@ -0,0 +1,109 @@
+package ethstats
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/consensus/dbft/backend"
+	"github.com/bcos-one/BCOS/consensus/ethash"
+	"github.com/bcos-one/BCOS/consensus/wpoa"
+	"github.com/bcos-one/BCOS/rpc"
+)
+
+type testWpoaAPI struct {
+	status *wpoa.Status
+	err    error
+}
+
+func (api *testWpoaAPI) GetStatus() (*wpoa.Status, error) { return api.status, api.err }
+
+type testDbftAPI struct {
+	status *backend.Status
+	err    error
+}
+
+func (api *testDbftAPI) GetStatus() (*backend.Status, error) { return api.status, api.err }
+
+// Tests that the consensus section of the node stats is assembled from the status
+// APIs of the wpoa and dbft engines, and left out for any other engine.
+func TestConsensusStats(t *testing.T) {
+	var (
+		sealer = common.HexToAddress("0x01")
+		other  = common.HexToAddress("0x02")
+	)
+	tests := []struct {
+		name string
+		apis []rpc.API
+		want string
+	}{
+		{
+			name: "no engine",
+			want: `{"active":false,"syncing":false,"mining":false,"hashrate":0,"peers":0,"gasPrice":0,"uptime":0}`,
+		},
+		{
+			name: "ethash",
+			apis: ethash.NewFaker().APIs(nil),
+			want: `{"active":false,"syncing":false,"mining":false,"hashrate":0,"peers":0,"gasPrice":0,"uptime":0}`,
+		},
+		{
+			name: "wpoa",
+			apis: []rpc.API{{Namespace: "wpoa", Service: &testWpoaAPI{status: &wpoa.Status{
+				Signers:  []common.Address{sealer, other},
+				Managers: []common.Address{sealer},
+				InTurn:   sealer,
+				Sealer:   sealer,
+			}}}},
+			want: `{"active":false,"syncing":false,"mining":false,"hashrate":0,"peers":0,"gasPrice":0,"uptime":0,"consensus":{` +
+				`"engine":"wpoa","sealer":"0x0000000000000000000000000000000000000001","inturn":"0x0000000000000000000000000000000000000001",` +
+				`"signers":["0x0000000000000000000000000000000000000001","0x0000000000000000000000000000000000000002"],` +
+				`"managers":["0x0000000000000000000000000000000000000001"],"commits":0,"missed":0}}`,
+		},
+		{
+			name: "wpoa failure",
+			apis: []rpc.API{{Namespace: "wpoa", Service: &testWpoaAPI{err: errors.New("no snapshot")}}},
+			want: `{"active":false,"syncing":false,"mining":false,"hashrate":0,"peers":0,"gasPrice":0,"uptime":0}`,
+		},
+		{
+			name: "dbft",
+			apis: []rpc.API{{Namespace: "dbft", Service: &testDbftAPI{status: &backend.Status{
+				Validators: []common.Address{sealer, other},
+				InTurn:     other,
+				Sealer:     sealer,
+				Commits:    2,
+				Missed:     1,
+			}}}},
+			want: `{"active":false,"syncing":false,"mining":false,"hashrate":0,"peers":0,"gasPrice":0,"uptime":0,"consensus":{` +
+				`"engine":"dbft","sealer":"0x0000000000000000000000000000000000000001","inturn":"0x0000000000000000000000000000000000000002",` +
+				`"validators":["0x0000000000000000000000000000000000000001","0x0000000000000000000000000000000000000002"],` +
+				`"commits":2,"missed":1}}`,
+		},
+		{
+			name: "dbft failure",
+			apis: []rpc.API{{Namespace: "dbft", Service: &testDbftAPI{err: errors.New("no snapshot")}}},
+			want: `{"active":false,"syncing":false,"mining":false,"hashrate":0,"peers":0,"gasPrice":0,"uptime":0}`,
+		},
+	}
+	for _, tt := range tests {
+		blob, err := json.Marshal(&nodeStats{Consensus: consensusStatsOf(tt.apis)})
+		if err != nil {
+			t.Fatalf("%s: failed to encode stats: %v", tt.name, err)
+		}
+		var have, want interface{}
+		json.Unmarshal(blob, &have)
+		json.Unmarshal([]byte(tt.want), &want)
+		if !reflect.DeepEqual(have, want) {
+			t.Errorf("%s: stats mismatch:\nhave %s\nwant %s", tt.name, blob, tt.want)
+		}
+	}
+}
+
+// Tests that light clients and services without an engine report no consensus
+// section.
+func TestConsensusStatsWithoutEngine(t *testing.T) {
+	if stats := new(Service).assembleConsensusStats(); stats != nil {
+		t.Fatalf("consensus stats without engine: have %+v, want nil", stats)
+	}
+}
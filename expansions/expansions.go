@@ -1,6 +1,9 @@
 package expansions
 
 import (
+	"math/big"
+
+	"github.com/bcos-one/BCOS/common"
 	"github.com/bcos-one/BCOS/core/state"
 	"github.com/bcos-one/BCOS/core/types"
 	"github.com/bcos-one/BCOS/expansions/management"
@@ -18,20 +21,23 @@ func NewExpansions(config *params.ChainConfig) *ExpansionsService {
 	}
 }
 
-func (self *ExpansionsService) ApplyMessage(db *state.StateDB, msg *types.Message) error {
+// ApplyMessage executes the expansion operation carried by a message included in
+// the block with the given number. Expansions not yet activated at that block
+// ignore the message.
+func (self *ExpansionsService) ApplyMessage(db *state.StateDB, msg *types.Message, num *big.Int) error {
 	to := msg.To()
 	if to == nil {
 		return nil
 	}
 
 	switch {
-	case self.TokenSupport && *to == self.TokenStorage:
-		return token.ApplyTokenOp(self.TokenStorage, db, msg)
+	case self.IsTokenEnabled(num) && *to == self.TokenStorage:
+		return token.ApplyTokenOp(self.ExpansionsConfig, db, msg, num)
 
-	case self.ManageSupport && *to == self.ManageStorage:
-		return management.ApplyManageOp(self.ExpansionsConfig, db, msg)
+	case self.IsManageEnabled(num) && *to == self.ManageStorage:
+		return management.ApplyManageOp(self.ExpansionsConfig, db, msg, num)
 
-	case self.IcapSupport && *to == self.IcapStorage:
+	case self.IsIcapEnabled(num) && *to == self.IcapStorage:
 		//TODO
 		return nil
 
@@ -42,11 +48,30 @@ func (self *ExpansionsService) ApplyMessage(db *state.StateDB, msg *types.Messag
 	return nil
 }
 
-
 func (self *ExpansionsService) InitGenesis(db *state.StateDB) error {
 
-	if self.ManageSupport {
+	if self.IsManageEnabled(common.Big0) {
 		management.SetManager(self.ManageStorage, self.Manager, db)
 	}
+	if self.IsManageRolesEnabled(common.Big0) {
+		management.EnableRoles(self.ManageStorage, self.managers(), db)
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+// ApplyForks sets up the expansions activated by the block with the given number
+// on a live chain, the same way InitGenesis does for those active since genesis.
+func (self *ExpansionsService) ApplyForks(db *state.StateDB, num *big.Int) {
+	if self.ManageSupport && self.ManageBlock != nil && self.ManageBlock.Sign() > 0 && self.ManageBlock.Cmp(num) == 0 {
+		management.SetManager(self.ManageStorage, self.Manager, db)
+	}
+	if self.ManageSupport && self.ManageRolesBlock != nil && self.ManageRolesBlock.Sign() > 0 && self.ManageRolesBlock.Cmp(num) == 0 {
+		management.EnableRoles(self.ManageStorage, self.managers(), db)
+	}
+}
+
+// managers returns the managers of the legacy layout to migrate into the admin
+// role at the management roles fork.
+func (self *ExpansionsService) managers() []common.Address {
+	return append([]common.Address{self.Manager}, self.LegacyManagers...)
+}
@@ -1,15 +1,67 @@
 package management
 
 import (
+	"math/big"
+
 	"github.com/bcos-one/BCOS/common"
 	"github.com/bcos-one/BCOS/core/state"
 	"github.com/bcos-one/BCOS/crypto"
 )
 
+// Role is a set of management permissions that can be granted to an account.
+type Role uint8
+
+const (
+	// RoleAdmin may grant and revoke every role and change the admin threshold.
+	// Admin actions only take effect once the threshold of admins approved them.
+	// It is the manager role of earlier releases.
+	RoleAdmin Role = iota
+
+	// RoleTokenWhitelister may add tokens to and remove them from the whitelist
+	// of tokens accepted for gas payment.
+	RoleTokenWhitelister
+
+	// RolePermissionManager may grant and revoke the token whitelister role
+	// without the approval of the admins.
+	RolePermissionManager
+
+	roleCount
+)
+
+var roleNames = []string{"admin", "tokenWhitelister", "permissionManager"}
+
+// String implements fmt.Stringer.
+func (r Role) String() string {
+	if r < roleCount {
+		return roleNames[r]
+	}
+	return "unknown"
+}
+
+// RoleByName returns the role with the given name.
+func RoleByName(name string) (Role, bool) {
+	for i, n := range roleNames {
+		if n == name {
+			return Role(i), true
+		}
+	}
+	return 0, false
+}
 
 var (
-	managerIndex   = common.BytesToHash([]byte{0x0}).Bytes()
-	whitelistIndex = common.BytesToHash([]byte{0x1}).Bytes()
+	managerIndex      = common.BytesToHash([]byte{0x0}).Bytes()
+	whitelistIndex    = common.BytesToHash([]byte{0x1}).Bytes()
+	whitelisterIndex  = common.BytesToHash([]byte{0x2}).Bytes()
+	permissionIndex   = common.BytesToHash([]byte{0x3}).Bytes()
+	thresholdIndex    = common.BytesToHash([]byte{0x4}).Bytes()
+	holdersIndex      = common.BytesToHash([]byte{0x5}).Bytes()
+	positionIndex     = common.BytesToHash([]byte{0x6}).Bytes()
+	approvalsIndex    = common.BytesToHash([]byte{0x7}).Bytes()
+	tenureIndex       = common.BytesToHash([]byte{0x8}).Bytes()
+	approvalRoundIdx  = common.BytesToHash([]byte{0x9}).Bytes()
+	gasQuotaIndex     = common.BytesToHash([]byte{0xa}).Bytes()
+	quotaEpochIndex   = common.BytesToHash([]byte{0xb}).Bytes()
+	roleMembersIndexs = [][]byte{managerIndex, whitelisterIndex, permissionIndex}
 )
 
 type ManageObj struct {
@@ -26,6 +78,9 @@ func NewManageObj(storage common.Address, from common.Address, db *state.StateDB
 	}
 }
 
+// SetManager makes the account a manager in the legacy single-manager layout,
+// which only sets its admin membership flag. The role registry is left untouched
+// until EnableRoles runs at the management roles fork.
 func SetManager(storage common.Address, manager common.Address, db *state.StateDB) error {
 	obj := NewManageObj(storage, common.Address{}, db)
 
@@ -33,6 +88,19 @@ func SetManager(storage common.Address, manager common.Address, db *state.StateD
 	return nil
 }
 
+// EnableRoles migrates the managers of the legacy layout into the holder list of
+// the admin role when the management roles activate. The legacy layout cannot be
+// enumerated, so every manager has to be listed; accounts that are no manager are
+// skipped.
+func EnableRoles(storage common.Address, managers []common.Address, db *state.StateDB) {
+	obj := NewManageObj(storage, common.Address{}, db)
+	for _, manager := range managers {
+		if obj.IsManager(manager) {
+			obj.enlist(RoleAdmin, manager)
+		}
+	}
+}
+
 func (self *ManageObj) SetManager(manager common.Address) error {
 	if !self.IsManager(self.from) {
 		return errUnauthorize
@@ -42,32 +110,206 @@ func (self *ManageObj) SetManager(manager common.Address) error {
 }
 
 func (self *ManageObj) setManager(manager common.Address) {
-	hashM := crypto.Keccak256Hash(append(manager.Hash().Bytes(), managerIndex[:]...))
-
-	self.db.SetState(self.storage, hashM, bool2Hash(true))
+	self.db.SetState(self.storage, self.memberHash(RoleAdmin, manager), bool2Hash(true))
 }
 
 func (self *ManageObj) DelManager(manager common.Address) error {
 	if !self.IsManager(self.from) {
 		return errUnauthorize
 	}
-	hashM := crypto.Keccak256Hash(append(manager.Hash().Bytes(), managerIndex[:]...))
-
-	self.db.SetState(self.storage, hashM, bool2Hash(false))
-	return nil
+	return self.revokeRole(RoleAdmin, manager)
 }
 
 func (self *ManageObj) IsManager(manager common.Address) bool {
-	hashM := crypto.Keccak256Hash(append(manager.Hash().Bytes(), managerIndex[:]...))
+	return self.HasRole(RoleAdmin, manager)
+}
 
-	hash := self.db.GetState(self.storage, hashM)
+// HasRole reports whether the account holds the given role.
+func (self *ManageObj) HasRole(role Role, account common.Address) bool {
+	if role >= roleCount {
+		return false
+	}
+	hash := self.db.GetState(self.storage, self.memberHash(role, account))
 
 	ok, _ := readBool(hash)
 	return ok
 }
 
-func (self *ManageObj) SetTokenWhiteList(tokenId common.Address) error {
+// RoleHolders returns the accounts holding the given role, in the order the
+// role was granted to them.
+func (self *ManageObj) RoleHolders(role Role) []common.Address {
+	if role >= roleCount {
+		return nil
+	}
+	length := self.db.GetState(self.storage, self.holdersHash(role)).Big().Uint64()
+
+	holders := make([]common.Address, 0, length)
+	for i := uint64(0); i < length; i++ {
+		holders = append(holders, common.BytesToAddress(self.db.GetState(self.storage, self.holderHash(role, i)).Bytes()))
+	}
+	return holders
+}
+
+// AdminThreshold returns the number of admins that must approve an admin action
+// before it takes effect.
+func (self *ManageObj) AdminThreshold() uint64 {
+	threshold := self.db.GetState(self.storage, common.BytesToHash(thresholdIndex)).Big().Uint64()
+	if threshold == 0 {
+		return 1
+	}
+	return threshold
+}
+
+// Approvals returns the number of current admins that approved the given admin
+// action so far. Approvals of accounts that lost the admin role do not count.
+func (self *ManageObj) Approvals(action common.Hash) uint64 {
+	var count uint64
+	for _, admin := range self.RoleHolders(RoleAdmin) {
+		if approved, _ := readBool(self.db.GetState(self.storage, self.approvalHash(action, admin))); approved {
+			count++
+		}
+	}
+	return count
+}
+
+// approve records the approval of the sender for an admin action, returning
+// whether the action collected enough approvals to be executed. Executing starts
+// a new approval round, so the same action can be proposed again later.
+func (self *ManageObj) approve(action common.Hash) (bool, error) {
 	if !self.IsManager(self.from) {
+		return false, errUnauthorize
+	}
+	if !self.enlisted(RoleAdmin, self.from) {
+		// Managers act alone until the roles fork migrates them into the holder
+		// list, afterwards only enlisted admins may approve.
+		if self.db.GetState(self.storage, self.holdersHash(RoleAdmin)) != (common.Hash{}) {
+			return false, errUnauthorize
+		}
+		return true, nil
+	}
+	approval := self.approvalHash(action, self.from)
+	if approved, _ := readBool(self.db.GetState(self.storage, approval)); approved {
+		return false, errAlreadyApproved
+	}
+	self.db.SetState(self.storage, approval, bool2Hash(true))
+	if self.Approvals(action) < self.AdminThreshold() {
+		return false, nil
+	}
+	roundHash := self.approvalRoundHash(action)
+	round := self.db.GetState(self.storage, roundHash).Big()
+	self.db.SetState(self.storage, roundHash, common.BigToHash(round.Add(round, common.Big1)))
+	return true, nil
+}
+
+// enlisted reports whether the account is in the holder list of the role.
+func (self *ManageObj) enlisted(role Role, account common.Address) bool {
+	return self.db.GetState(self.storage, self.positionHash(role, account)) != (common.Hash{})
+}
+
+// checkThreshold verifies that the admin threshold can be set to the given value,
+// which must lie between one and the number of admins.
+func (self *ManageObj) checkThreshold(threshold *big.Int) error {
+	if threshold.Sign() <= 0 || threshold.Cmp(new(big.Int).SetInt64(int64(len(self.RoleHolders(RoleAdmin))))) > 0 {
+		return errInvalidThreshold
+	}
+	return nil
+}
+
+// setAdminThreshold changes the number of approvals admin actions need.
+func (self *ManageObj) setAdminThreshold(threshold uint64) {
+	self.db.SetState(self.storage, common.BytesToHash(thresholdIndex), common.BigToHash(new(big.Int).SetUint64(threshold)))
+}
+
+// GasQuota returns the gas the account may submit to transaction pools per quota
+// epoch, zero if it is unlimited.
+func (self *ManageObj) GasQuota(account common.Address) uint64 {
+	return self.db.GetState(self.storage, self.gasQuotaHash(account)).Big().Uint64()
+}
+
+// QuotaEpoch returns the length of the gas quota epochs in blocks, zero if gas
+// quotas are disabled.
+func (self *ManageObj) QuotaEpoch() uint64 {
+	return self.db.GetState(self.storage, common.BytesToHash(quotaEpochIndex)).Big().Uint64()
+}
+
+// setGasQuota changes the gas quota of an account.
+func (self *ManageObj) setGasQuota(account common.Address, quota uint64) {
+	self.db.SetState(self.storage, self.gasQuotaHash(account), common.BigToHash(new(big.Int).SetUint64(quota)))
+}
+
+// setQuotaEpoch changes the length of the gas quota epochs.
+func (self *ManageObj) setQuotaEpoch(blocks uint64) {
+	self.db.SetState(self.storage, common.BytesToHash(quotaEpochIndex), common.BigToHash(new(big.Int).SetUint64(blocks)))
+}
+
+// checkRevoke verifies that the role can be taken from the account without
+// leaving fewer admins behind than needed to approve admin actions.
+func (self *ManageObj) checkRevoke(role Role, account common.Address) error {
+	if role == RoleAdmin && self.HasRole(role, account) && uint64(len(self.RoleHolders(RoleAdmin))) <= self.AdminThreshold() {
+		return errInvalidThreshold
+	}
+	return nil
+}
+
+// grantRole gives the role to the account. Granting it again to a holder missing
+// from the holder list, like a legacy manager not migrated at the roles fork,
+// enlists the holder.
+func (self *ManageObj) grantRole(role Role, account common.Address) {
+	self.db.SetState(self.storage, self.memberHash(role, account), bool2Hash(true))
+	self.enlist(role, account)
+}
+
+// enlist appends the account to the holder list of the role, remembering its
+// 1-based position. It does nothing for accounts already enlisted.
+func (self *ManageObj) enlist(role Role, account common.Address) {
+	if self.enlisted(role, account) {
+		return
+	}
+	length := self.db.GetState(self.storage, self.holdersHash(role)).Big().Uint64()
+	self.db.SetState(self.storage, self.holderHash(role, length), account.Hash())
+	self.db.SetState(self.storage, self.positionHash(role, account), common.BigToHash(new(big.Int).SetUint64(length+1)))
+	self.db.SetState(self.storage, self.holdersHash(role), common.BigToHash(new(big.Int).SetUint64(length+1)))
+}
+
+func (self *ManageObj) revokeRole(role Role, account common.Address) error {
+	if !self.HasRole(role, account) {
+		return nil
+	}
+	if err := self.checkRevoke(role, account); err != nil {
+		return err
+	}
+	self.db.SetState(self.storage, self.memberHash(role, account), bool2Hash(false))
+
+	// Start a new tenure for former admins, so their pending approvals are not
+	// revived if they are granted the role again.
+	if role == RoleAdmin {
+		tenure := self.db.GetState(self.storage, self.tenureHash(account)).Big()
+		self.db.SetState(self.storage, self.tenureHash(account), common.BigToHash(tenure.Add(tenure, common.Big1)))
+	}
+	// Swap the last holder into the position of the removed one. Accounts that
+	// received the role before holder lists existed have no position.
+	position := self.db.GetState(self.storage, self.positionHash(role, account)).Big().Uint64()
+	if position == 0 {
+		return nil
+	}
+	length := self.db.GetState(self.storage, self.holdersHash(role)).Big().Uint64()
+	if position != length {
+		last := self.db.GetState(self.storage, self.holderHash(role, length-1))
+		self.db.SetState(self.storage, self.holderHash(role, position-1), last)
+		self.db.SetState(self.storage, self.positionHash(role, common.BytesToAddress(last.Bytes())), common.BigToHash(new(big.Int).SetUint64(position)))
+	}
+	self.db.SetState(self.storage, self.holderHash(role, length-1), common.Hash{})
+	self.db.SetState(self.storage, self.positionHash(role, account), common.Hash{})
+	self.db.SetState(self.storage, self.holdersHash(role), common.BigToHash(new(big.Int).SetUint64(length-1)))
+	return nil
+}
+
+func (self *ManageObj) canWhitelist() bool {
+	return self.IsManager(self.from) || self.HasRole(RoleTokenWhitelister, self.from)
+}
+
+func (self *ManageObj) SetTokenWhiteList(tokenId common.Address) error {
+	if !self.canWhitelist() {
 		return errUnauthorize
 	}
 	hashW := crypto.Keccak256Hash(append(tokenId.Hash().Bytes(), whitelistIndex[:]...))
@@ -78,12 +320,11 @@ func (self *ManageObj) SetTokenWhiteList(tokenId common.Address) error {
 }
 
 func (self *ManageObj) DelTokenWhiteList(tokenId common.Address) error {
-	if !self.IsManager(self.from) {
+	if !self.canWhitelist() {
 		return errUnauthorize
 	}
 	hashW := crypto.Keccak256Hash(append(tokenId.Hash().Bytes(), whitelistIndex[:]...))
 
-
 	self.db.SetState(self.storage, hashW, bool2Hash(false))
 
 	return nil
@@ -97,6 +338,51 @@ func (self *ManageObj) IsTokenInWhiteList(tokenId common.Address) bool {
 	return ok
 }
 
+// memberHash is the slot of the membership flag of an account, laid out like a
+// solidity mapping rooted at the slot of the role.
+func (self *ManageObj) memberHash(role Role, account common.Address) common.Hash {
+	return crypto.Keccak256Hash(append(account.Hash().Bytes(), roleMembersIndexs[role]...))
+}
+
+// holdersHash is the slot of the length of the holder list of a role.
+func (self *ManageObj) holdersHash(role Role) common.Hash {
+	return crypto.Keccak256Hash(common.BigToHash(big.NewInt(int64(role))).Bytes(), holdersIndex)
+}
+
+// holderHash is the slot of the i-th element of the holder list of a role.
+func (self *ManageObj) holderHash(role Role, i uint64) common.Hash {
+	base := crypto.Keccak256Hash(self.holdersHash(role).Bytes()).Big()
+	return common.BigToHash(base.Add(base, new(big.Int).SetUint64(i)))
+}
+
+// positionHash is the slot of the 1-based position of an account in the holder
+// list of a role.
+func (self *ManageObj) positionHash(role Role, account common.Address) common.Hash {
+	return crypto.Keccak256Hash(account.Hash().Bytes(), crypto.Keccak256(common.BigToHash(big.NewInt(int64(role))).Bytes(), positionIndex))
+}
+
+func (self *ManageObj) approvalRoundHash(action common.Hash) common.Hash {
+	return crypto.Keccak256Hash(action.Bytes(), approvalRoundIdx)
+}
+
+// tenureHash is the slot of the number of times an account lost the admin role.
+func (self *ManageObj) tenureHash(account common.Address) common.Hash {
+	return crypto.Keccak256Hash(account.Hash().Bytes(), tenureIndex)
+}
+
+// approvalHash is the slot of the approval flag of an admin for an action in the
+// current approval round and the current tenure of the admin.
+func (self *ManageObj) approvalHash(action common.Hash, admin common.Address) common.Hash {
+	round := self.db.GetState(self.storage, self.approvalRoundHash(action))
+	tenure := self.db.GetState(self.storage, self.tenureHash(admin))
+	return crypto.Keccak256Hash(admin.Hash().Bytes(), crypto.Keccak256(action.Bytes(), round.Bytes(), tenure.Bytes(), approvalsIndex))
+}
+
+// gasQuotaHash is the slot of the gas quota of an account.
+func (self *ManageObj) gasQuotaHash(account common.Address) common.Hash {
+	return crypto.Keccak256Hash(account.Hash().Bytes(), gasQuotaIndex)
+}
+
 func bool2Hash(flag bool) common.Hash {
 	if flag {
 		return common.BytesToHash([]byte{0x01})
@@ -120,4 +406,4 @@ func readBool(hash common.Hash) (bool, error) {
 	default:
 		return false, errBadBool
 	}
-}
\ No newline at end of file
+}
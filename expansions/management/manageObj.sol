@@ -3,7 +3,16 @@ pragma solidity ^0.4.24;
 contract manageObj {
     mapping(address => bool) managers;
     mapping(address => bool) whitelist;
+    mapping(address => bool) whitelisters;
+    mapping(address => bool) permissionManagers;
+    uint256 adminThreshold;
 
     function setWhiteList(address tokenid) public;
     function delWhiteList(address tokenid) public;
+
+    function grantRole(uint8 role, address account) public;
+    function revokeRole(uint8 role, address account) public;
+    function setAdminThreshold(uint256 threshold) public;
+    function setGasQuota(address account, uint256 quota) public;
+    function setQuotaEpoch(uint256 blocks) public;
 }
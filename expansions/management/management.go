@@ -8,12 +8,18 @@ import (
 	"github.com/bcos-one/BCOS/common"
 	"github.com/bcos-one/BCOS/core/state"
 	"github.com/bcos-one/BCOS/core/types"
+	"github.com/bcos-one/BCOS/crypto"
 	"github.com/bcos-one/BCOS/expansions/token"
 	"github.com/bcos-one/BCOS/params"
+	"math/big"
 	"strings"
 )
 
-const manageAbi = `[{"constant":false,"inputs":[{"name":"tokenid","type":"address"}],"name":"setWhiteList","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"tokenid","type":"address"}],"name":"delWhiteList","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"}]`
+const manageAbi = `[{"constant":false,"inputs":[{"name":"tokenid","type":"address"}],"name":"setWhiteList","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"tokenid","type":"address"}],"name":"delWhiteList","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"role","type":"uint8"},{"name":"account","type":"address"}],"name":"grantRole","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"role","type":"uint8"},{"name":"account","type":"address"}],"name":"revokeRole","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"threshold","type":"uint256"}],"name":"setAdminThreshold","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"account","type":"address"},{"name":"quota","type":"uint256"}],"name":"setGasQuota","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"},{"constant":false,"inputs":[{"name":"blocks","type":"uint256"}],"name":"setQuotaEpoch","outputs":[],"payable":false,"stateMutability":"nonpayable","type":"function"}]`
+
+// ABI is the JSON interface of the operations of the management storage, for tools
+// decoding the transactions sent to it.
+const ABI = manageAbi
 
 var (
 	errBadBool      = errors.New("improperly encoded boolean value")
@@ -21,6 +27,11 @@ var (
 	errInvalidInput = errors.New("invalid input for management operation")
 	errInvalidSig   = errors.New("invalid management operation signature")
 	errInvalidToke  = errors.New("invalid token id")
+
+	errInvalidRole      = errors.New("invalid management role")
+	errInvalidThreshold = errors.New("invalid admin threshold")
+	errAlreadyApproved  = errors.New("admin action already approved")
+	errInvalidQuota     = errors.New("invalid gas quota")
 )
 
 var (
@@ -29,9 +40,27 @@ var (
 
 	// web3.sha3("delWhiteList(address)") = 0x605e5ee189a8a221d02b35a141f8fa5f96a4a4a6d8b2e3d1f5bd9a953b8dd72e
 	delWlSig, _ = hex.DecodeString("605e5ee1")
+
+	// web3.sha3("grantRole(uint8,address)") = 0x5d5664e1edaa319a559dcd5e9c098a058b06c650646b1e7f3235a45ecec22762
+	grantRoleSig, _ = hex.DecodeString("5d5664e1")
+
+	// web3.sha3("revokeRole(uint8,address)") = 0x4cbb87d38f33e49ad1a451bf768ba8eab12ef23ee8d5a82f255cea6ed6015971
+	revokeRoleSig, _ = hex.DecodeString("4cbb87d3")
+
+	// web3.sha3("setAdminThreshold(uint256)") = 0x5af28cf93016dcd237d4b93236add10e591dfd54e3f64d4efc71214a0f5fa4ea
+	setThresholdSig, _ = hex.DecodeString("5af28cf9")
+
+	// web3.sha3("setGasQuota(address,uint256)") = 0xd14254c5270c1817e316669cab97712c36de629f0e3653ed3189ab64339d2a64
+	setGasQuotaSig, _ = hex.DecodeString("d14254c5")
+
+	// web3.sha3("setQuotaEpoch(uint256)") = 0xf9962d93d347ac74d91e75deb9c4104ec220b78771788b1f1cd322b92b00cd58
+	setQuotaEpochSig, _ = hex.DecodeString("f9962d93")
 )
 
-func ApplyManageOp(config *params.ExpansionsConfig, db *state.StateDB, msg *types.Message) error {
+// ApplyManageOp executes the management operation carried by a message included
+// in the block with the given number. Operations of forks not yet active at that
+// block are rejected as unknown, like they were before the fork.
+func ApplyManageOp(config *params.ExpansionsConfig, db *state.StateDB, msg *types.Message, num *big.Int) error {
 	input := msg.Data()
 	from := msg.From()
 
@@ -40,11 +69,23 @@ func ApplyManageOp(config *params.ExpansionsConfig, db *state.StateDB, msg *type
 	}
 
 	sig := input[:4]
+	roles := config.IsManageRolesEnabled(num)
+	quotas := config.IsGasQuotaEnabled(num)
 	switch {
 	case bytes.Equal(sig, setWlSig):
 		return addWhiteList(config, from, db, input[4:])
 	case bytes.Equal(sig, delWlSig):
 		return delWhiteList(config, from, db, input[4:])
+	case roles && bytes.Equal(sig, grantRoleSig):
+		return changeRole(config, from, db, input, true)
+	case roles && bytes.Equal(sig, revokeRoleSig):
+		return changeRole(config, from, db, input, false)
+	case roles && bytes.Equal(sig, setThresholdSig):
+		return setAdminThreshold(config, from, db, input)
+	case quotas && bytes.Equal(sig, setGasQuotaSig):
+		return setGasQuota(config, from, db, input)
+	case quotas && bytes.Equal(sig, setQuotaEpochSig):
+		return setQuotaEpoch(config, from, db, input)
 	default:
 		return errInvalidSig
 	}
@@ -85,3 +126,109 @@ func delWhiteList(config *params.ExpansionsConfig, from common.Address, db *stat
 	manageObj := NewManageObj(config.ManageStorage, from, db)
 	return manageObj.DelTokenWhiteList(tokenid)
 }
+
+type roleParams struct {
+	Role    uint8
+	Account common.Address
+}
+
+// changeRole grants or revokes a role. Permission managers may hand out the token
+// whitelister role on their own, any other change is an admin action that only
+// takes effect once enough admins sent the very same request.
+func changeRole(config *params.ExpansionsConfig, from common.Address, db *state.StateDB, input []byte, grant bool) error {
+	method := "revokeRole"
+	if grant {
+		method = "grantRole"
+	}
+	var args roleParams
+	decoder, _ := abi.JSON(strings.NewReader(manageAbi))
+
+	if err := decoder.UnpackInput(&args, method, input[4:]); err != nil {
+		return errInvalidInput
+	}
+	role := Role(args.Role)
+	if role >= roleCount {
+		return errInvalidRole
+	}
+	manageObj := NewManageObj(config.ManageStorage, from, db)
+	if !grant {
+		if err := manageObj.checkRevoke(role, args.Account); err != nil {
+			return err
+		}
+	}
+	if role != RoleTokenWhitelister || !manageObj.HasRole(RolePermissionManager, from) {
+		if ok, err := manageObj.approve(crypto.Keccak256Hash(input)); !ok {
+			return err
+		}
+	}
+	if grant {
+		manageObj.grantRole(role, args.Account)
+		return nil
+	}
+	return manageObj.revokeRole(role, args.Account)
+}
+
+// setAdminThreshold changes the number of admins needed to approve admin actions,
+// which is an admin action itself.
+func setAdminThreshold(config *params.ExpansionsConfig, from common.Address, db *state.StateDB, input []byte) error {
+	var threshold *big.Int
+	decoder, _ := abi.JSON(strings.NewReader(manageAbi))
+
+	if err := decoder.UnpackInput(&threshold, "setAdminThreshold", input[4:]); err != nil {
+		return errInvalidInput
+	}
+	manageObj := NewManageObj(config.ManageStorage, from, db)
+	if err := manageObj.checkThreshold(threshold); err != nil {
+		return err
+	}
+	if ok, err := manageObj.approve(crypto.Keccak256Hash(input)); !ok {
+		return err
+	}
+	manageObj.setAdminThreshold(threshold.Uint64())
+	return nil
+}
+
+type quotaParams struct {
+	Account common.Address
+	Quota   *big.Int
+}
+
+// setGasQuota changes the gas an account may submit to transaction pools per
+// quota epoch, which is an admin action.
+func setGasQuota(config *params.ExpansionsConfig, from common.Address, db *state.StateDB, input []byte) error {
+	var args quotaParams
+	decoder, _ := abi.JSON(strings.NewReader(manageAbi))
+
+	if err := decoder.UnpackInput(&args, "setGasQuota", input[4:]); err != nil {
+		return errInvalidInput
+	}
+	if !args.Quota.IsUint64() {
+		return errInvalidQuota
+	}
+	manageObj := NewManageObj(config.ManageStorage, from, db)
+	if ok, err := manageObj.approve(crypto.Keccak256Hash(input)); !ok {
+		return err
+	}
+	manageObj.setGasQuota(args.Account, args.Quota.Uint64())
+	return nil
+}
+
+// setQuotaEpoch changes the length in blocks of the epochs gas quotas apply to,
+// which is an admin action. Zero disables the gas quotas.
+func setQuotaEpoch(config *params.ExpansionsConfig, from common.Address, db *state.StateDB, input []byte) error {
+	var blocks *big.Int
+	decoder, _ := abi.JSON(strings.NewReader(manageAbi))
+
+	if err := decoder.UnpackInput(&blocks, "setQuotaEpoch", input[4:]); err != nil {
+		return errInvalidInput
+	}
+	if !blocks.IsUint64() {
+		return errInvalidQuota
+	}
+	manageObj := NewManageObj(config.ManageStorage, from, db)
+	if ok, err := manageObj.approve(crypto.Keccak256Hash(input)); !ok {
+		return err
+	}
+	manageObj.setQuotaEpoch(blocks.Uint64())
+	return nil
+}
@@ -0,0 +1,315 @@
+package management
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bcos-one/BCOS/accounts/abi"
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/core/state"
+	"github.com/bcos-one/BCOS/core/types"
+	"github.com/bcos-one/BCOS/crypto"
+	"github.com/bcos-one/BCOS/ethdb"
+	"github.com/bcos-one/BCOS/params"
+)
+
+func TestRoles(t *testing.T) {
+	var (
+		config = &params.ExpansionsConfig{
+			ManageSupport:    true,
+			ManageStorage:    common.HexToAddress("0x2000"),
+			ManageRolesBlock: common.Big1,
+			TokenStorage:     common.HexToAddress("0x1000"),
+		}
+		alice = common.HexToAddress("0x01")
+		bob   = common.HexToAddress("0x02")
+		carol = common.HexToAddress("0x03")
+		dave  = common.HexToAddress("0x04")
+	)
+	db, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	parsed, _ := abi.JSON(strings.NewReader(manageAbi))
+
+	apply := func(from common.Address, method string, args ...interface{}) error {
+		input, err := parsed.Pack(method, args...)
+		if err != nil {
+			t.Fatalf("failed to pack %s: %v", method, err)
+		}
+		msg := types.NewMessage(from, &config.ManageStorage, 0, new(big.Int), 100000, new(big.Int), input, false)
+		return ApplyManageOp(config, db, &msg, common.Big1)
+	}
+	SetManager(config.ManageStorage, alice, db)
+	EnableRoles(config.ManageStorage, []common.Address{alice}, db)
+	obj := NewManageObj(config.ManageStorage, common.Address{}, db)
+
+	// With a single admin every admin action applies right away
+	if err := apply(alice, "grantRole", uint8(RoleAdmin), bob); err != nil {
+		t.Fatalf("failed to grant admin: %v", err)
+	}
+	if err := apply(alice, "grantRole", uint8(RolePermissionManager), carol); err != nil {
+		t.Fatalf("failed to grant permission manager: %v", err)
+	}
+	if holders := obj.RoleHolders(RoleAdmin); !reflect.DeepEqual(holders, []common.Address{alice, bob}) {
+		t.Fatalf("admin holders mismatch: have %x", holders)
+	}
+	if err := apply(carol, "grantRole", uint8(RoleAdmin), carol); err != errUnauthorize {
+		t.Fatalf("self promotion error mismatch: have %v, want %v", err, errUnauthorize)
+	}
+	// Permission managers hand out the whitelister role without admin approval
+	if err := apply(carol, "grantRole", uint8(RoleTokenWhitelister), dave); err != nil || !obj.HasRole(RoleTokenWhitelister, dave) {
+		t.Fatalf("failed to grant whitelister: %v", err)
+	}
+	// Raise the threshold, the next admin action needs both admins
+	if err := apply(alice, "setAdminThreshold", big.NewInt(3)); err != errInvalidThreshold {
+		t.Fatalf("oversized threshold error mismatch: have %v, want %v", err, errInvalidThreshold)
+	}
+	if err := apply(alice, "setAdminThreshold", big.NewInt(2)); err != nil || obj.AdminThreshold() != 2 {
+		t.Fatalf("failed to set threshold: %v", err)
+	}
+	if err := apply(alice, "revokeRole", uint8(RoleAdmin), bob); err != errInvalidThreshold {
+		t.Fatalf("threshold breaking revoke error mismatch: have %v, want %v", err, errInvalidThreshold)
+	}
+	if err := apply(alice, "grantRole", uint8(RoleAdmin), dave); err != nil || obj.IsManager(dave) {
+		t.Fatalf("admin granted with a single approval: %v", err)
+	}
+	if err := apply(alice, "grantRole", uint8(RoleAdmin), dave); err != errAlreadyApproved {
+		t.Fatalf("double approval error mismatch: have %v, want %v", err, errAlreadyApproved)
+	}
+	if err := apply(bob, "grantRole", uint8(RoleAdmin), dave); err != nil || !obj.IsManager(dave) {
+		t.Fatalf("admin not granted after threshold approvals: %v", err)
+	}
+	// Revoking swaps the last holder into the freed position
+	if err := apply(bob, "revokeRole", uint8(RoleAdmin), alice); err != nil {
+		t.Fatalf("failed to approve revoke: %v", err)
+	}
+	if err := apply(dave, "revokeRole", uint8(RoleAdmin), alice); err != nil || obj.IsManager(alice) {
+		t.Fatalf("admin not revoked after threshold approvals: %v", err)
+	}
+	if holders := obj.RoleHolders(RoleAdmin); !reflect.DeepEqual(holders, []common.Address{dave, bob}) {
+		t.Fatalf("admin holders mismatch after revoke: have %x", holders)
+	}
+	if err := apply(alice, "grantRole", uint8(RoleTokenWhitelister), alice); err != errUnauthorize {
+		t.Fatalf("revoked admin error mismatch: have %v, want %v", err, errUnauthorize)
+	}
+}
+
+func TestGasQuotas(t *testing.T) {
+	var (
+		config = &params.ExpansionsConfig{
+			ManageSupport: true,
+			ManageStorage: common.HexToAddress("0x2000"),
+			GasQuotaBlock: common.Big1,
+		}
+		alice = common.HexToAddress("0x01")
+		bob   = common.HexToAddress("0x02")
+	)
+	db, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	parsed, _ := abi.JSON(strings.NewReader(manageAbi))
+
+	applyAt := func(num *big.Int, from common.Address, method string, args ...interface{}) error {
+		input, err := parsed.Pack(method, args...)
+		if err != nil {
+			t.Fatalf("failed to pack %s: %v", method, err)
+		}
+		msg := types.NewMessage(from, &config.ManageStorage, 0, new(big.Int), 100000, new(big.Int), input, false)
+		return ApplyManageOp(config, db, &msg, num)
+	}
+	apply := func(from common.Address, method string, args ...interface{}) error {
+		return applyAt(common.Big1, from, method, args...)
+	}
+	SetManager(config.ManageStorage, alice, db)
+	obj := NewManageObj(config.ManageStorage, common.Address{}, db)
+
+	// Quotas are unknown operations before their fork
+	if err := applyAt(common.Big0, alice, "setGasQuota", bob, big.NewInt(1000000)); err != errInvalidSig {
+		t.Fatalf("pre-fork quota error mismatch: have %v, want %v", err, errInvalidSig)
+	}
+	if err := applyAt(common.Big0, alice, "setQuotaEpoch", big.NewInt(100)); err != errInvalidSig {
+		t.Fatalf("pre-fork epoch error mismatch: have %v, want %v", err, errInvalidSig)
+	}
+
+	// Quotas are admin actions
+	if err := apply(bob, "setGasQuota", bob, big.NewInt(1000000)); err != errUnauthorize {
+		t.Fatalf("unauthorized quota error mismatch: have %v, want %v", err, errUnauthorize)
+	}
+	if err := apply(alice, "setGasQuota", bob, big.NewInt(1000000)); err != nil || obj.GasQuota(bob) != 1000000 {
+		t.Fatalf("failed to set gas quota: %v", err)
+	}
+	if err := apply(alice, "setQuotaEpoch", big.NewInt(100)); err != nil || obj.QuotaEpoch() != 100 {
+		t.Fatalf("failed to set quota epoch: %v", err)
+	}
+	if quota := obj.GasQuota(alice); quota != 0 {
+		t.Fatalf("unset gas quota mismatch: have %d, want 0", quota)
+	}
+	// Oversized quotas are rejected
+	if err := apply(alice, "setGasQuota", bob, new(big.Int).Lsh(common.Big1, 64)); err != errInvalidQuota {
+		t.Fatalf("oversized quota error mismatch: have %v, want %v", err, errInvalidQuota)
+	}
+}
+
+func TestRolesFork(t *testing.T) {
+	var (
+		config = &params.ExpansionsConfig{
+			ManageSupport:    true,
+			ManageStorage:    common.HexToAddress("0x2000"),
+			ManageRolesBlock: big.NewInt(10),
+		}
+		alice = common.HexToAddress("0x01")
+		bob   = common.HexToAddress("0x02")
+	)
+	// The legacy manager only occupies its membership flag, keeping the genesis
+	// state of chains created before the roles existed
+	db, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	SetManager(config.ManageStorage, alice, db)
+
+	legacy, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	legacy.SetState(config.ManageStorage, crypto.Keccak256Hash(alice.Hash().Bytes(), managerIndex), bool2Hash(true))
+
+	if have, want := db.IntermediateRoot(false), legacy.IntermediateRoot(false); have != want {
+		t.Fatalf("legacy manager state root mismatch: have %x, want %x", have, want)
+	}
+	// Role operations are unknown before the fork
+	parsed, _ := abi.JSON(strings.NewReader(manageAbi))
+	input, _ := parsed.Pack("grantRole", uint8(RoleAdmin), bob)
+	msg := types.NewMessage(alice, &config.ManageStorage, 0, new(big.Int), 100000, new(big.Int), input, false)
+
+	if err := ApplyManageOp(config, db, &msg, big.NewInt(9)); err != errInvalidSig {
+		t.Fatalf("pre-fork role operation error mismatch: have %v, want %v", err, errInvalidSig)
+	}
+	// The fork migrates the legacy manager into the admin role
+	EnableRoles(config.ManageStorage, []common.Address{alice}, db)
+
+	obj := NewManageObj(config.ManageStorage, common.Address{}, db)
+	if holders := obj.RoleHolders(RoleAdmin); !reflect.DeepEqual(holders, []common.Address{alice}) {
+		t.Fatalf("admin holders mismatch after fork: have %x", holders)
+	}
+	if err := ApplyManageOp(config, db, &msg, big.NewInt(10)); err != nil || !obj.IsManager(bob) {
+		t.Fatalf("failed to grant admin after fork: %v", err)
+	}
+}
+
+func TestStaleApprovals(t *testing.T) {
+	var (
+		config = &params.ExpansionsConfig{
+			ManageSupport:    true,
+			ManageStorage:    common.HexToAddress("0x2000"),
+			ManageRolesBlock: common.Big1,
+			GasQuotaBlock:    common.Big1,
+		}
+		alice = common.HexToAddress("0x01")
+		bob   = common.HexToAddress("0x02")
+		carol = common.HexToAddress("0x03")
+		dave  = common.HexToAddress("0x04")
+	)
+	db, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	parsed, _ := abi.JSON(strings.NewReader(manageAbi))
+
+	apply := func(from common.Address, method string, args ...interface{}) error {
+		input, err := parsed.Pack(method, args...)
+		if err != nil {
+			t.Fatalf("failed to pack %s: %v", method, err)
+		}
+		msg := types.NewMessage(from, &config.ManageStorage, 0, new(big.Int), 100000, new(big.Int), input, false)
+		return ApplyManageOp(config, db, &msg, common.Big1)
+	}
+	action := func(method string, args ...interface{}) common.Hash {
+		input, _ := parsed.Pack(method, args...)
+		return crypto.Keccak256Hash(input)
+	}
+	SetManager(config.ManageStorage, alice, db)
+	EnableRoles(config.ManageStorage, []common.Address{alice}, db)
+	obj := NewManageObj(config.ManageStorage, common.Address{}, db)
+
+	for _, admin := range []common.Address{bob, carol} {
+		if err := apply(alice, "grantRole", uint8(RoleAdmin), admin); err != nil {
+			t.Fatalf("failed to grant admin: %v", err)
+		}
+	}
+	if err := apply(alice, "setAdminThreshold", big.NewInt(2)); err != nil {
+		t.Fatalf("failed to set threshold: %v", err)
+	}
+	// Carol approves a quota, then loses the admin role
+	quota := action("setGasQuota", dave, big.NewInt(1000))
+	if err := apply(carol, "setGasQuota", dave, big.NewInt(1000)); err != nil || obj.Approvals(quota) != 1 {
+		t.Fatalf("failed to approve quota: %v", err)
+	}
+	apply(alice, "revokeRole", uint8(RoleAdmin), carol)
+	if err := apply(bob, "revokeRole", uint8(RoleAdmin), carol); err != nil || obj.IsManager(carol) {
+		t.Fatalf("admin not revoked: %v", err)
+	}
+	// The approval of the former admin no longer counts
+	if approvals := obj.Approvals(quota); approvals != 0 {
+		t.Fatalf("stale approvals mismatch: have %d, want 0", approvals)
+	}
+	if err := apply(alice, "setGasQuota", dave, big.NewInt(1000)); err != nil || obj.GasQuota(dave) != 0 {
+		t.Fatalf("quota set with a stale approval: %v", err)
+	}
+	// Nor does it once the former admin is granted the role again
+	apply(alice, "grantRole", uint8(RoleAdmin), carol)
+	if err := apply(bob, "grantRole", uint8(RoleAdmin), carol); err != nil || !obj.IsManager(carol) {
+		t.Fatalf("admin not granted: %v", err)
+	}
+	if approvals := obj.Approvals(quota); approvals != 1 {
+		t.Fatalf("approvals mismatch after regrant: have %d, want 1", approvals)
+	}
+	if err := apply(carol, "setGasQuota", dave, big.NewInt(1000)); err != nil || obj.GasQuota(dave) != 1000 {
+		t.Fatalf("failed to set quota: %v", err)
+	}
+}
+
+func TestLegacyManagers(t *testing.T) {
+	var (
+		config = &params.ExpansionsConfig{
+			ManageSupport:    true,
+			ManageStorage:    common.HexToAddress("0x2000"),
+			ManageRolesBlock: big.NewInt(10),
+		}
+		alice = common.HexToAddress("0x01")
+		bob   = common.HexToAddress("0x02")
+		carol = common.HexToAddress("0x03")
+		dave  = common.HexToAddress("0x04")
+	)
+	db, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	parsed, _ := abi.JSON(strings.NewReader(manageAbi))
+
+	apply := func(from common.Address, method string, args ...interface{}) error {
+		input, err := parsed.Pack(method, args...)
+		if err != nil {
+			t.Fatalf("failed to pack %s: %v", method, err)
+		}
+		msg := types.NewMessage(from, &config.ManageStorage, 0, new(big.Int), 100000, new(big.Int), input, false)
+		return ApplyManageOp(config, db, &msg, big.NewInt(10))
+	}
+	// Set up three legacy managers, carol is not listed at the fork
+	SetManager(config.ManageStorage, alice, db)
+	SetManager(config.ManageStorage, bob, db)
+	SetManager(config.ManageStorage, carol, db)
+	EnableRoles(config.ManageStorage, []common.Address{alice, bob, dave}, db)
+
+	obj := NewManageObj(config.ManageStorage, common.Address{}, db)
+	if holders := obj.RoleHolders(RoleAdmin); !reflect.DeepEqual(holders, []common.Address{alice, bob}) {
+		t.Fatalf("admin holders mismatch after fork: have %x", holders)
+	}
+	// The threshold is bound by every migrated manager
+	if err := apply(alice, "setAdminThreshold", big.NewInt(2)); err != nil || obj.AdminThreshold() != 2 {
+		t.Fatalf("failed to set threshold: %v", err)
+	}
+	// Unlisted managers cannot approve until granted the role again
+	if err := apply(carol, "setAdminThreshold", big.NewInt(1)); err != errUnauthorize {
+		t.Fatalf("unlisted manager error mismatch: have %v, want %v", err, errUnauthorize)
+	}
+	apply(alice, "grantRole", uint8(RoleAdmin), carol)
+	if err := apply(bob, "grantRole", uint8(RoleAdmin), carol); err != nil {
+		t.Fatalf("failed to enlist manager: %v", err)
+	}
+	if holders := obj.RoleHolders(RoleAdmin); !reflect.DeepEqual(holders, []common.Address{alice, bob, carol}) {
+		t.Fatalf("admin holders mismatch after enlisting: have %x", holders)
+	}
+	if err := apply(alice, "setAdminThreshold", big.NewInt(3)); err != nil {
+		t.Fatalf("failed to approve threshold: %v", err)
+	}
+	if err := apply(carol, "setAdminThreshold", big.NewInt(3)); err != nil || obj.AdminThreshold() != 3 {
+		t.Fatalf("failed to set threshold: %v", err)
+	}
+}
@@ -8,13 +8,20 @@ import (
 	"github.com/bcos-one/BCOS/common"
 	"github.com/bcos-one/BCOS/core/state"
 	"github.com/bcos-one/BCOS/core/types"
+	"github.com/bcos-one/BCOS/core/vm"
 	"github.com/bcos-one/BCOS/crypto"
 	"github.com/bcos-one/BCOS/log"
+	"github.com/bcos-one/BCOS/params"
 	"math/big"
 	"strings"
 )
 
-const tokenabi = `[{"constant":true,"inputs":[{"name":"name","type":"string"},{"name":"manager","type":"address"},{"name":"beneficiary","type":"address"},{"name":"supply","type":"uint256"},{"name":"canIncrease","type":"bool"},{"name":"canburn","type":"bool"}],"name":"issue","outputs":[],"payable":false,"stateMutability":"pure","type":"function"},{"constant":true,"inputs":[{"name":"token","type":"address"},{"name":"beneficiary","type":"address"},{"name":"amount","type":"uint256"}],"name":"increase","outputs":[],"payable":false,"stateMutability":"pure","type":"function"},{"constant":true,"inputs":[{"name":"token","type":"address"},{"name":"amount","type":"uint256"}],"name":"burn","outputs":[],"payable":false,"stateMutability":"pure","type":"function"}]`
+const tokenabi = `[{"constant":true,"inputs":[{"name":"name","type":"string"},{"name":"manager","type":"address"},{"name":"beneficiary","type":"address"},{"name":"supply","type":"uint256"},{"name":"canIncrease","type":"bool"},{"name":"canburn","type":"bool"}],"name":"issue","outputs":[],"payable":false,"stateMutability":"pure","type":"function"},{"constant":true,"inputs":[{"name":"token","type":"address"},{"name":"beneficiary","type":"address"},{"name":"amount","type":"uint256"}],"name":"increase","outputs":[],"payable":false,"stateMutability":"pure","type":"function"},{"constant":true,"inputs":[{"name":"token","type":"address"},{"name":"amount","type":"uint256"}],"name":"burn","outputs":[],"payable":false,"stateMutability":"pure","type":"function"},{"constant":true,"inputs":[{"name":"name","type":"string"},{"name":"manager","type":"address"},{"name":"beneficiary","type":"address"},{"name":"supply","type":"uint256"},{"name":"canIncrease","type":"bool"},{"name":"canburn","type":"bool"},{"name":"salt","type":"bytes32"}],"name":"issueWithSalt","outputs":[],"payable":false,"stateMutability":"pure","type":"function"},{"constant":true,"inputs":[{"name":"token","type":"address"}],"name":"pause","outputs":[],"payable":false,"stateMutability":"pure","type":"function"},{"constant":true,"inputs":[{"name":"token","type":"address"}],"name":"unpause","outputs":[],"payable":false,"stateMutability":"pure","type":"function"},{"constant":true,"inputs":[{"name":"token","type":"address"},{"name":"account","type":"address"}],"name":"freezeAccount","outputs":[],"payable":false,"stateMutability":"pure","type":"function"},{"constant":true,"inputs":[{"name":"token","type":"address"},{"name":"account","type":"address"}],"name":"unfreezeAccount","outputs":[],"payable":false,"stateMutability":"pure","type":"function"},{"constant":true,"inputs":[{"name":"token","type":"address"},{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"name":"forceTransfer","outputs":[],"payable":false,"stateMutability":"pure","type":"function"}]`
+
+// ABI is the JSON interface of the operations of the token storage, for tools
+// decoding the transactions sent to it.
+const ABI = tokenabi
+
 const maxTokenNameLen = 32
 
 var (
@@ -29,6 +36,30 @@ var (
 	//function burn(address token, uint256 amount)
 	// web3.sha3("burn(address,uint256)") = 0x9dc29fac0ba6d4fc521c69c2b0c636d612e3343bc39ed934429b8876b0d12cba
 	burnSig, _ = hex.DecodeString("9dc29fac") //burn
+
+	// function issueWithSalt(string name, address manager, address beneficiary, uint256 supply, bool canIncrease, bool canburn, bytes32 salt)
+	// web3.sha3("issueWithSalt(string,address,address,uint256,bool,bool,bytes32)") = 0xfbbcd3437a1d2a88f1b9793efe7109f85bc86dbb2a8d783169da78bc7d95e1df
+	issueWithSaltSig, _ = hex.DecodeString("fbbcd343") // issueWithSalt
+
+	// function pause(address token)
+	// web3.sha3("pause(address)") = 0x76a67a51efd4a3cf3d964b9fe82d4ef55728cbf59a6a00de29ac4e5d0db0e3bd
+	pauseSig, _ = hex.DecodeString("76a67a51") // pause
+
+	// function unpause(address token)
+	// web3.sha3("unpause(address)") = 0x57b001f98e3a074936643ec5c2adaac1f32a319b9acd907c92790d853e314780
+	unpauseSig, _ = hex.DecodeString("57b001f9") // unpause
+
+	// function freezeAccount(address token, address account)
+	// web3.sha3("freezeAccount(address,address)") = 0xaa5c400730dcaefe3064e25edcfb9c84e1829960220db16231d4b2e15cd734c9
+	freezeSig, _ = hex.DecodeString("aa5c4007") // freezeAccount
+
+	// function unfreezeAccount(address token, address account)
+	// web3.sha3("unfreezeAccount(address,address)") = 0x2ebe0e7dd2fb3b38b29f1383653e615d7d877f07c213c7ce651e10a1de1700e1
+	unfreezeSig, _ = hex.DecodeString("2ebe0e7d") // unfreezeAccount
+
+	// function forceTransfer(address token, address from, address to, uint256 amount)
+	// web3.sha3("forceTransfer(address,address,address,uint256)") = 0x98b73188a045aecc20f25104a36135f68a57e1d3a6c46f0997112e3920446d1c
+	forceTransferSig, _ = hex.DecodeString("98b73188") // forceTransfer
 )
 
 var (
@@ -37,25 +68,47 @@ var (
 	errInvalidTokenName = errors.New("invalid token name")
 	errUnauthorize      = errors.New("unauthroize")
 	errInsufficient     = errors.New("insufficient funds to burn")
+	errInsufficientMove = errors.New("insufficient funds to transfer")
 	errBadBool          = errors.New("improperly encoded boolean value")
+	errTokenIdInUse     = errors.New("token id already in use")
+	errNotIssue         = errors.New("not a token issue operation")
+
+	// ErrTokenFrozen is returned when a token is moved while it is paused or
+	// either side of the transfer is frozen.
+	ErrTokenFrozen = vm.ErrTokenFrozen
 )
 
-func ApplyTokenOp(storage common.Address, db *state.StateDB, msg *types.Message) error {
+func ApplyTokenOp(config *params.ExpansionsConfig, db *state.StateDB, msg *types.Message, num *big.Int) error {
 	input := msg.Data()
 	from := msg.From()
+	storage := config.TokenStorage
 
 	if len(input) < 4 {
 		return errInvalidInput
 	}
 
 	sig := input[:4]
+	salted := config.IsTokenSaltEnabled(num)
+	controls := config.IsTokenControlEnabled(num)
 	switch {
 	case bytes.Equal(sig, issueSig):
 		return issue(storage, from, msg.Nonce(), db, input[4:])
+	case salted && bytes.Equal(sig, issueWithSaltSig):
+		return issueWithSalt(storage, from, db, input[4:])
 	case bytes.Equal(sig, increaseSig):
 		return increase(storage, from, db, input[4:])
 	case bytes.Equal(sig, burnSig):
 		return burn(storage, from, db, input[4:])
+	case controls && bytes.Equal(sig, pauseSig):
+		return setPaused(storage, from, db, input[4:], "pause", true)
+	case controls && bytes.Equal(sig, unpauseSig):
+		return setPaused(storage, from, db, input[4:], "unpause", false)
+	case controls && bytes.Equal(sig, freezeSig):
+		return setFrozen(storage, from, db, input[4:], "freezeAccount", true)
+	case controls && bytes.Equal(sig, unfreezeSig):
+		return setFrozen(storage, from, db, input[4:], "unfreezeAccount", false)
+	case controls && bytes.Equal(sig, forceTransferSig):
+		return forceTransfer(storage, from, db, input[4:])
 	default:
 		return errInvalidSig
 	}
@@ -63,38 +116,106 @@ func ApplyTokenOp(storage common.Address, db *state.StateDB, msg *types.Message)
 	return nil
 }
 
+// CreateTokenId derives a deterministic token id from the issuer, a salt and the
+// token name, the same way CREATE2 derives contract addresses from the init code.
+func CreateTokenId(from common.Address, salt [32]byte, name string) common.Address {
+	return crypto.CreateAddress2(from, salt, crypto.Keccak256([]byte(name)))
+}
+
+// IssuedTokenId returns the id of the token issued by the given token operation,
+// or an error if the input is not an issue operation.
+func IssuedTokenId(from common.Address, nonce uint64, input []byte) (common.Address, error) {
+	if len(input) < 4 {
+		return common.Address{}, errNotIssue
+	}
+	switch sig := input[:4]; {
+	case bytes.Equal(sig, issueSig):
+		return crypto.CreateAddress(from, nonce), nil
+	case bytes.Equal(sig, issueWithSaltSig):
+		params, salt, err := unpackIssueWithSalt(input[4:])
+		if err != nil {
+			return common.Address{}, err
+		}
+		return CreateTokenId(from, salt, params.name), nil
+	default:
+		return common.Address{}, errNotIssue
+	}
+}
+
+// issueParams are the token properties shared by all issue operations.
+type issueParams struct {
+	name        string
+	manager     common.Address
+	beneficiary common.Address
+	supply      *big.Int
+	canIncrease bool
+	canBurn     bool
+}
+
 func issue(storage common.Address, from common.Address, nonce uint64, db *state.StateDB, input []byte) error {
-	var (
-		name        string
-		manager     common.Address
-		beneficiary common.Address
-		supply      *big.Int
-		canBurn     bool
-		canIncrease bool
-	)
+	var params issueParams
 	decoder, _ := abi.JSON(strings.NewReader(tokenabi))
 
-	if err := decoder.UnpackInput(&[]interface{}{&name, &manager, &beneficiary, &supply, &canIncrease, &canBurn}, "issue", input); err != nil {
+	if err := decoder.UnpackInput(&[]interface{}{&params.name, &params.manager, &params.beneficiary, &params.supply, &params.canIncrease, &params.canBurn}, "issue", input); err != nil {
 		return errInvalidInput
 	}
 
-	if len(name) > maxTokenNameLen {
+	if len(params.name) > maxTokenNameLen {
 		return errInvalidTokenName
 	}
 
 	tokenid := crypto.CreateAddress(from, nonce)
 	log.Info("issue token", "tokeId", tokenid.String())
 
+	createToken(storage, tokenid, db, &params)
+	return nil
+}
+
+func issueWithSalt(storage common.Address, from common.Address, db *state.StateDB, input []byte) error {
+	params, salt, err := unpackIssueWithSalt(input)
+	if err != nil {
+		return err
+	}
+
+	if len(params.name) > maxTokenNameLen {
+		return errInvalidTokenName
+	}
+
+	// Unlike nonce derived ids, salted ids can be chosen to hit anything already
+	// living at the address, so refuse to issue over tokens and non-empty accounts.
+	tokenid := CreateTokenId(from, salt, params.name)
+	if NewTokenObject(storage, tokenid, db).IsExists() || (db.Exist(tokenid) && !db.Empty(tokenid)) {
+		return errTokenIdInUse
+	}
+	log.Info("issue token", "tokeId", tokenid.String())
+
+	createToken(storage, tokenid, db, params)
+	return nil
+}
+
+func unpackIssueWithSalt(input []byte) (*issueParams, [32]byte, error) {
+	var (
+		params issueParams
+		salt   [32]byte
+	)
+	decoder, _ := abi.JSON(strings.NewReader(tokenabi))
+
+	if err := decoder.UnpackInput(&[]interface{}{&params.name, &params.manager, &params.beneficiary, &params.supply, &params.canIncrease, &params.canBurn, &salt}, "issueWithSalt", input); err != nil {
+		return nil, salt, errInvalidInput
+	}
+	return &params, salt, nil
+}
+
+func createToken(storage common.Address, tokenid common.Address, db *state.StateDB, params *issueParams) {
 	tokenObj := NewTokenObject(storage, tokenid, db)
-	tokenObj.setName(name)
-	tokenObj.setManager(manager)
-	tokenObj.setSupply(supply)
-	tokenObj.setIncreaseFlag(canIncrease)
-	tokenObj.setBurnFlag(canBurn)
+	tokenObj.setName(params.name)
+	tokenObj.setManager(params.manager)
+	tokenObj.setSupply(params.supply)
+	tokenObj.setIncreaseFlag(params.canIncrease)
+	tokenObj.setBurnFlag(params.canBurn)
 	tokenObj.setExistsFlag(true)
 
-	db.AddTokenBalance(beneficiary, tokenid, supply)
-	return nil
+	db.AddTokenBalance(params.beneficiary, tokenid, params.supply)
 }
 
 func increase(storage common.Address, from common.Address, db *state.StateDB, input []byte) error {
@@ -137,6 +258,9 @@ func burn(storage common.Address, from common.Address, db *state.StateDB, input
 	if !tokenObj.IsExists() || !tokenObj.CanBurn() {
 		return errUnauthorize
 	}
+	if tokenObj.IsPaused() || tokenObj.IsFrozen(from) {
+		return ErrTokenFrozen
+	}
 
 	if db.GetTokenBalance(from, id).Cmp(value) < 0 {
 		return errInsufficient
@@ -147,3 +271,69 @@ func burn(storage common.Address, from common.Address, db *state.StateDB, input
 
 	return nil
 }
+
+func setPaused(storage common.Address, from common.Address, db *state.StateDB, input []byte, method string, paused bool) error {
+	var id common.Address
+	decoder, _ := abi.JSON(strings.NewReader(tokenabi))
+
+	if err := decoder.UnpackInput(&id, method, input); err != nil {
+		return errInvalidInput
+	}
+
+	tokenObj := NewTokenObject(storage, id, db)
+	if !tokenObj.IsExists() || tokenObj.Manager() != from {
+		return errUnauthorize
+	}
+
+	tokenObj.setPausedFlag(paused)
+	return nil
+}
+
+func setFrozen(storage common.Address, from common.Address, db *state.StateDB, input []byte, method string, frozen bool) error {
+	var (
+		id      common.Address
+		account common.Address
+	)
+	decoder, _ := abi.JSON(strings.NewReader(tokenabi))
+
+	if err := decoder.UnpackInput(&[]interface{}{&id, &account}, method, input); err != nil {
+		return errInvalidInput
+	}
+
+	tokenObj := NewTokenObject(storage, id, db)
+	if !tokenObj.IsExists() || tokenObj.Manager() != from {
+		return errUnauthorize
+	}
+
+	tokenObj.setFrozenFlag(account, frozen)
+	return nil
+}
+
+// forceTransfer lets the token manager move funds regardless of the pause and
+// freeze flags, e.g. to claw back funds under a court order.
+func forceTransfer(storage common.Address, from common.Address, db *state.StateDB, input []byte) error {
+	var (
+		id     common.Address
+		holder common.Address
+		to     common.Address
+		value  *big.Int
+	)
+	decoder, _ := abi.JSON(strings.NewReader(tokenabi))
+
+	if err := decoder.UnpackInput(&[]interface{}{&id, &holder, &to, &value}, "forceTransfer", input); err != nil {
+		return errInvalidInput
+	}
+
+	tokenObj := NewTokenObject(storage, id, db)
+	if !tokenObj.IsExists() || tokenObj.Manager() != from {
+		return errUnauthorize
+	}
+
+	if db.GetTokenBalance(holder, id).Cmp(value) < 0 {
+		return errInsufficientMove
+	}
+
+	db.SubTokenBalance(holder, id, value)
+	db.AddTokenBalance(to, id, value)
+	return nil
+}
@@ -3,7 +3,6 @@ package token
 import (
 	"bytes"
 	"github.com/bcos-one/BCOS/common"
-	"github.com/bcos-one/BCOS/core/state"
 	"github.com/bcos-one/BCOS/crypto"
 	"math/big"
 )
@@ -15,15 +14,24 @@ const (
 	canIncreaseIndex
 	canBurnIndex
 	existsIndex
+	pausedIndex
+	frozenIndex
 )
 
+// StateDB is the part of the state database token objects are kept in, so that
+// they can be used both on a *state.StateDB and from within the EVM.
+type StateDB interface {
+	GetState(common.Address, common.Hash) common.Hash
+	SetState(common.Address, common.Hash, common.Hash)
+}
+
 type TokenObject struct {
 	storage common.Address
-	db      *state.StateDB
+	db      StateDB
 	hash    common.Hash
 }
 
-func NewTokenObject(storage common.Address, tokenid common.Address, db *state.StateDB) *TokenObject {
+func NewTokenObject(storage common.Address, tokenid common.Address, db StateDB) *TokenObject {
 	return &TokenObject{
 		storage: storage,
 		db:      db,
@@ -71,6 +79,27 @@ func (self *TokenObject) Manager() common.Address {
 	return common.BytesToAddress(hash.Bytes())
 }
 
+func (self *TokenObject) IsPaused() bool {
+	hash := self.db.GetState(self.storage, self.hashAtIndex(pausedIndex))
+
+	paused, _ := readBool(hash)
+	return paused
+}
+
+func (self *TokenObject) IsFrozen(account common.Address) bool {
+	hash := self.db.GetState(self.storage, self.frozenHash(account))
+
+	frozen, _ := readBool(hash)
+	return frozen
+}
+
+// CanTransfer reports whether the compliance controls of the token allow moving
+// it from sender to recipient. Paused tokens and frozen accounts can only be
+// moved by the manager through a forced transfer.
+func (self *TokenObject) CanTransfer(sender common.Address, recipient common.Address) bool {
+	return !self.IsPaused() && !self.IsFrozen(sender) && !self.IsFrozen(recipient)
+}
+
 func (self *TokenObject) hashAtIndex(index int64) common.Hash {
 	  num := new(big.Int).Add(new(big.Int).SetBytes(self.hash.Bytes()), big.NewInt(index))
 
@@ -108,6 +137,22 @@ func (self *TokenObject) setBurnFlag(enable bool) {
 	self.db.SetState(self.storage, hash, bool2Hash(enable))
 }
 
+// frozenHash returns the storage slot of the frozen flag of an account, laid out
+// like a solidity mapping rooted at the frozenIndex slot of the token.
+func (self *TokenObject) frozenHash(account common.Address) common.Hash {
+	return crypto.Keccak256Hash(account.Hash().Bytes(), self.hashAtIndex(frozenIndex).Bytes())
+}
+
+func (self *TokenObject) setPausedFlag(paused bool) {
+	hash := self.hashAtIndex(pausedIndex)
+
+	self.db.SetState(self.storage, hash, bool2Hash(paused))
+}
+
+func (self *TokenObject) setFrozenFlag(account common.Address, frozen bool) {
+	self.db.SetState(self.storage, self.frozenHash(account), bool2Hash(frozen))
+}
+
 func (self *TokenObject) setExistsFlag(exists bool) {
 	hash := self.hashAtIndex(existsIndex)
 
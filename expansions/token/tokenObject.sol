@@ -7,6 +7,8 @@ contract tokenObject {
         address manager;
         bool canIncrease;
         bool canBurn;
+        bool paused;
+        mapping(address => bool) frozen;
     }
 
     mapping(address => token) tokens;
@@ -14,4 +16,10 @@ contract tokenObject {
     function issue(string name, address manager, address beneficiary, uint256 supply, bool canIncrease, bool canburn) public pure;
     function increase(address token, address beneficiary, uint256 amount) public pure;
     function burn(address token, uint256 amount) public pure;
+    function issueWithSalt(string name, address manager, address beneficiary, uint256 supply, bool canIncrease, bool canburn, bytes32 salt) public pure;
+    function pause(address token) public pure;
+    function unpause(address token) public pure;
+    function freezeAccount(address token, address account) public pure;
+    function unfreezeAccount(address token, address account) public pure;
+    function forceTransfer(address token, address from, address to, uint256 amount) public pure;
 }
\ No newline at end of file
@@ -0,0 +1,158 @@
+package token
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/bcos-one/BCOS/accounts/abi"
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/core/state"
+	"github.com/bcos-one/BCOS/core/types"
+	"github.com/bcos-one/BCOS/ethdb"
+	"github.com/bcos-one/BCOS/params"
+)
+
+// testConfig returns a token expansion config at the given storage with the
+// salted issues and compliance controls activated at block one.
+func testConfig(storage common.Address) *params.ExpansionsConfig {
+	return &params.ExpansionsConfig{
+		TokenSupport:      true,
+		TokenStorage:      storage,
+		TokenSaltBlock:    common.Big1,
+		TokenControlBlock: common.Big1,
+	}
+}
+
+func TestIssueWithSalt(t *testing.T) {
+	var (
+		storage = common.HexToAddress("0x1000")
+		issuer  = common.HexToAddress("0x01")
+		holder  = common.HexToAddress("0x02")
+		salt    = common.HexToHash("0x42")
+		supply  = big.NewInt(1000)
+	)
+	db, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	parsed, _ := abi.JSON(strings.NewReader(tokenabi))
+	config := testConfig(storage)
+
+	input, err := parsed.Pack("issueWithSalt", "gold", issuer, holder, supply, true, false, salt)
+	if err != nil {
+		t.Fatalf("failed to pack issue: %v", err)
+	}
+	msg := types.NewMessage(issuer, &storage, 7, new(big.Int), 100000, new(big.Int), input, false)
+
+	// The id must not depend on the nonce and must be predictable up front
+	id := CreateTokenId(issuer, salt, "gold")
+	if predicted, err := IssuedTokenId(issuer, msg.Nonce(), input); err != nil || predicted != id {
+		t.Fatalf("predicted id mismatch: have %x (%v), want %x", predicted, err, id)
+	}
+	// Salted issues must be rejected before the fork
+	if err := ApplyTokenOp(config, db, &msg, common.Big0); err != errInvalidSig {
+		t.Fatalf("pre-fork issue error mismatch: have %v, want %v", err, errInvalidSig)
+	}
+	if NewTokenObject(storage, id, db).IsExists() {
+		t.Fatalf("token issued before the fork")
+	}
+	if err := ApplyTokenOp(config, db, &msg, common.Big1); err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+	tokenObj := NewTokenObject(storage, id, db)
+	if !tokenObj.IsExists() || tokenObj.GetTokenName() != "gold" || tokenObj.GetSupply().Cmp(supply) != 0 {
+		t.Fatalf("token not issued at predicted id %x", id)
+	}
+	if balance := db.GetTokenBalance(holder, id); balance.Cmp(supply) != 0 {
+		t.Fatalf("beneficiary balance mismatch: have %v, want %v", balance, supply)
+	}
+	// Issuing again with the same salt and name must collide
+	if err := ApplyTokenOp(config, db, &msg, common.Big1); err != errTokenIdInUse {
+		t.Fatalf("reissue error mismatch: have %v, want %v", err, errTokenIdInUse)
+	}
+	// Ids occupied by accounts must be rejected as well
+	input, _ = parsed.Pack("issueWithSalt", "silver", issuer, holder, supply, true, false, salt)
+	msg = types.NewMessage(issuer, &storage, 8, new(big.Int), 100000, new(big.Int), input, false)
+	db.SetNonce(CreateTokenId(issuer, salt, "silver"), 1)
+
+	if err := ApplyTokenOp(config, db, &msg, common.Big1); err != errTokenIdInUse {
+		t.Fatalf("account collision error mismatch: have %v, want %v", err, errTokenIdInUse)
+	}
+}
+
+func TestComplianceControls(t *testing.T) {
+	var (
+		storage = common.HexToAddress("0x1000")
+		manager = common.HexToAddress("0x01")
+		alice   = common.HexToAddress("0x02")
+		bob     = common.HexToAddress("0x03")
+	)
+	db, _ := state.New(common.Hash{}, state.NewDatabase(ethdb.NewMemDatabase()))
+	parsed, _ := abi.JSON(strings.NewReader(tokenabi))
+
+	applyAt := func(num *big.Int, from common.Address, method string, args ...interface{}) error {
+		input, err := parsed.Pack(method, args...)
+		if err != nil {
+			t.Fatalf("failed to pack %s: %v", method, err)
+		}
+		msg := types.NewMessage(from, &storage, 0, new(big.Int), 100000, new(big.Int), input, false)
+		return ApplyTokenOp(testConfig(storage), db, &msg, num)
+	}
+	apply := func(from common.Address, method string, args ...interface{}) error {
+		return applyAt(common.Big1, from, method, args...)
+	}
+	if err := apply(manager, "issueWithSalt", "bond", manager, alice, big.NewInt(100), false, true, common.Hash{}); err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+	id := CreateTokenId(manager, common.Hash{}, "bond")
+	tokenObj := NewTokenObject(storage, id, db)
+
+	// The controls must be rejected before the fork
+	for _, op := range []struct {
+		method string
+		args   []interface{}
+	}{
+		{"pause", []interface{}{id}},
+		{"unpause", []interface{}{id}},
+		{"freezeAccount", []interface{}{id, bob}},
+		{"unfreezeAccount", []interface{}{id, bob}},
+		{"forceTransfer", []interface{}{id, alice, bob, big.NewInt(30)}},
+	} {
+		if err := applyAt(common.Big0, manager, op.method, op.args...); err != errInvalidSig {
+			t.Fatalf("pre-fork %s error mismatch: have %v, want %v", op.method, err, errInvalidSig)
+		}
+	}
+	if tokenObj.IsPaused() || tokenObj.IsFrozen(bob) || db.GetTokenBalance(bob, id).Sign() != 0 {
+		t.Fatalf("token state changed before the fork")
+	}
+	// Only the manager may pause and freeze
+	if err := apply(alice, "pause", id); err != errUnauthorize {
+		t.Fatalf("pause by holder error mismatch: have %v, want %v", err, errUnauthorize)
+	}
+	if err := apply(manager, "pause", id); err != nil || !tokenObj.IsPaused() || tokenObj.CanTransfer(alice, bob) {
+		t.Fatalf("failed to pause token: %v", err)
+	}
+	if err := apply(manager, "unpause", id); err != nil || tokenObj.IsPaused() || !tokenObj.CanTransfer(alice, bob) {
+		t.Fatalf("failed to unpause token: %v", err)
+	}
+	if err := apply(manager, "freezeAccount", id, bob); err != nil || !tokenObj.IsFrozen(bob) {
+		t.Fatalf("failed to freeze account: %v", err)
+	}
+	if tokenObj.CanTransfer(alice, bob) || tokenObj.CanTransfer(bob, alice) || !tokenObj.CanTransfer(alice, manager) {
+		t.Fatalf("frozen account transfer permissions mismatch")
+	}
+	// Forced transfers bypass the freeze, but burns by frozen holders do not
+	if err := apply(manager, "forceTransfer", id, alice, bob, big.NewInt(30)); err != nil {
+		t.Fatalf("failed to force transfer: %v", err)
+	}
+	if err := apply(bob, "burn", id, big.NewInt(10)); err != ErrTokenFrozen {
+		t.Fatalf("frozen burn error mismatch: have %v, want %v", err, ErrTokenFrozen)
+	}
+	if err := apply(manager, "forceTransfer", id, bob, manager, big.NewInt(30)); err != nil {
+		t.Fatalf("failed to claw back funds: %v", err)
+	}
+	if have := db.GetTokenBalance(manager, id); have.Cmp(big.NewInt(30)) != 0 {
+		t.Fatalf("clawed back balance mismatch: have %v, want 30", have)
+	}
+	if err := apply(manager, "unfreezeAccount", id, bob); err != nil || tokenObj.IsFrozen(bob) {
+		t.Fatalf("failed to unfreeze account: %v", err)
+	}
+}
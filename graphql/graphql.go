@@ -0,0 +1,1028 @@
+// Package graphql provides a GraphQL interface to the chain, state and native
+// token data of a node.
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/common/hexutil"
+	"github.com/bcos-one/BCOS/core"
+	"github.com/bcos-one/BCOS/core/bloombits"
+	"github.com/bcos-one/BCOS/core/rawdb"
+	"github.com/bcos-one/BCOS/core/state"
+	"github.com/bcos-one/BCOS/core/types"
+	"github.com/bcos-one/BCOS/eth/filters"
+	"github.com/bcos-one/BCOS/event"
+	"github.com/bcos-one/BCOS/expansions/token"
+	"github.com/bcos-one/BCOS/internal/ethapi"
+	"github.com/bcos-one/BCOS/rpc"
+)
+
+// maxBlockRange is the maximum number of blocks a single blocks or logs query may
+// cover.
+const maxBlockRange = 1024
+
+var (
+	errBlockInvariant   = errors.New("block objects must be instantiated with at least one of num or hash")
+	errTokenUnsupported = errors.New("token support disabled")
+)
+
+// Backend is the chain backend the GraphQL service resolves its queries from,
+// being an API backend which can also filter logs (both full and light nodes).
+type Backend interface {
+	ethapi.Backend
+
+	HeaderByHash(ctx context.Context, blockHash common.Hash) (*types.Header, error)
+	GetLogs(ctx context.Context, blockHash common.Hash) ([][]*types.Log, error)
+	SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription
+	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
+	BloomStatus() (uint64, uint64)
+	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
+}
+
+// Long is a 64 bit integer, transferred as a JSON number.
+type Long int64
+
+// ImplementsGraphQLType returns true if Long implements the provided GraphQL type.
+func (b Long) ImplementsGraphQLType(name string) bool { return name == "Long" }
+
+// UnmarshalGraphQL unmarshals the provided GraphQL query data.
+func (b *Long) UnmarshalGraphQL(input interface{}) error {
+	switch input := input.(type) {
+	case string:
+		value, err := strconv.ParseInt(input, 10, 64)
+		if err != nil {
+			return err
+		}
+		*b = Long(value)
+	case int32:
+		*b = Long(input)
+	case float64:
+		*b = Long(input)
+	default:
+		return fmt.Errorf("unexpected type %T for Long", input)
+	}
+	return nil
+}
+
+// Account represents an account at a specific block.
+type Account struct {
+	backend     Backend
+	address     common.Address
+	blockNumber rpc.BlockNumber
+}
+
+// getState fetches the state of the account's block.
+func (a *Account) getState(ctx context.Context) (*state.StateDB, error) {
+	state, _, err := a.backend.StateAndHeaderByNumber(ctx, a.blockNumber)
+	if state == nil && err == nil {
+		err = fmt.Errorf("state of block %d not available", a.blockNumber)
+	}
+	return state, err
+}
+
+func (a *Account) Address(ctx context.Context) (common.Address, error) {
+	return a.address, nil
+}
+
+func (a *Account) Balance(ctx context.Context) (hexutil.Big, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*state.GetBalance(a.address)), nil
+}
+
+func (a *Account) TransactionCount(ctx context.Context) (Long, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(state.GetNonce(a.address)), nil
+}
+
+func (a *Account) Code(ctx context.Context) (hexutil.Bytes, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(state.GetCode(a.address)), nil
+}
+
+func (a *Account) Storage(ctx context.Context, args struct{ Slot common.Hash }) (common.Hash, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return state.GetState(a.address, args.Slot), nil
+}
+
+func (a *Account) TokenBalance(ctx context.Context, args struct{ Token common.Address }) (hexutil.Big, error) {
+	state, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*state.GetTokenBalance(a.address, args.Token)), nil
+}
+
+// Token represents a native token at a specific block.
+type Token struct {
+	backend     Backend
+	id          common.Address
+	blockNumber rpc.BlockNumber
+}
+
+// getToken fetches the token object from the state of the token's block.
+func (t *Token) getToken(ctx context.Context) (*token.TokenObject, error) {
+	config := t.backend.ChainConfig().ExpansionsConfig
+	if config == nil || !config.TokenSupport {
+		return nil, errTokenUnsupported
+	}
+	state, _, err := t.backend.StateAndHeaderByNumber(ctx, t.blockNumber)
+	if state == nil || err != nil {
+		return nil, fmt.Errorf("state of block %d not available: %v", t.blockNumber, err)
+	}
+	obj := token.NewTokenObject(config.TokenStorage, t.id, state)
+	if !obj.IsExists() {
+		return nil, fmt.Errorf("token %x does not exist", t.id)
+	}
+	return obj, nil
+}
+
+// exists returns whether the token exists at its block.
+func (t *Token) exists(ctx context.Context) (bool, error) {
+	config := t.backend.ChainConfig().ExpansionsConfig
+	if config == nil || !config.TokenSupport {
+		return false, errTokenUnsupported
+	}
+	state, _, err := t.backend.StateAndHeaderByNumber(ctx, t.blockNumber)
+	if state == nil || err != nil {
+		return false, err
+	}
+	return token.NewTokenObject(config.TokenStorage, t.id, state).IsExists(), nil
+}
+
+func (t *Token) Id(ctx context.Context) (common.Address, error) {
+	return t.id, nil
+}
+
+func (t *Token) Name(ctx context.Context) (string, error) {
+	obj, err := t.getToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	return obj.GetTokenName(), nil
+}
+
+func (t *Token) Manager(ctx context.Context) (*Account, error) {
+	obj, err := t.getToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{backend: t.backend, address: obj.Manager(), blockNumber: t.blockNumber}, nil
+}
+
+func (t *Token) Supply(ctx context.Context) (hexutil.Big, error) {
+	obj, err := t.getToken(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*obj.GetSupply()), nil
+}
+
+func (t *Token) CanIncrease(ctx context.Context) (bool, error) {
+	obj, err := t.getToken(ctx)
+	if err != nil {
+		return false, err
+	}
+	return obj.CanIncrease(), nil
+}
+
+func (t *Token) CanBurn(ctx context.Context) (bool, error) {
+	obj, err := t.getToken(ctx)
+	if err != nil {
+		return false, err
+	}
+	return obj.CanBurn(), nil
+}
+
+func (t *Token) Paused(ctx context.Context) (bool, error) {
+	obj, err := t.getToken(ctx)
+	if err != nil {
+		return false, err
+	}
+	return obj.IsPaused(), nil
+}
+
+func (t *Token) Holders(ctx context.Context, args struct{ Addresses []common.Address }) ([]*TokenHolder, error) {
+	if _, err := t.getToken(ctx); err != nil {
+		return nil, err
+	}
+	holders := make([]*TokenHolder, 0, len(args.Addresses))
+	for _, addr := range args.Addresses {
+		holders = append(holders, &TokenHolder{token: t, address: addr})
+	}
+	return holders, nil
+}
+
+// TokenHolder represents the position of an account in a native token.
+type TokenHolder struct {
+	token   *Token
+	address common.Address
+}
+
+func (h *TokenHolder) Account(ctx context.Context) (*Account, error) {
+	return &Account{backend: h.token.backend, address: h.address, blockNumber: h.token.blockNumber}, nil
+}
+
+func (h *TokenHolder) Balance(ctx context.Context) (hexutil.Big, error) {
+	account, _ := h.Account(ctx)
+	return account.TokenBalance(ctx, struct{ Token common.Address }{h.token.id})
+}
+
+func (h *TokenHolder) Frozen(ctx context.Context) (bool, error) {
+	obj, err := h.token.getToken(ctx)
+	if err != nil {
+		return false, err
+	}
+	return obj.IsFrozen(h.address), nil
+}
+
+// TokenGasPayment represents gas paid for in a native token.
+type TokenGasPayment struct {
+	token  *Token
+	gas    uint64
+	amount *big.Int
+}
+
+func (p *TokenGasPayment) Token(ctx context.Context) (*Token, error) {
+	return p.token, nil
+}
+
+func (p *TokenGasPayment) GasUsed(ctx context.Context) (Long, error) {
+	return Long(p.gas), nil
+}
+
+func (p *TokenGasPayment) Amount(ctx context.Context) (hexutil.Big, error) {
+	return hexutil.Big(*p.amount), nil
+}
+
+// Log represents an individual log message. All arguments are mandatory.
+type Log struct {
+	backend     Backend
+	transaction *Transaction
+	log         *types.Log
+}
+
+func (l *Log) Transaction(ctx context.Context) (*Transaction, error) {
+	return l.transaction, nil
+}
+
+func (l *Log) Account(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	return &Account{
+		backend:     l.backend,
+		address:     l.log.Address,
+		blockNumber: args.Number(),
+	}, nil
+}
+
+func (l *Log) Index(ctx context.Context) (int32, error) {
+	return int32(l.log.Index), nil
+}
+
+func (l *Log) Topics(ctx context.Context) ([]common.Hash, error) {
+	return l.log.Topics, nil
+}
+
+func (l *Log) Data(ctx context.Context) (hexutil.Bytes, error) {
+	return hexutil.Bytes(l.log.Data), nil
+}
+
+// Transaction represents an Ethereum transaction.
+// backend and hash are mandatory; all others will be fetched when required.
+type Transaction struct {
+	backend Backend
+	hash    common.Hash
+	tx      *types.Transaction
+	block   *Block
+	index   uint64
+}
+
+// resolve returns the internal transaction object, fetching it if needed.
+func (t *Transaction) resolve(ctx context.Context) (*types.Transaction, error) {
+	if t.tx == nil {
+		tx, blockHash, _, index := rawdb.ReadTransaction(t.backend.ChainDb(), t.hash)
+		if tx != nil {
+			t.tx = tx
+			t.block = &Block{
+				backend: t.backend,
+				hash:    blockHash,
+			}
+			t.index = index
+		} else {
+			t.tx = t.backend.GetPoolTransaction(t.hash)
+		}
+	}
+	return t.tx, nil
+}
+
+func (t *Transaction) Hash(ctx context.Context) common.Hash {
+	return t.hash
+}
+
+func (t *Transaction) InputData(ctx context.Context) (hexutil.Bytes, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(tx.Data()), nil
+}
+
+func (t *Transaction) Gas(ctx context.Context) (Long, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return 0, err
+	}
+	return Long(tx.Gas()), nil
+}
+
+func (t *Transaction) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.GasPrice()), nil
+}
+
+func (t *Transaction) Value(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.Value()), nil
+}
+
+func (t *Transaction) Nonce(ctx context.Context) (Long, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return 0, err
+	}
+	return Long(tx.Nonce()), nil
+}
+
+func (t *Transaction) To(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	to := tx.To()
+	if to == nil {
+		return nil, nil
+	}
+	return &Account{
+		backend:     t.backend,
+		address:     *to,
+		blockNumber: args.Number(),
+	}, nil
+}
+
+func (t *Transaction) From(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil {
+		return nil, err
+	}
+	var signer types.Signer = types.HomesteadSigner{}
+	if tx.Protected() {
+		signer = types.NewEIP155Signer(tx.ChainId())
+	}
+	from, _ := types.Sender(signer, tx)
+
+	return &Account{
+		backend:     t.backend,
+		address:     from,
+		blockNumber: args.Number(),
+	}, nil
+}
+
+func (t *Transaction) Token(ctx context.Context, args BlockNumberArgs) (*Token, error) {
+	tx, err := t.resolve(ctx)
+	if err != nil || tx == nil || tx.Token() == nil {
+		return nil, err
+	}
+	return &Token{
+		backend:     t.backend,
+		id:          *tx.Token(),
+		blockNumber: args.Number(),
+	}, nil
+}
+
+func (t *Transaction) Block(ctx context.Context) (*Block, error) {
+	if _, err := t.resolve(ctx); err != nil {
+		return nil, err
+	}
+	return t.block, nil
+}
+
+func (t *Transaction) Index(ctx context.Context) (*int32, error) {
+	if _, err := t.resolve(ctx); err != nil {
+		return nil, err
+	}
+	if t.block == nil {
+		return nil, nil
+	}
+	index := int32(t.index)
+	return &index, nil
+}
+
+// getReceipt returns the receipt associated with this transaction, if any.
+func (t *Transaction) getReceipt(ctx context.Context) (*types.Receipt, error) {
+	if _, err := t.resolve(ctx); err != nil {
+		return nil, err
+	}
+	if t.block == nil {
+		return nil, nil
+	}
+	receipts, err := t.block.resolveReceipts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if t.index >= uint64(len(receipts)) {
+		return nil, nil
+	}
+	return receipts[t.index], nil
+}
+
+func (t *Transaction) Status(ctx context.Context) (*Long, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	ret := Long(receipt.Status)
+	return &ret, nil
+}
+
+func (t *Transaction) GasUsed(ctx context.Context) (*Long, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	ret := Long(receipt.GasUsed)
+	return &ret, nil
+}
+
+func (t *Transaction) CumulativeGasUsed(ctx context.Context) (*Long, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	ret := Long(receipt.CumulativeGasUsed)
+	return &ret, nil
+}
+
+func (t *Transaction) CreatedContract(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil || receipt.ContractAddress == (common.Address{}) {
+		return nil, err
+	}
+	return &Account{
+		backend:     t.backend,
+		address:     receipt.ContractAddress,
+		blockNumber: args.Number(),
+	}, nil
+}
+
+func (t *Transaction) Logs(ctx context.Context) (*[]*Log, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	ret := make([]*Log, 0, len(receipt.Logs))
+	for _, log := range receipt.Logs {
+		ret = append(ret, &Log{
+			backend:     t.backend,
+			transaction: t,
+			log:         log,
+		})
+	}
+	return &ret, nil
+}
+
+func (t *Transaction) TokenGas(ctx context.Context) (*TokenGasPayment, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil || t.tx.Token() == nil {
+		return nil, err
+	}
+	if !receipt.TokenGas {
+		return nil, nil
+	}
+	number, err := t.block.Number(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenGasPayment{
+		token:  &Token{backend: t.backend, id: *t.tx.Token(), blockNumber: rpc.BlockNumber(number)},
+		gas:    receipt.GasUsed,
+		amount: new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), t.tx.GasPrice()),
+	}, nil
+}
+
+// Block represents an Ethereum block.
+// backend, and either num or hash are mandatory. All other fields are lazily fetched
+// when required.
+type Block struct {
+	backend  Backend
+	num      *rpc.BlockNumber
+	hash     common.Hash
+	header   *types.Header
+	block    *types.Block
+	receipts []*types.Receipt
+}
+
+// resolve returns the internal Block object representing this block, fetching
+// it if necessary.
+func (b *Block) resolve(ctx context.Context) (*types.Block, error) {
+	if b.block != nil {
+		return b.block, nil
+	}
+	var err error
+	if b.hash != (common.Hash{}) {
+		b.block, err = b.backend.GetBlock(ctx, b.hash)
+	} else {
+		b.block, err = b.backend.BlockByNumber(ctx, *b.num)
+	}
+	if b.block != nil {
+		b.header = b.block.Header()
+	}
+	return b.block, err
+}
+
+// resolveHeader returns the internal Header object for this block, fetching it
+// if necessary. Call this function instead of `resolve` unless you need the
+// additional data (transactions and uncles).
+func (b *Block) resolveHeader(ctx context.Context) (*types.Header, error) {
+	if b.num == nil && b.hash == (common.Hash{}) {
+		return nil, errBlockInvariant
+	}
+	if b.header == nil {
+		var err error
+		if b.hash != (common.Hash{}) {
+			b.header, err = b.backend.HeaderByHash(ctx, b.hash)
+		} else {
+			b.header, err = b.backend.HeaderByNumber(ctx, *b.num)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if b.header == nil {
+			return nil, errors.New("block not found")
+		}
+	}
+	return b.header, nil
+}
+
+// resolveReceipts returns the list of receipts for this block, fetching them
+// if necessary.
+func (b *Block) resolveReceipts(ctx context.Context) ([]*types.Receipt, error) {
+	if b.receipts == nil {
+		hash := b.hash
+		if hash == (common.Hash{}) {
+			header, err := b.resolveHeader(ctx)
+			if err != nil {
+				return nil, err
+			}
+			hash = header.Hash()
+		}
+		receipts, err := b.backend.GetReceipts(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		b.receipts = []*types.Receipt(receipts)
+	}
+	return b.receipts, nil
+}
+
+func (b *Block) Number(ctx context.Context) (Long, error) {
+	if b.num == nil || *b.num == rpc.LatestBlockNumber {
+		header, err := b.resolveHeader(ctx)
+		if err != nil {
+			return 0, err
+		}
+		num := rpc.BlockNumber(header.Number.Uint64())
+		b.num = &num
+	}
+	return Long(*b.num), nil
+}
+
+func (b *Block) Hash(ctx context.Context) (common.Hash, error) {
+	if b.hash == (common.Hash{}) {
+		header, err := b.resolveHeader(ctx)
+		if err != nil {
+			return common.Hash{}, err
+		}
+		b.hash = header.Hash()
+	}
+	return b.hash, nil
+}
+
+func (b *Block) GasLimit(ctx context.Context) (Long, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(header.GasLimit), nil
+}
+
+func (b *Block) GasUsed(ctx context.Context) (Long, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(header.GasUsed), nil
+}
+
+func (b *Block) Parent(ctx context.Context) (*Block, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if header.Number.Sign() == 0 {
+		return nil, nil
+	}
+	num := rpc.BlockNumber(header.Number.Uint64() - 1)
+	return &Block{
+		backend: b.backend,
+		num:     &num,
+		hash:    header.ParentHash,
+	}, nil
+}
+
+func (b *Block) Difficulty(ctx context.Context) (hexutil.Big, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*header.Difficulty), nil
+}
+
+func (b *Block) Timestamp(ctx context.Context) (hexutil.Big, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*header.Time), nil
+}
+
+func (b *Block) ExtraData(ctx context.Context) (hexutil.Bytes, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(header.Extra), nil
+}
+
+func (b *Block) LogsBloom(ctx context.Context) (hexutil.Bytes, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return hexutil.Bytes(header.Bloom.Bytes()), nil
+}
+
+func (b *Block) TotalDifficulty(ctx context.Context) (hexutil.Big, error) {
+	hash, err := b.Hash(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	td := b.backend.GetTd(hash)
+	if td == nil {
+		return hexutil.Big{}, fmt.Errorf("total difficulty not found %x", hash)
+	}
+	return hexutil.Big(*td), nil
+}
+
+func (b *Block) StateRoot(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.Root, nil
+}
+
+func (b *Block) TransactionsRoot(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.TxHash, nil
+}
+
+func (b *Block) ReceiptsRoot(ctx context.Context) (common.Hash, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return header.ReceiptHash, nil
+}
+
+func (b *Block) Miner(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	header, err := b.resolveHeader(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		backend:     b.backend,
+		address:     header.Coinbase,
+		blockNumber: args.Number(),
+	}, nil
+}
+
+func (b *Block) TransactionCount(ctx context.Context) (int32, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return 0, err
+	}
+	return int32(len(block.Transactions())), nil
+}
+
+func (b *Block) Transactions(ctx context.Context) ([]*Transaction, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	ret := make([]*Transaction, 0, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		ret = append(ret, &Transaction{
+			backend: b.backend,
+			hash:    tx.Hash(),
+			tx:      tx,
+			block:   b,
+			index:   uint64(i),
+		})
+	}
+	return ret, nil
+}
+
+func (b *Block) TransactionAt(ctx context.Context, args struct{ Index int32 }) (*Transaction, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	txs := block.Transactions()
+	if args.Index < 0 || int(args.Index) >= len(txs) {
+		return nil, nil
+	}
+	tx := txs[args.Index]
+	return &Transaction{
+		backend: b.backend,
+		hash:    tx.Hash(),
+		tx:      tx,
+		block:   b,
+		index:   uint64(args.Index),
+	}, nil
+}
+
+// BlockFilterCriteria encapsulates criteria passed to a `logs` accessor inside
+// a block.
+type BlockFilterCriteria struct {
+	Addresses *[]common.Address // restricts matches to events created by specific contracts
+
+	// The Topic list restricts matches to particular event topics. Each event has a list
+	// of topics. Topics matches a prefix of that list. An empty element slice matches any
+	// topic. Non-empty elements represent an alternative that matches any of the
+	// contained topics.
+	Topics *[][]common.Hash
+}
+
+// filterCriteria dereferences the optional addresses and topics of a filter.
+func filterCriteria(addresses *[]common.Address, topics *[][]common.Hash) ([]common.Address, [][]common.Hash) {
+	var (
+		addrs []common.Address
+		tops  [][]common.Hash
+	)
+	if addresses != nil {
+		addrs = *addresses
+	}
+	if topics != nil {
+		tops = *topics
+	}
+	return addrs, tops
+}
+
+// runFilter runs a filter and returns the matching logs.
+func runFilter(ctx context.Context, be Backend, filter *filters.Filter) ([]*Log, error) {
+	logs, err := filter.Logs(ctx)
+	if err != nil || logs == nil {
+		return nil, err
+	}
+	ret := make([]*Log, 0, len(logs))
+	for _, log := range logs {
+		ret = append(ret, &Log{
+			backend:     be,
+			transaction: &Transaction{backend: be, hash: log.TxHash},
+			log:         log,
+		})
+	}
+	return ret, nil
+}
+
+func (b *Block) Logs(ctx context.Context, args struct{ Filter BlockFilterCriteria }) ([]*Log, error) {
+	hash, err := b.Hash(ctx)
+	if err != nil {
+		return nil, err
+	}
+	addresses, topics := filterCriteria(args.Filter.Addresses, args.Filter.Topics)
+	return runFilter(ctx, b.backend, filters.NewBlockFilter(b.backend, hash, addresses, topics))
+}
+
+func (b *Block) Account(ctx context.Context, args struct{ Address common.Address }) (*Account, error) {
+	number, err := b.Number(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		backend:     b.backend,
+		address:     args.Address,
+		blockNumber: rpc.BlockNumber(number),
+	}, nil
+}
+
+func (b *Block) Token(ctx context.Context, args struct{ Id common.Address }) (*Token, error) {
+	number, err := b.Number(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t := &Token{
+		backend:     b.backend,
+		id:          args.Id,
+		blockNumber: rpc.BlockNumber(number),
+	}
+	if exists, err := t.exists(ctx); err != nil || !exists {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (b *Block) TokenGasPayments(ctx context.Context) ([]*TokenGasPayment, error) {
+	block, err := b.resolve(ctx)
+	if err != nil || block == nil {
+		return nil, err
+	}
+	receipts, err := b.resolveReceipts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		ret      []*TokenGasPayment
+		payments = make(map[common.Address]*TokenGasPayment)
+	)
+	for i, tx := range block.Transactions() {
+		if i >= len(receipts) || !receipts[i].TokenGas {
+			continue
+		}
+		payment, ok := payments[*tx.Token()]
+		if !ok {
+			payment = &TokenGasPayment{
+				token:  &Token{backend: b.backend, id: *tx.Token(), blockNumber: rpc.BlockNumber(block.NumberU64())},
+				amount: new(big.Int),
+			}
+			payments[*tx.Token()] = payment
+			ret = append(ret, payment)
+		}
+		gas := receipts[i].GasUsed
+		payment.gas += gas
+		payment.amount.Add(payment.amount, new(big.Int).Mul(new(big.Int).SetUint64(gas), tx.GasPrice()))
+	}
+	return ret, nil
+}
+
+// BlockNumberArgs encapsulates arguments to accessors that specify a block number.
+type BlockNumberArgs struct {
+	Block *Long
+}
+
+// Number returns the provided block number, or rpc.LatestBlockNumber if none
+// was provided.
+func (a BlockNumberArgs) Number() rpc.BlockNumber {
+	if a.Block != nil {
+		return rpc.BlockNumber(*a.Block)
+	}
+	return rpc.LatestBlockNumber
+}
+
+// Resolver is the top-level object in the GraphQL hierarchy.
+type Resolver struct {
+	backend Backend
+}
+
+func (r *Resolver) Block(ctx context.Context, args struct {
+	Number *Long
+	Hash   *common.Hash
+}) (*Block, error) {
+	var block *Block
+	if args.Number != nil {
+		num := rpc.BlockNumber(*args.Number)
+		block = &Block{
+			backend: r.backend,
+			num:     &num,
+		}
+	} else if args.Hash != nil {
+		block = &Block{
+			backend: r.backend,
+			hash:    *args.Hash,
+		}
+	} else {
+		num := rpc.LatestBlockNumber
+		block = &Block{
+			backend: r.backend,
+			num:     &num,
+		}
+	}
+	// Resolve the header, return nil if it doesn't exist
+	if _, err := block.resolveHeader(ctx); err != nil {
+		return nil, nil
+	}
+	return block, nil
+}
+
+func (r *Resolver) Blocks(ctx context.Context, args struct {
+	From Long
+	To   *Long
+}) ([]*Block, error) {
+	head, err := r.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	from, to := rpc.BlockNumber(args.From), rpc.BlockNumber(head.Number.Int64())
+	if args.To != nil && rpc.BlockNumber(*args.To) < to {
+		to = rpc.BlockNumber(*args.To)
+	}
+	if to < from {
+		return []*Block{}, nil
+	}
+	if to-from >= maxBlockRange {
+		return nil, fmt.Errorf("block range %d-%d exceeds the limit of %d blocks", from, to, maxBlockRange)
+	}
+	ret := make([]*Block, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		num := i
+		ret = append(ret, &Block{
+			backend: r.backend,
+			num:     &num,
+		})
+	}
+	return ret, nil
+}
+
+func (r *Resolver) Transaction(ctx context.Context, args struct{ Hash common.Hash }) (*Transaction, error) {
+	tx := &Transaction{
+		backend: r.backend,
+		hash:    args.Hash,
+	}
+	// Resolve the transaction; if it doesn't exist, return nil.
+	t, err := tx.resolve(ctx)
+	if err != nil || t == nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// FilterCriteria encapsulates the arguments to `logs` on the root resolver object.
+type FilterCriteria struct {
+	FromBlock *Long // beginning of the queried range, nil means latest block
+	ToBlock   *Long // end of the range, nil means latest block
+
+	Addresses *[]common.Address // restricts matches to events created by specific contracts
+	Topics    *[][]common.Hash  // restricts matches to particular event topics, see BlockFilterCriteria
+}
+
+func (r *Resolver) Logs(ctx context.Context, args struct{ Filter FilterCriteria }) ([]*Log, error) {
+	head, err := r.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return nil, err
+	}
+	begin, end := head.Number.Int64(), head.Number.Int64()
+	if args.Filter.FromBlock != nil && *args.Filter.FromBlock >= 0 {
+		begin = int64(*args.Filter.FromBlock)
+	}
+	if args.Filter.ToBlock != nil && *args.Filter.ToBlock >= 0 && int64(*args.Filter.ToBlock) < end {
+		end = int64(*args.Filter.ToBlock)
+	}
+	if end-begin >= maxBlockRange {
+		return nil, fmt.Errorf("block range %d-%d exceeds the limit of %d blocks", begin, end, maxBlockRange)
+	}
+	addresses, topics := filterCriteria(args.Filter.Addresses, args.Filter.Topics)
+	return runFilter(ctx, r.backend, filters.NewRangeFilter(r.backend, begin, end, addresses, topics))
+}
+
+func (r *Resolver) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	price, err := r.backend.SuggestPrice(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*price), nil
+}
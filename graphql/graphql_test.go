@@ -0,0 +1,394 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bcos-one/BCOS/accounts/abi"
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/consensus/ethash"
+	"github.com/bcos-one/BCOS/core"
+	"github.com/bcos-one/BCOS/core/state"
+	"github.com/bcos-one/BCOS/core/types"
+	"github.com/bcos-one/BCOS/core/vm"
+	"github.com/bcos-one/BCOS/crypto"
+	"github.com/bcos-one/BCOS/ethdb"
+	"github.com/bcos-one/BCOS/params"
+	"github.com/bcos-one/BCOS/rpc"
+)
+
+// testBackend serves the GraphQL queries from a local chain, implementing the
+// parts of the backend the tests exercise.
+type testBackend struct {
+	Backend
+	db    ethdb.Database
+	chain *core.BlockChain
+}
+
+func (b *testBackend) ChainDb() ethdb.Database                           { return b.db }
+func (b *testBackend) ChainConfig() *params.ChainConfig                  { return b.chain.Config() }
+func (b *testBackend) GetTd(hash common.Hash) *big.Int                   { return b.chain.GetTdByHash(hash) }
+func (b *testBackend) GetPoolTransaction(common.Hash) *types.Transaction { return nil }
+
+func (b *testBackend) HeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Header, error) {
+	if number == rpc.LatestBlockNumber {
+		return b.chain.CurrentHeader(), nil
+	}
+	return b.chain.GetHeaderByNumber(uint64(number)), nil
+}
+
+func (b *testBackend) HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error) {
+	return b.chain.GetHeaderByHash(hash), nil
+}
+
+func (b *testBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*types.Block, error) {
+	if number == rpc.LatestBlockNumber {
+		return b.chain.CurrentBlock(), nil
+	}
+	return b.chain.GetBlockByNumber(uint64(number)), nil
+}
+
+func (b *testBackend) GetBlock(ctx context.Context, hash common.Hash) (*types.Block, error) {
+	return b.chain.GetBlockByHash(hash), nil
+}
+
+func (b *testBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return b.chain.GetReceiptsByHash(hash), nil
+}
+
+func (b *testBackend) GetLogs(ctx context.Context, hash common.Hash) ([][]*types.Log, error) {
+	receipts := b.chain.GetReceiptsByHash(hash)
+	logs := make([][]*types.Log, len(receipts))
+	for i, receipt := range receipts {
+		logs[i] = receipt.Logs
+	}
+	return logs, nil
+}
+
+func (b *testBackend) StateAndHeaderByNumber(ctx context.Context, number rpc.BlockNumber) (*state.StateDB, *types.Header, error) {
+	header, _ := b.HeaderByNumber(ctx, number)
+	if header == nil {
+		return nil, nil, nil
+	}
+	statedb, err := b.chain.StateAt(header.Root)
+	return statedb, header, err
+}
+
+// newTestBackend creates a chain issuing a token and transferring it, as well as
+// ether, to a recipient.
+func newTestBackend(t *testing.T) (*testBackend, common.Address, common.Address, common.Address) {
+	var (
+		storage   = common.HexToAddress("0x1000")
+		key, _    = crypto.GenerateKey()
+		sender    = crypto.PubkeyToAddress(key.PublicKey)
+		recipient = common.HexToAddress("0x2000")
+	)
+	config := *params.TestChainConfig
+	config.ExpansionsConfig = &params.ExpansionsConfig{TokenSupport: true, TokenStorage: storage}
+	signer := types.NewEIP155Signer(config.ChainID)
+
+	gspec := &core.Genesis{
+		Config:   &config,
+		GasLimit: 10000000,
+		Alloc:    core.GenesisAlloc{sender: {Balance: big.NewInt(params.Ether)}},
+	}
+	issuer, _ := abi.JSON(strings.NewReader(`[{"name":"issue","type":"function","inputs":[{"name":"name","type":"string"},{"name":"manager","type":"address"},{"name":"beneficiary","type":"address"},{"name":"supply","type":"uint256"},{"name":"canIncrease","type":"bool"},{"name":"canburn","type":"bool"}]}]`))
+	input, err := issuer.Pack("issue", "gold", sender, sender, big.NewInt(1000000), true, false)
+	if err != nil {
+		t.Fatalf("failed to pack token issue: %v", err)
+	}
+	token := crypto.CreateAddress(sender, 0)
+
+	db := ethdb.NewMemDatabase()
+	genesis := gspec.MustCommit(db)
+	blocks, _ := core.GenerateChain(&config, genesis, ethash.NewFaker(), db, 2, func(i int, gen *core.BlockGen) {
+		var txs []*types.Transaction
+		switch i {
+		case 0:
+			txs = append(txs, types.NewTransaction(gen.TxNonce(sender), storage, nil, new(big.Int), 500000, big.NewInt(1), input))
+		case 1:
+			txs = append(txs, types.NewTransaction(gen.TxNonce(sender), recipient, &token, big.NewInt(250), 100000, big.NewInt(1), nil))
+			txs = append(txs, types.NewTransaction(gen.TxNonce(sender)+1, recipient, nil, big.NewInt(1000), 100000, big.NewInt(1), nil))
+		}
+		for _, tx := range txs {
+			signed, err := types.SignTx(tx, signer, key)
+			if err != nil {
+				t.Fatalf("failed to sign transaction: %v", err)
+			}
+			gen.AddTx(signed)
+		}
+	})
+	chaindb := ethdb.NewMemDatabase()
+	gspec.MustCommit(chaindb)
+	chain, _ := core.NewBlockChain(chaindb, nil, &config, ethash.NewFaker(), vm.Config{}, nil)
+	if n, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert block %d: %v", n, err)
+	}
+	return &testBackend{db: chaindb, chain: chain}, sender, recipient, token
+}
+
+// query posts a GraphQL query to the handler, decoding the result into data.
+func query(t *testing.T, url string, q string, data interface{}) []interface{} {
+	body, _ := json.Marshal(map[string]interface{}{"query": q})
+	resp, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []interface{}   `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode result: %v", err)
+	}
+	if len(result.Data) > 0 && data != nil {
+		if err := json.Unmarshal(result.Data, data); err != nil {
+			t.Fatalf("failed to decode data %s: %v", result.Data, err)
+		}
+	}
+	return result.Errors
+}
+
+// Tests that a single query resolves a block, its transactions and receipts, the
+// accounts involved and the native token moved.
+func TestQuery(t *testing.T) {
+	backend, sender, recipient, token := newTestBackend(t)
+	defer backend.chain.Stop()
+
+	handler, err := newHandler(backend, nil, rpc.Limits{})
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	var data struct {
+		Block struct {
+			Number       int64
+			Transactions []struct {
+				Index  int
+				Status int64
+				Value  string
+				From   struct{ Address common.Address }
+				Token  *struct{ Id common.Address }
+			}
+			Account struct {
+				Balance      string
+				TokenBalance string
+			}
+			Token struct {
+				Name        string
+				Supply      string
+				CanIncrease bool
+				CanBurn     bool
+				Manager     struct{ Address common.Address }
+				Holders     []struct {
+					Account struct{ Address common.Address }
+					Balance string
+					Frozen  bool
+				}
+			}
+			TokenGasPayments []interface{}
+		}
+	}
+	q := `{ block { number
+		transactions { index status value from { address } token { id } }
+		account(address: "` + recipient.Hex() + `") { balance tokenBalance(token: "` + token.Hex() + `") }
+		token(id: "` + token.Hex() + `") { name supply canIncrease canBurn manager { address }
+			holders(addresses: ["` + sender.Hex() + `", "` + recipient.Hex() + `"]) { account { address } balance frozen } }
+		tokenGasPayments { gasUsed }
+	} }`
+	if errs := query(t, srv.URL+"/graphql", q, &data); len(errs) > 0 {
+		t.Fatalf("query failed: %v", errs)
+	}
+	block := data.Block
+	if block.Number != 2 {
+		t.Errorf("block number mismatch: have %d, want 2", block.Number)
+	}
+	if len(block.Transactions) != 2 {
+		t.Fatalf("transaction count mismatch: have %d, want 2", len(block.Transactions))
+	}
+	for i, tx := range block.Transactions {
+		if tx.Index != i || tx.Status != 1 || tx.From.Address != sender {
+			t.Errorf("transaction %d: mismatch: %+v", i, tx)
+		}
+	}
+	if tx := block.Transactions[0]; tx.Token == nil || tx.Token.Id != token || tx.Value != "0xfa" {
+		t.Errorf("token transfer mismatch: %+v", tx)
+	}
+	if tx := block.Transactions[1]; tx.Token != nil || tx.Value != "0x3e8" {
+		t.Errorf("ether transfer mismatch: %+v", tx)
+	}
+	if block.Account.Balance != "0x3e8" || block.Account.TokenBalance != "0xfa" {
+		t.Errorf("recipient balances mismatch: %+v", block.Account)
+	}
+	if tok := block.Token; tok.Name != "gold" || tok.Supply != "0xf4240" || !tok.CanIncrease || tok.CanBurn || tok.Manager.Address != sender {
+		t.Errorf("token mismatch: %+v", tok)
+	}
+	want := []string{"0xf4146", "0xfa"}
+	if len(block.Token.Holders) != len(want) {
+		t.Fatalf("holder count mismatch: have %d, want %d", len(block.Token.Holders), len(want))
+	}
+	for i, holder := range block.Token.Holders {
+		if holder.Balance != want[i] || holder.Frozen {
+			t.Errorf("holder %d: mismatch: have %+v, want balance %s", i, holder, want[i])
+		}
+	}
+	if len(block.TokenGasPayments) != 0 {
+		t.Errorf("token gas payments without whitelisted tokens: %v", block.TokenGasPayments)
+	}
+}
+
+// Tests that transactions are found by hash and that historical state is served
+// at the block requested.
+func TestQueryTransaction(t *testing.T) {
+	backend, _, recipient, token := newTestBackend(t)
+	defer backend.chain.Stop()
+
+	handler, err := newHandler(backend, nil, rpc.Limits{})
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	hash := backend.chain.GetBlockByNumber(2).Transactions()[0].Hash()
+	var data struct {
+		Transaction struct {
+			Hash    common.Hash
+			GasUsed int64
+			Block   struct{ Number int64 }
+			Before  struct {
+				Address      common.Address
+				TokenBalance string
+			}
+			After struct{ TokenBalance string }
+		}
+		Missing *struct{ Hash common.Hash }
+	}
+	q := `{ transaction(hash: "` + hash.Hex() + `") { hash gasUsed block { number }
+			before: to(block: 1) { address tokenBalance(token: "` + token.Hex() + `") }
+			after: to { tokenBalance(token: "` + token.Hex() + `") } }
+		missing: transaction(hash: "` + common.Hash{0x01}.Hex() + `") { hash } }`
+	if errs := query(t, srv.URL+"/graphql", q, &data); len(errs) > 0 {
+		t.Fatalf("query failed: %v", errs)
+	}
+	tx := data.Transaction
+	if tx.Hash != hash || tx.GasUsed != 21000 || tx.Block.Number != 2 {
+		t.Errorf("transaction mismatch: %+v", tx)
+	}
+	if tx.Before.Address != recipient || tx.Before.TokenBalance != "0x0" || tx.After.TokenBalance != "0xfa" {
+		t.Errorf("historical balances mismatch: %+v %+v", tx.Before, tx.After)
+	}
+	if data.Missing != nil {
+		t.Errorf("unknown transaction found: %+v", data.Missing)
+	}
+	// Ensure invalid queries are reported as errors
+	if errs := query(t, srv.URL+"/graphql", `{ block { unknown } }`, nil); len(errs) == 0 {
+		t.Errorf("invalid query accepted")
+	}
+}
+
+// Tests that block ranges are resolved, capping them at the chain head.
+func TestQueryBlocks(t *testing.T) {
+	backend, _, _, _ := newTestBackend(t)
+	defer backend.chain.Stop()
+
+	handler, err := newHandler(backend, nil, rpc.Limits{})
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	var data struct {
+		Blocks []struct {
+			Number           int64
+			TransactionCount int
+		}
+	}
+	if errs := query(t, srv.URL+"/graphql", `{ blocks(from: 0) { number transactionCount } }`, &data); len(errs) > 0 {
+		t.Fatalf("query failed: %v", errs)
+	}
+	want := []int{0, 1, 2}
+	if len(data.Blocks) != len(want) {
+		t.Fatalf("block count mismatch: have %d, want %d", len(data.Blocks), len(want))
+	}
+	for i, block := range data.Blocks {
+		if block.Number != int64(i) || block.TransactionCount != want[i] {
+			t.Errorf("block %d: mismatch: %+v", i, block)
+		}
+	}
+	if errs := query(t, srv.URL+"/graphql", `{ blocks(from: 0, to: 5000) { number } }`, nil); len(errs) != 0 {
+		t.Errorf("range capped by the head rejected: %v", errs)
+	}
+}
+
+// Tests that queries are authenticated and limited like the RPC calls.
+func TestQueryAuthLimits(t *testing.T) {
+	backend, _, _, _ := newTestBackend(t)
+	defer backend.chain.Stop()
+
+	auth, err := rpc.NewAuthenticator(&rpc.AuthConfig{
+		Credentials: []rpc.Credential{
+			{Name: "reader", APIKey: "reader-key", Allow: []string{"graphql"}},
+			{Name: "rpc", APIKey: "rpc-key", Allow: []string{"eth"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+	handler, err := newHandler(backend, auth, rpc.Limits{ResponseBytes: 200})
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	post := func(key string, q string) (int, string) {
+		body, _ := json.Marshal(map[string]interface{}{"query": q})
+		req, _ := http.NewRequest(http.MethodPost, srv.URL+"/graphql", bytes.NewReader(body))
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("query failed: %v", err)
+		}
+		defer resp.Body.Close()
+
+		blob, _ := ioutil.ReadAll(resp.Body)
+		return resp.StatusCode, string(blob)
+	}
+	tests := []struct {
+		key    string
+		query  string
+		status int
+		result string
+	}{
+		{"", `{ block { number } }`, http.StatusUnauthorized, ""},
+		{"wrong-key", `{ block { number } }`, http.StatusUnauthorized, ""},
+		{"rpc-key", `{ block { number } }`, http.StatusForbidden, ""},
+		{"reader-key", `{ block { number } }`, http.StatusOK, `{"data":{"block":{"number":2}}}`},
+		{"reader-key", `{ blocks(from: 0) { hash parent { hash } } }`, http.StatusOK, "response too large"},
+	}
+	for i, tt := range tests {
+		status, result := post(tt.key, tt.query)
+		if status != tt.status {
+			t.Errorf("test %d: status mismatch: have %d, want %d", i, status, tt.status)
+			continue
+		}
+		if !strings.Contains(result, tt.result) {
+			t.Errorf("test %d: result mismatch: have %s, want %s", i, result, tt.result)
+		}
+	}
+}
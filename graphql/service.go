@@ -0,0 +1,196 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/bcos-one/BCOS/log"
+	"github.com/bcos-one/BCOS/p2p"
+	"github.com/bcos-one/BCOS/rpc"
+	graphql "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+const (
+	maxRequestContentLength = 1024 * 512 // Maximum size of a query accepted over HTTP
+	maxQueryDepth           = 16         // Maximum nesting of the selections of a query
+
+	// queryModule and queryMethod are the names the queries are authorized and
+	// limited as, so credentials need to allow "graphql" or "graphql_query".
+	queryModule = "graphql"
+	queryMethod = "query"
+)
+
+// Service encapsulates a GraphQL service.
+type Service struct {
+	endpoint string           // The host:port endpoint for this service.
+	cors     []string         // Allowed CORS domains
+	vhosts   []string         // Recognised vhosts
+	timeouts rpc.HTTPTimeouts // Timeout settings for HTTP requests.
+	backend  Backend          // The backend that queries will operate on.
+	handler  http.Handler     // The `http.Handler` used to answer queries.
+	listener net.Listener     // The listening socket.
+
+	authConfig *rpc.AuthConfig    // Credentials required to query, shared with the RPC endpoints
+	auth       *rpc.Authenticator // Authenticator of the queries (nil = open endpoint)
+	limits     rpc.Limits         // Resource limits of the queries, shared with the RPC endpoints
+}
+
+// New constructs a new GraphQL service instance. Queries are authenticated and
+// limited like the calls of the HTTP RPC endpoint, as graphql_query method.
+func New(backend Backend, endpoint string, cors, vhosts []string, timeouts rpc.HTTPTimeouts, auth *rpc.AuthConfig, limits rpc.Limits) (*Service, error) {
+	return &Service{
+		endpoint:   endpoint,
+		cors:       cors,
+		vhosts:     vhosts,
+		timeouts:   timeouts,
+		backend:    backend,
+		authConfig: auth,
+		limits:     limits,
+	}, nil
+}
+
+// Protocols returns the list of protocols exported by this service.
+func (s *Service) Protocols() []p2p.Protocol { return nil }
+
+// APIs returns the list of APIs exported by this service.
+func (s *Service) APIs() []rpc.API { return nil }
+
+// Start is called after all services have been constructed and the networking
+// layer was also initialized to spawn any goroutines required by the service.
+func (s *Service) Start(server *p2p.Server) error {
+	var err error
+	if s.authConfig != nil && len(s.authConfig.Credentials) > 0 {
+		if s.auth, err = rpc.NewAuthenticator(s.authConfig); err != nil {
+			return err
+		}
+	}
+	if s.handler, err = newHandler(s.backend, s.auth, s.limits); err != nil {
+		s.stopAuth()
+		return err
+	}
+	if s.listener, err = net.Listen("tcp", s.endpoint); err != nil {
+		s.stopAuth()
+		return err
+	}
+	go rpc.NewHTTPServer(s.cors, s.vhosts, s.timeouts, s.handler).Serve(s.listener)
+	log.Info("GraphQL endpoint opened", "url", fmt.Sprintf("http://%s/graphql", s.endpoint), "auth", s.auth != nil)
+	return nil
+}
+
+// Stop terminates all goroutines belonging to the service, blocking until they
+// are all terminated.
+func (s *Service) Stop() error {
+	if s.listener != nil {
+		s.listener.Close()
+		s.listener = nil
+		log.Info("GraphQL endpoint closed", "url", fmt.Sprintf("http://%s/graphql", s.endpoint))
+	}
+	s.stopAuth()
+	return nil
+}
+
+// stopAuth closes the audit log of the authenticator, if any.
+func (s *Service) stopAuth() {
+	if s.auth != nil {
+		if err := s.auth.Close(); err != nil {
+			log.Error("Failed to close GraphQL audit log", "err", err)
+		}
+		s.auth = nil
+	}
+}
+
+// newHandler returns a new `http.Handler` that will answer GraphQL queries on
+// the /graphql endpoint, authenticating them if an authenticator is given.
+func newHandler(backend Backend, auth *rpc.Authenticator, limits rpc.Limits) (http.Handler, error) {
+	s, err := graphql.ParseSchema(schema, &Resolver{backend}, graphql.MaxDepth(maxQueryDepth))
+	if err != nil {
+		return nil, err
+	}
+	h := &handler{schema: s, auth: auth, limits: limits}
+	if limits.Concurrency > 0 {
+		h.calls = make(chan struct{}, limits.Concurrency)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/graphql", h)
+	mux.Handle("/graphql/", h)
+	return mux, nil
+}
+
+// handler answers the GraphQL queries posted to it as JSON.
+type handler struct {
+	schema *graphql.Schema
+	auth   *rpc.Authenticator // Authenticator of the queries (nil = open endpoint)
+	limits rpc.Limits         // Execution time and response size limits of the queries
+	calls  chan struct{}      // Slots of the queries executing concurrently (nil = unlimited)
+}
+
+// ServeHTTP executes the query of the request and writes the JSON encoded result,
+// which may contain both data and errors.
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.auth != nil {
+		if code, err := h.auth.AuthorizeHTTP(r, queryModule, queryMethod); err != nil {
+			http.Error(w, err.Error(), code)
+			return
+		}
+	}
+	var params struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxRequestContentLength)).Decode(&params); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if h.calls != nil {
+		select {
+		case h.calls <- struct{}{}:
+			defer func() { <-h.calls }()
+		default:
+			h.write(w, errorResponse("too many concurrent requests (max %d)", cap(h.calls)))
+			return
+		}
+	}
+	ctx := r.Context()
+	timeout := h.limits.Timeout(queryModule + "_" + queryMethod)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	response := h.schema.Exec(ctx, params.Query, params.OperationName, params.Variables)
+	if ctx.Err() == context.DeadlineExceeded {
+		response = errorResponse("query execution timed out after %v", timeout)
+	}
+	h.write(w, response)
+}
+
+// write writes the JSON encoded response, replacing it with an error if it
+// exceeds the response size limit.
+func (h *handler) write(w http.ResponseWriter, response *graphql.Response) {
+	blob, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if limit := h.limits.ResponseBytes; limit > 0 && len(blob) > limit {
+		blob, _ = json.Marshal(errorResponse("response too large (%d > %d bytes)", len(blob), limit))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(blob)
+}
+
+// errorResponse creates a query response carrying only the given error.
+func errorResponse(format string, args ...interface{}) *graphql.Response {
+	return &graphql.Response{Errors: []*gqlerrors.QueryError{gqlerrors.Errorf(format, args...)}}
+}
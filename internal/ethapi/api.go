@@ -21,6 +21,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/bcos-one/BCOS/expansions/management"
 	"github.com/bcos-one/BCOS/expansions/token"
 	"math/big"
 	"strings"
@@ -42,9 +43,8 @@ import (
 	"github.com/bcos-one/BCOS/params"
 	"github.com/bcos-one/BCOS/rlp"
 	"github.com/bcos-one/BCOS/rpc"
+	"github.com/bcos-one/BCOS/signer/typeddata"
 	"github.com/davecgh/go-spew/spew"
-	"github.com/syndtr/goleveldb/leveldb"
-	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
 const (
@@ -355,7 +355,7 @@ func (s *PrivateAccountAPI) signTransaction(ctx context.Context, args *SendTxArg
 		return nil, err
 	}
 	// Assemble the transaction and sign with the wallet
-	tx := args.toTransaction()
+	tx := args.toTransaction(s.b)
 
 	var chainID *big.Int
 	if config := s.b.ChainConfig(); config.IsEIP155(s.b.CurrentBlock().Number()) {
@@ -403,7 +403,7 @@ func (s *PrivateAccountAPI) SignTransaction(ctx context.Context, args SendTxArgs
 		log.Warn("Failed transaction sign attempt", "from", args.From, "to", args.To, "value", args.Value.ToInt(), "err", err)
 		return nil, err
 	}
-	data, err := rlp.EncodeToBytes(signed)
+	data, err := signed.MarshalBinary()
 	if err != nil {
 		return nil, err
 	}
@@ -449,6 +449,53 @@ func (s *PrivateAccountAPI) Sign(ctx context.Context, data hexutil.Bytes, addr c
 	return signature, nil
 }
 
+// SignTypedData calculates an Ethereum ECDSA signature for the EIP-712 typed
+// structured data:
+// keccak256("\x19\x01" ‖ domainSeparator ‖ hashStruct(message))
+//
+// Note, the produced signature conforms to the secp256k1 curve R, S and V values,
+// where the V value will be 27 or 28 for legacy reasons.
+//
+// The key used to calculate the signature is decrypted with the given password.
+func (s *PrivateAccountAPI) SignTypedData(ctx context.Context, typedData typeddata.TypedData, addr common.Address, passwd string) (hexutil.Bytes, error) {
+	sighash, err := typedDataHash(s.b, &typedData)
+	if err != nil {
+		return nil, err
+	}
+	// Look up the wallet containing the requested signer
+	account := accounts.Account{Address: addr}
+
+	wallet, err := s.b.AccountManager().Find(account)
+	if err != nil {
+		return nil, err
+	}
+	// Assemble sign the data with the wallet
+	signature, err := wallet.SignHashWithPassphrase(account, passwd, sighash)
+	if err != nil {
+		log.Warn("Failed typed data sign attempt", "address", addr, "err", err)
+		return nil, err
+	}
+	signature[64] += 27 // Transform V from 0/1 to 27/28 according to the yellow paper
+	return signature, nil
+}
+
+// typedDataHash validates the EIP-712 typed data and returns the hash to sign,
+// refusing data bound to a different chain.
+func typedDataHash(b Backend, typedData *typeddata.TypedData) ([]byte, error) {
+	sighash, _, err := typedData.SignHash()
+	if err != nil {
+		return nil, err
+	}
+	chainID, err := typedData.ChainID()
+	if err != nil {
+		return nil, err
+	}
+	if config := b.ChainConfig(); chainID != nil && config.ChainID != nil && chainID.Cmp(config.ChainID) != 0 {
+		return nil, fmt.Errorf("typed data is bound to chain %v, not %v", chainID, config.ChainID)
+	}
+	return sighash, nil
+}
+
 // EcRecover returns the address for the account that was used to create the signature.
 // Note, this function is compatible with eth_sign and personal_sign. As such it recovers
 // the address of:
@@ -488,6 +535,7 @@ type RPCTokenResult struct {
 	Supply      *hexutil.Big   `json:"supply"`
 	CanIncrease bool           `json:"canIncrease"`
 	CanBurn     bool           `json:"canBurn"`
+	Paused      bool           `json:"paused"`
 }
 
 // PublicBlockChainAPI provides an API to access the Ethereum blockchain.
@@ -536,16 +584,29 @@ func (s *PublicBlockChainAPI) GetTokenId(ctx context.Context, hash common.Hash)
 		return common.Address{}, errors.New("Token support disabled")
 	}
 
-	if tx, _, _, _ := rawdb.ReadTransaction(s.b.ChainDb(), hash); tx != nil && *tx.To() == config.TokenStorage {
+	if tx, _, _, _ := rawdb.ReadTransaction(s.b.ChainDb(), hash); tx != nil && tx.To() != nil && *tx.To() == config.TokenStorage {
 		signer := types.NewEIP155Signer(s.b.ChainConfig().ChainID)
 		from, _ := types.Sender(signer, tx)
 
-		return crypto.CreateAddress(from, tx.Nonce()), nil
+		if id, err := token.IssuedTokenId(from, tx.Nonce(), tx.Data()); err == nil {
+			return id, nil
+		}
 	}
 
 	return common.Address{}, errors.New("The transaction does not contain any token operation")
 }
 
+// PredictTokenId returns the id a token issued by from through the salted issue
+// operation will get, allowing the id to be used before the issue is submitted.
+func (s *PublicBlockChainAPI) PredictTokenId(ctx context.Context, from common.Address, salt common.Hash, name string) (common.Address, error) {
+	config := s.b.ChainConfig().ExpansionsConfig
+	if config == nil || !config.TokenSupport {
+		return common.Address{}, errors.New("Token support disabled")
+	}
+
+	return token.CreateTokenId(from, salt, name), nil
+}
+
 // GetToken returns the token information
 func (s *PublicBlockChainAPI) GetToken(ctx context.Context, tokenId common.Address, blockNr rpc.BlockNumber) (*RPCTokenResult, error) {
 	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
@@ -568,9 +629,76 @@ func (s *PublicBlockChainAPI) GetToken(ctx context.Context, tokenId common.Addre
 		Supply:      (*hexutil.Big)(tokenObj.GetSupply()),
 		CanIncrease: tokenObj.CanIncrease(),
 		CanBurn:     tokenObj.CanBurn(),
+		Paused:      tokenObj.IsPaused(),
 	}, nil
 }
 
+// IsTokenAccountFrozen returns whether the token manager froze the given account
+func (s *PublicBlockChainAPI) IsTokenAccountFrozen(ctx context.Context, tokenId common.Address, account common.Address, blockNr rpc.BlockNumber) (bool, error) {
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return false, err
+	}
+	config := s.b.ChainConfig().ExpansionsConfig
+	if config == nil || !config.TokenSupport {
+		return false, errors.New("Token support disabled")
+	}
+
+	tokenObj := token.NewTokenObject(config.TokenStorage, tokenId, state)
+	if !tokenObj.IsExists() {
+		return false, errors.New("Token does not exist")
+	}
+
+	return tokenObj.IsFrozen(account), nil
+}
+
+// GetRoleHolders returns the accounts holding the given management role, one of
+// "admin", "tokenWhitelister" or "permissionManager".
+func (s *PublicBlockChainAPI) GetRoleHolders(ctx context.Context, role string, blockNr rpc.BlockNumber) ([]common.Address, error) {
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, err
+	}
+	config := s.b.ChainConfig().ExpansionsConfig
+	if config == nil || !config.ManageSupport {
+		return nil, errors.New("Management support disabled")
+	}
+	r, ok := management.RoleByName(role)
+	if !ok {
+		return nil, fmt.Errorf("unknown management role %q", role)
+	}
+
+	manageObj := management.NewManageObj(config.ManageStorage, common.Address{}, state)
+	holders := manageObj.RoleHolders(r)
+
+	// Chains set up before holder lists existed only flagged the genesis manager
+	if r == management.RoleAdmin && manageObj.IsManager(config.Manager) {
+		listed := false
+		for _, holder := range holders {
+			listed = listed || holder == config.Manager
+		}
+		if !listed {
+			holders = append(holders, config.Manager)
+		}
+	}
+	return holders, nil
+}
+
+// GetAdminThreshold returns the number of management admins that must approve an
+// admin action before it takes effect.
+func (s *PublicBlockChainAPI) GetAdminThreshold(ctx context.Context, blockNr rpc.BlockNumber) (hexutil.Uint64, error) {
+	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return 0, err
+	}
+	config := s.b.ChainConfig().ExpansionsConfig
+	if config == nil || !config.ManageSupport {
+		return 0, errors.New("Management support disabled")
+	}
+
+	return hexutil.Uint64(management.NewManageObj(config.ManageStorage, common.Address{}, state).AdminThreshold()), nil
+}
+
 func (s *PublicBlockChainAPI) GetTokenSupport(ctx context.Context, address common.Address, blockNr rpc.BlockNumber) (common.Address, error) {
 	state, _, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
 	if state == nil || err != nil {
@@ -1018,6 +1146,7 @@ type RPCTransaction struct {
 	To               *common.Address `json:"to"`
 	TransactionIndex hexutil.Uint    `json:"transactionIndex"`
 	Token            *common.Address `json:"token,omitempty"`
+	Type             hexutil.Uint64  `json:"type"`
 	Value            *hexutil.Big    `json:"value"`
 	V                *hexutil.Big    `json:"v"`
 	R                *hexutil.Big    `json:"r"`
@@ -1043,6 +1172,7 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		Nonce:    hexutil.Uint64(tx.Nonce()),
 		To:       tx.To(),
 		Token:    tx.Token(),
+		Type:     hexutil.Uint64(tx.Type()),
 		Value:    (*hexutil.Big)(tx.Value()),
 		V:        (*hexutil.Big)(v),
 		R:        (*hexutil.Big)(r),
@@ -1076,7 +1206,7 @@ func newRPCRawTransactionFromBlockIndex(b *types.Block, index uint64) hexutil.By
 	if index >= uint64(len(txs)) {
 		return nil
 	}
-	blob, _ := rlp.EncodeToBytes(txs[index])
+	blob, _ := txs[index].MarshalBinary()
 	return blob
 }
 
@@ -1186,8 +1316,8 @@ func (s *PublicTransactionPoolAPI) GetRawTransactionByHash(ctx context.Context,
 			return nil, nil
 		}
 	}
-	// Serialize to RLP and return
-	return rlp.EncodeToBytes(tx)
+	// Serialize to the transaction envelope and return
+	return tx.MarshalBinary()
 }
 
 // GetTransactionReceipt returns the transaction receipt for the given transaction hash.
@@ -1314,17 +1444,24 @@ func (args *SendTxArgs) setDefaults(ctx context.Context, b Backend) error {
 	return nil
 }
 
-func (args *SendTxArgs) toTransaction() *types.Transaction {
+func (args *SendTxArgs) toTransaction(b Backend) *types.Transaction {
 	var input []byte
 	if args.Data != nil {
 		input = *args.Data
 	} else if args.Input != nil {
 		input = *args.Input
 	}
+	var tx *types.Transaction
 	if args.To == nil {
-		return types.NewContractCreation(uint64(*args.Nonce), args.Token, (*big.Int)(args.Value), uint64(*args.Gas), (*big.Int)(args.GasPrice), input)
+		tx = types.NewContractCreation(uint64(*args.Nonce), args.Token, (*big.Int)(args.Value), uint64(*args.Gas), (*big.Int)(args.GasPrice), input)
+	} else {
+		tx = types.NewTransaction(uint64(*args.Nonce), *args.To, args.Token, (*big.Int)(args.Value), uint64(*args.Gas), (*big.Int)(args.GasPrice), input)
 	}
-	return types.NewTransaction(uint64(*args.Nonce), *args.To, args.Token, (*big.Int)(args.Value), uint64(*args.Gas), (*big.Int)(args.GasPrice), input)
+	// Token transactions keep the legacy envelope until the typed ones are activated
+	if !b.ChainConfig().IsTokenTx(new(big.Int).Add(b.CurrentBlock().Number(), common.Big1)) {
+		tx = tx.AsLegacy()
+	}
+	return tx
 }
 
 // submitTransaction is a helper function that submits tx to txPool and logs a message.
@@ -1370,7 +1507,7 @@ func (s *PublicTransactionPoolAPI) SendTransaction(ctx context.Context, args Sen
 		return common.Hash{}, err
 	}
 	// Assemble the transaction and sign with the wallet
-	tx := args.toTransaction()
+	tx := args.toTransaction(s.b)
 
 	var chainID *big.Int
 	if config := s.b.ChainConfig(); config.IsEIP155(s.b.CurrentBlock().Number()) {
@@ -1387,13 +1524,11 @@ func (s *PublicTransactionPoolAPI) SendTransaction(ctx context.Context, args Sen
 // The sender is responsible for signing the transaction and using the correct nonce.
 func (s *PublicTransactionPoolAPI) SendRawTransaction(ctx context.Context, encodedTx hexutil.Bytes) (common.Hash, error) {
 	tx := new(types.Transaction)
-	if err := rlp.DecodeBytes(encodedTx, tx); err != nil {
-		tmp := new(types.TransactionWithoutToken)
-		if err = rlp.DecodeBytes(encodedTx, tmp); err != nil {
-			return common.Hash{}, err
-		} else {
-			tx = types.NewTransactionCompatible(tmp)
-		}
+	if err := tx.UnmarshalBinary(encodedTx); err != nil {
+		return common.Hash{}, err
+	}
+	if err := core.ValidateTxType(s.b.ChainConfig(), tx, new(big.Int).Add(s.b.CurrentBlock().Number(), common.Big1)); err != nil {
+		return common.Hash{}, err
 	}
 	return submitTransaction(ctx, s.b, tx)
 }
@@ -1423,6 +1558,33 @@ func (s *PublicTransactionPoolAPI) Sign(addr common.Address, data hexutil.Bytes)
 	return signature, err
 }
 
+// SignTypedData calculates an ECDSA signature for the EIP-712 typed structured data:
+// keccak256("\x19\x01" ‖ domainSeparator ‖ hashStruct(message))
+//
+// Note, the produced signature conforms to the secp256k1 curve R, S and V values,
+// where the V value will be 27 or 28 for legacy reasons.
+//
+// The account associated with addr must be unlocked.
+func (s *PublicTransactionPoolAPI) SignTypedData(addr common.Address, typedData typeddata.TypedData) (hexutil.Bytes, error) {
+	sighash, err := typedDataHash(s.b, &typedData)
+	if err != nil {
+		return nil, err
+	}
+	// Look up the wallet containing the requested signer
+	account := accounts.Account{Address: addr}
+
+	wallet, err := s.b.AccountManager().Find(account)
+	if err != nil {
+		return nil, err
+	}
+	// Sign the requested hash with the wallet
+	signature, err := wallet.SignHash(account, sighash)
+	if err == nil {
+		signature[64] += 27 // Transform V from 0/1 to 27/28 according to the yellow paper
+	}
+	return signature, err
+}
+
 // SignTransactionResult represents a RLP encoded signed transaction.
 type SignTransactionResult struct {
 	Raw hexutil.Bytes      `json:"raw"`
@@ -1445,11 +1607,11 @@ func (s *PublicTransactionPoolAPI) SignTransaction(ctx context.Context, args Sen
 	if err := args.setDefaults(ctx, s.b); err != nil {
 		return nil, err
 	}
-	tx, err := s.sign(args.From, args.toTransaction())
+	tx, err := s.sign(args.From, args.toTransaction(s.b))
 	if err != nil {
 		return nil, err
 	}
-	data, err := rlp.EncodeToBytes(tx)
+	data, err := tx.MarshalBinary()
 	if err != nil {
 		return nil, err
 	}
@@ -1492,7 +1654,7 @@ func (s *PublicTransactionPoolAPI) Resend(ctx context.Context, sendArgs SendTxAr
 	if err := sendArgs.setDefaults(ctx, s.b); err != nil {
 		return common.Hash{}, err
 	}
-	matchTx := sendArgs.toTransaction()
+	matchTx := sendArgs.toTransaction(s.b)
 	pending, err := s.b.GetPoolTransactions()
 	if err != nil {
 		return common.Hash{}, err
@@ -1513,7 +1675,7 @@ func (s *PublicTransactionPoolAPI) Resend(ctx context.Context, sendArgs SendTxAr
 			if gasLimit != nil && *gasLimit != 0 {
 				sendArgs.Gas = gasLimit
 			}
-			signedTx, err := s.sign(sendArgs.From, sendArgs.toTransaction())
+			signedTx, err := s.sign(sendArgs.From, sendArgs.toTransaction(s.b))
 			if err != nil {
 				return common.Hash{}, err
 			}
@@ -1527,6 +1689,85 @@ func (s *PublicTransactionPoolAPI) Resend(ctx context.Context, sendArgs SendTxAr
 	return common.Hash{}, fmt.Errorf("Transaction %#x not found", matchTx.Hash())
 }
 
+// errReplaceNotFound is returned if a transaction is attempted to be replaced
+// explicitly, but the pool has no transaction with the same sender and nonce.
+var errReplaceNotFound = errors.New("no transaction to replace")
+
+// ReplaceTransaction signs the given transaction as an explicit replacement of
+// the pooled transaction with the same sender and nonce and submits it. Unlike
+// Resend it needs no price bump, so it is the way to replace a transaction on
+// chains with zero gas prices. The replacement marker is part of the signed
+// transaction, so it is honoured by the pools of the whole network.
+func (s *PublicTransactionPoolAPI) ReplaceTransaction(ctx context.Context, args SendTxArgs) (common.Hash, error) {
+	if args.Nonce == nil {
+		return common.Hash{}, fmt.Errorf("missing transaction nonce in transaction spec")
+	}
+	if !s.pooled(args.From, uint64(*args.Nonce)) {
+		return common.Hash{}, errReplaceNotFound
+	}
+	if err := args.setDefaults(ctx, s.b); err != nil {
+		return common.Hash{}, err
+	}
+	signed, err := s.sign(args.From, args.toTransaction(s.b).AsReplacement())
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if err := s.b.SendTx(ctx, signed); err != nil {
+		return common.Hash{}, err
+	}
+	log.Info("Submitted replacement transaction", "fullhash", signed.Hash().Hex(), "from", args.From, "nonce", signed.Nonce())
+	return signed.Hash(), nil
+}
+
+// ReplaceRawTransaction submits an externally signed replacement transaction,
+// which must be marked as replacement by its sender.
+func (s *PublicTransactionPoolAPI) ReplaceRawTransaction(ctx context.Context, encodedTx hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(encodedTx); err != nil {
+		return common.Hash{}, err
+	}
+	if !tx.Replaces() {
+		return common.Hash{}, errors.New("transaction not marked as replacement")
+	}
+	if err := core.ValidateTxType(s.b.ChainConfig(), tx, new(big.Int).Add(s.b.CurrentBlock().Number(), common.Big1)); err != nil {
+		return common.Hash{}, err
+	}
+	from, err := types.Sender(types.MakeSigner(s.b.ChainConfig(), s.b.CurrentBlock().Number()), tx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if !s.pooled(from, tx.Nonce()) {
+		return common.Hash{}, errReplaceNotFound
+	}
+	if err := s.b.SendTx(ctx, tx); err != nil {
+		return common.Hash{}, err
+	}
+	log.Info("Submitted replacement transaction", "fullhash", tx.Hash().Hex(), "from", from, "nonce", tx.Nonce())
+	return tx.Hash(), nil
+}
+
+// pooled returns whether the pool holds a transaction of the sender with the
+// given nonce.
+func (s *PublicTransactionPoolAPI) pooled(from common.Address, nonce uint64) bool {
+	pending, queue := s.b.TxPoolContent()
+	for _, txs := range []types.Transactions{pending[from], queue[from]} {
+		for _, tx := range txs {
+			if tx.Nonce() == nonce {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CancelTransaction cancels the pooled transaction with the given sender and
+// nonce on chains with zero gas prices, replacing it with an empty transfer from
+// the sender to itself.
+func (s *PublicTransactionPoolAPI) CancelTransaction(ctx context.Context, from common.Address, nonce hexutil.Uint64) (common.Hash, error) {
+	gas := hexutil.Uint64(params.TxGas)
+	return s.ReplaceTransaction(ctx, SendTxArgs{From: from, To: &from, Gas: &gas, Nonce: &nonce})
+}
+
 // PublicDebugAPI is the collection of Ethereum APIs exposed over the public
 // debugging endpoint.
 type PublicDebugAPI struct {
@@ -1584,30 +1825,18 @@ func NewPrivateDebugAPI(b Backend) *PrivateDebugAPI {
 
 // ChaindbProperty returns leveldb properties of the chain database.
 func (api *PrivateDebugAPI) ChaindbProperty(property string) (string, error) {
-	ldb, ok := api.b.ChainDb().(interface {
-		LDB() *leveldb.DB
-	})
-	if !ok {
-		return "", fmt.Errorf("chaindbProperty does not work for memory databases")
-	}
 	if property == "" {
 		property = "leveldb.stats"
 	} else if !strings.HasPrefix(property, "leveldb.") {
 		property = "leveldb." + property
 	}
-	return ldb.LDB().GetProperty(property)
+	return api.b.ChainDb().Stat(property)
 }
 
 func (api *PrivateDebugAPI) ChaindbCompact() error {
-	ldb, ok := api.b.ChainDb().(interface {
-		LDB() *leveldb.DB
-	})
-	if !ok {
-		return fmt.Errorf("chaindbCompact does not work for memory databases")
-	}
 	for b := byte(0); b < 255; b++ {
 		log.Info("Compacting chain database", "range", fmt.Sprintf("0x%0.2X-0x%0.2X", b, b+1))
-		err := ldb.LDB().CompactRange(util.Range{Start: []byte{b}, Limit: []byte{b + 1}})
+		err := api.b.ChainDb().Compact([]byte{b}, []byte{b + 1})
 		if err != nil {
 			log.Error("Database compaction failed", "err", err)
 			return err
@@ -35,6 +35,7 @@ var Modules = map[string]string{
 	"txpool":     TxPool_JS,
 
 	"wpoa":     WPoa_JS,
+	"dbft":     Dbft_JS,
 	"istanbul": Istanbul_JS,
 
 	params.ClientIdentifier: BCOS_JS,
@@ -154,6 +155,34 @@ web3._extend({
 			call: 'wpoa_getManagersAtHash',
 			params: 1
 		}),
+		new web3._extend.Method({
+			name: 'getLiveness',
+			call: 'wpoa_getLiveness',
+			params: 1,
+			inputFormatter: [null]
+		}),
+	],
+	properties: [
+	]
+});
+`
+
+const Dbft_JS = `
+web3._extend({
+	property: 'dbft',
+	methods: [
+		new web3._extend.Method({
+			name: 'getValidators',
+			call: 'dbft_getValidators',
+			params: 1,
+			inputFormatter: [null]
+		}),
+		new web3._extend.Method({
+			name: 'getLiveness',
+			call: 'dbft_getLiveness',
+			params: 1,
+			inputFormatter: [null]
+		}),
 	],
 	properties: [
 	]
@@ -500,12 +529,35 @@ web3._extend({
 			params: 2,
 			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
 		}),
+		new web3._extend.Method({
+			name: 'signTypedData',
+			call: 'eth_signTypedData',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
+		}),
 		new web3._extend.Method({
 			name: 'resend',
 			call: 'eth_resend',
 			params: 3,
 			inputFormatter: [web3._extend.formatters.inputTransactionFormatter, web3._extend.utils.fromDecimal, web3._extend.utils.fromDecimal]
 		}),
+		new web3._extend.Method({
+			name: 'replaceTransaction',
+			call: 'eth_replaceTransaction',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputTransactionFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'cancelTransaction',
+			call: 'eth_cancelTransaction',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'replaceRawTransaction',
+			call: 'eth_replaceRawTransaction',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'signTransaction',
 			call: 'eth_signTransaction',
@@ -537,6 +589,43 @@ web3._extend({
 			params: 3,
 			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputBlockNumberFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'getTokenBalanceHistory',
+			call: 'eth_getTokenBalanceHistory',
+			params: 4,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getTokenSupplyHistory',
+			call: 'eth_getTokenSupplyHistory',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputBlockNumberFormatter, web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'predictTokenId',
+			call: 'eth_predictTokenId',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'isTokenAccountFrozen',
+			call: 'eth_isTokenAccountFrozen',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getRoleHolders',
+			call: 'eth_getRoleHolders',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getAdminThreshold',
+			call: 'eth_getAdminThreshold',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter],
+			outputFormatter: web3._extend.utils.toDecimal
+		}),
 	],
 	properties: [
 		new web3._extend.Property({
@@ -628,6 +717,12 @@ web3._extend({
 			params: 3,
 			inputFormatter: [null, web3._extend.formatters.inputAddressFormatter, null]
 		}),
+		new web3._extend.Method({
+			name: 'signTypedData',
+			call: 'personal_signTypedData',
+			params: 3,
+			inputFormatter: [null, web3._extend.formatters.inputAddressFormatter, null]
+		}),
 		new web3._extend.Method({
 			name: 'ecRecover',
 			call: 'personal_ecRecover',
@@ -55,6 +55,31 @@ web3._extend({
 			params: 3,
 			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, web3._extend.formatters.inputBlockNumberFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'predictTokenId',
+			call: '` + params.ClientIdentifier + `_predictTokenId',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null, null]
+		}),
+		new web3._extend.Method({
+			name: 'isTokenAccountFrozen',
+			call: '` + params.ClientIdentifier + `_isTokenAccountFrozen',
+			params: 3,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputAddressFormatter, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getRoleHolders',
+			call: '` + params.ClientIdentifier + `_getRoleHolders',
+			params: 2,
+			inputFormatter: [null, web3._extend.formatters.inputDefaultBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getAdminThreshold',
+			call: '` + params.ClientIdentifier + `_getAdminThreshold',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputDefaultBlockNumberFormatter],
+			outputFormatter: web3._extend.utils.toDecimal
+		}),
 		//TODO
 		new web3._extend.Method({
 			name: 'getBalance',
@@ -84,7 +84,7 @@ type BlockChain interface {
 }
 
 type txPool interface {
-	AddRemotes(txs []*types.Transaction) []error
+	AddRemotesFrom(peer string, txs []*types.Transaction) []error
 	Status(hashes []common.Hash) []core.TxStatus
 }
 
@@ -1021,7 +1021,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		if reject(uint64(reqCnt), MaxTxSend) {
 			return errResp(ErrRequestRejected, "")
 		}
-		pm.txpool.AddRemotes(txs)
+		pm.txpool.AddRemotesFrom(p.id, txs)
 
 		_, rcost := p.fcClient.RequestProcessed(costs.baseCost + uint64(reqCnt)*costs.reqCost)
 		pm.server.fcCostStats.update(msg.Code, uint64(reqCnt), rcost)
@@ -1050,7 +1050,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		stats := pm.txStatus(hashes)
 		for i, stat := range stats {
 			if stat.Status == core.TxStatusUnknown {
-				if errs := pm.txpool.AddRemotes([]*types.Transaction{req.Txs[i]}); errs[0] != nil {
+				if errs := pm.txpool.AddRemotesFrom(p.id, []*types.Transaction{req.Txs[i]}); errs[0] != nil {
 					stats[i].Error = errs[0].Error()
 					continue
 				}
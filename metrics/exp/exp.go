@@ -8,7 +8,9 @@ import (
 	"net/http"
 	"sync"
 
+	"github.com/bcos-one/BCOS/log"
 	"github.com/bcos-one/BCOS/metrics"
+	"github.com/bcos-one/BCOS/metrics/prometheus"
 )
 
 type exp struct {
@@ -42,6 +44,22 @@ func Exp(r metrics.Registry) {
 	// http.HandleFunc("/debug/vars", e.expHandler)
 	// haven't found an elegant way, so just use a different endpoint
 	http.Handle("/debug/metrics", h)
+	http.Handle("/debug/metrics/prometheus", prometheus.Handler(r))
+}
+
+// Setup starts a dedicated metrics server at the given address, serving the
+// metrics of the default registry both as expvar JSON on /debug/metrics and in
+// the Prometheus text format on /debug/metrics/prometheus.
+func Setup(address string) {
+	m := http.NewServeMux()
+	m.Handle("/debug/metrics", ExpHandler(metrics.DefaultRegistry))
+	m.Handle("/debug/metrics/prometheus", prometheus.Handler(metrics.DefaultRegistry))
+	log.Info("Starting metrics server", "addr", fmt.Sprintf("http://%s/debug/metrics", address))
+	go func() {
+		if err := http.ListenAndServe(address, m); err != nil {
+			log.Error("Failure in running metrics server", "err", err)
+		}
+	}()
 }
 
 // ExpHandler will return an expvar powered metrics handler.
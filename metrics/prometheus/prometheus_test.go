@@ -0,0 +1,57 @@
+package prometheus
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bcos-one/BCOS/metrics"
+)
+
+func init() {
+	metrics.Enabled = true
+}
+
+// Tests that all metric types are exposed in the Prometheus text format.
+func TestHandler(t *testing.T) {
+	reg := metrics.NewRegistry()
+
+	metrics.NewRegisteredCounter("test/counter", reg).Inc(3)
+	metrics.NewRegisteredGauge("test/gauge", reg).Update(-5)
+	metrics.NewRegisteredGaugeFloat64("test/gauge-float", reg).Update(1.5)
+	metrics.NewRegisteredMeter("test/meter", reg).Mark(7)
+	metrics.NewRegisteredHistogram("test/histogram", reg, metrics.NewUniformSample(100)).Update(10)
+	metrics.NewRegisteredTimer("test/timer", reg).Update(time.Second)
+	resetting := metrics.NewRegisteredResettingTimer("test/resetting", reg)
+	resetting.Update(time.Millisecond)
+	resetting.Update(3 * time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	Handler(reg).ServeHTTP(rec, httptest.NewRequest("GET", "/debug/metrics/prometheus", nil))
+	body, _ := ioutil.ReadAll(rec.Body)
+
+	for _, want := range []string{
+		"# TYPE test_counter counter\ntest_counter 3\n",
+		"# TYPE test_gauge gauge\ntest_gauge -5\n",
+		"# TYPE test_gauge_float gauge\ntest_gauge_float 1.5\n",
+		"# TYPE test_meter counter\ntest_meter 7\n",
+		"# TYPE test_histogram summary\ntest_histogram{quantile=\"0.5\"} 10\n",
+		"test_histogram_sum 10\ntest_histogram_count 1\n",
+		"test_timer{quantile=\"0.99\"} 1e+09\n",
+		"test_timer_sum 1000000000\ntest_timer_count 1\n",
+		"# TYPE test_resetting summary\n",
+		"test_resetting_sum 4000000\ntest_resetting_count 2\n",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("output misses %q:\n%s", want, body)
+		}
+	}
+	// Resetting timers are reset by the scrape and omitted until updated again
+	rec = httptest.NewRecorder()
+	Handler(reg).ServeHTTP(rec, httptest.NewRequest("GET", "/debug/metrics/prometheus", nil))
+	if body, _ := ioutil.ReadAll(rec.Body); strings.Contains(string(body), "test_resetting") {
+		t.Errorf("reset timer exposed again:\n%s", body)
+	}
+}
@@ -33,6 +33,23 @@ import (
 	"github.com/bcos-one/BCOS/params"
 )
 
+// Ordering policies of the pending transactions included in the mined blocks.
+const (
+	OrderingPriceNonce = "price"      // Highest gas price first, the nonces of every account honoured
+	OrderingFIFO       = "fifo"       // First seen first, the nonces of every account honoured
+	OrderingRoundRobin = "roundrobin" // One transaction per account in turn, in order of arrival
+)
+
+// ValidOrdering reports whether ordering names a known transaction ordering
+// policy. The empty name stands for the default price and nonce ordering.
+func ValidOrdering(ordering string) bool {
+	switch ordering {
+	case "", OrderingPriceNonce, OrderingFIFO, OrderingRoundRobin:
+		return true
+	}
+	return false
+}
+
 // Backend wraps all methods required for mining.
 type Backend interface {
 	BlockChain() *core.BlockChain
@@ -52,13 +69,13 @@ type Miner struct {
 	shouldStart int32 // should start indicates whether we should start after sync
 }
 
-func New(eth Backend, config *params.ChainConfig, mux *event.TypeMux, engine consensus.Engine, recommit time.Duration, gasFloor, gasCeil uint64, isLocalBlock func(block *types.Block) bool) *Miner {
+func New(eth Backend, config *params.ChainConfig, mux *event.TypeMux, engine consensus.Engine, recommit time.Duration, gasFloor, gasCeil uint64, ordering string, isLocalBlock func(block *types.Block) bool) *Miner {
 	miner := &Miner{
 		eth:      eth,
 		mux:      mux,
 		engine:   engine,
 		exitCh:   make(chan struct{}),
-		worker:   newWorker(config, engine, eth, mux, recommit, gasFloor, gasCeil, isLocalBlock),
+		worker:   newWorker(config, engine, eth, mux, recommit, gasFloor, gasCeil, ordering, isLocalBlock),
 		canStart: 1,
 	}
 	go miner.update()
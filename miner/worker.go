@@ -32,6 +32,7 @@ import (
 	"github.com/bcos-one/BCOS/core/types"
 	"github.com/bcos-one/BCOS/core/vm"
 	"github.com/bcos-one/BCOS/event"
+	"github.com/bcos-one/BCOS/expansions"
 	"github.com/bcos-one/BCOS/log"
 	"github.com/bcos-one/BCOS/params"
 	"github.com/deckarep/golang-set"
@@ -130,6 +131,7 @@ type worker struct {
 
 	gasFloor uint64
 	gasCeil  uint64
+	ordering string // Ordering policy of the pending transactions
 
 	// Subscriptions
 	mux          *event.TypeMux
@@ -179,7 +181,7 @@ type worker struct {
 	resubmitHook func(time.Duration, time.Duration) // Method to call upon updating resubmitting interval.
 }
 
-func newWorker(config *params.ChainConfig, engine consensus.Engine, eth Backend, mux *event.TypeMux, recommit time.Duration, gasFloor, gasCeil uint64, isLocalBlock func(*types.Block) bool) *worker {
+func newWorker(config *params.ChainConfig, engine consensus.Engine, eth Backend, mux *event.TypeMux, recommit time.Duration, gasFloor, gasCeil uint64, ordering string, isLocalBlock func(*types.Block) bool) *worker {
 	worker := &worker{
 		config:             config,
 		engine:             engine,
@@ -188,6 +190,7 @@ func newWorker(config *params.ChainConfig, engine consensus.Engine, eth Backend,
 		chain:              eth.BlockChain(),
 		gasFloor:           gasFloor,
 		gasCeil:            gasCeil,
+		ordering:           ordering,
 		isLocalBlock:       isLocalBlock,
 		localUncles:        make(map[common.Hash]*types.Block),
 		remoteUncles:       make(map[common.Hash]*types.Block),
@@ -489,7 +492,7 @@ func (w *worker) mainLoop() {
 					acc, _ := types.Sender(w.current.signer, tx)
 					txs[acc] = append(txs[acc], tx)
 				}
-				txset := types.NewTransactionsByPriceAndNonce(w.current.signer, txs)
+				txset := w.orderTransactions(txs)
 				w.commitTransactions(txset, coinbase, nil)
 				w.updateSnapshot()
 			} else {
@@ -737,7 +740,20 @@ func (w *worker) commitTransaction(tx *types.Transaction, coinbase common.Addres
 	return receipt.Logs, nil
 }
 
-func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coinbase common.Address, interrupt *int32) bool {
+// orderTransactions creates the transaction set iterating the given pending
+// transactions in the order of the configured policy.
+func (w *worker) orderTransactions(txs map[common.Address]types.Transactions) types.OrderedTransactions {
+	switch w.ordering {
+	case OrderingFIFO:
+		return types.NewTransactionsByArrivalAndNonce(w.current.signer, txs)
+	case OrderingRoundRobin:
+		return types.NewTransactionsByAccountAndNonce(w.current.signer, txs)
+	default:
+		return types.NewTransactionsByPriceAndNonce(w.current.signer, txs)
+	}
+}
+
+func (w *worker) commitTransactions(txs types.OrderedTransactions, coinbase common.Address, interrupt *int32) bool {
 	// Short circuit if current is nil
 	if w.current == nil {
 		return true
@@ -916,6 +932,9 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 	if w.config.DAOForkSupport && w.config.DAOForkBlock != nil && w.config.DAOForkBlock.Cmp(header.Number) == 0 {
 		misc.ApplyDAOHardFork(env.state)
 	}
+	if w.config.ExpansionsConfig != nil {
+		expansions.NewExpansions(w.config).ApplyForks(env.state, header.Number)
+	}
 	// Accumulate the uncles for the current block
 	uncles := make([]*types.Header, 0, 2)
 	commitUncles := func(blocks map[common.Hash]*types.Block) {
@@ -971,13 +990,13 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 		}
 	}
 	if len(localTxs) > 0 {
-		txs := types.NewTransactionsByPriceAndNonce(w.current.signer, localTxs)
+		txs := w.orderTransactions(localTxs)
 		if w.commitTransactions(txs, w.coinbase, interrupt) {
 			return
 		}
 	}
 	if len(remoteTxs) > 0 {
-		txs := types.NewTransactionsByPriceAndNonce(w.current.signer, remoteTxs)
+		txs := w.orderTransactions(remoteTxs)
 		if w.commitTransactions(txs, w.coinbase, interrupt) {
 			return
 		}
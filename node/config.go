@@ -27,6 +27,7 @@ import (
 	"strings"
 
 	"github.com/bcos-one/BCOS/accounts"
+	"github.com/bcos-one/BCOS/accounts/external"
 	"github.com/bcos-one/BCOS/accounts/keystore"
 	"github.com/bcos-one/BCOS/accounts/usbwallet"
 	"github.com/bcos-one/BCOS/common"
@@ -87,6 +88,16 @@ type Config struct {
 	// NoUSB disables hardware wallet monitoring and connectivity.
 	NoUSB bool `toml:",omitempty"`
 
+	// ExternalSigner is the endpoint of a clef-compatible signer. If set, the local
+	// keystore and hardware wallets are replaced by the accounts of the external
+	// signer, which also seals the blocks of wpoa and dbft validators.
+	ExternalSigner string `toml:",omitempty"`
+
+	// DatabaseEngine is the storage engine of newly created databases ("leveldb" or
+	// "bolt"). Existing databases are always opened with the engine they were
+	// created with, an explicitly requested different engine is an error.
+	DatabaseEngine string `toml:",omitempty"`
+
 	// IPCPath is the requested location to place the IPC endpoint. If the path is
 	// a simple file name, it is placed inside the data directory (or on the root
 	// pipe path on Windows), whereas if it's a resolvable path name (absolute or
@@ -151,6 +162,35 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// GraphQLHost is the host interface on which to start the GraphQL server. If this
+	// field is empty, no GraphQL endpoint will be started.
+	GraphQLHost string `toml:",omitempty"`
+
+	// GraphQLPort is the TCP port number on which to start the GraphQL server. The
+	// default zero value is/ valid and will pick a port number randomly (useful
+	// for ephemeral nodes).
+	GraphQLPort int `toml:",omitempty"`
+
+	// GraphQLCors is the Cross-Origin Resource Sharing header to send to requesting
+	// clients. Please be aware that CORS is a browser enforced security, it's fully
+	// useless for custom HTTP clients.
+	GraphQLCors []string `toml:",omitempty"`
+
+	// GraphQLVirtualHosts is the list of virtual hostnames which are allowed on incoming
+	// requests. This is by default {'localhost'}, see HTTPVirtualHosts.
+	GraphQLVirtualHosts []string `toml:",omitempty"`
+
+	// RPCAuth configures the credentials required to access the HTTP and websocket
+	// RPC interfaces, the methods allowed for each of them and the audit log of
+	// privileged calls. The interfaces are open to anyone reaching them if unset.
+	// GraphQL queries are authorized as the graphql_query method.
+	RPCAuth *rpc.AuthConfig `toml:",omitempty"`
+
+	// RPCLimits restricts the batch sizes, response sizes, execution time and
+	// concurrency of the requests of the HTTP and websocket RPC interfaces. The
+	// GraphQL queries are limited the same way, as the graphql_query method.
+	RPCLimits rpc.Limits
+
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger `toml:",omitempty"`
 }
@@ -230,6 +270,15 @@ func DefaultWSEndpoint() string {
 	return config.WSEndpoint()
 }
 
+// GraphQLEndpoint resolves a GraphQL endpoint based on the configured host interface
+// and port parameters.
+func (c *Config) GraphQLEndpoint() string {
+	if c.GraphQLHost == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", c.GraphQLHost, c.GraphQLPort)
+}
+
 // NodeName returns the devp2p node identifier.
 func (c *Config) NodeName() string {
 	name := c.name()
@@ -421,6 +470,15 @@ func makeAccountManager(conf *Config) (*accounts.Manager, string, error) {
 	if err := os.MkdirAll(keydir, 0700); err != nil {
 		return nil, "", err
 	}
+	// Use the external signer as the sole backend if requested
+	if conf.ExternalSigner != "" {
+		log.Info("Using external signer", "endpoint", conf.ExternalSigner)
+		extapi, err := external.NewExternalBackend(conf.ExternalSigner)
+		if err != nil {
+			return nil, "", fmt.Errorf("error connecting to external signer: %v", err)
+		}
+		return accounts.NewManager(extapi), ephemeral, nil
+	}
 	// Assemble the account manager and supported backends
 	backends := []accounts.Backend{
 		keystore.NewKeyStore(keydir, scryptN, scryptP),
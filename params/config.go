@@ -119,6 +119,7 @@ var (
 		EIP155Block:    big.NewInt(0),
 		EIP158Block:    big.NewInt(0),
 		ByzantiumBlock: big.NewInt(0),
+		TokenTxBlock:   big.NewInt(0),
 		Ethash:         new(EthashConfig),
 	}
 
@@ -135,6 +136,7 @@ var (
 		EIP155Block:    big.NewInt(0),
 		EIP158Block:    big.NewInt(0),
 		ByzantiumBlock: big.NewInt(0),
+		TokenTxBlock:   big.NewInt(0),
 		Clique:         &CliqueConfig{Period: 0, Epoch: 30000},
 	}
 
@@ -146,6 +148,7 @@ var (
 		EIP155Block:    big.NewInt(0),
 		EIP158Block:    big.NewInt(0),
 		ByzantiumBlock: big.NewInt(0),
+		TokenTxBlock:   big.NewInt(0),
 		Ethash:         new(EthashConfig),
 	}
 
@@ -187,6 +190,7 @@ type ChainConfig struct {
 	ByzantiumBlock      *big.Int `json:"byzantiumBlock,omitempty"`      // Byzantium switch block (nil = no fork, 0 = already on byzantium)
 	ConstantinopleBlock *big.Int `json:"constantinopleBlock,omitempty"` // Constantinople switch block (nil = no fork, 0 = already activated)
 	EWASMBlock          *big.Int `json:"ewasmBlock,omitempty"`          // EWASM switch block (nil = no fork, 0 = already activated)
+	TokenTxBlock        *big.Int `json:"tokenTxBlock,omitempty"`        // Typed token and replacement transaction switch block (nil = no fork, 0 = already activated)
 
 	// Various consensus engines
 	Ethash *EthashConfig `json:"ethash,omitempty"`
@@ -208,13 +212,152 @@ type ChainConfig struct {
 type ExpansionsConfig struct {
 	TokenSupport bool           `json:"tokenSupport,omitempty"`
 	TokenStorage common.Address `json:"tokenStorage,omitempty"`
+	TokenBlock   *big.Int       `json:"tokenBlock,omitempty"` // Token expansion switch block (nil = active since genesis)
+
+	TokenSaltBlock    *big.Int `json:"tokenSaltBlock,omitempty"`    // Salted token issue switch block (nil = no fork, 0 = active since genesis)
+	TokenControlBlock *big.Int `json:"tokenControlBlock,omitempty"` // Token pause, freeze and forced transfer switch block (nil = no fork, 0 = active since genesis)
 
 	ManageSupport bool           `json:"manageSupport,omitempty"`
 	ManageStorage common.Address `json:"manageStorage,omitempty"`
 	Manager       common.Address `json:"manager,omitempty"`
+	ManageBlock   *big.Int       `json:"manageBlock,omitempty"` // Management expansion switch block (nil = active since genesis)
+
+	LegacyManagers []common.Address `json:"legacyManagers,omitempty"` // Managers besides Manager set up before the roles fork, enlisted as admins at the fork
+
+	ManageRolesBlock *big.Int `json:"manageRolesBlock,omitempty"` // Management roles switch block (nil = no fork, 0 = active since genesis)
+	GasQuotaBlock    *big.Int `json:"gasQuotaBlock,omitempty"`    // Management gas quotas switch block (nil = no fork, 0 = active since genesis)
 
 	IcapSupport bool           `json:"icapSupport,omitempty"`
 	IcapStorage common.Address `json:"icapStorage,omitempty"`
+	IcapBlock   *big.Int       `json:"icapBlock,omitempty"` // ICAP expansion switch block (nil = active since genesis)
+}
+
+// IsTokenEnabled returns whether the token expansion is active at block num.
+func (c *ExpansionsConfig) IsTokenEnabled(num *big.Int) bool {
+	return isForked(c.tokenBlock(), num)
+}
+
+// IsTokenSaltEnabled returns whether tokens can be issued at deterministic,
+// salted ids at block num.
+func (c *ExpansionsConfig) IsTokenSaltEnabled(num *big.Int) bool {
+	return isForked(c.tokenSaltBlock(), num)
+}
+
+// IsTokenControlEnabled returns whether token managers can pause tokens, freeze
+// accounts and force transfers at block num.
+func (c *ExpansionsConfig) IsTokenControlEnabled(num *big.Int) bool {
+	return isForked(c.tokenControlBlock(), num)
+}
+
+// IsManageEnabled returns whether the management expansion is active at block num.
+func (c *ExpansionsConfig) IsManageEnabled(num *big.Int) bool {
+	return isForked(c.manageBlock(), num)
+}
+
+// IsManageRolesEnabled returns whether the role-based multi-admin access of the
+// management expansion is active at block num.
+func (c *ExpansionsConfig) IsManageRolesEnabled(num *big.Int) bool {
+	return isForked(c.manageRolesBlock(), num)
+}
+
+// IsGasQuotaEnabled returns whether the gas quotas of the management expansion
+// are active at block num.
+func (c *ExpansionsConfig) IsGasQuotaEnabled(num *big.Int) bool {
+	return isForked(c.gasQuotaBlock(), num)
+}
+
+// IsIcapEnabled returns whether the ICAP expansion is active at block num.
+func (c *ExpansionsConfig) IsIcapEnabled(num *big.Int) bool {
+	return isForked(c.icapBlock(), num)
+}
+
+// tokenBlock returns the block the token expansion activates at, or nil if it
+// is not supported at all.
+func (c *ExpansionsConfig) tokenBlock() *big.Int {
+	if c == nil || !c.TokenSupport {
+		return nil
+	}
+	return expansionBlock(c.TokenBlock)
+}
+
+// tokenSaltBlock returns the block salted token issues activate at, or nil if
+// they are not scheduled. They never activate before the expansion itself.
+func (c *ExpansionsConfig) tokenSaltBlock() *big.Int {
+	token := c.tokenBlock()
+	if token == nil || c.TokenSaltBlock == nil {
+		return nil
+	}
+	if c.TokenSaltBlock.Cmp(token) < 0 {
+		return token
+	}
+	return c.TokenSaltBlock
+}
+
+// tokenControlBlock returns the block the token compliance controls activate
+// at, or nil if they are not scheduled. They never activate before the
+// expansion itself.
+func (c *ExpansionsConfig) tokenControlBlock() *big.Int {
+	token := c.tokenBlock()
+	if token == nil || c.TokenControlBlock == nil {
+		return nil
+	}
+	if c.TokenControlBlock.Cmp(token) < 0 {
+		return token
+	}
+	return c.TokenControlBlock
+}
+
+// manageBlock returns the block the management expansion activates at, or nil
+// if it is not supported at all.
+func (c *ExpansionsConfig) manageBlock() *big.Int {
+	if c == nil || !c.ManageSupport {
+		return nil
+	}
+	return expansionBlock(c.ManageBlock)
+}
+
+// manageRolesBlock returns the block the management roles activate at, or nil
+// if they are not scheduled. Roles never activate before the expansion itself.
+func (c *ExpansionsConfig) manageRolesBlock() *big.Int {
+	manage := c.manageBlock()
+	if manage == nil || c.ManageRolesBlock == nil {
+		return nil
+	}
+	if c.ManageRolesBlock.Cmp(manage) < 0 {
+		return manage
+	}
+	return c.ManageRolesBlock
+}
+
+// gasQuotaBlock returns the block the management gas quotas activate at, or nil
+// if they are not scheduled. Quotas never activate before the expansion itself.
+func (c *ExpansionsConfig) gasQuotaBlock() *big.Int {
+	manage := c.manageBlock()
+	if manage == nil || c.GasQuotaBlock == nil {
+		return nil
+	}
+	if c.GasQuotaBlock.Cmp(manage) < 0 {
+		return manage
+	}
+	return c.GasQuotaBlock
+}
+
+// icapBlock returns the block the ICAP expansion activates at, or nil if it is
+// not supported at all.
+func (c *ExpansionsConfig) icapBlock() *big.Int {
+	if c == nil || !c.IcapSupport {
+		return nil
+	}
+	return expansionBlock(c.IcapBlock)
+}
+
+// expansionBlock returns the switch block of a supported expansion, which is
+// the genesis block unless scheduled otherwise.
+func expansionBlock(block *big.Int) *big.Int {
+	if block == nil {
+		return new(big.Int)
+	}
+	return block
 }
 
 type GasFeeConfig struct {
@@ -235,6 +378,14 @@ type DbftConfig struct {
 	Epoch       uint64 `json:"epoch,omitempty"`  // The number of blocks after which to checkpoint and reset the pending votes
 
 	GenesisTimestamp uint64 `json:"genesisTimestamp"` // The LoopStartTime of first Block
+
+	// Exclusion is the percentage of its slots a validator may miss within an epoch
+	// before it is excluded from the validators of the next one (0 = disabled). It
+	// applies to the checkpoints from ExclusionBlock on.
+	Exclusion      uint64   `json:"exclusion,omitempty"`
+	ExclusionBlock *big.Int `json:"exclusionBlock,omitempty"` // Offline validator exclusion switch block (nil = no fork, 0 = already activated)
+
+	SealDomainBlock *big.Int `json:"sealDomainBlock,omitempty"` // Domain separated seal and message signatures switch block (nil = no fork, 0 = already activated)
 }
 
 var DefaultConfig = &DbftConfig{
@@ -248,6 +399,33 @@ func (d *DbftConfig) String() string {
 	return "dbft"
 }
 
+// IsExclusion returns whether offline validators are excluded from the validators
+// of the epoch starting at checkpoint num.
+func (d *DbftConfig) IsExclusion(num *big.Int) bool {
+	if d == nil || d.Exclusion == 0 {
+		return false
+	}
+	return isForked(d.ExclusionBlock, num)
+}
+
+// exclusionBlock returns the block the offline validator exclusion activates at,
+// nil if it is disabled.
+func (d *DbftConfig) exclusionBlock() *big.Int {
+	if d.Exclusion == 0 {
+		return nil
+	}
+	return d.ExclusionBlock
+}
+
+// IsSealDomain returns whether validators sign domain separated seals, committed
+// seals and PBFT messages at block num.
+func (d *DbftConfig) IsSealDomain(num *big.Int) bool {
+	if d == nil {
+		return false
+	}
+	return isForked(d.SealDomainBlock, num)
+}
+
 // WPoaConfig is the consensus engine configs for bcos proof-of-authority based sealing.
 type WPoaConfig struct {
 	Period uint64 `json:"period"` // Number of seconds between blocks to enforce
@@ -348,6 +526,13 @@ func (c *ChainConfig) IsConstantinople(num *big.Int) bool {
 	return isForked(c.ConstantinopleBlock, num)
 }
 
+// IsTokenTx returns whether num is either equal to the typed token transaction
+// fork block or greater. Before it, token transactions are only known in their
+// legacy encoding, whose signature does not cover the token.
+func (c *ChainConfig) IsTokenTx(num *big.Int) bool {
+	return isForked(c.TokenTxBlock, num)
+}
+
 // IsEWASM returns whether num represents a block number after the EWASM fork
 func (c *ChainConfig) IsEWASM(num *big.Int) bool {
 	return isForked(c.EWASMBlock, num)
@@ -421,6 +606,49 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.EWASMBlock, newcfg.EWASMBlock, head) {
 		return newCompatError("ewasm fork block", c.EWASMBlock, newcfg.EWASMBlock)
 	}
+	if isForkIncompatible(c.TokenTxBlock, newcfg.TokenTxBlock, head) {
+		return newCompatError("token transaction fork block", c.TokenTxBlock, newcfg.TokenTxBlock)
+	}
+	if c.Dbft != nil && newcfg.Dbft != nil && isForkIncompatible(c.Dbft.SealDomainBlock, newcfg.Dbft.SealDomainBlock, head) {
+		return newCompatError("dbft seal domain fork block", c.Dbft.SealDomainBlock, newcfg.Dbft.SealDomainBlock)
+	}
+	if c.Dbft != nil && newcfg.Dbft != nil && isForkIncompatible(c.Dbft.exclusionBlock(), newcfg.Dbft.exclusionBlock(), head) {
+		return newCompatError("dbft exclusion fork block", c.Dbft.ExclusionBlock, newcfg.Dbft.ExclusionBlock)
+	}
+	return c.ExpansionsConfig.checkCompatible(newcfg.ExpansionsConfig, head)
+}
+
+func (c *ExpansionsConfig) checkCompatible(newcfg *ExpansionsConfig, head *big.Int) *ConfigCompatError {
+	if isForkIncompatible(c.tokenBlock(), newcfg.tokenBlock(), head) {
+		return newCompatError("token expansion block", c.tokenBlock(), newcfg.tokenBlock())
+	}
+	if c.IsTokenEnabled(head) && c.TokenStorage != newcfg.TokenStorage {
+		return newCompatError("token storage address", c.tokenBlock(), newcfg.tokenBlock())
+	}
+	if isForkIncompatible(c.tokenSaltBlock(), newcfg.tokenSaltBlock(), head) {
+		return newCompatError("token salt block", c.tokenSaltBlock(), newcfg.tokenSaltBlock())
+	}
+	if isForkIncompatible(c.tokenControlBlock(), newcfg.tokenControlBlock(), head) {
+		return newCompatError("token control block", c.tokenControlBlock(), newcfg.tokenControlBlock())
+	}
+	if isForkIncompatible(c.manageBlock(), newcfg.manageBlock(), head) {
+		return newCompatError("management expansion block", c.manageBlock(), newcfg.manageBlock())
+	}
+	if c.IsManageEnabled(head) && (c.ManageStorage != newcfg.ManageStorage || c.Manager != newcfg.Manager) {
+		return newCompatError("management storage and manager", c.manageBlock(), newcfg.manageBlock())
+	}
+	if isForkIncompatible(c.manageRolesBlock(), newcfg.manageRolesBlock(), head) {
+		return newCompatError("management roles block", c.manageRolesBlock(), newcfg.manageRolesBlock())
+	}
+	if isForkIncompatible(c.gasQuotaBlock(), newcfg.gasQuotaBlock(), head) {
+		return newCompatError("management gas quota block", c.gasQuotaBlock(), newcfg.gasQuotaBlock())
+	}
+	if isForkIncompatible(c.icapBlock(), newcfg.icapBlock(), head) {
+		return newCompatError("ICAP expansion block", c.icapBlock(), newcfg.icapBlock())
+	}
+	if c.IsIcapEnabled(head) && c.IcapStorage != newcfg.IcapStorage {
+		return newCompatError("ICAP storage address", c.icapBlock(), newcfg.icapBlock())
+	}
 	return nil
 }
 
@@ -20,6 +20,8 @@ import (
 	"math/big"
 	"reflect"
 	"testing"
+
+	"github.com/bcos-one/BCOS/common"
 )
 
 func TestCheckCompatible(t *testing.T) {
@@ -70,6 +72,34 @@ func TestCheckCompatible(t *testing.T) {
 				RewindTo:     9,
 			},
 		},
+		{
+			stored:  &ChainConfig{},
+			new:     &ChainConfig{ExpansionsConfig: &ExpansionsConfig{TokenSupport: true, TokenBlock: big.NewInt(20)}},
+			head:    10,
+			wantErr: nil,
+		},
+		{
+			stored: &ChainConfig{},
+			new:    &ChainConfig{ExpansionsConfig: &ExpansionsConfig{ManageSupport: true, ManageBlock: big.NewInt(5)}},
+			head:   10,
+			wantErr: &ConfigCompatError{
+				What:         "management expansion block",
+				StoredConfig: nil,
+				NewConfig:    big.NewInt(5),
+				RewindTo:     4,
+			},
+		},
+		{
+			stored: &ChainConfig{ExpansionsConfig: &ExpansionsConfig{TokenSupport: true}},
+			new:    &ChainConfig{ExpansionsConfig: &ExpansionsConfig{TokenSupport: true, TokenStorage: common.HexToAddress("0x01")}},
+			head:   10,
+			wantErr: &ConfigCompatError{
+				What:         "token storage address",
+				StoredConfig: big.NewInt(0),
+				NewConfig:    big.NewInt(0),
+				RewindTo:     0,
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -32,6 +32,20 @@ const (
 	// considered probably final and its rotated bits are calculated.
 	BloomConfirms = 256
 
+	// TokenHistoryBlocks is the number of blocks a single token history section
+	// contains. It is kept small so that the indexer stays within the window of
+	// recent states a non-archive node retains in memory.
+	TokenHistoryBlocks uint64 = 64
+
+	// TokenHistoryConfirms is the number of confirmation blocks before a token
+	// history section is considered probably final and indexed.
+	TokenHistoryConfirms = 16
+
+	// MinAncientThreshold is the minimum number of recent blocks kept in the
+	// key-value store before being moved into the ancient store. It covers the
+	// window of recent states a non-archive node retains in memory.
+	MinAncientThreshold uint64 = 128
+
 	// CHTFrequencyClient is the block frequency for creating CHTs on the client side.
 	CHTFrequencyClient = 32768
 
@@ -0,0 +1,233 @@
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bcos-one/BCOS/log"
+	"github.com/dgrijalva/jwt-go"
+)
+
+// defaultAuditModules are the modules whose calls are audited if none are configured.
+var defaultAuditModules = []string{"admin", "debug", "miner", "personal"}
+
+// errUnauthenticated is returned if a request carries no or unknown credentials.
+var errUnauthenticated = errors.New("missing or invalid credentials")
+
+// Credential is an identity allowed to access the HTTP and WebSocket endpoints,
+// authenticated either by a static API key or by HS256 JWT tokens signed with a
+// shared secret. Both are sent as bearer token in the Authorization header, or
+// as basic authentication password for clients dialing user:token@host URLs.
+type Credential struct {
+	Name      string   // Name of the credential (e.g. tenant), recorded in the audit log
+	APIKey    string   `toml:",omitempty"` // Static API key of the credential
+	JWTSecret string   `toml:",omitempty"` // Secret of the JWT tokens, which must expire
+	Allow     []string `toml:",omitempty"` // Modules ("eth") or methods ("personal_sign", "eth_subscribe") callable, "*" for all
+}
+
+// allows returns whether the credential may call the given method of a module.
+func (c *Credential) allows(module, method string) bool {
+	for _, allowed := range c.Allow {
+		if allowed == "*" || allowed == module || allowed == module+serviceMethodSeparator+method {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthConfig is the authentication and access control configuration of the HTTP
+// and WebSocket endpoints, allowing several tenants to share one endpoint.
+type AuthConfig struct {
+	Credentials []Credential
+
+	// AuditModules are the modules whose calls are recorded in the audit log. The
+	// admin, debug, miner and personal modules are audited if none are set.
+	AuditModules []string `toml:",omitempty"`
+
+	// AuditLog is the file to append the audit records to as JSON lines. If it's
+	// empty, the records are written to the node log instead.
+	AuditLog string `toml:",omitempty"`
+}
+
+// auditRecord is an entry of the audit log.
+type auditRecord struct {
+	Time       time.Time `json:"time"`
+	Credential string    `json:"credential"`
+	Remote     string    `json:"remote,omitempty"`
+	Method     string    `json:"method"`
+	Allowed    bool      `json:"allowed"`
+}
+
+// credentialKey is the context key of the authenticated credential.
+type credentialKey struct{}
+
+// Authenticator authenticates the requests of the HTTP and WebSocket endpoints,
+// checks the authenticated credentials against the called methods and records
+// the privileged calls in an audit log.
+type Authenticator struct {
+	credentials []Credential
+	audited     map[string]bool
+
+	audit   *os.File   // Audit log file, nil if logging to the node log
+	auditMu sync.Mutex // Serializes the writes to the audit log
+}
+
+// NewAuthenticator creates an authenticator for the given configuration, opening
+// its audit log if configured.
+func NewAuthenticator(config *AuthConfig) (*Authenticator, error) {
+	for i, cred := range config.Credentials {
+		if cred.APIKey == "" && cred.JWTSecret == "" {
+			return nil, fmt.Errorf("credential %d (%s) has neither API key nor JWT secret", i, cred.Name)
+		}
+	}
+	modules := config.AuditModules
+	if len(modules) == 0 {
+		modules = defaultAuditModules
+	}
+	auth := &Authenticator{
+		credentials: config.Credentials,
+		audited:     make(map[string]bool),
+	}
+	for _, module := range modules {
+		auth.audited[module] = true
+	}
+	if config.AuditLog != "" {
+		file, err := os.OpenFile(config.AuditLog, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			return nil, err
+		}
+		auth.audit = file
+	}
+	return auth, nil
+}
+
+// Close closes the audit log of the authenticator.
+func (a *Authenticator) Close() error {
+	if a.audit != nil {
+		return a.audit.Close()
+	}
+	return nil
+}
+
+// authenticate resolves the credential of the bearer token of the request, falling
+// back to the basic password if no bearer token is sent.
+func (a *Authenticator) authenticate(r *http.Request) (*Credential, error) {
+	var token string
+	for _, header := range r.Header["Authorization"] {
+		if strings.HasPrefix(header, "Bearer ") {
+			token = strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+			break
+		}
+	}
+	if token == "" {
+		_, token, _ = r.BasicAuth()
+	}
+	if token == "" {
+		return nil, errUnauthenticated
+	}
+	for i := range a.credentials {
+		cred := &a.credentials[i]
+		if cred.APIKey != "" && subtle.ConstantTimeCompare([]byte(cred.APIKey), []byte(token)) == 1 {
+			return cred, nil
+		}
+	}
+	for i := range a.credentials {
+		if cred := &a.credentials[i]; cred.JWTSecret != "" && verifyJWT(token, cred.JWTSecret) {
+			return cred, nil
+		}
+	}
+	return nil, errUnauthenticated
+}
+
+// verifyJWT returns whether the token is a valid, expiring HS256 token signed
+// with the given secret.
+func verifyJWT(token string, secret string) bool {
+	claims := new(jwt.StandardClaims)
+	parsed, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method != jwt.SigningMethodHS256 {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		return []byte(secret), nil
+	})
+	return err == nil && parsed.Valid && claims.ExpiresAt != 0
+}
+
+// authorize checks whether the credential authenticated for the request context
+// may call the requested method, recording the privileged calls in the audit log.
+// Requests without credentials (IPC, in-process) are not restricted. All the
+// subscriptions of a module are authorized as its subscribe method.
+func (a *Authenticator) authorize(ctx context.Context, req *serverRequest) Error {
+	method := formatName(req.callb.method.Name)
+	if req.callb.isSubscribe {
+		method = "subscribe"
+	}
+	return a.check(ctx, req.svcname, method)
+}
+
+// AuthorizeHTTP authenticates a request to an HTTP service served besides the RPC
+// endpoints (e.g. GraphQL) and checks whether its credential may call the given
+// method of the module, returning the HTTP status to reject the request with.
+func (a *Authenticator) AuthorizeHTTP(r *http.Request, module, method string) (int, error) {
+	cred, err := a.authenticate(r)
+	if err != nil {
+		return http.StatusUnauthorized, err
+	}
+	ctx := context.WithValue(r.Context(), credentialKey{}, cred)
+	ctx = context.WithValue(ctx, "remote", r.RemoteAddr)
+	if err := a.check(ctx, module, method); err != nil {
+		return http.StatusForbidden, err
+	}
+	return http.StatusOK, nil
+}
+
+// check checks whether the credential of the context may call the method of the
+// module, recording the calls of audited modules.
+func (a *Authenticator) check(ctx context.Context, module, method string) Error {
+	cred, ok := ctx.Value(credentialKey{}).(*Credential)
+	if !ok {
+		return nil
+	}
+	allowed := cred.allows(module, method)
+
+	if a.audited[module] {
+		remote, _ := ctx.Value("remote").(string)
+		a.record(&auditRecord{
+			Time:       time.Now(),
+			Credential: cred.Name,
+			Remote:     remote,
+			Method:     module + serviceMethodSeparator + method,
+			Allowed:    allowed,
+		})
+	}
+	if !allowed {
+		return &accessDeniedError{module, method}
+	}
+	return nil
+}
+
+// record appends an entry to the audit log.
+func (a *Authenticator) record(rec *auditRecord) {
+	if a.audit == nil {
+		log.Info("Audited RPC call", "credential", rec.Credential, "remote", rec.Remote, "method", rec.Method, "allowed", rec.Allowed)
+		return
+	}
+	blob, err := json.Marshal(rec)
+	if err != nil {
+		log.Error("Failed to encode audit record", "err", err)
+		return
+	}
+	a.auditMu.Lock()
+	defer a.auditMu.Unlock()
+
+	if _, err := a.audit.Write(append(blob, '\n')); err != nil {
+		log.Error("Failed to write audit record", "err", err)
+	}
+}
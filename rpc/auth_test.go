@@ -0,0 +1,309 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// newTestAuthServer creates an HTTP server requiring authentication, exposing the
+// test service both as a plain and as an audited module.
+func newTestAuthServer(t *testing.T, auditLog string) (*httptest.Server, *Authenticator) {
+	auth, err := NewAuthenticator(&AuthConfig{
+		Credentials: []Credential{
+			{Name: "reader", APIKey: "reader-key", Allow: []string{"test"}},
+			{Name: "operator", JWTSecret: "operator-secret", Allow: []string{"test", "admin_echo"}},
+		},
+		AuditModules: []string{"admin"},
+		AuditLog:     auditLog,
+	})
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+	server := NewServer()
+	server.SetAuthenticator(auth)
+	if err := server.RegisterName("test", new(Service)); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	if err := server.RegisterName("admin", new(Service)); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	return httptest.NewServer(server), auth
+}
+
+// authCall sends a JSON-RPC request with the given bearer token, returning the
+// HTTP status and the decoded response.
+func authCall(t *testing.T, url, token, method string) (int, *jsonrpcMessage) {
+	body := `{"jsonrpc":"2.0","id":1,"method":"` + method + `","params":["x",1,{"S":"y"}]}`
+	req, _ := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil
+	}
+	msg := new(jsonrpcMessage)
+	if err := json.NewDecoder(resp.Body).Decode(msg); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return resp.StatusCode, msg
+}
+
+func signTestToken(t *testing.T, secret string, expiry time.Duration) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{
+		IssuedAt:  time.Now().Unix(),
+		ExpiresAt: time.Now().Add(expiry).Unix(),
+	})
+	signed, err := token.SignedString([]byte(secret))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+// Tests that unauthenticated requests are rejected and authenticated ones are
+// restricted to the modules and methods allowed for their credentials.
+func TestHTTPAuthentication(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rpc-auth")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	audit := filepath.Join(dir, "audit.log")
+
+	srv, auth := newTestAuthServer(t, audit)
+	defer srv.Close()
+	defer auth.Close()
+
+	operator := signTestToken(t, "operator-secret", time.Minute)
+	tests := []struct {
+		token   string
+		method  string
+		status  int
+		allowed bool
+	}{
+		{"", "test_echo", http.StatusUnauthorized, false},
+		{"wrong-key", "test_echo", http.StatusUnauthorized, false},
+		{signTestToken(t, "other-secret", time.Minute), "test_echo", http.StatusUnauthorized, false},
+		{signTestToken(t, "operator-secret", -time.Minute), "test_echo", http.StatusUnauthorized, false},
+		{"reader-key", "test_echo", http.StatusOK, true},
+		{"reader-key", "admin_echo", http.StatusOK, false},
+		{operator, "test_echo", http.StatusOK, true},
+		{operator, "admin_echo", http.StatusOK, true},
+	}
+	for i, tt := range tests {
+		status, msg := authCall(t, srv.URL, tt.token, tt.method)
+		if status != tt.status {
+			t.Errorf("test %d: status mismatch: have %d, want %d", i, status, tt.status)
+			continue
+		}
+		if msg == nil {
+			continue
+		}
+		if allowed := msg.Error == nil; allowed != tt.allowed {
+			t.Errorf("test %d: access mismatch: have %v, want %v (error %v)", i, allowed, tt.allowed, msg.Error)
+		}
+	}
+	// Ensure both privileged calls were audited, but not the regular ones
+	blob, err := ioutil.ReadFile(audit)
+	if err != nil {
+		t.Fatalf("failed to read audit log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(blob)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("audit record count mismatch: have %d, want 2", len(lines))
+	}
+	want := []auditRecord{
+		{Credential: "reader", Method: "admin_echo", Allowed: false},
+		{Credential: "operator", Method: "admin_echo", Allowed: true},
+	}
+	for i, line := range lines {
+		var rec auditRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("record %d: failed to decode: %v", i, err)
+		}
+		if rec.Credential != want[i].Credential || rec.Method != want[i].Method || rec.Allowed != want[i].Allowed {
+			t.Errorf("record %d: mismatch: have %+v, want %+v", i, rec, want[i])
+		}
+	}
+}
+
+// Tests that tokens without expiry or signature are rejected, even if their claims
+// are otherwise valid.
+func TestHTTPAuthenticationUnsafeTokens(t *testing.T) {
+	srv, auth := newTestAuthServer(t, "")
+	defer srv.Close()
+	defer auth.Close()
+
+	unexpiring, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{
+		IssuedAt: time.Now().Unix(),
+	}).SignedString([]byte("operator-secret"))
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.StandardClaims{
+		IssuedAt:  time.Now().Unix(),
+		ExpiresAt: time.Now().Add(time.Minute).Unix(),
+	}).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("failed to encode token: %v", err)
+	}
+	for name, token := range map[string]string{"no exp": unexpiring, "alg none": unsigned} {
+		if status, _ := authCall(t, srv.URL, token, "test_echo"); status != http.StatusUnauthorized {
+			t.Errorf("%s: status mismatch: have %d, want %d", name, status, http.StatusUnauthorized)
+		}
+	}
+}
+
+// Tests that bearer tokens take precedence over basic passwords, which are only
+// used if no bearer token is sent.
+func TestAuthenticateBearerFirst(t *testing.T) {
+	auth, err := NewAuthenticator(&AuthConfig{
+		Credentials: []Credential{
+			{Name: "reader", APIKey: "reader-key"},
+			{Name: "writer", APIKey: "writer-key"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+	tests := []struct {
+		basic  []string // Basic password, if any
+		bearer string
+		want   string
+	}{
+		{bearer: "reader-key", want: "reader"},
+		{basic: []string{""}, bearer: "reader-key", want: "reader"},
+		{basic: []string{"writer-key"}, bearer: "reader-key", want: "reader"},
+		{basic: []string{"writer-key"}, want: "writer"},
+		{basic: []string{""}},
+		{},
+	}
+	for i, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "http://localhost", nil)
+		if tt.basic != nil {
+			req.SetBasicAuth("user", tt.basic[0])
+		}
+		if tt.bearer != "" {
+			req.Header.Add("Authorization", "Bearer "+tt.bearer)
+		}
+		cred, err := auth.authenticate(req)
+		if tt.want == "" {
+			if err != errUnauthenticated {
+				t.Errorf("test %d: error mismatch: have %v, want %v", i, err, errUnauthenticated)
+			}
+			continue
+		}
+		if err != nil || cred.Name != tt.want {
+			t.Errorf("test %d: credential mismatch: have %v (error %v), want %s", i, cred, err, tt.want)
+		}
+	}
+}
+
+// Tests that clients dialing URLs with user info authenticate by the password.
+func TestHTTPAuthenticationUserInfo(t *testing.T) {
+	srv, auth := newTestAuthServer(t, "")
+	defer srv.Close()
+	defer auth.Close()
+
+	url := strings.Replace(srv.URL, "http://", "http://reader:reader-key@", 1)
+	client, err := DialHTTP(url)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer client.Close()
+
+	var result Result
+	if err := client.Call(&result, "test_echo", "x", 1, &Args{"y"}); err != nil {
+		t.Fatalf("authenticated call failed: %v", err)
+	}
+	if err := client.Call(&result, "admin_echo", "x", 1, &Args{"y"}); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Fatalf("privileged call error mismatch: have %v, want access denied", err)
+	}
+}
+
+// Tests that websocket connections are authenticated during the handshake.
+func TestWebsocketAuthentication(t *testing.T) {
+	auth, err := NewAuthenticator(&AuthConfig{
+		Credentials: []Credential{{Name: "reader", APIKey: "reader-key", Allow: []string{"test"}}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+	server := NewServer()
+	server.SetAuthenticator(auth)
+	server.RegisterName("test", new(Service))
+	server.RegisterName("admin", new(Service))
+
+	srv := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer srv.Close()
+
+	endpoint := strings.Replace(srv.URL, "http://", "ws://", 1)
+	if client, err := DialWebsocket(context.Background(), endpoint, ""); err == nil {
+		client.Close()
+		t.Fatalf("unauthenticated connection accepted")
+	}
+	client, err := DialWebsocket(context.Background(), strings.Replace(endpoint, "ws://", "ws://reader:reader-key@", 1), "")
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer client.Close()
+
+	var result Result
+	if err := client.Call(&result, "test_echo", "x", 1, &Args{"y"}); err != nil {
+		t.Fatalf("authenticated call failed: %v", err)
+	}
+	if err := client.Call(&result, "admin_echo", "x", 1, &Args{"y"}); err == nil {
+		t.Fatalf("privileged call allowed")
+	}
+}
+
+// Tests that credentials without any secret are refused.
+func TestAuthenticatorInvalidCredential(t *testing.T) {
+	if _, err := NewAuthenticator(&AuthConfig{Credentials: []Credential{{Name: "nobody"}}}); err == nil {
+		t.Fatalf("credential without secrets accepted")
+	}
+}
+
+// Tests that subscriptions are authorized as the subscribe method of their module.
+func TestAuthorizeSubscription(t *testing.T) {
+	auth, err := NewAuthenticator(&AuthConfig{})
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+	server := NewServer()
+	server.RegisterName("test", new(Service))
+
+	req := &serverRequest{svcname: "test", callb: server.services["test"].subscriptions["subscription"]}
+	tests := []struct {
+		allow   string
+		allowed bool
+	}{
+		{"test", true},
+		{"test_subscribe", true},
+		{"test_subscription", false},
+		{"test_echo", false},
+	}
+	for i, tt := range tests {
+		ctx := context.WithValue(context.Background(), credentialKey{}, &Credential{Name: "subscriber", Allow: []string{tt.allow}})
+		if allowed := auth.authorize(ctx, req) == nil; allowed != tt.allowed {
+			t.Errorf("test %d: access mismatch for %q: have %v, want %v", i, tt.allow, allowed, tt.allowed)
+		}
+	}
+}
@@ -16,7 +16,10 @@
 
 package rpc
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 // request is for an unknown service
 type methodNotFoundError struct {
@@ -58,6 +61,38 @@ func (e *callbackError) ErrorCode() int { return -32000 }
 
 func (e *callbackError) Error() string { return e.message }
 
+// issued when the authenticated credential isn't allowed to call a method
+type accessDeniedError struct {
+	service string
+	method  string
+}
+
+func (e *accessDeniedError) ErrorCode() int { return -32003 }
+
+func (e *accessDeniedError) Error() string {
+	return fmt.Sprintf("access to %s%s%s denied", e.service, serviceMethodSeparator, e.method)
+}
+
+// issued when a request exceeds one of the limits of the server
+type limitExceededError struct{ message string }
+
+func (e *limitExceededError) ErrorCode() int { return -32005 }
+
+func (e *limitExceededError) Error() string { return e.message }
+
+// issued when a call did not finish within its execution timeout
+type timeoutError struct {
+	service string
+	method  string
+	timeout time.Duration
+}
+
+func (e *timeoutError) ErrorCode() int { return -32002 }
+
+func (e *timeoutError) Error() string {
+	return fmt.Sprintf("%s%s%s timed out after %v", e.service, serviceMethodSeparator, e.method, e.timeout)
+}
+
 // issued when a request is received after the server is issued to stop.
 type shutdownError struct{}
 
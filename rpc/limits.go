@@ -0,0 +1,86 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Limits restricts the resources the clients of an RPC server may consume, so a
+// single client cannot exhaust the node with huge batches or expensive calls.
+// Zero values disable the respective limit.
+type Limits struct {
+	BatchItems    int `toml:",omitempty"` // Maximum number of requests in a batch
+	ResponseBytes int `toml:",omitempty"` // Maximum size of a response, of all items for batches
+
+	CallTimeout    time.Duration            `toml:",omitempty"` // Maximum execution time of a call, after which its context is canceled
+	MethodTimeouts map[string]time.Duration `toml:",omitempty"` // Timeouts of specific methods (e.g. debug_traceTransaction), overriding CallTimeout
+
+	Concurrency     int `toml:",omitempty"` // Maximum number of calls executing concurrently over all connections
+	ConnConcurrency int `toml:",omitempty"` // Maximum number of calls executing concurrently on a single connection
+}
+
+// Timeout returns the execution timeout of the given method.
+func (l *Limits) Timeout(method string) time.Duration {
+	if timeout, ok := l.MethodTimeouts[method]; ok {
+		return timeout
+	}
+	return l.CallTimeout
+}
+
+// acquire reserves a slot of the given concurrency limit, returning an error if
+// all of them are taken. A nil limit is unlimited.
+func acquire(slots chan struct{}, scope string) Error {
+	if slots == nil {
+		return nil
+	}
+	select {
+	case slots <- struct{}{}:
+		return nil
+	default:
+		return &limitExceededError{fmt.Sprintf("too many concurrent %s requests (max %d)", scope, cap(slots))}
+	}
+}
+
+// release frees a slot of the given concurrency limit.
+func release(slots chan struct{}) {
+	if slots != nil {
+		<-slots
+	}
+}
+
+// heldCallsKey is the context key of the slots held by a (batch) request.
+type heldCallsKey struct{}
+
+// heldCalls keeps the concurrency slots of a (batch) request reserved until both
+// its execution finished and its calls which timed out in the background returned.
+type heldCalls struct {
+	refs    int32  // Execution and background calls still running
+	release func() // Frees the slots once none is running anymore
+}
+
+// hold marks a call of the request as still running in the background.
+func (h *heldCalls) hold() {
+	atomic.AddInt32(&h.refs, 1)
+}
+
+// done marks the execution or a background call of the request as returned.
+func (h *heldCalls) done() {
+	if atomic.AddInt32(&h.refs, -1) == 0 {
+		h.release()
+	}
+}
+
+// limitResponse replaces the response with an error if its encoding exceeds the
+// remaining response size budget, returning the budget left afterwards.
+func limitResponse(codec ServerCodec, id *interface{}, response interface{}, budget int) (interface{}, int) {
+	blob, err := json.Marshal(response)
+	if err != nil {
+		return response, budget
+	}
+	if len(blob) > budget {
+		return codec.CreateErrorResponse(id, &limitExceededError{fmt.Sprintf("response too large (%d > %d bytes)", len(blob), budget)}), budget
+	}
+	return response, budget - len(blob)
+}
@@ -0,0 +1,183 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// BlockingService is a test service whose calls block until released.
+type BlockingService struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *BlockingService) Block() {
+	s.started <- struct{}{}
+	<-s.release
+}
+
+// newTestLimitServer creates an HTTP server with the given limits, exposing the
+// test service.
+func newTestLimitServer(t *testing.T, limits Limits) (*httptest.Server, *Server) {
+	server := NewServer()
+	server.SetLimits(limits)
+	if err := server.RegisterName("test", new(Service)); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	return httptest.NewServer(server), server
+}
+
+// limitCall posts the raw request, returning the raw response.
+func limitCall(t *testing.T, url, body string) json.RawMessage {
+	resp, err := http.Post(url, contentType, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var res json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return res
+}
+
+// errorCode returns the error code of a single JSON-RPC response, 0 if successful.
+func errorCode(t *testing.T, raw json.RawMessage) int {
+	var msg jsonrpcMessage
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		t.Fatalf("failed to decode response %s: %v", raw, err)
+	}
+	if msg.Error == nil {
+		return 0
+	}
+	return msg.Error.Code
+}
+
+// Tests that batches exceeding the item limit are rejected as a whole.
+func TestBatchLimit(t *testing.T) {
+	srv, _ := newTestLimitServer(t, Limits{BatchItems: 2})
+	defer srv.Close()
+
+	call := `{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["x",1,{"S":"y"}]}`
+
+	var resps []json.RawMessage
+	if err := json.Unmarshal(limitCall(t, srv.URL, "["+call+","+call+"]"), &resps); err != nil || len(resps) != 2 {
+		t.Fatalf("batch within limit failed: %v, %d responses", err, len(resps))
+	}
+	if code := errorCode(t, limitCall(t, srv.URL, "["+call+","+call+","+call+"]")); code != -32005 {
+		t.Fatalf("oversized batch error code mismatch: have %d, want %d", code, -32005)
+	}
+}
+
+// Tests that responses exceeding the size limit are replaced by errors, counting
+// the sizes of all the items of a batch.
+func TestResponseLimit(t *testing.T) {
+	srv, _ := newTestLimitServer(t, Limits{ResponseBytes: 200})
+	defer srv.Close()
+
+	small := `{"jsonrpc":"2.0","id":1,"method":"test_echo","params":["x",1,{"S":"y"}]}`
+	large := `{"jsonrpc":"2.0","id":2,"method":"test_echo","params":["` + strings.Repeat("x", 200) + `",1,{"S":"y"}]}`
+
+	if code := errorCode(t, limitCall(t, srv.URL, small)); code != 0 {
+		t.Fatalf("small response failed with code %d", code)
+	}
+	if code := errorCode(t, limitCall(t, srv.URL, large)); code != -32005 {
+		t.Fatalf("large response error code mismatch: have %d, want %d", code, -32005)
+	}
+	var resps []json.RawMessage
+	if err := json.Unmarshal(limitCall(t, srv.URL, "["+small+","+small+","+small+"]"), &resps); err != nil {
+		t.Fatalf("failed to decode batch response: %v", err)
+	}
+	want := []int{0, 0, -32005}
+	for i, resp := range resps {
+		if code := errorCode(t, resp); code != want[i] {
+			t.Errorf("batch item %d: error code mismatch: have %d, want %d", i, code, want[i])
+		}
+	}
+}
+
+// Tests that calls exceeding their execution timeout are aborted.
+func TestCallTimeout(t *testing.T) {
+	srv, _ := newTestLimitServer(t, Limits{
+		CallTimeout:    50 * time.Millisecond,
+		MethodTimeouts: map[string]time.Duration{"test_rets": 0},
+	})
+	defer srv.Close()
+
+	start := time.Now()
+	if code := errorCode(t, limitCall(t, srv.URL, `{"jsonrpc":"2.0","id":1,"method":"test_sleep","params":[10000000000]}`)); code != -32002 {
+		t.Fatalf("timeout error code mismatch: have %d, want %d", code, -32002)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("timed out call took %v", elapsed)
+	}
+	if code := errorCode(t, limitCall(t, srv.URL, `{"jsonrpc":"2.0","id":1,"method":"test_rets","params":[]}`)); code != 0 {
+		t.Fatalf("call without timeout failed with code %d", code)
+	}
+}
+
+// Tests that calls exceeding the server wide concurrency limit are rejected.
+func TestConcurrencyLimit(t *testing.T) {
+	srv, server := newTestLimitServer(t, Limits{Concurrency: 1})
+	defer srv.Close()
+
+	blocker := &BlockingService{started: make(chan struct{}), release: make(chan struct{})}
+	if err := server.RegisterName("block", blocker); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	done := make(chan json.RawMessage)
+	go func() {
+		var res json.RawMessage
+		if resp, err := http.Post(srv.URL, contentType, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"block_block","params":[]}`)); err == nil {
+			json.NewDecoder(resp.Body).Decode(&res)
+			resp.Body.Close()
+		}
+		done <- res
+	}()
+	<-blocker.started
+
+	if code := errorCode(t, limitCall(t, srv.URL, `{"jsonrpc":"2.0","id":2,"method":"test_rets","params":[]}`)); code != -32005 {
+		t.Errorf("concurrent call error code mismatch: have %d, want %d", code, -32005)
+	}
+	close(blocker.release)
+	if code := errorCode(t, <-done); code != 0 {
+		t.Errorf("blocking call failed with code %d", code)
+	}
+	if code := errorCode(t, limitCall(t, srv.URL, `{"jsonrpc":"2.0","id":3,"method":"test_rets","params":[]}`)); code != 0 {
+		t.Errorf("call after release failed with code %d", code)
+	}
+}
+
+// Tests that calls running on in the background after their timeout keep holding
+// their concurrency slot until they return.
+func TestTimedOutCallConcurrency(t *testing.T) {
+	srv, server := newTestLimitServer(t, Limits{Concurrency: 1, CallTimeout: 50 * time.Millisecond})
+	defer srv.Close()
+
+	blocker := &BlockingService{started: make(chan struct{}, 1), release: make(chan struct{})}
+	if err := server.RegisterName("block", blocker); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	if code := errorCode(t, limitCall(t, srv.URL, `{"jsonrpc":"2.0","id":1,"method":"block_block","params":[]}`)); code != -32002 {
+		t.Fatalf("timeout error code mismatch: have %d, want %d", code, -32002)
+	}
+	if code := errorCode(t, limitCall(t, srv.URL, `{"jsonrpc":"2.0","id":2,"method":"test_rets","params":[]}`)); code != -32005 {
+		t.Errorf("call during timed out call error code mismatch: have %d, want %d", code, -32005)
+	}
+	close(blocker.release)
+	for i := 0; ; i++ {
+		code := errorCode(t, limitCall(t, srv.URL, `{"jsonrpc":"2.0","id":3,"method":"test_rets","params":[]}`))
+		if code == 0 {
+			break
+		}
+		if i == 100 {
+			t.Fatalf("slot not freed after the timed out call returned: error code %d", code)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
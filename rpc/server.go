@@ -73,6 +73,22 @@ func (s *RPCService) Modules() map[string]string {
 	return modules
 }
 
+// SetAuthenticator requires the HTTP and WebSocket requests served by the server
+// to be authenticated, restricting them to the methods allowed for their credentials.
+// It must be called before serving any requests.
+func (s *Server) SetAuthenticator(auth *Authenticator) {
+	s.auth = auth
+}
+
+// SetLimits restricts the resources the requests served by the server may consume.
+// It must be called before serving any requests.
+func (s *Server) SetLimits(limits Limits) {
+	s.limits = limits
+	if limits.Concurrency > 0 {
+		s.calls = make(chan struct{}, limits.Concurrency)
+	}
+}
+
 // RegisterName will create a service for the given rcvr type under the given name. When no methods on the given rcvr
 // match the criteria to be either a RPC method or a subscription an error is returned. Otherwise a new service is
 // created and added to the service collection this server instance serves.
@@ -155,6 +171,12 @@ func (s *Server) serveRequest(ctx context.Context, codec ServerCodec, singleShot
 	s.codecs.Add(codec)
 	s.codecsMu.Unlock()
 
+	// limit the calls executing concurrently on this connection
+	var conncalls chan struct{}
+	if s.limits.ConnConcurrency > 0 {
+		conncalls = make(chan struct{}, s.limits.ConnConcurrency)
+	}
+
 	// test if the server is ordered to stop
 	for atomic.LoadInt32(&s.run) == 1 {
 		reqs, batch, err := s.readRequest(codec)
@@ -172,42 +194,86 @@ func (s *Server) serveRequest(ctx context.Context, codec ServerCodec, singleShot
 		// check if server is ordered to shutdown and return an error
 		// telling the client that his request failed.
 		if atomic.LoadInt32(&s.run) != 1 {
-			err = &shutdownError{}
-			if batch {
-				resps := make([]interface{}, len(reqs))
-				for i, r := range reqs {
-					resps[i] = codec.CreateErrorResponse(&r.id, err)
-				}
-				codec.Write(resps)
-			} else {
-				codec.Write(codec.CreateErrorResponse(&reqs[0].id, err))
-			}
+			writeErrors(codec, reqs, batch, &shutdownError{})
 			return nil
 		}
+		// reject oversized batches as a whole and requests exceeding the concurrency limits
+		if batch && s.limits.BatchItems > 0 && len(reqs) > s.limits.BatchItems {
+			codec.Write(codec.CreateErrorResponse(nil, &limitExceededError{fmt.Sprintf("batch too large (%d > %d items)", len(reqs), s.limits.BatchItems)}))
+			if singleShot {
+				return nil
+			}
+			continue
+		}
+		if err := s.acquireCalls(conncalls); err != nil {
+			writeErrors(codec, reqs, batch, err)
+			if singleShot {
+				return nil
+			}
+			continue
+		}
+		// the slots stay reserved while calls which timed out are still running
+		held := &heldCalls{refs: 1, release: func() { s.releaseCalls(conncalls) }}
+		callctx := context.WithValue(ctx, heldCallsKey{}, held)
+
 		// If a single shot request is executing, run and return immediately
 		if singleShot {
+			defer held.done()
 			if batch {
-				s.execBatch(ctx, codec, reqs)
+				s.execBatch(callctx, codec, reqs)
 			} else {
-				s.exec(ctx, codec, reqs[0])
+				s.exec(callctx, codec, reqs[0])
 			}
 			return nil
 		}
 		// For multi-shot connections, start a goroutine to serve and loop back
 		pend.Add(1)
 
-		go func(reqs []*serverRequest, batch bool) {
+		go func(ctx context.Context, reqs []*serverRequest, batch bool) {
 			defer pend.Done()
+			defer held.done()
 			if batch {
 				s.execBatch(ctx, codec, reqs)
 			} else {
 				s.exec(ctx, codec, reqs[0])
 			}
-		}(reqs, batch)
+		}(callctx, reqs, batch)
 	}
 	return nil
 }
 
+// acquireCalls reserves a slot for executing a (batch) request, both of the server
+// wide and of the connection concurrency limits.
+func (s *Server) acquireCalls(conncalls chan struct{}) Error {
+	if err := acquire(s.calls, "server"); err != nil {
+		return err
+	}
+	if err := acquire(conncalls, "connection"); err != nil {
+		release(s.calls)
+		return err
+	}
+	return nil
+}
+
+// releaseCalls frees the slots reserved by acquireCalls.
+func (s *Server) releaseCalls(conncalls chan struct{}) {
+	release(conncalls)
+	release(s.calls)
+}
+
+// writeErrors responds to all the (batch) requests with the given error.
+func writeErrors(codec ServerCodec, reqs []*serverRequest, batch bool, err Error) {
+	if batch {
+		resps := make([]interface{}, len(reqs))
+		for i, r := range reqs {
+			resps[i] = codec.CreateErrorResponse(&r.id, err)
+		}
+		codec.Write(resps)
+	} else {
+		codec.Write(codec.CreateErrorResponse(&reqs[0].id, err))
+	}
+}
+
 // ServeCodec reads incoming requests from codec, calls the appropriate callback and writes the
 // response back using the given codec. It will block until the codec is closed or the server is
 // stopped. In either case the codec is closed.
@@ -274,6 +340,13 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 		return codec.CreateErrorResponse(&req.id, &invalidParamsError{"Expected subscription id as first argument"}), nil
 	}
 
+	// check that the authenticated credential (if any) may call the method
+	if s.auth != nil {
+		if err := s.auth.authorize(ctx, req); err != nil {
+			return codec.CreateErrorResponse(&req.id, err), nil
+		}
+	}
+
 	if req.callb.isSubscribe {
 		subid, err := s.createSubscription(ctx, codec, req)
 		if err != nil {
@@ -297,6 +370,15 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 		return codec.CreateErrorResponse(&req.id, rpcErr), nil
 	}
 
+	// cancel the context of the call once its execution timeout expires
+	method := formatName(req.callb.method.Name)
+	timeout := s.limits.Timeout(req.svcname + serviceMethodSeparator + method)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	arguments := []reflect.Value{req.callb.rcvr}
 	if req.callb.hasCtx {
 		arguments = append(arguments, reflect.ValueOf(ctx))
@@ -306,7 +388,18 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 	}
 
 	// execute RPC method and return result
-	reply := req.callb.method.Func.Call(arguments)
+	var reply []reflect.Value
+	if timeout > 0 {
+		var err Error
+		if reply, err = callWithTimeout(ctx, req.callb.method.Func, arguments); err != nil {
+			if _, ok := err.(*timeoutError); ok {
+				err = &timeoutError{req.svcname, method, timeout}
+			}
+			return codec.CreateErrorResponse(&req.id, err), nil
+		}
+	} else {
+		reply = req.callb.method.Func.Call(arguments)
+	}
 	if len(reply) == 0 {
 		return codec.CreateResponse(req.id, nil), nil
 	}
@@ -320,6 +413,42 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 	return codec.CreateResponse(req.id, reply[0].Interface()), nil
 }
 
+// callWithTimeout invokes the callback in the background, giving up waiting for
+// its reply once the context expires. Callbacks honouring their context abort,
+// others run to completion in the background with their reply discarded, still
+// holding the concurrency slots of their request.
+func callWithTimeout(ctx context.Context, fn reflect.Value, arguments []reflect.Value) ([]reflect.Value, Error) {
+	held, _ := ctx.Value(heldCallsKey{}).(*heldCalls)
+	if held != nil {
+		held.hold()
+	}
+	done := make(chan []reflect.Value, 1)
+	go func() {
+		if held != nil {
+			defer held.done()
+		}
+		defer func() {
+			if err := recover(); err != nil {
+				const size = 64 << 10
+				buf := make([]byte, size)
+				buf = buf[:runtime.Stack(buf, false)]
+				log.Error(string(buf))
+				close(done)
+			}
+		}()
+		done <- fn.Call(arguments)
+	}()
+	select {
+	case reply, ok := <-done:
+		if !ok {
+			return nil, &callbackError{"method handler crashed"}
+		}
+		return reply, nil
+	case <-ctx.Done():
+		return nil, &timeoutError{}
+	}
+}
+
 // exec executes the given request and writes the result back using the codec.
 func (s *Server) exec(ctx context.Context, codec ServerCodec, req *serverRequest) {
 	var response interface{}
@@ -329,6 +458,9 @@ func (s *Server) exec(ctx context.Context, codec ServerCodec, req *serverRequest
 	} else {
 		response, callback = s.handle(ctx, codec, req)
 	}
+	if s.limits.ResponseBytes > 0 {
+		response, _ = limitResponse(codec, &req.id, response, s.limits.ResponseBytes)
+	}
 
 	if err := codec.Write(response); err != nil {
 		log.Error(fmt.Sprintf("%v\n", err))
@@ -346,6 +478,7 @@ func (s *Server) exec(ctx context.Context, codec ServerCodec, req *serverRequest
 func (s *Server) execBatch(ctx context.Context, codec ServerCodec, requests []*serverRequest) {
 	responses := make([]interface{}, len(requests))
 	var callbacks []func()
+	budget := s.limits.ResponseBytes
 	for i, req := range requests {
 		if req.err != nil {
 			responses[i] = codec.CreateErrorResponse(&req.id, req.err)
@@ -355,6 +488,9 @@ func (s *Server) execBatch(ctx context.Context, codec ServerCodec, requests []*s
 				callbacks = append(callbacks, callback)
 			}
 		}
+		if s.limits.ResponseBytes > 0 {
+			responses[i], budget = limitResponse(codec, &req.id, responses[i], budget)
+		}
 	}
 
 	if err := codec.Write(responses); err != nil {
@@ -74,6 +74,11 @@ type Server struct {
 	run      int32
 	codecsMu sync.Mutex
 	codecs   mapset.Set
+
+	auth *Authenticator // Authenticator of the HTTP and WebSocket requests, nil if open
+
+	limits Limits        // Resource limits of the requests
+	calls  chan struct{} // Slots of the concurrently executing calls, nil if unlimited
 }
 
 // rpcRequest represents a raw incoming RPC request
@@ -57,8 +57,22 @@ var websocketJSONCodec = websocket.Codec{
 // allowedOrigins should be a comma-separated list of allowed origin URLs.
 // To allow connections with any origin, pass "*".
 func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
+	validator := wsHandshakeValidator(allowedOrigins)
+
 	return websocket.Server{
-		Handshake: wsHandshakeValidator(allowedOrigins),
+		Handshake: func(cfg *websocket.Config, req *http.Request) error {
+			if err := validator(cfg, req); err != nil {
+				return err
+			}
+			// Reject unauthenticated connections before the upgrade
+			if srv.auth != nil {
+				if _, err := srv.auth.authenticate(req); err != nil {
+					log.Warn("Rejected unauthenticated WS-RPC connection", "remote", req.RemoteAddr)
+					return err
+				}
+			}
+			return nil
+		},
 		Handler: func(conn *websocket.Conn) {
 			// Create a custom encode/decode pair to enforce payload size and number encoding
 			conn.MaxPayloadBytes = maxRequestContentLength
@@ -69,7 +83,20 @@ func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
 			decoder := func(v interface{}) error {
 				return websocketJSONCodec.Receive(conn, v)
 			}
-			srv.ServeCodec(NewCodec(conn, encoder, decoder), OptionMethodInvocation|OptionSubscriptions)
+			ctx := context.Background()
+			if srv.auth != nil {
+				cred, err := srv.auth.authenticate(conn.Request())
+				if err != nil {
+					conn.Close()
+					return
+				}
+				ctx = context.WithValue(ctx, credentialKey{}, cred)
+				ctx = context.WithValue(ctx, "remote", conn.Request().RemoteAddr)
+			}
+			codec := NewCodec(conn, encoder, decoder)
+			defer codec.Close()
+
+			srv.serveRequest(ctx, codec, false, OptionMethodInvocation|OptionSubscriptions)
 		},
 	}
 }
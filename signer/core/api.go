@@ -17,6 +17,7 @@
 package core
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -30,10 +31,13 @@ import (
 	"github.com/bcos-one/BCOS/accounts/usbwallet"
 	"github.com/bcos-one/BCOS/common"
 	"github.com/bcos-one/BCOS/common/hexutil"
+	"github.com/bcos-one/BCOS/consensus/dbft"
+	"github.com/bcos-one/BCOS/core/types"
 	"github.com/bcos-one/BCOS/crypto"
 	"github.com/bcos-one/BCOS/internal/ethapi"
 	"github.com/bcos-one/BCOS/log"
 	"github.com/bcos-one/BCOS/rlp"
+	"github.com/bcos-one/BCOS/signer/typeddata"
 )
 
 // numberOfAccountsToDerive For hardware wallets, the number of accounts to derive
@@ -49,6 +53,10 @@ type ExternalAPI interface {
 	SignTransaction(ctx context.Context, args SendTxArgs, methodSelector *string) (*ethapi.SignTransactionResult, error)
 	// Sign - request to sign the given data (plus prefix)
 	Sign(ctx context.Context, addr common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error)
+	// SignData - request to sign the given data of a content type
+	SignData(ctx context.Context, contentType string, addr common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error)
+	// SignTypedData - request to sign the given EIP-712 typed structured data
+	SignTypedData(ctx context.Context, addr common.MixedcaseAddress, typedData typeddata.TypedData) (hexutil.Bytes, error)
 	// Export - request to export an account
 	Export(ctx context.Context, addr common.Address) (json.RawMessage, error)
 	// Import - request to import an account
@@ -170,11 +178,14 @@ type (
 		NewPassword string `json:"new_password"`
 	}
 	SignDataRequest struct {
-		Address common.MixedcaseAddress `json:"address"`
-		Rawdata hexutil.Bytes           `json:"raw_data"`
-		Message string                  `json:"message"`
-		Hash    hexutil.Bytes           `json:"hash"`
-		Meta    Metadata                `json:"meta"`
+		ContentType string                     `json:"content_type,omitempty"`
+		Address     common.MixedcaseAddress    `json:"address"`
+		Rawdata     hexutil.Bytes              `json:"raw_data"`
+		Message     string                     `json:"message"`
+		Hash        hexutil.Bytes              `json:"hash"`
+		TypedData   []*typeddata.NameValueType `json:"typed_data,omitempty"`
+		Callinfo    []ValidationInfo           `json:"call_info,omitempty"`
+		Meta        Metadata                   `json:"meta"`
 	}
 	SignDataResponse struct {
 		Approved bool `json:"approved"`
@@ -223,7 +234,8 @@ var ErrRequestDenied = errors.New("Request denied")
 // key that is generated when a new Account is created.
 // noUSB disables USB support that is required to support hardware devices such as
 // ledger and trezor.
-func NewSignerAPI(chainID int64, ksLocation string, noUSB bool, ui SignerUI, abidb *AbiDb, lightKDF bool, advancedMode bool) *SignerAPI {
+// expansions describes the BCOS expansions of the chain, it may be nil if unknown.
+func NewSignerAPI(chainID int64, ksLocation string, noUSB bool, ui SignerUI, abidb *AbiDb, expansions *Expansions, lightKDF bool, advancedMode bool) *SignerAPI {
 	var (
 		backends []accounts.Backend
 		n, p     = keystore.StandardScryptN, keystore.StandardScryptP
@@ -254,7 +266,7 @@ func NewSignerAPI(chainID int64, ksLocation string, noUSB bool, ui SignerUI, abi
 			log.Debug("Trezor support enabled")
 		}
 	}
-	signer := &SignerAPI{big.NewInt(chainID), accounts.NewManager(backends...), ui, NewValidator(abidb), !advancedMode}
+	signer := &SignerAPI{big.NewInt(chainID), accounts.NewManager(backends...), ui, NewValidator(abidb, expansions), !advancedMode}
 	if !noUSB {
 		signer.startUSBListener()
 	}
@@ -424,6 +436,10 @@ func logDiff(original *SignTxRequest, new *SignTxResponse) bool {
 		modified = true
 		log.Info("GasPrice changed by UI", "was", g0, "is", g1)
 	}
+	if t0, t1 := original.Transaction.Token, new.Transaction.Token; !reflect.DeepEqual(t0, t1) {
+		log.Info("Token changed by UI", "was", t0, "is", t1)
+		modified = true
+	}
 	if v0, v1 := big.Int(original.Transaction.Value), big.Int(new.Transaction.Value); v0.Cmp(&v1) != 0 {
 		modified = true
 		log.Info("Value changed by UI", "was", v0, "is", v1)
@@ -500,7 +516,7 @@ func (api *SignerAPI) SignTransaction(ctx context.Context, args SendTxArgs, meth
 		return nil, err
 	}
 
-	rlpdata, err := rlp.EncodeToBytes(signedTx)
+	rlpdata, err := signedTx.MarshalBinary()
 	response := ethapi.SignTransactionResult{Raw: rlpdata, Tx: signedTx}
 
 	// Finally, send the signed tx to the UI
@@ -523,9 +539,104 @@ func (api *SignerAPI) Sign(ctx context.Context, addr common.MixedcaseAddress, da
 	sighash, msg := SignHash(data)
 	// We make the request prior to looking up if we actually have the account, to prevent
 	// account-enumeration via the API
-	req := &SignDataRequest{Address: addr, Rawdata: data, Message: msg, Hash: sighash, Meta: MetadataFromContext(ctx)}
-	res, err := api.UI.ApproveSignData(req)
+	req := &SignDataRequest{ContentType: accounts.MimetypeTextPlain, Address: addr, Rawdata: data, Message: msg, Hash: sighash, Meta: MetadataFromContext(ctx)}
+	return api.signData(req)
+}
+
+// SignData calculates an Ethereum ECDSA signature for data of the given content
+// type. Plain text is signed with the message prefix exactly as by Sign, while the
+// consensus data of the wpoa and dbft engines is signed as keccak256(data), which
+// allows the signer to seal the blocks of a validator.
+//
+// Consensus data is decoded for the user, wpoa headers in full, dbft data must be
+// prefixed with the domain of a block seal, committed seal or PBFT message.
+func (api *SignerAPI) SignData(ctx context.Context, contentType string, addr common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error) {
+	msgs := &ValidationMessages{}
+	req := &SignDataRequest{
+		ContentType: contentType,
+		Address:     addr,
+		Rawdata:     data,
+		Hash:        crypto.Keccak256(data),
+		Meta:        MetadataFromContext(ctx),
+	}
+	switch contentType {
+	case accounts.MimetypeTextPlain:
+		return api.Sign(ctx, addr, data)
+
+	case accounts.MimetypeWPoaHeader:
+		header := new(types.Header)
+		if err := rlp.DecodeBytes(data, header); err != nil {
+			return nil, fmt.Errorf("invalid wpoa header: %v", err)
+		}
+		req.Message = fmt.Sprintf("wpoa block %v, parent %s, coinbase %s, %d gas used",
+			header.Number, header.ParentHash.Hex(), header.Coinbase.Hex(), header.GasUsed)
+		msgs.info("Request seals a wpoa block")
+
+	case accounts.MimetypeDbft:
+		// Refuse anything but domain separated consensus data, the hash of the data
+		// could be the signing hash of a transaction otherwise
+		desc, err := dbft.CheckSignData(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dbft consensus data: %v", err)
+		}
+		req.Message = desc
+		msgs.info("Request signs a dbft block seal or PBFT message")
+
+	default:
+		return nil, fmt.Errorf("unsupported content type %q", contentType)
+	}
+	req.Callinfo = msgs.Messages
+	return api.signData(req)
+}
+
+// SignTypedData calculates an Ethereum ECDSA signature for:
+// keccak256("\x19\x01" ‖ domainSeparator ‖ hashStruct(message))
+//
+// The typed data is validated against its schema and rendered for the user to
+// inspect before approving, as specified by EIP-712.
+func (api *SignerAPI) SignTypedData(ctx context.Context, addr common.MixedcaseAddress, typedData typeddata.TypedData) (hexutil.Bytes, error) {
+	sighash, rawData, err := typedData.SignHash()
+	if err != nil {
+		return nil, err
+	}
+	formatted, err := typedData.Format()
+	if err != nil {
+		return nil, err
+	}
+	msgs := &ValidationMessages{}
+	if chainID, err := typedData.ChainID(); err != nil {
+		return nil, err
+	} else if chainID != nil && chainID.Cmp(api.chainID) != 0 {
+		msgs.crit(fmt.Sprintf("Typed data is bound to chain %v, not %v", chainID, api.chainID))
+	}
+	// If we are in 'rejectMode', then reject rather than show the user warnings
+	if api.rejectMode {
+		if err := msgs.getWarnings(); err != nil {
+			return nil, err
+		}
+	}
+	var message bytes.Buffer
+	for _, field := range formatted {
+		message.WriteString(field.Pprint(0))
+	}
+	req := &SignDataRequest{
+		Address:   addr,
+		Rawdata:   rawData,
+		Message:   message.String(),
+		Hash:      sighash,
+		TypedData: formatted,
+		Callinfo:  msgs.Messages,
+		Meta:      MetadataFromContext(ctx),
+	}
+	return api.signData(req)
+}
 
+// signData requests the approval of the UI to sign the hash of the request, and
+// signs it with the requested account.
+func (api *SignerAPI) signData(req *SignDataRequest) (hexutil.Bytes, error) {
+	addr, sighash := req.Address, req.Hash
+
+	res, err := api.UI.ApproveSignData(req)
 	if err != nil {
 		return nil, err
 	}
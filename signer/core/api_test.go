@@ -19,6 +19,7 @@ package core
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -28,13 +29,17 @@ import (
 	"testing"
 	"time"
 
+	"github.com/bcos-one/BCOS/accounts"
 	"github.com/bcos-one/BCOS/accounts/keystore"
 	"github.com/bcos-one/BCOS/cmd/utils"
 	"github.com/bcos-one/BCOS/common"
 	"github.com/bcos-one/BCOS/common/hexutil"
+	"github.com/bcos-one/BCOS/consensus/dbft"
 	"github.com/bcos-one/BCOS/core/types"
+	"github.com/bcos-one/BCOS/crypto"
 	"github.com/bcos-one/BCOS/internal/ethapi"
 	"github.com/bcos-one/BCOS/rlp"
+	"github.com/bcos-one/BCOS/signer/typeddata"
 )
 
 //Used for testing
@@ -145,6 +150,7 @@ func setup(t *testing.T) (*SignerAPI, chan string) {
 			true,
 			ui,
 			db,
+			nil,
 			true, true)
 	)
 	return api, controller
@@ -284,6 +290,111 @@ func TestSignData(t *testing.T) {
 		t.Errorf("Expected 65 byte signature (got %d bytes)", len(h))
 	}
 }
+func TestSignTypedData(t *testing.T) {
+	api, control := setup(t)
+	createAccount(control, api, t)
+	control <- "A"
+	list, err := api.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := common.NewMixedcaseAddress(list[0])
+
+	var typedData typeddata.TypedData
+	if err := json.Unmarshal([]byte(`{
+		"types": {
+			"EIP712Domain": [{"name": "name", "type": "string"}, {"name": "chainId", "type": "uint256"}],
+			"Order": [{"name": "amount", "type": "uint256"}, {"name": "price", "type": "uint256"}]
+		},
+		"primaryType": "Order",
+		"domain": {"name": "Exchange", "chainId": 1},
+		"message": {"amount": "0x10", "price": 42}
+	}`), &typedData); err != nil {
+		t.Fatal(err)
+	}
+	control <- "Y"
+	control <- "a_long_password"
+	sig, err := api.SignTypedData(context.Background(), a, typedData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig) != 65 {
+		t.Fatalf("Expected 65 byte signature (got %d bytes)", len(sig))
+	}
+	sighash, _, _ := typedData.SignHash()
+	sig[64] -= 27
+	pubkey, err := crypto.SigToPub(sighash, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer := crypto.PubkeyToAddress(*pubkey); signer != a.Address() {
+		t.Errorf("Signer mismatch: have %x, want %x", signer, a.Address())
+	}
+	// Malformed typed data must be rejected before asking the user
+	delete(typedData.Message, "price")
+	if _, err := api.SignTypedData(context.Background(), a, typedData); err == nil {
+		t.Errorf("Expected error for malformed typed data")
+	}
+}
+
+func TestSignConsensusData(t *testing.T) {
+	api, control := setup(t)
+	createAccount(control, api, t)
+	control <- "A"
+	list, err := api.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := common.NewMixedcaseAddress(list[0])
+
+	header := &types.Header{Number: big.NewInt(1), Difficulty: big.NewInt(2), Time: big.NewInt(3), Extra: make([]byte, 32)}
+	data, _ := rlp.EncodeToBytes(header)
+
+	control <- "Y"
+	control <- "a_long_password"
+	sig, err := api.SignData(context.Background(), accounts.MimetypeWPoaHeader, a, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig[64] -= 27
+	pubkey, err := crypto.SigToPub(crypto.Keccak256(data), sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer := crypto.PubkeyToAddress(*pubkey); signer != a.Address() {
+		t.Errorf("Signer mismatch: have %x, want %x", signer, a.Address())
+	}
+	// Malformed headers and unknown content types must be rejected before asking the user
+	if _, err := api.SignData(context.Background(), accounts.MimetypeWPoaHeader, a, []byte{0x01}); err == nil {
+		t.Errorf("Expected error for malformed header")
+	}
+	if _, err := api.SignData(context.Background(), "application/x-unknown", a, data); err == nil {
+		t.Errorf("Expected error for unknown content type")
+	}
+	// Domain separated dbft seals are signed, anything else that could be the
+	// signing payload of a transaction must be refused
+	seal := dbft.SealData(header.Hash())
+	control <- "Y"
+	control <- "a_long_password"
+	if sig, err = api.SignData(context.Background(), accounts.MimetypeDbft, a, seal); err != nil {
+		t.Fatal(err)
+	}
+	sig[64] -= 27
+	if pubkey, err = crypto.SigToPub(crypto.Keccak256(seal), sig); err != nil {
+		t.Fatal(err)
+	}
+	if signer := crypto.PubkeyToAddress(*pubkey); signer != a.Address() {
+		t.Errorf("Signer mismatch: have %x, want %x", signer, a.Address())
+	}
+	tx := types.NewTransaction(0, common.Address{1}, nil, big.NewInt(1), 21000, big.NewInt(1), nil)
+	payload, _ := rlp.EncodeToBytes([]interface{}{tx.Nonce(), tx.GasPrice(), tx.Gas(), tx.To(), tx.Value(), tx.Data(), big.NewInt(1), uint(0), uint(0)})
+	for _, data := range [][]byte{payload, header.Hash().Bytes(), seal[:len(seal)-1]} {
+		if _, err := api.SignData(context.Background(), accounts.MimetypeDbft, a, data); err == nil {
+			t.Errorf("Expected error for non-consensus data %x", data)
+		}
+	}
+}
+
 func mkTestTx(from common.MixedcaseAddress) SendTxArgs {
 	to := common.NewMixedcaseAddress(common.HexToAddress("0x1337"))
 	gas := hexutil.Uint64(21000)
@@ -130,7 +130,12 @@ func (ui *CommandlineUI) ApproveTx(request *SignTxRequest) (SignTxResponse, erro
 		fmt.Printf("to:    <contact creation>\n")
 	}
 	fmt.Printf("from:     %v\n", request.Transaction.From.String())
-	fmt.Printf("value:    %v wei\n", weival)
+	if token := request.Transaction.Token; token != nil {
+		fmt.Printf("token:    %v\n", token.Original())
+		fmt.Printf("value:    %v\n", weival)
+	} else {
+		fmt.Printf("value:    %v wei\n", weival)
+	}
 	fmt.Printf("gas:      %v (%v)\n", request.Transaction.Gas, uint64(request.Transaction.Gas))
 	fmt.Printf("gasprice: %v wei\n", request.Transaction.GasPrice.ToInt())
 	fmt.Printf("nonce:    %v (%v)\n", request.Transaction.Nonce, uint64(request.Transaction.Nonce))
@@ -165,9 +170,25 @@ func (ui *CommandlineUI) ApproveSignData(request *SignDataRequest) (SignDataResp
 
 	fmt.Printf("-------- Sign data request--------------\n")
 	fmt.Printf("Account:  %s\n", request.Address.String())
-	fmt.Printf("message:  \n%q\n", request.Message)
+	if request.ContentType != "" {
+		fmt.Printf("content type: %s\n", request.ContentType)
+	}
+	if request.TypedData != nil {
+		fmt.Printf("typed data: \n")
+		for _, field := range request.TypedData {
+			fmt.Print(field.Pprint(1))
+		}
+	} else {
+		fmt.Printf("message:  \n%q\n", request.Message)
+	}
 	fmt.Printf("raw data: \n%v\n", request.Rawdata)
 	fmt.Printf("message hash:  %v\n", request.Hash)
+	if request.Callinfo != nil {
+		fmt.Printf("\nValidation:\n")
+		for _, m := range request.Callinfo {
+			fmt.Printf("  * %s : %s\n", m.Typ, m.Message)
+		}
+	}
 	fmt.Printf("-------------------------------------------\n")
 	showMetadata(request.Meta)
 	if !ui.confirm() {
@@ -0,0 +1,113 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/consensus/wpoa"
+	"github.com/bcos-one/BCOS/expansions/management"
+	"github.com/bcos-one/BCOS/expansions/token"
+)
+
+// Expansions describes the BCOS expansions of the chain the signer signs for,
+// allowing the validator to decode the operations sent to the expansion storages
+// and to recognise the tokens transferred.
+type Expansions struct {
+	TokenStorage  *common.Address           `json:"tokenStorage"`
+	ManageStorage *common.Address           `json:"manageStorage"`
+	Tokens        map[common.Address]string `json:"tokens"` // Known tokens along with their names
+}
+
+// NewExpansionsFromFile loads the expansion storages and known tokens from a
+// JSON file, and errors if the file is not valid json.
+func NewExpansionsFromFile(path string) (*Expansions, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	expansions := new(Expansions)
+	if err := json.Unmarshal(raw, expansions); err != nil {
+		return nil, err
+	}
+	return expansions, nil
+}
+
+// isTokenStorage reports whether the address is the token storage.
+func (e *Expansions) isTokenStorage(addr common.Address) bool {
+	return e != nil && e.TokenStorage != nil && *e.TokenStorage == addr
+}
+
+// isManageStorage reports whether the address is the management storage.
+func (e *Expansions) isManageStorage(addr common.Address) bool {
+	return e != nil && e.ManageStorage != nil && *e.ManageStorage == addr
+}
+
+// tokenName returns the name of a known token, and whether the token is known.
+func (e *Expansions) tokenName(addr common.Address) (string, bool) {
+	if e == nil {
+		return "", false
+	}
+	name, ok := e.Tokens[addr]
+	return name, ok
+}
+
+// validateToken checks the token a transaction transfers, warning about tokens
+// missing from the list of known ones.
+func (v *Validator) validateToken(msgs *ValidationMessages, tokenAddr *common.MixedcaseAddress) {
+	if tokenAddr == nil {
+		return
+	}
+	if !tokenAddr.ValidChecksum() {
+		msgs.warn("Invalid checksum on token address")
+	}
+	addr := tokenAddr.Address()
+	if addr == (common.Address{}) {
+		msgs.crit("Tx token is the zero address!")
+		return
+	}
+	if name, ok := v.expansions.tokenName(addr); ok {
+		msgs.info(fmt.Sprintf("Tx transfers token %s (%s)", name, addr.Hex()))
+		return
+	}
+	msgs.warn(fmt.Sprintf("Tx transfers unknown token %s", addr.Hex()))
+}
+
+// validateExpansionCall decodes the operation of a transaction sent to one of the
+// expansion storages into human-readable form.
+func (v *Validator) validateExpansionCall(msgs *ValidationMessages, txargs *SendTxArgs, data []byte) {
+	storage, abidata := "token storage", token.ABI
+	if v.expansions.isManageStorage(txargs.To.Address()) {
+		storage, abidata = "management storage", management.ABI
+		msgs.warn("Tx targets the management storage, changing the permissions of the chain")
+	}
+	if txargs.Value.ToInt().Sign() > 0 {
+		msgs.warn(fmt.Sprintf("Tx sends value to the %s", storage))
+	}
+	if len(data) == 0 {
+		msgs.crit(fmt.Sprintf("Tx targets the %s without any operation", storage))
+		return
+	}
+	info, err := parseCallData(data, abidata)
+	if err != nil {
+		msgs.crit(fmt.Sprintf("Tx targets the %s, but the operation could not be decoded: %v", storage, err))
+		return
+	}
+	msgs.info(fmt.Sprintf("%s operation: %s", storage, info))
+}
+
+// validateCustomTx decodes a wpoa management payload into human-readable form.
+// Such payloads change the signers or managers if sent by a manager.
+func validateCustomTx(msgs *ValidationMessages, data []byte) {
+	op := wpoa.ParseCustomTx(data)
+	if op == nil {
+		msgs.crit(fmt.Sprintf("Tx carries a malformed wpoa management payload: %q", data))
+		return
+	}
+	if !op.Known() {
+		msgs.warn(fmt.Sprintf("Tx carries an unknown wpoa management operation %q", op.Category))
+		return
+	}
+	msgs.warn(fmt.Sprintf("Tx carries wpoa management operation %s(%s)", op.Category, op.Address.Hex()))
+}
@@ -24,6 +24,7 @@ import (
 	"regexp"
 
 	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/consensus/wpoa"
 )
 
 // The validation package contains validation checks for transactions
@@ -32,11 +33,12 @@ import (
 // The package provides warnings for typical pitfalls
 
 type Validator struct {
-	db *AbiDb
+	db         *AbiDb
+	expansions *Expansions
 }
 
-func NewValidator(db *AbiDb) *Validator {
-	return &Validator{db}
+func NewValidator(db *AbiDb, expansions *Expansions) *Validator {
+	return &Validator{db, expansions}
 }
 func testSelector(selector string, data []byte) (*decodedCallData, error) {
 	if selector == "" {
@@ -114,6 +116,7 @@ func (v *Validator) validate(msgs *ValidationMessages, txargs *SendTxArgs, metho
 	if txargs.Data != nil {
 		data = *txargs.Data
 	}
+	v.validateToken(msgs, txargs.Token)
 
 	if txargs.To == nil {
 		//Contract creation should contain sufficient data to deploy a contract
@@ -143,8 +146,15 @@ func (v *Validator) validate(msgs *ValidationMessages, txargs *SendTxArgs, metho
 			// Sending to 0
 			msgs.crit("Tx destination is the zero address!")
 		}
-		// Validate calldata
-		v.validateCallData(msgs, data, methodSelector)
+		// Validate calldata, decoding the BCOS expansion and management payloads
+		switch to := txargs.To.Address(); {
+		case v.expansions.isTokenStorage(to) || v.expansions.isManageStorage(to):
+			v.validateExpansionCall(msgs, txargs, data)
+		case wpoa.IsCustomTx(data):
+			validateCustomTx(msgs, data)
+		default:
+			v.validateCallData(msgs, data, methodSelector)
+		}
 	}
 	return nil
 }
@@ -19,10 +19,14 @@ package core
 import (
 	"fmt"
 	"math/big"
+	"strings"
 	"testing"
 
+	"github.com/bcos-one/BCOS/accounts/abi"
 	"github.com/bcos-one/BCOS/common"
 	"github.com/bcos-one/BCOS/common/hexutil"
+	"github.com/bcos-one/BCOS/expansions/management"
+	"github.com/bcos-one/BCOS/expansions/token"
 )
 
 func hexAddr(a string) common.Address { return common.BytesToAddress(common.FromHex(a)) }
@@ -78,7 +82,7 @@ func TestValidator(t *testing.T) {
 	var (
 		// use empty db, there are other tests for the abi-specific stuff
 		db, _ = NewEmptyAbiDB()
-		v     = NewValidator(db)
+		v     = NewValidator(db, nil)
 	)
 	testcases := []txtestcase{
 		// Invalid to checksum
@@ -138,6 +142,85 @@ func TestValidator(t *testing.T) {
 	}
 }
 
+// Tests that token transfers, expansion operations and wpoa management payloads
+// are decoded and warned about.
+func TestValidatorExpansions(t *testing.T) {
+	var (
+		tokenStorage  = common.HexToAddress("0x1000")
+		manageStorage = common.HexToAddress("0x2000")
+		known         = common.HexToAddress("0x3000")
+		unknown       = common.HexToAddress("0x4000")
+
+		db, _ = NewEmptyAbiDB()
+		v     = NewValidator(db, &Expansions{
+			TokenStorage:  &tokenStorage,
+			ManageStorage: &manageStorage,
+			Tokens:        map[common.Address]string{known: "TST"},
+		})
+	)
+	pack := func(abidata string, method string, args ...interface{}) []byte {
+		parsed, err := abi.JSON(strings.NewReader(abidata))
+		if err != nil {
+			t.Fatalf("failed to parse abi: %v", err)
+		}
+		data, err := parsed.Pack(method, args...)
+		if err != nil {
+			t.Fatalf("failed to pack %s: %v", method, err)
+		}
+		return data
+	}
+	tx := func(to common.Address, tokenAddr *common.Address, data []byte) *SendTxArgs {
+		args := &SendTxArgs{
+			From: common.NewMixedcaseAddress(common.HexToAddress("0xdead")),
+			To:   new(common.MixedcaseAddress),
+			Gas:  0x5208,
+		}
+		*args.To = common.NewMixedcaseAddress(to)
+		if tokenAddr != nil {
+			args.Token = new(common.MixedcaseAddress)
+			*args.Token = common.NewMixedcaseAddress(*tokenAddr)
+		}
+		if data != nil {
+			input := hexutil.Bytes(data)
+			args.Data = &input
+		}
+		return args
+	}
+	signer := common.HexToAddress("0xbeef").Hex()
+
+	testcases := []struct {
+		args *SendTxArgs
+		want []string
+	}{
+		// Transfers of known and unknown tokens
+		{tx(unknown, &known, nil), []string{INFO}},
+		{tx(known, &unknown, nil), []string{WARN}},
+		// Token storage operations, decodable or not
+		{tx(tokenStorage, nil, pack(token.ABI, "pause", known)), []string{INFO}},
+		{tx(tokenStorage, nil, []byte{0x01, 0x02, 0x03, 0x04}), []string{CRIT}},
+		// Management storage operations are always warned about
+		{tx(manageStorage, nil, pack(management.ABI, "setAdminThreshold", big.NewInt(2))), []string{WARN, INFO}},
+		// Wpoa management payloads, known, unknown and malformed
+		{tx(unknown, nil, []byte("WTX:1:AddSigner:"+signer)), []string{WARN}},
+		{tx(unknown, nil, []byte("WTX:1:Promote:"+signer)), []string{WARN}},
+		{tx(unknown, nil, []byte("WTX:2:AddSigner:"+signer)), []string{CRIT}},
+	}
+	for i, test := range testcases {
+		msgs, err := v.ValidateTransaction(test.args, nil)
+		if err != nil {
+			t.Errorf("test %d: unexpected error: %v", i, err)
+			continue
+		}
+		var have []string
+		for _, msg := range msgs.Messages {
+			have = append(have, msg.Typ)
+		}
+		if strings.Join(have, ",") != strings.Join(test.want, ",") {
+			t.Errorf("test %d: message types mismatch: have %v, want %v (%v)", i, have, test.want, msgs.Messages)
+		}
+	}
+}
+
 func TestPasswordValidation(t *testing.T) {
 	testcases := []struct {
 		pw         string
@@ -0,0 +1,464 @@
+// Package typeddata implements the hashing of typed structured data specified by
+// EIP-712, allowing signers to display domain separated messages before signing.
+package typeddata
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/common/hexutil"
+	"github.com/bcos-one/BCOS/common/math"
+	"github.com/bcos-one/BCOS/crypto"
+)
+
+// DomainType is the name of the type describing the signing domain.
+const DomainType = "EIP712Domain"
+
+// domainFields are the fields a signing domain may consist of, with their types.
+var domainFields = map[string]string{
+	"name":              "string",
+	"version":           "string",
+	"chainId":           "uint256",
+	"verifyingContract": "address",
+	"salt":              "bytes32",
+}
+
+var (
+	typeNameRegexp = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+	arrayRegexp    = regexp.MustCompile(`^(.+)\[([0-9]*)\]$`)
+	sizedRegexp    = regexp.MustCompile(`^(u?int|bytes)([0-9]+)$`)
+)
+
+// Type is a single field of a struct type.
+type Type struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Types maps the struct type names to their fields.
+type Types map[string][]Type
+
+// Message is a struct value, mapping field names to their values as decoded
+// from JSON.
+type Message map[string]interface{}
+
+// TypedData is a typed structured message to sign, along with the domain it
+// is bound to.
+type TypedData struct {
+	Types       Types   `json:"types"`
+	PrimaryType string  `json:"primaryType"`
+	Domain      Message `json:"domain"`
+	Message     Message `json:"message"`
+}
+
+// Validate checks the schema of the typed data: the domain and primary types must
+// be defined, and every field must be of an atomic, dynamic, array or defined
+// struct type.
+func (typedData *TypedData) Validate() error {
+	domain, ok := typedData.Types[DomainType]
+	if !ok {
+		return fmt.Errorf("type %s not defined", DomainType)
+	}
+	for _, field := range domain {
+		if want, ok := domainFields[field.Name]; !ok || field.Type != want {
+			return fmt.Errorf("invalid domain field %s %s", field.Type, field.Name)
+		}
+	}
+	if _, ok := typedData.Types[typedData.PrimaryType]; !ok || typedData.PrimaryType == DomainType {
+		return fmt.Errorf("primary type %q not defined", typedData.PrimaryType)
+	}
+	for name, fields := range typedData.Types {
+		if !typeNameRegexp.MatchString(name) || isAtomic(name) {
+			return fmt.Errorf("invalid type name %q", name)
+		}
+		seen := make(map[string]bool)
+		for _, field := range fields {
+			if !typeNameRegexp.MatchString(field.Name) {
+				return fmt.Errorf("invalid field name %q in type %s", field.Name, name)
+			}
+			if seen[field.Name] {
+				return fmt.Errorf("duplicate field %s in type %s", field.Name, name)
+			}
+			seen[field.Name] = true
+
+			base := baseType(field.Type)
+			if _, ok := typedData.Types[base]; !ok && !isAtomic(base) {
+				return fmt.Errorf("undefined type %s of field %s in type %s", field.Type, field.Name, name)
+			}
+		}
+	}
+	return nil
+}
+
+// SignHash validates the typed data and returns the hash to sign along with its
+// preimage, computed as keccak256("\x19\x01" ‖ domainSeparator ‖ hashStruct(message)).
+func (typedData *TypedData) SignHash() ([]byte, []byte, error) {
+	if err := typedData.Validate(); err != nil {
+		return nil, nil, err
+	}
+	domainSeparator, err := typedData.HashStruct(DomainType, typedData.Domain)
+	if err != nil {
+		return nil, nil, fmt.Errorf("domain: %v", err)
+	}
+	messageHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		return nil, nil, fmt.Errorf("message: %v", err)
+	}
+	raw := append([]byte{0x19, 0x01}, domainSeparator...)
+	raw = append(raw, messageHash...)
+
+	return crypto.Keccak256(raw), raw, nil
+}
+
+// ChainID returns the chain the domain is bound to, or nil if it isn't bound to
+// any chain.
+func (typedData *TypedData) ChainID() (*big.Int, error) {
+	value, ok := typedData.Domain["chainId"]
+	if !ok {
+		return nil, nil
+	}
+	return parseInteger(value)
+}
+
+// HashStruct returns the hash of the encoding of a struct value.
+func (typedData *TypedData) HashStruct(primaryType string, data Message) (hexutil.Bytes, error) {
+	encoded, err := typedData.EncodeData(primaryType, data)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.Keccak256(encoded), nil
+}
+
+// TypeHash returns the hash of the encoding of a struct type.
+func (typedData *TypedData) TypeHash(primaryType string) hexutil.Bytes {
+	return crypto.Keccak256(typedData.EncodeType(primaryType))
+}
+
+// EncodeType returns the encoding of a struct type, which is its signature
+// followed by the signatures of the struct types it references, sorted by name.
+//
+// For example: Mail(Person from,Person to,string contents)Person(string name,address wallet)
+func (typedData *TypedData) EncodeType(primaryType string) []byte {
+	deps := typedData.dependencies(primaryType, nil)
+	sort.Strings(deps[1:])
+
+	var buffer bytes.Buffer
+	for _, dep := range deps {
+		fields := make([]string, len(typedData.Types[dep]))
+		for i, field := range typedData.Types[dep] {
+			fields[i] = field.Type + " " + field.Name
+		}
+		buffer.WriteString(dep + "(" + strings.Join(fields, ",") + ")")
+	}
+	return buffer.Bytes()
+}
+
+// dependencies returns the given struct type followed by all the struct types it
+// references, directly or indirectly.
+func (typedData *TypedData) dependencies(primaryType string, found []string) []string {
+	for _, dep := range found {
+		if dep == primaryType {
+			return found
+		}
+	}
+	if _, ok := typedData.Types[primaryType]; !ok {
+		return found
+	}
+	found = append(found, primaryType)
+	for _, field := range typedData.Types[primaryType] {
+		found = typedData.dependencies(baseType(field.Type), found)
+	}
+	return found
+}
+
+// EncodeData returns the encoding of a struct value, which is the hash of its
+// type followed by the encodings of its fields.
+func (typedData *TypedData) EncodeData(primaryType string, data Message) ([]byte, error) {
+	fields := typedData.Types[primaryType]
+	if len(data) > len(fields) {
+		return nil, fmt.Errorf("%s: unknown fields provided", primaryType)
+	}
+	buffer := bytes.NewBuffer(typedData.TypeHash(primaryType))
+	for _, field := range fields {
+		value, ok := data[field.Name]
+		if !ok {
+			return nil, fmt.Errorf("%s: missing field %s", primaryType, field.Name)
+		}
+		encoded, err := typedData.encodeValue(field.Type, value)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %v", primaryType, field.Name, err)
+		}
+		buffer.Write(encoded)
+	}
+	return buffer.Bytes(), nil
+}
+
+// encodeValue returns the 32 byte encoding of a field value. Structs, arrays and
+// dynamic values are encoded by their hashes.
+func (typedData *TypedData) encodeValue(typ string, value interface{}) ([]byte, error) {
+	if elem, length, ok := parseArray(typ); ok {
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid %s value %v", typ, value)
+		}
+		if length >= 0 && len(items) != length {
+			return nil, fmt.Errorf("invalid %s length %d", typ, len(items))
+		}
+		var buffer bytes.Buffer
+		for _, item := range items {
+			encoded, err := typedData.encodeValue(elem, item)
+			if err != nil {
+				return nil, err
+			}
+			buffer.Write(encoded)
+		}
+		return crypto.Keccak256(buffer.Bytes()), nil
+	}
+	if _, ok := typedData.Types[typ]; ok {
+		data, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid %s value %v", typ, value)
+		}
+		return typedData.HashStruct(typ, data)
+	}
+	return encodeAtomic(typ, value)
+}
+
+// encodeAtomic returns the 32 byte encoding of an atomic or dynamic value.
+func encodeAtomic(typ string, value interface{}) ([]byte, error) {
+	switch typ {
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid string value %v", value)
+		}
+		return crypto.Keccak256([]byte(str)), nil
+
+	case "bytes":
+		blob, err := parseBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		return crypto.Keccak256(blob), nil
+
+	case "bool":
+		flag, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("invalid bool value %v", value)
+		}
+		if flag {
+			return math.PaddedBigBytes(common.Big1, 32), nil
+		}
+		return make([]byte, 32), nil
+
+	case "address":
+		str, ok := value.(string)
+		if !ok || !common.IsHexAddress(str) {
+			return nil, fmt.Errorf("invalid address value %v", value)
+		}
+		return common.LeftPadBytes(common.HexToAddress(str).Bytes(), 32), nil
+	}
+	match := sizedRegexp.FindStringSubmatch(typ)
+	if match == nil {
+		return nil, fmt.Errorf("unknown type %s", typ)
+	}
+	size, _ := strconv.Atoi(match[2])
+
+	if match[1] == "bytes" {
+		blob, err := parseBytes(value)
+		if err != nil {
+			return nil, err
+		}
+		if len(blob) != size {
+			return nil, fmt.Errorf("invalid %s length %d", typ, len(blob))
+		}
+		return common.RightPadBytes(blob, 32), nil
+	}
+	number, err := parseInteger(value)
+	if err != nil {
+		return nil, err
+	}
+	if !inRange(number, size, match[1] == "int") {
+		return nil, fmt.Errorf("%s value %v out of range", typ, number)
+	}
+	return math.PaddedBigBytes(math.U256(new(big.Int).Set(number)), 32), nil
+}
+
+// isAtomic reports whether the type is an atomic or dynamic one.
+func isAtomic(typ string) bool {
+	switch typ {
+	case "string", "bytes", "bool", "address":
+		return true
+	}
+	match := sizedRegexp.FindStringSubmatch(typ)
+	if match == nil {
+		return false
+	}
+	size, err := strconv.Atoi(match[2])
+	if err != nil || match[2][0] == '0' {
+		return false
+	}
+	if match[1] == "bytes" {
+		return size >= 1 && size <= 32
+	}
+	return size >= 8 && size <= 256 && size%8 == 0
+}
+
+// parseArray splits an array type into its element type and fixed length, which
+// is -1 for dynamic arrays.
+func parseArray(typ string) (string, int, bool) {
+	match := arrayRegexp.FindStringSubmatch(typ)
+	if match == nil {
+		return "", 0, false
+	}
+	if match[2] == "" {
+		return match[1], -1, true
+	}
+	length, err := strconv.Atoi(match[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return match[1], length, true
+}
+
+// baseType strips all array dimensions from a type.
+func baseType(typ string) string {
+	for {
+		elem, _, ok := parseArray(typ)
+		if !ok {
+			return typ
+		}
+		typ = elem
+	}
+}
+
+// parseBytes decodes a hex encoded byte value.
+func parseBytes(value interface{}) ([]byte, error) {
+	str, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid bytes value %v", value)
+	}
+	return hexutil.Decode(str)
+}
+
+// parseInteger decodes an integer given as a JSON number or as a decimal or hex
+// string.
+func parseInteger(value interface{}) (*big.Int, error) {
+	switch v := value.(type) {
+	case float64:
+		number, accuracy := big.NewFloat(v).Int(nil)
+		if accuracy != big.Exact {
+			return nil, fmt.Errorf("invalid integer value %v", v)
+		}
+		return number, nil
+	case json.Number:
+		if number, ok := new(big.Int).SetString(string(v), 10); ok {
+			return number, nil
+		}
+	case string:
+		if number, ok := new(big.Int).SetString(v, 0); ok {
+			return number, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid integer value %v", value)
+}
+
+// inRange reports whether the number fits an integer type of the given size.
+func inRange(number *big.Int, size int, signed bool) bool {
+	if !signed {
+		return number.Sign() >= 0 && number.BitLen() <= size
+	}
+	limit := new(big.Int).Lsh(common.Big1, uint(size-1))
+	return number.Cmp(new(big.Int).Neg(limit)) >= 0 && number.Cmp(limit) < 0
+}
+
+// NameValueType is a field of typed data rendered for display. The value of a
+// struct or array is the list of its fields or items, that of an atomic one its
+// textual form.
+type NameValueType struct {
+	Name  string      `json:"name"`
+	Typ   string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// Format renders the domain and the message of the typed data for display.
+func (typedData *TypedData) Format() ([]*NameValueType, error) {
+	domain, err := typedData.formatStruct(DomainType, typedData.Domain)
+	if err != nil {
+		return nil, err
+	}
+	message, err := typedData.formatStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		return nil, err
+	}
+	return []*NameValueType{
+		{Name: DomainType, Typ: DomainType, Value: domain},
+		{Name: typedData.PrimaryType, Typ: typedData.PrimaryType, Value: message},
+	}, nil
+}
+
+// formatStruct renders the fields of a struct value.
+func (typedData *TypedData) formatStruct(primaryType string, data map[string]interface{}) ([]*NameValueType, error) {
+	var output []*NameValueType
+	for _, field := range typedData.Types[primaryType] {
+		value, err := typedData.formatValue(field.Type, data[field.Name])
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: %v", primaryType, field.Name, err)
+		}
+		output = append(output, &NameValueType{Name: field.Name, Typ: field.Type, Value: value})
+	}
+	return output, nil
+}
+
+// formatValue renders a field value.
+func (typedData *TypedData) formatValue(typ string, value interface{}) (interface{}, error) {
+	if elem, _, ok := parseArray(typ); ok {
+		items, _ := value.([]interface{})
+		output := make([]*NameValueType, len(items))
+		for i, item := range items {
+			formatted, err := typedData.formatValue(elem, item)
+			if err != nil {
+				return nil, err
+			}
+			output[i] = &NameValueType{Name: strconv.Itoa(i), Typ: elem, Value: formatted}
+		}
+		return output, nil
+	}
+	if _, ok := typedData.Types[typ]; ok {
+		data, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid %s value %v", typ, value)
+		}
+		return typedData.formatStruct(typ, data)
+	}
+	if match := sizedRegexp.FindStringSubmatch(typ); match != nil && match[1] != "bytes" {
+		if number, err := parseInteger(value); err == nil {
+			return number.String(), nil
+		}
+	}
+	return fmt.Sprintf("%v", value), nil
+}
+
+// Pprint returns an indented textual rendering of the field.
+func (nvt *NameValueType) Pprint(depth int) string {
+	var output bytes.Buffer
+	output.WriteString(strings.Repeat("  ", depth))
+	output.WriteString(fmt.Sprintf("%s [%s]: ", nvt.Name, nvt.Typ))
+
+	if fields, ok := nvt.Value.([]*NameValueType); ok {
+		output.WriteString("\n")
+		for _, field := range fields {
+			output.WriteString(field.Pprint(depth + 1))
+		}
+	} else {
+		output.WriteString(fmt.Sprintf("%q\n", nvt.Value))
+	}
+	return output.String()
+}
@@ -0,0 +1,172 @@
+package typeddata
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bcos-one/BCOS/common"
+	"github.com/bcos-one/BCOS/common/hexutil"
+)
+
+// mailJSON is the example message of the EIP-712 specification.
+const mailJSON = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": 1,
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+func mailTypedData(t *testing.T) *TypedData {
+	typedData := new(TypedData)
+	if err := json.Unmarshal([]byte(mailJSON), typedData); err != nil {
+		t.Fatalf("failed to decode typed data: %v", err)
+	}
+	return typedData
+}
+
+// Tests that the example of the specification hashes to the expected values.
+func TestSignHash(t *testing.T) {
+	typedData := mailTypedData(t)
+
+	if have, want := string(typedData.EncodeType("Mail")), "Mail(Person from,Person to,string contents)Person(string name,address wallet)"; have != want {
+		t.Errorf("type encoding mismatch: have %s, want %s", have, want)
+	}
+	if have, want := typedData.TypeHash("Mail"), common.FromHex("0xa0cedeb2dc280ba39b857546d74f5549c3a1d7bdc2dd96bf881f76108e23dac2"); !bytes.Equal(have, want) {
+		t.Errorf("type hash mismatch: have %x, want %x", have, want)
+	}
+	domainSeparator, err := typedData.HashStruct(DomainType, typedData.Domain)
+	if err != nil {
+		t.Fatalf("failed to hash domain: %v", err)
+	}
+	if want := common.FromHex("0xf2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"); !bytes.Equal(domainSeparator, want) {
+		t.Errorf("domain separator mismatch: have %x, want %x", domainSeparator, want)
+	}
+	messageHash, err := typedData.HashStruct(typedData.PrimaryType, typedData.Message)
+	if err != nil {
+		t.Fatalf("failed to hash message: %v", err)
+	}
+	if want := common.FromHex("0xc52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e"); !bytes.Equal(messageHash, want) {
+		t.Errorf("message hash mismatch: have %x, want %x", messageHash, want)
+	}
+	sighash, _, err := typedData.SignHash()
+	if err != nil {
+		t.Fatalf("failed to hash typed data: %v", err)
+	}
+	if want := common.FromHex("0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2"); !bytes.Equal(sighash, want) {
+		t.Errorf("sign hash mismatch: have %x, want %x", sighash, want)
+	}
+}
+
+// Tests that malformed schemas and values are rejected.
+func TestInvalidTypedData(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*TypedData)
+		err    string
+	}{
+		{"no domain type", func(td *TypedData) { delete(td.Types, DomainType) }, "not defined"},
+		{"bad domain field", func(td *TypedData) { td.Types[DomainType][2].Type = "uint64" }, "invalid domain field"},
+		{"no primary type", func(td *TypedData) { td.PrimaryType = "Letter" }, "primary type"},
+		{"undefined field type", func(td *TypedData) { td.Types["Person"][1].Type = "Wallet" }, "undefined type"},
+		{"bad atomic size", func(td *TypedData) { td.Types["Person"][1].Type = "uint7" }, "undefined type"},
+		{"duplicate field", func(td *TypedData) { td.Types["Person"][1].Name = "name" }, "duplicate field"},
+		{"missing field", func(td *TypedData) { delete(td.Message, "contents") }, "missing field"},
+		{"extra field", func(td *TypedData) { td.Message["subject"] = "Hi" }, "unknown fields"},
+		{"bad address", func(td *TypedData) { td.Message["to"].(map[string]interface{})["wallet"] = "0x01" }, "invalid address"},
+		{"fractional integer", func(td *TypedData) { td.Domain["chainId"] = 1.5 }, "invalid integer"},
+	}
+	for _, test := range tests {
+		typedData := mailTypedData(t)
+		test.modify(typedData)
+		if _, _, err := typedData.SignHash(); err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: error mismatch: have %v, want %q", test.name, err, test.err)
+		}
+	}
+}
+
+// Tests the encoding of atomic values at the boundaries of their types.
+func TestEncodeAtomic(t *testing.T) {
+	tests := []struct {
+		typ   string
+		value interface{}
+		want  string
+		fails bool
+	}{
+		{typ: "uint8", value: "0xff", want: "0x00000000000000000000000000000000000000000000000000000000000000ff"},
+		{typ: "uint8", value: "256", fails: true},
+		{typ: "uint256", value: "-1", fails: true},
+		{typ: "int8", value: -128.0, want: "0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff80"},
+		{typ: "int8", value: 128.0, fails: true},
+		{typ: "bool", value: true, want: "0x0000000000000000000000000000000000000000000000000000000000000001"},
+		{typ: "bytes2", value: "0x0102", want: "0x0102000000000000000000000000000000000000000000000000000000000000"},
+		{typ: "bytes2", value: "0x01", fails: true},
+		{typ: "bytes33", value: "0x01", fails: true},
+	}
+	for _, test := range tests {
+		have, err := encodeAtomic(test.typ, test.value)
+		if test.fails {
+			if err == nil {
+				t.Errorf("%s %v: expected failure, have %x", test.typ, test.value, have)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %v: unexpected error: %v", test.typ, test.value, err)
+			continue
+		}
+		if hexutil.Encode(have) != test.want {
+			t.Errorf("%s %v: encoding mismatch: have %x, want %s", test.typ, test.value, have, test.want)
+		}
+	}
+}
+
+// Tests that arrays of structs are hashed item by item and rendered for display.
+func TestArrays(t *testing.T) {
+	typedData := mailTypedData(t)
+	typedData.Types["Mail"][1].Type = "Person[]"
+	typedData.Message["to"] = []interface{}{typedData.Message["to"], typedData.Message["from"]}
+
+	if have, want := string(typedData.EncodeType("Mail")), "Mail(Person from,Person[] to,string contents)Person(string name,address wallet)"; have != want {
+		t.Errorf("type encoding mismatch: have %s, want %s", have, want)
+	}
+	if _, _, err := typedData.SignHash(); err != nil {
+		t.Fatalf("failed to hash typed data: %v", err)
+	}
+	formatted, err := typedData.Format()
+	if err != nil {
+		t.Fatalf("failed to format typed data: %v", err)
+	}
+	rendered := formatted[1].Pprint(0)
+	for _, want := range []string{"Mail [Mail]:", "  to [Person[]]:", "    1 [Person]:", `      name [string]: "Cow"`} {
+		if !strings.Contains(rendered, want) {
+			t.Errorf("rendering misses %q:\n%s", want, rendered)
+		}
+	}
+}